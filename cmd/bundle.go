@@ -0,0 +1,88 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/landanqrew/mermaid-agent-documenter/internal/bundle"
+	"github.com/landanqrew/mermaid-agent-documenter/internal/config"
+	"github.com/landanqrew/mermaid-agent-documenter/internal/console"
+	"github.com/landanqrew/mermaid-agent-documenter/internal/output"
+	"github.com/landanqrew/mermaid-agent-documenter/internal/runs"
+	"github.com/spf13/cobra"
+)
+
+// exportBundleCmd represents the export-bundle command
+var exportBundleCmd = &cobra.Command{
+	Use:   "export-bundle [--run <id>] <output.zip>",
+	Short: "Zip the generated documentation for sharing",
+	Long: `Zip the generated Markdown, rendered images, and manifest into one archive.
+
+The archive has a top-level index.md linking every document and its images.
+When a current project is set, its out/ directory is bundled; otherwise the
+configured outDir is used.
+
+With --run, only the documents saved by that run are bundled, as they were when the
+run finished, together with the images rendered from them and the run's record
+(run.json). A unique prefix of a run ID is accepted.
+
+Examples:
+  mad export-bundle docs.zip
+  mad export-bundle --run 3f2a9c login-flow.zip`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		runID, _ := cmd.Flags().GetString("run")
+		zipPath := args[0]
+		if !strings.EqualFold(filepath.Ext(zipPath), ".zip") {
+			zipPath += ".zip"
+		}
+
+		cfg, err := config.Load()
+		if err != nil {
+			console.Printf("Error loading config: %v\n", err)
+			os.Exit(1)
+		}
+		outputDir, logsDir := runDirectories(cfg)
+		if strings.HasPrefix(outputDir, "~") {
+			if home, err := os.UserHomeDir(); err == nil {
+				outputDir = strings.Replace(outputDir, "~", home, 1)
+			}
+		}
+
+		var files []bundle.File
+		if runID != "" {
+			snapshot, err := runs.Load(logsDir, runID)
+			if err != nil {
+				console.Printf("Error loading run: %v\n", err)
+				os.Exit(1)
+			}
+			console.Printf("📦 Bundling run %s (%s)\n", snapshot.RunID, snapshot.Model)
+			files, err = bundle.FromSnapshot(snapshot, outputDir)
+		} else {
+			console.Printf("📦 Bundling %s\n", outputDir)
+			files, err = bundle.FromOutputDir(outputDir, output.IndexInfo{})
+		}
+		if err != nil {
+			console.Printf("Error collecting documentation: %v\n", err)
+			os.Exit(1)
+		}
+
+		if err := bundle.Write(zipPath, files); err != nil {
+			console.Printf("Error writing bundle: %v\n", err)
+			os.Exit(1)
+		}
+		for _, file := range files {
+			console.Printf("  📄 %s\n", file.Name)
+		}
+		console.Printf("✅ Wrote %d files to %s\n", len(files), zipPath)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(exportBundleCmd)
+	exportBundleCmd.Flags().String("run", "", "Bundle only the documents saved by this run (ID or unique prefix)")
+}
@@ -0,0 +1,123 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/landanqrew/mermaid-agent-documenter/internal/config"
+	"github.com/landanqrew/mermaid-agent-documenter/internal/console"
+	"github.com/landanqrew/mermaid-agent-documenter/internal/runs"
+	"github.com/landanqrew/mermaid-agent-documenter/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+// cleanCmd represents the clean command
+var cleanCmd = &cobra.Command{
+	Use:   "clean",
+	Short: "Delete generated outputs, logs, and run snapshots",
+	Long: `Delete the generated files from the current project's out/ directory, its execution
+logs, and the run snapshots in logs/runs/, to start the workspace over. Without a current
+project, the global output directory and ~/mermaid-agent-documenter/logs are cleaned.
+
+Without flags everything is cleaned; pass --out, --logs, or --runs to pick. The directories
+themselves are kept. transcripts/ is never touched, and nothing outside the project root
+(or ~/mermaid-agent-documenter) is deleted, even when outDir points elsewhere.
+
+Examples:
+  mad clean              # Clear out/, logs/, and run snapshots (asks first)
+  mad clean --out        # Clear only the generated documentation
+  mad clean --runs --yes # Delete run snapshots without asking`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cleanOut, _ := cmd.Flags().GetBool("out")
+		cleanLogs, _ := cmd.Flags().GetBool("logs")
+		cleanRuns, _ := cmd.Flags().GetBool("runs")
+		yes, _ := cmd.Flags().GetBool("yes")
+		if !cleanOut && !cleanLogs && !cleanRuns {
+			cleanOut, cleanLogs, cleanRuns = true, true, true
+		}
+
+		cfg, err := config.Load()
+		if err != nil {
+			console.Printf("Error loading config: %v\n", err)
+			os.Exit(1)
+		}
+		outputDir, logsDir := runDirectories(cfg)
+		if strings.HasPrefix(outputDir, "~") {
+			if home, err := os.UserHomeDir(); err == nil {
+				outputDir = strings.Replace(outputDir, "~", home, 1)
+			}
+		}
+		root := config.Dir()
+		if cfg.CurrentProject != nil {
+			root = cfg.CurrentProject.RootDir
+		}
+
+		// Each target lists its entries; logs skip runs/, which --runs covers
+		type cleanTarget struct {
+			label string
+			dir   string
+			skip  []string
+		}
+		var targets []cleanTarget
+		if cleanOut {
+			targets = append(targets, cleanTarget{label: "outputs", dir: outputDir})
+		}
+		if cleanLogs {
+			targets = append(targets, cleanTarget{label: "logs", dir: logsDir, skip: []string{runs.DirName}})
+		}
+		if cleanRuns {
+			targets = append(targets, cleanTarget{label: "run snapshots", dir: filepath.Join(logsDir, runs.DirName)})
+		}
+
+		var paths []string
+		for _, target := range targets {
+			if err := workspace.CheckTarget(root, target.dir); err != nil {
+				console.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+			entries, err := workspace.Entries(target.dir, target.skip...)
+			if err != nil {
+				console.Printf("Error reading %s: %v\n", target.dir, err)
+				os.Exit(1)
+			}
+			console.Printf("🗑️  %s: %d items in %s\n", target.label, len(entries), target.dir)
+			paths = append(paths, entries...)
+		}
+		if len(paths) == 0 {
+			console.Println("✨ Nothing to clean")
+			return
+		}
+
+		if !yes {
+			if !stdinIsTerminal() {
+				console.Println("Error: confirmation needs a terminal; pass --yes to clean without asking")
+				os.Exit(1)
+			}
+			console.Printf("Delete these %d items? Transcripts are not touched. (y/N): ", len(paths))
+			if !isYes(readLine()) {
+				console.Println("Cancelled; nothing was deleted.")
+				return
+			}
+		}
+
+		removed, err := workspace.Remove(paths)
+		if err != nil {
+			console.Printf("Error: %v (%d of %d items removed)\n", err, removed, len(paths))
+			os.Exit(1)
+		}
+		console.Printf("🧹 Removed %d items\n", removed)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(cleanCmd)
+
+	cleanCmd.Flags().Bool("out", false, "Delete the generated documentation in the output directory")
+	cleanCmd.Flags().Bool("logs", false, "Delete the execution logs (except run snapshots)")
+	cleanCmd.Flags().Bool("runs", false, "Delete the run snapshots in logs/runs/")
+	cleanCmd.Flags().BoolP("yes", "y", false, "Delete without asking for confirmation")
+}
@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/landanqrew/mermaid-agent-documenter/internal/agent"
+	"github.com/landanqrew/mermaid-agent-documenter/internal/console"
+	"github.com/landanqrew/mermaid-agent-documenter/internal/logs"
+)
+
+// compareProviderNames lists the providers a --compare run tries, in order
+var compareProviderNames = []string{"openai", "anthropic", "google"}
+
+// providerComparison is the outcome of running one provider in a --compare run
+type providerComparison struct {
+	Provider string
+	Model    string
+	Results  []*agent.RunResult
+	Err      error
+}
+
+// providerConfig returns a copy of the config with another provider selected
+func providerConfig(cfg *Config, provider string) *Config {
+	copied := *cfg
+	copied.Provider = provider
+	return &copied
+}
+
+// comparisonProviders returns the providers with an API key (or Vertex AI) configured
+func comparisonProviders(cfg *Config) []string {
+	var available []string
+	for _, provider := range compareProviderNames {
+		if getAPIKey(provider, cfg) != "" || usesVertex(provider, cfg) {
+			available = append(available, provider)
+		}
+	}
+	return available
+}
+
+// providerAgentConfig copies the run's agent configuration for another
+// provider, writing into out/<provider>/
+func providerAgentConfig(base *agent.AgentConfig, cfg *Config, provider, outputDir string) *agent.AgentConfig {
+	agentConfig := *base
+	agentConfig.Provider = provider
+	agentConfig.Model = resolveModel(cfg, provider)
+	agentConfig.APIKey = getAPIKey(provider, cfg)
+	agentConfig.OutputDir = filepath.Join(outputDir, provider)
+
+	// Rate limits are per provider; determinism carries over from the base options
+	options := providerOptions(providerConfig(cfg, provider))
+	if base.ProviderOptions.Temperature != nil {
+		options = options.Deterministic()
+	}
+	agentConfig.ProviderOptions = options
+	return &agentConfig
+}
+
+// runComparison runs the transcript through every provider with a key and
+// prints a side-by-side summary. It stops early when the context is cancelled.
+func runComparison(ctx context.Context, segments []string, base *agent.AgentConfig, cfg *Config, outputDir string) ([]providerComparison, error) {
+	available := comparisonProviders(cfg)
+	if len(available) == 0 {
+		return nil, fmt.Errorf("no providers have API keys configured; add one with: mad config secrets set <provider> \"your-api-key\"")
+	}
+	console.Printf("⚖️  Comparing %d providers: %s\n", len(available), strings.Join(available, ", "))
+
+	var comparisons []providerComparison
+	for _, provider := range available {
+		agentConfig := providerAgentConfig(base, cfg, provider, outputDir)
+		console.Println()
+		console.Printf("━━━━━━━━━━ %s · %s ━━━━━━━━━━\n", provider, agentConfig.Model)
+
+		results, err := runSegments(ctx, segments, agentConfig, cfg.Limits.RunTimeoutSec, base.TranscriptName)
+		if err != nil && !errors.Is(ctx.Err(), context.Canceled) {
+			console.Printf("❌ %s run failed: %v\n", provider, err)
+		}
+		comparisons = append(comparisons, providerComparison{Provider: provider, Model: agentConfig.Model, Results: results, Err: err})
+		if errors.Is(ctx.Err(), context.Canceled) {
+			return comparisons, ctx.Err()
+		}
+	}
+	return comparisons, nil
+}
+
+// anyProviderSucceeded reports whether at least one provider's run completed
+func anyProviderSucceeded(comparisons []providerComparison) bool {
+	for _, c := range comparisons {
+		if c.Err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// printComparison prints one row per provider with the totals across segments
+func printComparison(comparisons []providerComparison, outputDir string) {
+	console.Println()
+	console.Println("⚖️  Provider Comparison")
+	console.Println("══════════════════════")
+
+	w := tabwriter.NewWriter(console.Output(), 0, 0, 2, ' ', 0)
+	console.Fprintf(w, "PROVIDER\tMODEL\tRESULT\tSTEPS\tTOKENS\tCOST\tARTIFACTS\tDURATION\tLATENCY P50/P95\n")
+	for _, c := range comparisons {
+		var steps, tokens, artifacts int
+		var cost float64
+		var duration time.Duration
+		var latencies []int64
+		for _, result := range c.Results {
+			steps += result.Steps
+			tokens += result.TotalTokens()
+			cost += result.EstimatedCostUsd
+			artifacts += len(result.Artifacts)
+			duration += result.Duration()
+			latencies = append(latencies, result.LatenciesMs...)
+		}
+		latency := "-"
+		if stats := logs.SummarizeLatency(latencies); stats.Count > 0 {
+			latency = fmt.Sprintf("%s / %s", stats.P50.Round(100*time.Millisecond), stats.P95.Round(100*time.Millisecond))
+		}
+
+		status := "success"
+		if c.Err != nil {
+			status = "failed"
+		}
+		console.Fprintf(w, "%s\t%s\t%s\t%d\t%d\t$%.4f\t%d\t%s\t%s\n",
+			c.Provider, c.Model, status, steps, tokens, cost, artifacts, duration.Round(time.Second), latency)
+	}
+	w.Flush()
+	console.Printf("\nOutput for each provider is in %s\n", filepath.Join(outputDir, "<provider>"))
+}
@@ -4,14 +4,23 @@ Copyright © 2025 NAME HERE <EMAIL ADDRESS>
 package cmd
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/landanqrew/mermaid-agent-documenter/internal/agent"
+	"github.com/landanqrew/mermaid-agent-documenter/internal/config"
+	"github.com/landanqrew/mermaid-agent-documenter/internal/console"
+	"github.com/landanqrew/mermaid-agent-documenter/internal/output"
 	"github.com/landanqrew/mermaid-agent-documenter/internal/providers"
+	"github.com/landanqrew/mermaid-agent-documenter/internal/secrets"
 	"github.com/spf13/cobra"
 )
 
@@ -25,6 +34,7 @@ This command provides subcommands to manage:
 - API keys for different model providers (secrets)
 - Current project settings (project)
 - Default provider and model selection (provider, model)
+- Moving configuration between machines (export, import)
 - View current configuration`,
 }
 
@@ -48,12 +58,25 @@ Supported providers:
 - anthropic: Anthropic API key
 - google: Google AI API key
 
-Example:
-  mad config secrets set openai "sk-your-openai-key-here"`,
+Instead of a raw key you can store an environment variable reference such as
+'${OPENAI_API_KEY}'. It is resolved when the key is used, so config.json never
+holds the secret itself. Quote it with single quotes so your shell doesn't expand it.
+
+Backends:
+- file (default): stored in config.json (mode 0600)
+- keychain: stored in the OS credential store (macOS Keychain, libsecret, Windows Credential Manager)
+
+Keys are looked up in the keychain first (when selected), then config.json, then environment variables.
+
+Examples:
+  mad config secrets set openai "sk-your-openai-key-here"
+  mad config secrets set openai '${OPENAI_API_KEY}'
+  mad config secrets set --backend keychain openai "sk-your-openai-key-here"`,
 	Args: cobra.ExactArgs(2),
 	Run: func(cmd *cobra.Command, args []string) {
 		provider := strings.ToLower(args[0])
 		apiKey := args[1]
+		backendName, _ := cmd.Flags().GetString("backend")
 
 		// Validate provider
 		validProviders := map[string]bool{
@@ -63,40 +86,44 @@ Example:
 		}
 
 		if !validProviders[provider] {
-			fmt.Printf("Error: Invalid provider '%s'. Supported providers: openai, anthropic, google\n", provider)
+			console.Printf("Error: Invalid provider '%s'. Supported providers: openai, anthropic, google\n", provider)
 			os.Exit(1)
 		}
 
 		// Load current config
-		config, err := loadConfig()
+		cfg, err := config.Load()
 		if err != nil {
-			fmt.Printf("Error loading config: %v\n", err)
+			console.Printf("Error loading config: %v\n", err)
 			os.Exit(1)
 		}
 
-		// Initialize secrets if not exists
-		if config.Secrets == nil {
-			config.Secrets = make(map[string]string)
+		backend, err := getSecretBackend(backendName, cfg)
+		if err != nil {
+			console.Printf("Error: %v\n", err)
+			os.Exit(1)
 		}
 
 		// Set the API key
-		config.Secrets[provider] = apiKey
-
-		// Save config
-		configDir := getConfigDir()
-		configPath := filepath.Join(configDir, "config.json")
-		data, err := json.MarshalIndent(config, "", "  ")
-		if err != nil {
-			fmt.Printf("Error marshaling config: %v\n", err)
+		if err := backend.Set(provider, apiKey); err != nil {
+			console.Printf("Error storing API key: %v\n", err)
 			os.Exit(1)
 		}
 
-		if err := os.WriteFile(configPath, data, 0600); err != nil {
-			fmt.Printf("Error saving config: %v\n", err)
+		if backend.Name() == "keychain" {
+			// Don't leave a plaintext copy behind in config.json
+			if cfg.Secrets != nil {
+				delete(cfg.Secrets, provider)
+			}
+			cfg.SecretsBackend = "keychain"
+		}
+
+		// Save config
+		if err := config.Save(cfg); err != nil {
+			console.Printf("Error saving config: %v\n", err)
 			os.Exit(1)
 		}
 
-		fmt.Printf("✅ API key for '%s' has been set successfully\n", provider)
+		console.Printf("✅ API key for '%s' has been set successfully (%s backend)\n", provider, backend.Name())
 	},
 }
 
@@ -108,39 +135,54 @@ var secretsListCmd = &cobra.Command{
 
 This shows which providers have API keys configured.`,
 	Run: func(cmd *cobra.Command, args []string) {
-		config, err := loadConfig()
+		cfg, err := config.Load()
 		if err != nil {
-			fmt.Printf("Error loading config: %v\n", err)
+			console.Printf("Error loading config: %v\n", err)
 			os.Exit(1)
 		}
 
-		fmt.Println("🔑 Configured API Keys:")
-		fmt.Println()
+		console.Println("🔑 Configured API Keys:")
+		console.Println()
 
 		providers := []string{"openai", "anthropic", "google"}
 		hasAnyKeys := false
 
 		for _, provider := range providers {
-			if config.Secrets != nil && config.Secrets[provider] != "" {
+			if cfg.SecretsBackend == "keychain" {
+				keychain := &secrets.KeychainBackend{}
+				if _, err := keychain.Get(provider); err == nil {
+					console.Printf("✅ %s: stored in OS keychain\n", provider)
+					hasAnyKeys = true
+					continue
+				}
+			}
+
+			if cfg.Secrets != nil && cfg.Secrets[provider] != "" {
 				// Show first 4 and last 4 characters for verification
-				key := config.Secrets[provider]
+				key := cfg.Secrets[provider]
 				maskedKey := ""
-				if len(key) > 8 {
+				if isSecretReference(key) {
+					// Environment references are not secret themselves
+					maskedKey = key
+					if _, ok := expandSecret(key); !ok {
+						maskedKey += " (unset)"
+					}
+				} else if len(key) > 8 {
 					maskedKey = key[:4] + "..." + key[len(key)-4:]
 				} else {
 					maskedKey = "***hidden***"
 				}
-				fmt.Printf("✅ %s: %s\n", provider, maskedKey)
+				console.Printf("✅ %s: %s\n", provider, maskedKey)
 				hasAnyKeys = true
 			} else {
-				fmt.Printf("❌ %s: Not configured\n", provider)
+				console.Printf("❌ %s: Not configured\n", provider)
 			}
 		}
 
 		if !hasAnyKeys {
-			fmt.Println()
-			fmt.Println("No API keys are currently configured.")
-			fmt.Println("Use 'mad config secrets set <provider> <api-key>' to configure API keys.")
+			console.Println()
+			console.Println("No API keys are currently configured.")
+			console.Println("Use 'mad config secrets set <provider> <api-key>' to configure API keys.")
 		}
 	},
 }
@@ -175,7 +217,7 @@ Examples:
 		if !filepath.IsAbs(projectPath) {
 			cwd, err := os.Getwd()
 			if err != nil {
-				fmt.Printf("Error getting current directory: %v\n", err)
+				console.Printf("Error getting current directory: %v\n", err)
 				os.Exit(1)
 			}
 			projectPath = filepath.Join(cwd, projectPath)
@@ -183,14 +225,14 @@ Examples:
 
 		// Verify the directory exists
 		if _, err := os.Stat(projectPath); os.IsNotExist(err) {
-			fmt.Printf("Error: Project directory '%s' does not exist\n", projectPath)
-			fmt.Println("Make sure to create the project first with 'mad init <project-name>'")
+			console.Printf("Error: Project directory '%s' does not exist\n", projectPath)
+			console.Println("Make sure to create the project first with 'mad init <project-name>'")
 			os.Exit(1)
 		}
 
 		// Verify it's a directory
 		if info, err := os.Stat(projectPath); err != nil || !info.IsDir() {
-			fmt.Printf("Error: '%s' is not a directory\n", projectPath)
+			console.Printf("Error: '%s' is not a directory\n", projectPath)
 			os.Exit(1)
 		}
 
@@ -199,15 +241,15 @@ Examples:
 		for _, dir := range requiredDirs {
 			dirPath := filepath.Join(projectPath, dir)
 			if _, err := os.Stat(dirPath); os.IsNotExist(err) {
-				fmt.Printf("Warning: Required directory '%s' not found in project\n", dirPath)
-				fmt.Println("The project may not be properly initialized.")
+				console.Printf("Warning: Required directory '%s' not found in project\n", dirPath)
+				console.Println("The project may not be properly initialized.")
 			}
 		}
 
 		// Load current config
-		config, err := loadConfig()
+		cfg, err := config.Load()
 		if err != nil {
-			fmt.Printf("Error loading config: %v\n", err)
+			console.Printf("Error loading config: %v\n", err)
 			os.Exit(1)
 		}
 
@@ -215,28 +257,20 @@ Examples:
 		projectName := filepath.Base(projectPath)
 
 		// Update current project
-		config.CurrentProject = &ProjectConfig{
+		cfg.CurrentProject = &ProjectConfig{
 			Name:      projectName,
 			RootDir:   projectPath,
 			CreatedAt: fmt.Sprintf("Updated %s", "now"), // Could use proper timestamp
 		}
 
 		// Save config
-		configDir := getConfigDir()
-		configPath := filepath.Join(configDir, "config.json")
-		data, err := json.MarshalIndent(config, "", "  ")
-		if err != nil {
-			fmt.Printf("Error marshaling config: %v\n", err)
-			os.Exit(1)
-		}
-
-		if err := os.WriteFile(configPath, data, 0600); err != nil {
-			fmt.Printf("Error saving config: %v\n", err)
+		if err := config.Save(cfg); err != nil {
+			console.Printf("Error saving config: %v\n", err)
 			os.Exit(1)
 		}
 
-		fmt.Printf("✅ Current project set to: %s\n", projectPath)
-		fmt.Printf("📁 Project name: %s\n", projectName)
+		console.Printf("✅ Current project set to: %s\n", projectPath)
+		console.Printf("📁 Project name: %s\n", projectName)
 	},
 }
 
@@ -246,25 +280,25 @@ var projectListCmd = &cobra.Command{
 	Short: "List current project",
 	Long: `List current project settings.`,
 	Run: func(cmd *cobra.Command, args []string) {
-		config, err := loadConfig()
+		cfg, err := config.Load()
 		if err != nil {
-			fmt.Printf("Error loading config: %v\n", err)
+			console.Printf("Error loading config: %v\n", err)
 			os.Exit(1)
 		}
 
 		currentProject := ""
-		if config.CurrentProject != nil {
-			currentProject = config.CurrentProject.Name
+		if cfg.CurrentProject != nil {
+			currentProject = cfg.CurrentProject.Name
 		}
 
 		if currentProject == "" {
-			fmt.Println("No current project defined")
-			fmt.Println("You can set your current project configurations with 'mad config project set <project-directory>'")
+			console.Println("No current project defined")
+			console.Println("You can set your current project configurations with 'mad config project set <project-directory>'")
 			return
 		}
 		
-		fmt.Printf("Current Project: %s\n", currentProject)
-		fmt.Printf("Project Directory: %s\n", config.CurrentProject.RootDir)
+		console.Printf("Current Project: %s\n", currentProject)
+		console.Printf("Project Directory: %s\n", cfg.CurrentProject.RootDir)
 	},
 }
 
@@ -288,8 +322,12 @@ Supported providers:
 - anthropic: Anthropic Claude models
 - google: Google Gemini models
 
+When no API key is configured for the provider and stdin is a terminal, you are
+offered a hidden prompt to save one right away. Pass --no-prompt to only warn.
+
 Example:
-  mad config provider set openai`,
+  mad config provider set openai
+  mad config provider set anthropic --no-prompt`,
 	Args: cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		provider := strings.ToLower(args[0])
@@ -302,42 +340,78 @@ Example:
 		}
 
 		if !validProviders[provider] {
-			fmt.Printf("Error: Invalid provider '%s'. Supported providers: openai, anthropic, google\n", provider)
+			console.Printf("Error: Invalid provider '%s'. Supported providers: openai, anthropic, google\n", provider)
 			os.Exit(1)
 		}
 
 		// Load current config
-		config, err := loadConfig()
+		cfg, err := config.Load()
 		if err != nil {
-			fmt.Printf("Error loading config: %v\n", err)
+			console.Printf("Error loading config: %v\n", err)
 			os.Exit(1)
 		}
 
-		// Check if API key is configured for this provider
-		if config.Secrets == nil || config.Secrets[provider] == "" {
-			fmt.Printf("⚠️  Warning: No API key configured for '%s'\n", provider)
-			fmt.Printf("   Configure it using: mad config secrets set %s \"your-api-key\"\n", provider)
-			fmt.Println()
+		// Check if API key is configured for this provider, offering to set it on a terminal
+		noPrompt, _ := cmd.Flags().GetBool("no-prompt")
+		if getAPIKey(provider, cfg) == "" && !usesVertex(provider, cfg) {
+			if noPrompt || !stdinIsTerminal() || !promptForAPIKey(provider, cfg) {
+				console.Printf("⚠️  Warning: No API key configured for '%s'\n", provider)
+				console.Printf("   Configure it using: mad config secrets set %s \"your-api-key\"\n", provider)
+				console.Println()
+			}
 		}
 
 		// Set the provider
-		config.Provider = provider
+		cfg.Provider = provider
 
 		// Save config
-		configDir := getConfigDir()
-		configPath := filepath.Join(configDir, "config.json")
-		data, err := json.MarshalIndent(config, "", "  ")
+		if err := config.Save(cfg); err != nil {
+			console.Printf("Error saving config: %v\n", err)
+			os.Exit(1)
+		}
+
+		console.Printf("✅ Default provider set to: %s\n", provider)
+	},
+}
+
+// providerSetVertexCmd represents the provider set-vertex command
+var providerSetVertexCmd = &cobra.Command{
+	Use:   "set-vertex <project> <location>",
+	Short: "Use Google Vertex AI for the google provider",
+	Long: `Configure the google provider to use the Vertex AI backend instead of an AI Studio API key.
+
+Vertex AI authenticates with Application Default Credentials, so make sure you have run
+'gcloud auth application-default login' (or set GOOGLE_APPLICATION_CREDENTIALS) first.
+When no Vertex project/location is configured, the API-key backend is used.
+
+Example:
+  mad config provider set-vertex my-gcp-project us-central1`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		project := args[0]
+		location := args[1]
+
+		// Load current config
+		cfg, err := config.Load()
 		if err != nil {
-			fmt.Printf("Error marshaling config: %v\n", err)
+			console.Printf("Error loading config: %v\n", err)
 			os.Exit(1)
 		}
 
-		if err := os.WriteFile(configPath, data, 0600); err != nil {
-			fmt.Printf("Error saving config: %v\n", err)
+		cfg.VertexProject = project
+		cfg.VertexLocation = location
+
+		// Save config
+		if err := config.Save(cfg); err != nil {
+			console.Printf("Error saving config: %v\n", err)
 			os.Exit(1)
 		}
 
-		fmt.Printf("✅ Default provider set to: %s\n", provider)
+		console.Printf("✅ Vertex AI configured (project: %s, location: %s)\n", project, location)
+		if cfg.Provider != "google" {
+			console.Println("ℹ️  Vertex AI is only used by the google provider.")
+			console.Println("   Switch to it with: mad config provider set google")
+		}
 	},
 }
 
@@ -347,14 +421,14 @@ var providerListCmd = &cobra.Command{
 	Short: "List available providers and current selection",
 	Long:  `List all available LLM providers and show which one is currently selected as default.`,
 	Run: func(cmd *cobra.Command, args []string) {
-		config, err := loadConfig()
+		cfg, err := config.Load()
 		if err != nil {
-			fmt.Printf("Error loading config: %v\n", err)
+			console.Printf("Error loading config: %v\n", err)
 			os.Exit(1)
 		}
 
-		fmt.Println("🤖 Available LLM Providers:")
-		fmt.Println()
+		console.Println("🤖 Available LLM Providers:")
+		console.Println()
 
 		providers := []struct {
 			name string
@@ -366,15 +440,15 @@ var providerListCmd = &cobra.Command{
 		}
 
 		for _, p := range providers {
-			if config.Provider == p.name {
-				fmt.Printf("✅ %s: %s (current)\n", p.name, p.desc)
+			if cfg.Provider == p.name {
+				console.Printf("✅ %s: %s (current)\n", p.name, p.desc)
 			} else {
-				fmt.Printf("○ %s: %s\n", p.name, p.desc)
+				console.Printf("○ %s: %s\n", p.name, p.desc)
 			}
 		}
 
-		fmt.Println()
-		fmt.Printf("Current default: %s\n", config.Provider)
+		console.Println()
+		console.Printf("Current default: %s\n", cfg.Provider)
 	},
 }
 
@@ -396,9 +470,13 @@ var modelSetCmd = &cobra.Command{
 You can use any model name that the provider supports. The system will attempt to use
 the model you specify, even if it's not in our known models list.
 
+You can also give a model alias such as sonnet (see 'mad config model alias'). The alias
+is saved, so changing what it points to changes the model used.
+
 Examples:
   mad config model set gpt-4o           # Known OpenAI model
   mad config model set claude-3-haiku   # Known Anthropic model
+  mad config model set sonnet           # Alias for claude-3-5-sonnet-20241022
   mad config model set custom-model-xyz # Custom/unknown model (will attempt to use)
 
 Note: If you use a custom model that's not in our known list, the system will still
@@ -408,34 +486,27 @@ try to use it. You'll get an error only if the provider's API rejects the model
 		model := args[0]
 
 		// Load current config
-		config, err := loadConfig()
+		cfg, err := config.Load()
 		if err != nil {
-			fmt.Printf("Error loading config: %v\n", err)
+			console.Printf("Error loading config: %v\n", err)
 			os.Exit(1)
 		}
 
 		// Initialize models map if not exists
-		if config.Models == nil {
-			config.Models = make(map[string]string)
+		if cfg.Models == nil {
+			cfg.Models = make(map[string]string)
 		}
 
-		// Check if this is a known model
-		isKnown := isKnownModel(config.Provider, model)
+		// Check if this is a known model, looking through aliases
+		resolved := cfg.ResolveModelAlias(model)
+		isKnown := isKnownModel(cfg.Provider, resolved)
 
 		// Set the model for the current provider
-		config.Models[config.Provider] = model
+		cfg.Models[cfg.Provider] = model
 
 		// Save config
-		configDir := getConfigDir()
-		configPath := filepath.Join(configDir, "config.json")
-		data, err := json.MarshalIndent(config, "", "  ")
-		if err != nil {
-			fmt.Printf("Error marshaling config: %v\n", err)
-			os.Exit(1)
-		}
-
-		if err := os.WriteFile(configPath, data, 0600); err != nil {
-			fmt.Printf("Error saving config: %v\n", err)
+		if err := config.Save(cfg); err != nil {
+			console.Printf("Error saving config: %v\n", err)
 			os.Exit(1)
 		}
 
@@ -444,17 +515,136 @@ try to use it. You'll get an error only if the provider's API rejects the model
 			modelType = "custom"
 		}
 
-		fmt.Printf("✅ Model for '%s' set to: %s (%s)\n", config.Provider, model, modelType)
+		if resolved != model {
+			console.Printf("✅ Model for '%s' set to: %s → %s (%s)\n", cfg.Provider, model, resolved, modelType)
+		} else {
+			console.Printf("✅ Model for '%s' set to: %s (%s)\n", cfg.Provider, model, modelType)
+		}
 
 		if !isKnown {
-			fmt.Println()
-			fmt.Println("ℹ️  Note: This appears to be a custom model not in our known list.")
-			fmt.Println("   The system will attempt to use it, but it may not be available.")
-			fmt.Println("   Check the provider's documentation for the correct model name.")
+			console.Println()
+			console.Println("ℹ️  Note: This appears to be a custom model not in our known list.")
+			console.Println("   The system will attempt to use it, but it may not be available.")
+			console.Println("   Check the provider's documentation for the correct model name.")
 		}
 	},
 }
 
+// modelAliasCmd represents the model alias command
+var modelAliasCmd = &cobra.Command{
+	Use:   "alias [name] [model]",
+	Short: "Manage friendly names for model IDs",
+	Long: `Give a provider model ID a friendly name that can be used anywhere a model is
+expected: 'mad config model set', 'mad summarize --model', and at run time. Aliases
+are resolved before the provider is called and are matched case-insensitively.
+
+Built-in aliases (sonnet, haiku, gpt4o, flash) are always available; an alias you
+set with the same name overrides the built-in one.
+
+Examples:
+  mad config model alias                                    # List aliases
+  mad config model alias sonnet claude-3-5-sonnet-20241022  # Set an alias
+  mad config model alias fast                               # Show one alias
+  mad config model alias fast --remove                      # Remove an alias`,
+	Args: cobra.RangeArgs(0, 2),
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg, err := config.Load()
+		if err != nil {
+			console.Printf("Error loading config: %v\n", err)
+			os.Exit(1)
+		}
+
+		aliases := cfg.ModelAliasMap()
+		if len(args) == 0 {
+			names := make([]string, 0, len(aliases))
+			for name := range aliases {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+
+			console.Println("🏷️  Model aliases:")
+			for _, name := range names {
+				source := "built-in"
+				if _, custom := cfg.ModelAliases[name]; custom {
+					source = "custom"
+				}
+				console.Printf("  %-10s → %s (%s)\n", name, aliases[name], source)
+			}
+			return
+		}
+
+		name := strings.ToLower(strings.TrimSpace(args[0]))
+		remove, _ := cmd.Flags().GetBool("remove")
+		switch {
+		case remove:
+			if len(args) != 1 {
+				console.Println("Error: Usage is 'mad config model alias <name> --remove'")
+				os.Exit(1)
+			}
+			if _, ok := cfg.ModelAliases[name]; !ok {
+				if _, builtin := config.BuiltinModelAliases()[name]; builtin {
+					console.Printf("Error: '%s' is a built-in alias and cannot be removed; set it to another model instead\n", name)
+				} else {
+					console.Printf("Error: No alias named '%s'\n", name)
+				}
+				os.Exit(1)
+			}
+			delete(cfg.ModelAliases, name)
+			if err := config.Save(cfg); err != nil {
+				console.Printf("Error saving config: %v\n", err)
+				os.Exit(1)
+			}
+			console.Printf("✅ Removed alias '%s'\n", name)
+			if model, builtin := config.BuiltinModelAliases()[name]; builtin {
+				console.Printf("ℹ️  '%s' now uses the built-in alias for %s\n", name, model)
+			}
+		case len(args) == 1:
+			model, ok := aliases[name]
+			if !ok {
+				console.Printf("Error: No alias named '%s'\n", name)
+				os.Exit(1)
+			}
+			console.Printf("%s → %s\n", name, model)
+		default:
+			model := strings.TrimSpace(args[1])
+			if name == "" || model == "" || strings.ContainsAny(name, " \t") {
+				console.Println("Error: Alias names and models must be non-empty and alias names cannot contain spaces")
+				os.Exit(1)
+			}
+			if _, ok := aliases[strings.ToLower(model)]; ok {
+				console.Printf("Error: '%s' is itself an alias; aliases must point to a model ID\n", model)
+				os.Exit(1)
+			}
+			if cfg.ModelAliases == nil {
+				cfg.ModelAliases = make(map[string]string)
+			}
+			cfg.ModelAliases[name] = model
+			if err := config.Save(cfg); err != nil {
+				console.Printf("Error saving config: %v\n", err)
+				os.Exit(1)
+			}
+			console.Printf("✅ Alias '%s' set to: %s\n", name, model)
+		}
+	},
+}
+
+// formatCapabilities renders a model's capabilities as a " — ..." suffix, or "" if unknown
+func formatCapabilities(model providers.ModelInfo) string {
+	if summary := model.CapabilitySummary(); summary != "" {
+		return " — " + summary
+	}
+	return ""
+}
+
+// filterChatModels hides models that cannot be used for chat and notes how many were hidden
+func filterChatModels(provider string, models []providers.ModelInfo) []providers.ModelInfo {
+	chat := providers.FilterChatModels(provider, models)
+	if hidden := len(models) - len(chat); hidden > 0 {
+		console.Printf("ℹ️  Hiding %d models that cannot be used for chat (use --all to show them)\n", hidden)
+	}
+	return chat
+}
+
 // getKnownModels returns a map of known models for each provider
 func getKnownModels() map[string][]string {
 	return map[string][]string{
@@ -501,96 +691,156 @@ func isKnownModel(provider, model string) bool {
 	return false
 }
 
+// modelUnsetCmd represents the model unset command
+var modelUnsetCmd = &cobra.Command{
+	Use:   "unset",
+	Short: "Clear the model for a provider",
+	Long: `Clear the configured model for the current provider (or the one given with --provider),
+so the provider's built-in default model is used on the next run.
+
+Examples:
+  mad config model unset                       # Current provider
+  mad config model unset --provider anthropic  # Specific provider`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		// Load current config
+		cfg, err := config.Load()
+		if err != nil {
+			console.Printf("Error loading config: %v\n", err)
+			os.Exit(1)
+		}
+
+		provider, _ := cmd.Flags().GetString("provider")
+		if provider == "" {
+			provider = cfg.Provider
+		}
+		provider = strings.ToLower(provider)
+
+		// Validate provider
+		validProviders := map[string]bool{
+			"openai":    true,
+			"anthropic": true,
+			"google":    true,
+		}
+
+		if !validProviders[provider] {
+			console.Printf("Error: Invalid provider '%s'. Supported providers: openai, anthropic, google\n", provider)
+			os.Exit(1)
+		}
+
+		builtIn := defaultModel(provider)
+
+		if cfg.Models[provider] == "" {
+			console.Printf("ℹ️  No model is set for '%s'; it already uses the built-in default (%s)\n", provider, builtIn)
+			return
+		}
+
+		previous := cfg.Models[provider]
+		delete(cfg.Models, provider)
+
+		// Save config
+		if err := config.Save(cfg); err != nil {
+			console.Printf("Error saving config: %v\n", err)
+			os.Exit(1)
+		}
+
+		console.Printf("✅ Model for '%s' unset (was: %s)\n", provider, previous)
+		console.Printf("   The next run will use the built-in default: %s\n", builtIn)
+	},
+}
+
 // modelListCmd represents the model list command
 var modelListCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List available models for the current provider",
 	Long: `List all known models for the currently configured provider and show which one is selected.
 
+For OpenAI, embedding, audio, image, and moderation models are hidden; use --all to show them.
+
 Note: Model availability can change frequently. If you don't see a model you want to use,
 you can still set it with 'mad config model set <model>' and the system will attempt to use it.`,
 	Run: func(cmd *cobra.Command, args []string) {
-		config, err := loadConfig()
+		cfg, err := config.Load()
 		if err != nil {
-			fmt.Printf("Error loading config: %v\n", err)
+			console.Printf("Error loading config: %v\n", err)
 			os.Exit(1)
 		}
 
-		currentModel := ""
-		if config.Models != nil {
-			currentModel = config.Models[config.Provider]
-		}
+		currentModel := resolveModel(cfg, cfg.Provider)
 
-		fmt.Printf("🧠 Models for %s:\n", strings.Title(config.Provider))
-		fmt.Println()
+		console.Printf("🧠 Models for %s:\n", strings.Title(cfg.Provider))
+		console.Println()
 
-		provider := providers.GetProvider(config.Provider)
+		provider, err := providers.NewProvider(cfg.Provider, providerOptions(cfg))
+		if err != nil {
+			console.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
 
-		knownModels, err := provider.ListModels(context.Background(), config.Secrets[config.Provider])
+		knownModels, err := provider.ListModels(context.Background(), getAPIKey(cfg.Provider, cfg))
 		if err != nil {
-			fmt.Printf("Error listing models: %v\n", err)
+			console.Printf("Error listing models: %v\n", err)
 			os.Exit(1)
 		}
+		if showAll, _ := cmd.Flags().GetBool("all"); !showAll {
+			knownModels = filterChatModels(cfg.Provider, knownModels)
+		}
 		if len(knownModels) == 0 {
-			fmt.Printf("No known models defined for provider: %s\n", config.Provider)
-			fmt.Println("You can still set custom models with 'mad config model set <model>'")
+			console.Printf("No known models defined for provider: %s\n", cfg.Provider)
+			console.Println("You can still set custom models with 'mad config model set <model>'")
 			return
 		}
-		models := make([]string, len(knownModels))
-		for i, model := range knownModels {
-			models[i] = model.ID
-		}
-
-		fmt.Println("📋 Known Models:")
-		for _, model := range models {
-			if currentModel == model {
-				fmt.Printf("✅ %s (current, known)\n", model)
+		console.Println("📋 Known Models:")
+		for _, model := range knownModels {
+			if currentModel == model.ID {
+				console.Printf("✅ %s (current, known)%s\n", model.ID, formatCapabilities(model))
 			} else {
-				fmt.Printf("○ %s (known)\n", model)
+				console.Printf("○ %s (known)%s\n", model.ID, formatCapabilities(model))
 			}
 		}
 
-		fmt.Println()
-		fmt.Println("💡 Custom Models:")
+		console.Println()
+		console.Println("💡 Custom Models:")
 
 		// Show custom models that have been set but aren't in our known list
 		customModels := []string{}
-		if config.Models != nil {
-			for provider, model := range config.Models {
-				if provider == config.Provider && model != "" && !isKnownModel(provider, model) {
+		if cfg.Models != nil {
+			for provider, model := range cfg.Models {
+				if provider == cfg.Provider && model != "" && !isKnownModel(provider, model) {
 					customModels = append(customModels, model)
 				}
 			}
 		}
 
 		if len(customModels) == 0 {
-			fmt.Println("○ No custom models configured")
+			console.Println("○ No custom models configured")
 		} else {
 			for _, model := range customModels {
+				info := providers.WithCapabilities(providers.ModelInfo{ID: model})
 				if currentModel == model {
-					fmt.Printf("✅ %s (current, custom)\n", model)
+					console.Printf("✅ %s (current, custom)%s\n", model, formatCapabilities(info))
 				} else {
-					fmt.Printf("○ %s (custom)\n", model)
+					console.Printf("○ %s (custom)%s\n", model, formatCapabilities(info))
 				}
 			}
 		}
 
-		fmt.Println()
+		console.Println()
 		if currentModel != "" {
 			modelType := "known"
-			if !isKnownModel(config.Provider, currentModel) {
+			if !isKnownModel(cfg.Provider, currentModel) {
 				modelType = "custom"
 			}
-			fmt.Printf("Current model: %s (%s)\n", currentModel, modelType)
+			console.Printf("Current model: %s (%s)\n", currentModel, modelType)
 		} else {
-			fmt.Printf("No model set for %s.\n", config.Provider)
-			fmt.Printf("Use 'mad config model set <model>' to set one.\n")
-			fmt.Printf("You can use any model name - the system will attempt to use it.\n")
+			console.Printf("No model set for %s.\n", cfg.Provider)
+			console.Printf("Use 'mad config model set <model>' to set one.\n")
+			console.Printf("You can use any model name - the system will attempt to use it.\n")
 		}
 
-		fmt.Println()
-		fmt.Println("ℹ️  Note: Model availability changes frequently.")
-		fmt.Println("   If a model you want isn't listed, you can still use it.")
+		console.Println()
+		console.Println("ℹ️  Note: Model availability changes frequently.")
+		console.Println("   If a model you want isn't listed, you can still use it.")
 	},
 }
 
@@ -607,33 +857,61 @@ This command will:
 • Display models with their current status
 • Help you discover new models that aren't in our known list
 
+For OpenAI, embedding, audio, image, and moderation models are hidden; use --all to show them.
+
+Use --all-providers to query every provider with a configured key at once and
+print one table; a provider that fails does not stop the others. (--all keeps its
+meaning of showing non-chat models, as on 'model list', and can be combined with it.)
+
 Note: Works best with a valid API key, but will show known models as fallback.`,
 	Run: func(cmd *cobra.Command, args []string) {
-		config, err := loadConfig()
+		cfg, err := config.Load()
 		if err != nil {
-			fmt.Printf("Error loading config: %v\n", err)
+			console.Printf("Error loading config: %v\n", err)
 			os.Exit(1)
 		}
 
+		if allProviders, _ := cmd.Flags().GetBool("all-providers"); allProviders {
+			available := comparisonProviders(cfg)
+			if len(available) == 0 {
+				console.Println("❌ No providers have API keys configured")
+				console.Println("Configure one using: mad config secrets set <provider> \"your-api-key\"")
+				os.Exit(1)
+			}
+			console.Printf("🔄 Refreshing models for %s...\n", strings.Join(available, ", "))
+			showAll, _ := cmd.Flags().GetBool("all")
+			results := listAllProviderModels(context.Background(), cfg, showAll)
+			console.Println()
+			printAllProviderModels(cfg, results)
+			return
+		}
+
 		// Get API key for current provider
-		apiKey := getAPIKey(config.Provider, config)
+		apiKey := getAPIKey(cfg.Provider, cfg)
 
-		fmt.Printf("🔄 Refreshing models for %s...\n", strings.Title(config.Provider))
+		console.Printf("🔄 Refreshing models for %s...\n", strings.Title(cfg.Provider))
 
 		var models []providers.ModelInfo
 		var fetchSource string
 
-		if apiKey != "" {
+		if apiKey != "" || usesVertex(cfg.Provider, cfg) {
 			// Try to fetch from API
-			fmt.Println("📡 Fetching from provider API...")
-			provider := providers.GetProvider(config.Provider)
+			console.Println("📡 Fetching from provider API...")
+			provider, err := providers.NewProvider(cfg.Provider, providerOptions(cfg))
+			if err != nil {
+				console.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
 			ctx := context.Background()
 			apiModels, err := provider.ListModels(ctx, apiKey)
 			if err != nil {
-				fmt.Printf("⚠️  API call failed: %v\n", err)
-				fmt.Println("Falling back to known models...")
+				console.Printf("⚠️  API call failed: %v\n", err)
+				console.Println("Falling back to known models...")
 			} else {
 				models = apiModels
+				if showAll, _ := cmd.Flags().GetBool("all"); !showAll {
+					models = filterChatModels(cfg.Provider, models)
+				}
 				fetchSource = "API"
 			}
 		}
@@ -641,43 +919,40 @@ Note: Works best with a valid API key, but will show known models as fallback.`,
 		// If API call failed or no API key, use known models
 		if len(models) == 0 {
 			if apiKey == "" {
-				fmt.Println("📋 Using known models (no API key configured)...")
+				console.Println("📋 Using known models (no API key configured)...")
 			} else {
-				fmt.Println("📋 Using known models as fallback...")
+				console.Println("📋 Using known models as fallback...")
 			}
 
 			knownModels := getKnownModels()
-			if providerModels, exists := knownModels[config.Provider]; exists {
+			if providerModels, exists := knownModels[cfg.Provider]; exists {
 				for _, modelName := range providerModels {
-					models = append(models, providers.ModelInfo{
+					models = append(models, providers.WithCapabilities(providers.ModelInfo{
 						ID:   modelName,
 						Name: modelName,
-					})
+					}))
 				}
 			}
 			fetchSource = "known list"
 		}
 
 		if len(models) == 0 {
-			fmt.Printf("❌ No models available for provider '%s'\n", config.Provider)
+			console.Printf("❌ No models available for provider '%s'\n", cfg.Provider)
 			return
 		}
 
-		fmt.Printf("✅ Found %d models from %s:\n", len(models), fetchSource)
-		fmt.Println()
+		console.Printf("✅ Found %d models from %s:\n", len(models), fetchSource)
+		console.Println()
 
 		knownModels := getKnownModels()
 		knownModelMap := make(map[string]bool)
-		if providerModels, exists := knownModels[config.Provider]; exists {
+		if providerModels, exists := knownModels[cfg.Provider]; exists {
 			for _, model := range providerModels {
 				knownModelMap[model] = true
 			}
 		}
 
-		currentModel := ""
-		if config.Models != nil {
-			currentModel = config.Models[config.Provider]
-		}
+		currentModel := resolveModel(cfg, cfg.Provider)
 
 		// Group models by type
 		var knownAvailable []providers.ModelInfo
@@ -690,8 +965,8 @@ Note: Works best with a valid API key, but will show known models as fallback.`,
 			} else {
 				// Check if this is a custom model we've configured
 				isCustom := false
-				if config.Models != nil {
-					for _, configuredModel := range config.Models {
+				if cfg.Models != nil {
+					for _, configuredModel := range cfg.Models {
 						if configuredModel == model.ID {
 							isCustom = true
 							break
@@ -708,47 +983,47 @@ Note: Works best with a valid API key, but will show known models as fallback.`,
 
 		// Display known models
 		if len(knownAvailable) > 0 {
-			fmt.Println("📋 Known Models (available via API):")
+			console.Println("📋 Known Models (available via API):")
 			for _, model := range knownAvailable {
 				if currentModel == model.ID {
-					fmt.Printf("✅ %s (current)\n", model.ID)
+					console.Printf("✅ %s (current)%s\n", model.ID, formatCapabilities(model))
 				} else {
-					fmt.Printf("○ %s\n", model.ID)
+					console.Printf("○ %s%s\n", model.ID, formatCapabilities(model))
 				}
 			}
-			fmt.Println()
+			console.Println()
 		}
 
 		// Display custom models
 		if len(customModels) > 0 {
-			fmt.Println("💡 Your Custom Models:")
+			console.Println("💡 Your Custom Models:")
 			for _, model := range customModels {
 				if currentModel == model.ID {
-					fmt.Printf("✅ %s (current, custom)\n", model.ID)
+					console.Printf("✅ %s (current, custom)%s\n", model.ID, formatCapabilities(model))
 				} else {
-					fmt.Printf("○ %s (custom)\n", model.ID)
+					console.Printf("○ %s (custom)%s\n", model.ID, formatCapabilities(model))
 				}
 			}
-			fmt.Println()
+			console.Println()
 		}
 
 		// Display new models
 		if len(newModels) > 0 {
-			fmt.Println("🆕 New/Discovered Models (not in our known list):")
+			console.Println("🆕 New/Discovered Models (not in our known list):")
 			for _, model := range newModels {
-				fmt.Printf("○ %s", model.ID)
+				console.Printf("○ %s", model.ID)
 				if model.Name != "" && model.Name != model.ID {
-					fmt.Printf(" (%s)", model.Name)
+					console.Printf(" (%s)", model.Name)
 				}
-				fmt.Println()
+				console.Println(formatCapabilities(model))
 			}
-			fmt.Println()
-			fmt.Println("💡 Tip: You can use these new models with:")
-			fmt.Printf("   mad config model set <model-name>\n")
+			console.Println()
+			console.Println("💡 Tip: You can use these new models with:")
+			console.Printf("   mad config model set <model-name>\n")
 		}
 
-		fmt.Println()
-		fmt.Printf("📊 Summary: %d total models, %d known, %d custom, %d new\n",
+		console.Println()
+		console.Printf("📊 Summary: %d total models, %d known, %d custom, %d new\n",
 			len(models), len(knownAvailable), len(customModels), len(newModels))
 
 		if currentModel != "" {
@@ -756,18 +1031,388 @@ Note: Works best with a valid API key, but will show known models as fallback.`,
 			if !knownModelMap[currentModel] {
 				modelType = "custom"
 			}
-			fmt.Printf("Current model: %s (%s)\n", currentModel, modelType)
+			console.Printf("Current model: %s (%s)\n", currentModel, modelType)
+		}
+	},
+}
+
+// configExportCmd represents the config export command
+var configExportCmd = &cobra.Command{
+	Use:   "export <file>",
+	Short: "Export the configuration to a file",
+	Long: `Export the current configuration to a JSON file so it can be imported on another machine.
+
+API keys and the transcript bearer token are excluded by default. Use --with-secrets to
+include them.
+
+Examples:
+  mad config export mad-config.json
+  mad config export mad-config.json --with-secrets`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		withSecrets, _ := cmd.Flags().GetBool("with-secrets")
+
+		cfg, err := config.Load()
+		if err != nil {
+			console.Printf("Error loading config: %v\n", err)
+			os.Exit(1)
+		}
+
+		if !withSecrets {
+			cfg.Secrets = nil
+			cfg.Transcript.BearerToken = ""
+		}
+
+		if err := config.WriteFile(args[0], cfg); err != nil {
+			console.Printf("Error writing export file: %v\n", err)
+			os.Exit(1)
+		}
+
+		console.Printf("✅ Configuration exported to: %s\n", args[0])
+		if withSecrets {
+			console.Println("⚠️  The export contains API keys and tokens. Keep it private.")
+		} else {
+			console.Println("ℹ️  API keys and the transcript bearer token were not included (use --with-secrets to include them)")
+		}
+	},
+}
+
+// configImportCmd represents the config import command
+var configImportCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: "Import configuration from a file",
+	Long: `Import configuration from a JSON file created with 'mad config export'.
+
+The imported settings are merged over the current configuration: fields present in the
+file replace current values and secrets are added per provider. The existing config.json
+is backed up before it is changed.
+
+Example:
+  mad config import mad-config.json`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		data, err := os.ReadFile(args[0])
+		if err != nil {
+			console.Printf("Error reading import file: %v\n", err)
+			os.Exit(1)
+		}
+
+		// Validate the schema before touching the current config
+		var imported Config
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&imported); err != nil {
+			console.Printf("Error: Invalid config file: %v\n", err)
+			os.Exit(1)
+		}
+		if err := validateImportedConfig(&imported); err != nil {
+			console.Printf("Error: Invalid config file: %v\n", err)
+			os.Exit(1)
+		}
+
+		cfg, err := config.Load()
+		if err != nil {
+			console.Printf("Error loading config: %v\n", err)
+			os.Exit(1)
+		}
+
+		// Back up the existing config
+		configDir := config.Dir()
+		configPath := filepath.Join(configDir, "config.json")
+		if existing, err := os.ReadFile(configPath); err == nil {
+			backupPath := filepath.Join(configDir, fmt.Sprintf("config.json.%s.bak", time.Now().Format("20060102-150405")))
+			if err := os.WriteFile(backupPath, existing, config.FileMode); err != nil {
+				console.Printf("Error backing up config: %v\n", err)
+				os.Exit(1)
+			}
+			console.Printf("💾 Backed up existing config to: %s\n", backupPath)
+		}
+
+		// Merge by decoding the import over the current config
+		if err := json.Unmarshal(data, cfg); err != nil {
+			console.Printf("Error merging config: %v\n", err)
+			os.Exit(1)
+		}
+
+		// Save config
+		if err := config.Save(cfg); err != nil {
+			console.Printf("Error saving config: %v\n", err)
+			os.Exit(1)
+		}
+
+		console.Printf("✅ Configuration imported from: %s\n", args[0])
+	},
+}
+
+// configGetCmd represents the config get command
+var configGetCmd = &cobra.Command{
+	Use:   "get [key]",
+	Short: "Show a configuration value",
+	Long: `Show a configuration value by its dotted key, using the field names from config.json
+(matched case-insensitively). Without a key, every key and its value is listed.
+
+Secrets and the current project are not shown; use 'mad config secrets list' and
+'mad config project list'.
+
+Examples:
+  mad config get limits.maxSteps
+  mad config get confidenceThreshold
+  mad config get models.openai
+  mad config get log`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg, err := config.Load()
+		if err != nil {
+			console.Printf("Error loading config: %v\n", err)
+			os.Exit(1)
+		}
+
+		if len(args) == 0 {
+			for _, key := range config.Keys() {
+				if group, isMap := strings.CutSuffix(key, ".<name>"); isMap {
+					value, _ := config.Get(cfg, group)
+					console.Dataf("%s = %s\n", group, formatConfigValue(value))
+					continue
+				}
+				value, _ := config.Get(cfg, key)
+				console.Dataf("%s = %s\n", key, formatConfigValue(value))
+			}
+			return
 		}
+
+		value, err := config.Get(cfg, args[0])
+		if err != nil {
+			console.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		console.Dataln(formatConfigValue(value))
 	},
 }
 
+// formatConfigValue renders a config value: scalars as-is, groups as JSON
+func formatConfigValue(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "(unset)"
+	case string, bool, int, float64:
+		return fmt.Sprint(value)
+	}
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
+
+// configKeyAliases route dotted keys that need more than type checking to the
+// dedicated handling of their config set alias
+var configKeyAliases = map[string]string{
+	"outputformat":         "output-format",
+	"embedimages":          "embed-images",
+	"language":             "language",
+	"prefersimplediagrams": "prefer-simple-diagrams",
+}
+
+// configSetCmd represents the config set command
+var configSetCmd = &cobra.Command{
+	Use:   "set <key> <value>",
+	Short: "Set a configuration value",
+	Long: `Set a configuration value by its dotted key, using the field names from config.json
+(matched case-insensitively). Values are checked against the field's type (text, true/false,
+whole number, or number) and the resulting config is validated before it is saved.
+See 'mad config get' for every key. Secrets and the current project have their own commands.
+
+Shorthand keys:
+- output-format: documentation format to produce (md, adoc, html)
+- embed-images: write a companion .rendered.md linking rendered images (true, false)
+- language: language for documentation prose and diagram labels (en, es, fr, pt-br, ...)
+- prefer-simple-diagrams: restrict diagrams to sequence/flowchart and reject typed ER attributes (true, false)
+- rate-limit <provider>: maximum requests per minute to a provider (0 removes the limit)
+
+Examples:
+  mad config set confidenceThreshold 0.85
+  mad config set limits.maxSteps 40
+  mad config set log.level debug
+  mad config set models.openai gpt-5
+  mad config set output-format html
+  mad config set embed-images true
+  mad config set language es
+  mad config set prefer-simple-diagrams false
+  mad config set rate-limit openai 50`,
+	Args: cobra.RangeArgs(2, 3),
+	Run: func(cmd *cobra.Command, args []string) {
+		key := strings.ToLower(args[0])
+		value := args[1]
+		if alias, ok := configKeyAliases[key]; ok {
+			key = alias
+		}
+
+		// rate-limit is the only key that takes a provider before the value
+		if (key == "rate-limit") != (len(args) == 3) {
+			console.Println("Error: Usage is 'mad config set <key> <value>' or 'mad config set rate-limit <provider> <requests-per-minute>'")
+			os.Exit(1)
+		}
+
+		// Load current config
+		cfg, err := config.Load()
+		if err != nil {
+			console.Printf("Error loading config: %v\n", err)
+			os.Exit(1)
+		}
+
+		switch key {
+		case "output-format":
+			format, err := output.ParseFormat(value)
+			if err != nil {
+				console.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+			cfg.OutputFormat = string(format)
+			value = string(format)
+		case "embed-images":
+			enabled, err := strconv.ParseBool(value)
+			if err != nil {
+				console.Printf("Error: embed-images must be true or false, got '%s'\n", value)
+				os.Exit(1)
+			}
+			cfg.EmbedImages = enabled
+			value = strconv.FormatBool(enabled)
+		case "language":
+			language, err := agent.ParseLanguage(value)
+			if err != nil {
+				console.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+			cfg.Language = language
+			if language == agent.DefaultLanguage {
+				cfg.Language = ""
+			}
+			value = language
+		case "prefer-simple-diagrams":
+			enabled, err := strconv.ParseBool(value)
+			if err != nil {
+				console.Printf("Error: prefer-simple-diagrams must be true or false, got '%s'\n", value)
+				os.Exit(1)
+			}
+			cfg.PreferSimpleDiagrams = &enabled
+			value = strconv.FormatBool(enabled)
+		case "rate-limit":
+			provider := strings.ToLower(args[1])
+			validProviders := map[string]bool{
+				"openai":    true,
+				"anthropic": true,
+				"google":    true,
+			}
+			if !validProviders[provider] {
+				console.Printf("Error: Invalid provider '%s'. Supported providers: openai, anthropic, google\n", provider)
+				os.Exit(1)
+			}
+			rpm, err := strconv.Atoi(args[2])
+			if err != nil || rpm < 0 {
+				console.Printf("Error: rate-limit must be a non-negative number of requests per minute, got '%s'\n", args[2])
+				os.Exit(1)
+			}
+			if rpm == 0 {
+				delete(cfg.RateLimits, provider)
+			} else {
+				if cfg.RateLimits == nil {
+					cfg.RateLimits = make(map[string]int)
+				}
+				cfg.RateLimits[provider] = rpm
+			}
+			key = "rate-limit " + provider
+			value = fmt.Sprintf("%d requests/minute", rpm)
+			if rpm == 0 {
+				value = "unlimited"
+			}
+		default:
+			parsed, err := config.Set(cfg, args[0], value)
+			if err != nil {
+				console.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+			if err := validateImportedConfig(cfg); err != nil {
+				console.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+			key, value = args[0], formatConfigValue(parsed)
+		}
+
+		// Save config
+		if err := config.Save(cfg); err != nil {
+			console.Printf("Error saving config: %v\n", err)
+			os.Exit(1)
+		}
+
+		console.Printf("✅ %s set to: %s\n", key, value)
+	},
+}
+
+// validateImportedConfig checks values that would break a run if imported
+func validateImportedConfig(cfg *Config) error {
+	if cfg.Provider != "" {
+		validProviders := map[string]bool{
+			"openai":    true,
+			"anthropic": true,
+			"google":    true,
+		}
+		if !validProviders[cfg.Provider] {
+			return fmt.Errorf("unsupported provider '%s'", cfg.Provider)
+		}
+	}
+
+	for provider := range cfg.Secrets {
+		if provider != "openai" && provider != "anthropic" && provider != "google" {
+			return fmt.Errorf("secret for unsupported provider '%s'", provider)
+		}
+	}
+
+	if err := config.ValidateConfidenceThreshold(cfg.ConfidenceThreshold); err != nil {
+		return err
+	}
+
+	if err := config.ValidateSafetyMode(cfg.Safety.Mode); err != nil {
+		return err
+	}
+
+	if cfg.FileNameTemplate != "" {
+		if _, err := agent.ParseFileNameTemplate(cfg.FileNameTemplate); err != nil {
+			return err
+		}
+	}
+
+	if cfg.ConversationWindow != nil {
+		if _, err := agent.ParseWindowStrategy(cfg.ConversationWindow.Strategy); err != nil {
+			return err
+		}
+		if cfg.ConversationWindow.MaxTurns < 0 {
+			return fmt.Errorf("conversationWindow.maxTurns must not be negative")
+		}
+	}
+
+	if cfg.Limits.MaxSteps < 0 || cfg.Limits.RunTimeoutSec < 0 || cfg.Limits.TokenBudget < 0 || cfg.Limits.CostCeilingUsd < 0 {
+		return fmt.Errorf("limits must not be negative")
+	}
+
+	return nil
+}
+
 func init() {
 	rootCmd.AddCommand(configCmd)
 
+	// Add import/export subcommands
+	configCmd.AddCommand(configExportCmd)
+	configCmd.AddCommand(configImportCmd)
+	configCmd.AddCommand(configGetCmd)
+	configCmd.AddCommand(configSetCmd)
+	configExportCmd.Flags().Bool("with-secrets", false, "Include API keys in the export")
+
 	// Add secrets subcommand
 	configCmd.AddCommand(secretsCmd)
 	secretsCmd.AddCommand(secretsSetCmd)
 	secretsCmd.AddCommand(secretsListCmd)
+	secretsSetCmd.Flags().String("backend", "file", "Secret backend to store the key in: file or keychain")
+	providerSetCmd.Flags().Bool("no-prompt", false, "Only warn about a missing API key instead of prompting for it")
 
 	// Add project subcommand
 	configCmd.AddCommand(projectCmd)
@@ -778,10 +1423,18 @@ func init() {
 	configCmd.AddCommand(providerCmd)
 	providerCmd.AddCommand(providerSetCmd)
 	providerCmd.AddCommand(providerListCmd)
+	providerCmd.AddCommand(providerSetVertexCmd)
 
 	// Add model subcommand
 	configCmd.AddCommand(modelCmd)
 	modelCmd.AddCommand(modelSetCmd)
+	modelCmd.AddCommand(modelUnsetCmd)
+	modelCmd.AddCommand(modelAliasCmd)
+	modelAliasCmd.Flags().Bool("remove", false, "Remove the named alias")
+	modelUnsetCmd.Flags().String("provider", "", "Provider to clear the model for (default: current provider)")
+	modelListCmd.Flags().Bool("all", false, "Show every model, including ones that cannot be used for chat")
+	modelRefreshCmd.Flags().Bool("all", false, "Show every model, including ones that cannot be used for chat")
+	modelRefreshCmd.Flags().Bool("all-providers", false, "Query every provider with a configured key concurrently and print one table")
 	modelCmd.AddCommand(modelListCmd)
 	modelCmd.AddCommand(modelRefreshCmd)
 }
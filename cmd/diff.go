@@ -0,0 +1,101 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"os"
+
+	"github.com/landanqrew/mermaid-agent-documenter/internal/config"
+	"github.com/landanqrew/mermaid-agent-documenter/internal/console"
+	"github.com/landanqrew/mermaid-agent-documenter/internal/runs"
+	"github.com/spf13/cobra"
+)
+
+// diffCmd represents the diff command
+var diffCmd = &cobra.Command{
+	Use:   "diff <run-id-a> <run-id-b>",
+	Short: "Compare the documentation produced by two runs",
+	Long: `Compare the Markdown and Mermaid files produced by two runs.
+
+Every run saves a copy of its .md and .mmd outputs under logs/runs/<run-id>/.
+This command lists the files that were added, removed, or changed between the two
+runs, the diagrams that differ inside each changed file, and a unified diff.
+
+A unique prefix of a run ID is accepted.
+
+Examples:
+  mad diff 3f2a9c 8b71d0`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg, err := config.Load()
+		if err != nil {
+			console.Printf("Error loading config: %v\n", err)
+			os.Exit(1)
+		}
+		_, logsDir := runDirectories(cfg)
+
+		before, err := runs.Load(logsDir, args[0])
+		if err != nil {
+			console.Printf("Error loading run: %v\n", err)
+			os.Exit(1)
+		}
+		after, err := runs.Load(logsDir, args[1])
+		if err != nil {
+			console.Printf("Error loading run: %v\n", err)
+			os.Exit(1)
+		}
+
+		changes, err := runs.Compare(before, after)
+		if err != nil {
+			console.Printf("Error comparing runs: %v\n", err)
+			os.Exit(1)
+		}
+
+		console.Printf("🔍 Comparing %s (%s) with %s (%s)\n", before.RunID, before.Model, after.RunID, after.Model)
+		printRunDiff(changes)
+	},
+}
+
+// changeMarkers prefix files and diagrams in the diff listing
+var changeMarkers = map[string]string{
+	runs.StatusAdded:     "+",
+	runs.StatusRemoved:   "-",
+	runs.StatusChanged:   "~",
+	runs.StatusUnchanged: "=",
+}
+
+// printRunDiff lists the changed files and diagrams, then the unified diffs
+func printRunDiff(changes []runs.FileChange) {
+	changed := 0
+	for _, change := range changes {
+		if change.Status != runs.StatusUnchanged {
+			changed++
+		}
+	}
+	if changed == 0 {
+		console.Printf("✅ No differences in %d files\n", len(changes))
+		return
+	}
+
+	console.Printf("\n📄 %d of %d files differ:\n", changed, len(changes))
+	for _, change := range changes {
+		if change.Status == runs.StatusUnchanged {
+			continue
+		}
+		console.Printf("  %s %s (%s)\n", changeMarkers[change.Status], change.Path, change.Status)
+		for _, diagram := range change.Diagrams {
+			console.Printf("      %s diagram %d (%s) %s\n", changeMarkers[diagram.Status], diagram.Index, diagram.Type, diagram.Status)
+		}
+	}
+
+	for _, change := range changes {
+		if change.Diff != "" {
+			console.Dataf("\n%s", change.Diff)
+		}
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(diffCmd)
+}
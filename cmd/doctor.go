@@ -0,0 +1,96 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/landanqrew/mermaid-agent-documenter/internal/config"
+	"github.com/landanqrew/mermaid-agent-documenter/internal/console"
+	"github.com/landanqrew/mermaid-agent-documenter/internal/providers"
+	"github.com/landanqrew/mermaid-agent-documenter/internal/tools"
+	"github.com/spf13/cobra"
+)
+
+// doctorCmd represents the doctor command
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check the provider credentials, Mermaid CLI, and output directory",
+	Long: `Check that a run can start: the current provider is reachable and accepts its API
+key (a lightweight models request, nothing is generated), the Mermaid CLI is installed for
+image rendering, and the output directory is writable.
+
+Exits with status 1 when a check fails, so it can gate CI jobs.
+
+Examples:
+  mad doctor`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg, err := config.Load()
+		if err != nil {
+			console.Printf("Error loading config: %v\n", err)
+			os.Exit(1)
+		}
+
+		failed := false
+		apiKey := getAPIKey(cfg.Provider, cfg)
+		if err := providers.ValidateProvider(cfg.Provider); err != nil {
+			console.Printf("❌ Provider: %v (mad config provider set <%s>)\n", err, strings.Join(providers.SupportedProviders, "|"))
+			failed = true
+		} else if apiKey == "" && !usesVertex(cfg.Provider, cfg) {
+			console.Printf("❌ %s: no API key (mad config secrets set %s \"your-api-key\")\n", cfg.Provider, cfg.Provider)
+			failed = true
+		} else if err := pingProvider(context.Background(), cfg, apiKey); err != nil {
+			console.Printf("❌ %s: %v\n", cfg.Provider, err)
+			failed = true
+		} else {
+			console.Printf("✅ %s: reachable, API key accepted\n", cfg.Provider)
+		}
+
+		if tools.MermaidCLIInstalled() {
+			console.Println("✅ Mermaid CLI: installed")
+		} else {
+			console.Printf("⚠️  Mermaid CLI: %s\n", tools.MissingMermaidCLIMessage())
+		}
+
+		outputDir, _ := runDirectories(cfg)
+		if err := checkOutputDir(cfg); err != nil {
+			console.Printf("❌ Output directory: %v\n", err)
+			failed = true
+		} else {
+			console.Printf("✅ Output directory: %s is writable\n", outputDir)
+		}
+
+		if failed {
+			os.Exit(1)
+		}
+	},
+}
+
+// pingProvider checks that the configured provider accepts apiKey, describing
+// authentication and network failures
+func pingProvider(ctx context.Context, cfg *Config, apiKey string) error {
+	provider, err := providers.NewProvider(cfg.Provider, providerOptions(cfg))
+	if err != nil {
+		return err
+	}
+	err = provider.Ping(ctx, apiKey)
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, providers.ErrAuthFailed):
+		return fmt.Errorf("the API key was rejected (%w); update it with 'mad config secrets set %s'", err, cfg.Provider)
+	case errors.Is(err, providers.ErrUnreachable):
+		return fmt.Errorf("could not reach the provider (%w); check the network or proxy settings", err)
+	default:
+		return err
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+}
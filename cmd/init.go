@@ -4,84 +4,24 @@ Copyright © 2025 NAME HERE <EMAIL ADDRESS>
 package cmd
 
 import (
-	"encoding/json"
-	"fmt"
 	"os"
 	"path/filepath"
 	"time"
 
+	"github.com/landanqrew/mermaid-agent-documenter/internal/config"
+	"github.com/landanqrew/mermaid-agent-documenter/internal/console"
 	"github.com/spf13/cobra"
 )
 
-type ProjectConfig struct {
-	Name        string `json:"name"`
-	RootDir     string `json:"rootDir"`
-	Description string `json:"description,omitempty"`
-	CreatedAt   string `json:"createdAt,omitempty"`
-}
-
-type Config struct {
-	Provider            string            `json:"provider"`
-	Models              map[string]string `json:"models"`
-	Log                 LogConfig         `json:"log"`
-	Safety              SafetyConfig      `json:"safety"`
-	Limits              LimitsConfig      `json:"limits"`
-	ConfidenceThreshold float64           `json:"confidenceThreshold"`
-	OutDir              string            `json:"outDir"`
-	Secrets             map[string]string `json:"secrets,omitempty"`
-	CurrentProject      *ProjectConfig    `json:"currentProject,omitempty"`
-}
-
-type LogConfig struct {
-	Level               string `json:"level"`
-	Redact              bool   `json:"redact"`
-	StoreChainOfThought bool   `json:"storeChainOfThought"`
-}
-
-type SafetyConfig struct {
-	Mode         string `json:"mode"`
-	PIIRedaction bool   `json:"piiRedaction"`
-}
-
-type LimitsConfig struct {
-	MaxSteps       int     `json:"maxSteps"`
-	RunTimeoutSec  int     `json:"runTimeoutSec"`
-	TokenBudget    int     `json:"tokenBudget"`
-	CostCeilingUsd float64 `json:"costCeilingUsd"`
-}
-
-func defaultConfig() *Config {
-	return &Config{
-		Provider: "openai",
-		Models: map[string]string{
-			"openai":    "gpt-5-mini",
-			"anthropic": "claude-3.5-sonnet",
-			"google":    "gemini-2.5-flash",
-		},
-		Log: LogConfig{
-			Level:               "info",
-			Redact:              true,
-			StoreChainOfThought: false,
-		},
-		Safety: SafetyConfig{
-			Mode:         "standard",
-			PIIRedaction: true,
-		},
-		Limits: LimitsConfig{
-			MaxSteps:       25,
-			RunTimeoutSec:  300,
-			TokenBudget:    100000,
-			CostCeilingUsd: 1.0,
-		},
-		ConfidenceThreshold: 0.90,
-		OutDir:              "~/mermaid-agent-documenter/output",
-	}
-}
-
-func getConfigDir() string {
-	home, _ := os.UserHomeDir()
-	return filepath.Join(home, "mermaid-agent-documenter")
-}
+// The config types live in internal/config so the tools read the same schema
+type (
+	Config           = config.Config
+	ProjectConfig    = config.ProjectConfig
+	LogConfig        = config.LogConfig
+	SafetyConfig     = config.SafetyConfig
+	LimitsConfig     = config.LimitsConfig
+	TranscriptConfig = config.TranscriptConfig
+)
 
 // initCmd represents the init command
 var initCmd = &cobra.Command{
@@ -98,29 +38,17 @@ Examples:
   mad init ecommerce-app      # Initialize project for e-commerce application`,
 	Run: func(cmd *cobra.Command, args []string) {
 		// First, ensure global config directory exists
-		globalConfigDir := getConfigDir()
+		globalConfigDir := config.Dir()
 		if err := os.MkdirAll(globalConfigDir, 0755); err != nil {
-			fmt.Printf("Error creating global config dir: %v\n", err)
+			console.Printf("Error creating global config dir: %v\n", err)
 			os.Exit(1)
 		}
 
 		// Load or create global config
-		globalConfigPath := filepath.Join(globalConfigDir, "config.json")
-		var config *Config
-
-		if _, err := os.Stat(globalConfigPath); os.IsNotExist(err) {
-			config = defaultConfig()
-		} else {
-			data, err := os.ReadFile(globalConfigPath)
-			if err != nil {
-				fmt.Printf("Error reading global config: %v\n", err)
-				os.Exit(1)
-			}
-			config = &Config{}
-			if err := json.Unmarshal(data, config); err != nil {
-				fmt.Printf("Error parsing global config: %v\n", err)
-				os.Exit(1)
-			}
+		cfg, err := config.Load()
+		if err != nil {
+			console.Printf("Error reading global config: %v\n", err)
+			os.Exit(1)
 		}
 
 		if len(args) > 0 {
@@ -128,7 +56,7 @@ Examples:
 			projectName := args[0]
 			cwd, err := os.Getwd()
 			if err != nil {
-				fmt.Printf("Error getting current directory: %v\n", err)
+				console.Printf("Error getting current directory: %v\n", err)
 				os.Exit(1)
 			}
 
@@ -136,58 +64,52 @@ Examples:
 
 			// Create project directory structure
 			if err := os.MkdirAll(projectDir, 0755); err != nil {
-				fmt.Printf("Error creating project dir: %v\n", err)
+				console.Printf("Error creating project dir: %v\n", err)
 				os.Exit(1)
 			}
 
 			// Create subdirectories
 			transcriptsDir := filepath.Join(projectDir, "transcripts")
 			if err := os.MkdirAll(transcriptsDir, 0755); err != nil {
-				fmt.Printf("Error creating transcripts dir: %v\n", err)
+				console.Printf("Error creating transcripts dir: %v\n", err)
 				os.Exit(1)
 			}
 
 			outDir := filepath.Join(projectDir, "out")
 			if err := os.MkdirAll(outDir, 0755); err != nil {
-				fmt.Printf("Error creating output dir: %v\n", err)
+				console.Printf("Error creating output dir: %v\n", err)
 				os.Exit(1)
 			}
 
 			logsDir := filepath.Join(projectDir, "logs")
 			if err := os.MkdirAll(logsDir, 0755); err != nil {
-				fmt.Printf("Error creating logs dir: %v\n", err)
+				console.Printf("Error creating logs dir: %v\n", err)
 				os.Exit(1)
 			}
 
 			// Update global config with current project
-			config.CurrentProject = &ProjectConfig{
+			cfg.CurrentProject = &ProjectConfig{
 				Name:      projectName,
 				RootDir:   projectDir,
 				CreatedAt: time.Now().Format(time.RFC3339),
 			}
 
-			fmt.Printf("Project '%s' initialized at %s\n", projectName, projectDir)
-			fmt.Printf("Project structure:\n")
-			fmt.Printf("  📁 %s/\n", projectName)
-			fmt.Printf("    📁 transcripts/     (place your transcript files here)\n")
-			fmt.Printf("    📁 out/            (generated diagrams will be saved here)\n")
-			fmt.Printf("    📁 logs/           (execution logs)\n")
-			fmt.Printf("\nProject set as current in global config.\n")
+			console.Printf("Project '%s' initialized at %s\n", projectName, projectDir)
+			console.Printf("Project structure:\n")
+			console.Printf("  📁 %s/\n", projectName)
+			console.Printf("    📁 transcripts/     (place your transcript files here)\n")
+			console.Printf("    📁 out/            (generated diagrams will be saved here)\n")
+			console.Printf("    📁 logs/           (execution logs)\n")
+			console.Printf("\nProject set as current in global config.\n")
 
 		} else {
 			// Initialize global environment only
-			fmt.Printf("Global environment initialized at %s\n", globalConfigDir)
+			console.Printf("Global environment initialized at %s\n", globalConfigDir)
 		}
 
 		// Save global config
-		data, err := json.MarshalIndent(config, "", "  ")
-		if err != nil {
-			fmt.Printf("Error marshaling config: %v\n", err)
-			os.Exit(1)
-		}
-
-		if err := os.WriteFile(globalConfigPath, data, 0644); err != nil {
-			fmt.Printf("Error writing global config: %v\n", err)
+		if err := config.Save(cfg); err != nil {
+			console.Printf("Error writing global config: %v\n", err)
 			os.Exit(1)
 		}
 	},
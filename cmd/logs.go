@@ -0,0 +1,265 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/landanqrew/mermaid-agent-documenter/internal/config"
+	"github.com/landanqrew/mermaid-agent-documenter/internal/console"
+	"github.com/landanqrew/mermaid-agent-documenter/internal/logs"
+	"github.com/spf13/cobra"
+)
+
+// logsCmd represents the logs command
+var logsCmd = &cobra.Command{
+	Use:   "logs",
+	Short: "Inspect execution logs",
+	Long: `Inspect the execution logs written by runs.
+
+Each run appends to logs.jsonl in the logs directory (the project's logs/ directory, or
+~/mermaid-agent-documenter/logs) and writes its steps to its own <run-id>.jsonl there
+(see log.perRunFiles and log.sharedLog). When a run starts, logs.jsonl is rotated to
+logs-YYYYMMDD.jsonl if it was last written on an earlier day or has grown past 10 MB, and
+rotated logs and per-run logs older than log.retentionDays (default 30) are deleted.`,
+}
+
+// logsShowCmd represents the logs show command
+var logsShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Show logged agent steps",
+	Long: `Show one line per logged agent step from logs.jsonl and the rotated logs.
+
+With --run, show one run's steps and logged events from its own <run-id>.jsonl,
+falling back to its entries in the shared logs. A unique prefix of the run ID is accepted.
+
+Examples:
+  mad logs show
+  mad logs show --since 2025-01-01
+  mad logs show --since 2025-01-01T09:00:00Z
+  mad logs show --run 3f2a9c`,
+	Run: func(cmd *cobra.Command, args []string) {
+		sinceFlag, _ := cmd.Flags().GetString("since")
+		runFlag, _ := cmd.Flags().GetString("run")
+		since, err := parseSince(sinceFlag)
+		if err != nil {
+			console.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		cfg, err := config.Load()
+		if err != nil {
+			console.Printf("Error loading config: %v\n", err)
+			os.Exit(1)
+		}
+		_, logsDir := runDirectories(cfg)
+
+		var entries []logs.Entry
+		if runFlag != "" {
+			entries, err = runLogEntries(logsDir, runFlag)
+		} else {
+			entries, err = logs.Read(logsDir, since)
+		}
+		if err != nil {
+			console.Printf("Error reading logs: %v\n", err)
+			os.Exit(1)
+		}
+		if len(entries) == 0 {
+			console.Printf("No log entries found in %s\n", logsDir)
+			return
+		}
+
+		for _, entry := range entries {
+			console.Dataln(formatLogEntry(entry))
+		}
+		console.Printf("\n📜 %d entries from %s\n", len(entries), logsDir)
+	},
+}
+
+// logsConfidenceCmd represents the logs confidence command
+var logsConfidenceCmd = &cobra.Command{
+	Use:   "confidence <run-id>",
+	Short: "Show confidence statistics for a run",
+	Long: `Aggregate the confidence logged at each step of a run into min/mean/max and a
+histogram, to see whether the run was consistently confident or borderline and to tune
+confidenceThreshold. The p50/p95 latency of the run's model calls is shown below it.
+A unique prefix of the run ID is accepted.
+
+Examples:
+  mad logs confidence 3f2a9c`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg, err := config.Load()
+		if err != nil {
+			console.Printf("Error loading config: %v\n", err)
+			os.Exit(1)
+		}
+		_, logsDir := runDirectories(cfg)
+
+		entries, err := logs.Read(logsDir, time.Time{})
+		if err != nil {
+			console.Printf("Error reading logs: %v\n", err)
+			os.Exit(1)
+		}
+		runID, err := matchRunID(entries, args[0])
+		if err != nil {
+			console.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		console.Printf("🎯 Confidence for run %s\n", runID)
+		printConfidenceStats(logs.SummarizeConfidence(logs.RunConfidences(entries, runID)))
+		console.Printf("Threshold: %.2f\n", cfg.ConfidenceThreshold)
+		printLatencyStats(logs.SummarizeLatency(logs.RunLatencies(entries, runID)))
+	},
+}
+
+// runLogEntries returns a run's entries from its own log file, plus the events
+// logEvent wrote to the global logs directory, falling back to the run's
+// entries in the shared logs when it has no file
+func runLogEntries(logsDir, runID string) ([]logs.Entry, error) {
+	shared, err := logs.Read(logsDir, time.Time{})
+	if err != nil {
+		return nil, err
+	}
+	if resolved, err := matchRunID(shared, runID); err == nil {
+		runID = resolved
+	} else if !logs.ValidRunID(runID) {
+		return nil, err
+	}
+
+	entries, err := logs.ReadRun(logsDir, runID)
+	if err != nil {
+		return nil, err
+	}
+	if eventsDir := filepath.Join(config.Dir(), "logs"); filepath.Clean(eventsDir) != filepath.Clean(logsDir) {
+		events, err := logs.ReadRun(eventsDir, runID)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, events...)
+		sort.SliceStable(entries, func(i, j int) bool { return entries[i].Timestamp().Before(entries[j].Timestamp()) })
+	}
+	if len(entries) > 0 {
+		return entries, nil
+	}
+
+	for _, entry := range shared {
+		if entry.RunID() == runID {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+// matchRunID resolves a run ID or unique prefix against the logged runs
+func matchRunID(entries []logs.Entry, prefix string) (string, error) {
+	var matches []string
+	seen := map[string]bool{}
+	for _, entry := range entries {
+		id := entry.RunID()
+		if id == prefix {
+			return id, nil
+		}
+		if strings.HasPrefix(id, prefix) && !seen[id] {
+			seen[id] = true
+			matches = append(matches, id)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("no logged steps for run '%s'", prefix)
+	case 1:
+		return matches[0], nil
+	default:
+		return "", fmt.Errorf("run ID prefix '%s' is ambiguous (%s)", prefix, strings.Join(matches, ", "))
+	}
+}
+
+// printConfidenceStats prints min/mean/max and a histogram of step confidences
+func printConfidenceStats(stats logs.ConfidenceStats) {
+	if stats.Count == 0 {
+		console.Println("Confidence: no steps recorded")
+		return
+	}
+	console.Printf("Confidence: min %.2f, mean %.2f, max %.2f over %d steps\n", stats.Min, stats.Mean, stats.Max, stats.Count)
+	for _, line := range stats.Histogram(20) {
+		console.Printf("  %s\n", line)
+	}
+}
+
+// printLatencyStats prints the median and 95th percentile model call latency
+func printLatencyStats(stats logs.LatencyStats) {
+	if stats.Count == 0 {
+		return
+	}
+	console.Printf("Model latency: p50 %s, p95 %s, max %s over %d calls (%s total)\n",
+		stats.P50.Round(10*time.Millisecond), stats.P95.Round(10*time.Millisecond), stats.Max.Round(10*time.Millisecond), stats.Count, stats.Total.Round(time.Second))
+}
+
+// parseSince accepts a date (2025-01-01, local midnight) or an RFC3339 timestamp
+func parseSince(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid --since '%s': use YYYY-MM-DD or an RFC3339 timestamp", value)
+	}
+	return t, nil
+}
+
+// formatLogEntry renders a log entry as a single line
+func formatLogEntry(entry logs.Entry) string {
+	runID := entry.RunID()
+	if len(runID) > 8 {
+		runID = runID[:8]
+	}
+	if _, isStep := entry["output_type"]; !isStep {
+		if level, ok := entry["level"].(string); ok {
+			return fmt.Sprintf("%s  %s  %s  %v", entry["timestamp"], runID, level, entry["message"])
+		}
+	}
+	line := fmt.Sprintf("%s  %s  step %v  %v", entry["timestamp"], runID, entry["step"], entry["output_type"])
+	if confidence, ok := entry["confidence"].(float64); ok {
+		line += fmt.Sprintf(" (confidence: %.2f)", confidence)
+	}
+	if tool, ok := entry["tool"].(string); ok && tool != "" {
+		line += "  " + tool
+	}
+	return line
+}
+
+// maintainLogs rotates logs.jsonl and prunes rotated logs past the retention
+// before a run starts writing to the logs directory
+func maintainLogs(logsDir string, logConfig LogConfig) {
+	now := time.Now()
+	if _, err := logs.Rotate(logsDir, logs.DefaultMaxBytes, now); err != nil {
+		console.Printf("⚠️  %v\n", err)
+	}
+	removed, err := logs.Prune(logsDir, logConfig.RetentionDays, now)
+	if err != nil {
+		console.Printf("⚠️  Failed to prune old logs: %v\n", err)
+	}
+	if len(removed) > 0 {
+		console.Printf("🧹 Removed %d logs older than the retention period\n", len(removed))
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(logsCmd)
+	logsCmd.AddCommand(logsShowCmd)
+	logsCmd.AddCommand(logsConfidenceCmd)
+
+	logsShowCmd.Flags().String("since", "", "Only show entries at or after this date (YYYY-MM-DD) or RFC3339 timestamp")
+	logsShowCmd.Flags().String("run", "", "Only show the steps and events of this run (ID or unique prefix)")
+}
@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/landanqrew/mermaid-agent-documenter/internal/console"
+	"github.com/landanqrew/mermaid-agent-documenter/internal/providers"
+)
+
+// modelRefreshConcurrency bounds how many providers are queried at once
+const modelRefreshConcurrency = 3
+
+// providerModels is the outcome of listing one provider's models
+type providerModels struct {
+	Provider string
+	Models   []providers.ModelInfo
+	Hidden   int // non-chat models filtered out
+	Err      error
+}
+
+// listAllProviderModels queries every provider with a key concurrently. A
+// failing provider is reported in its result without affecting the others.
+func listAllProviderModels(ctx context.Context, cfg *Config, showAll bool) []providerModels {
+	available := comparisonProviders(cfg)
+	results := make([]providerModels, len(available))
+
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, modelRefreshConcurrency)
+	for i, name := range available {
+		wg.Add(1)
+		go func(i int, name string) {
+			defer wg.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+
+			provider, err := providers.NewProvider(name, providerOptions(providerConfig(cfg, name)))
+			if err != nil {
+				results[i] = providerModels{Provider: name, Err: err}
+				return
+			}
+			models, err := provider.ListModels(ctx, getAPIKey(name, cfg))
+			result := providerModels{Provider: name, Models: models, Err: err}
+			if err == nil && !showAll {
+				result.Models = providers.FilterChatModels(name, models)
+				result.Hidden = len(models) - len(result.Models)
+			}
+			results[i] = result
+		}(i, name)
+	}
+	wg.Wait()
+	return results
+}
+
+// printAllProviderModels prints one table of every provider's models, then
+// the providers that failed
+func printAllProviderModels(cfg *Config, results []providerModels) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PROVIDER\tMODEL\tCAPABILITIES")
+	total, hidden := 0, 0
+	for _, result := range results {
+		if result.Err != nil {
+			continue
+		}
+		current := resolveModel(cfg, result.Provider)
+		for _, model := range result.Models {
+			id := model.ID
+			if id == current {
+				id += " (current)"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\n", result.Provider, id, model.CapabilitySummary())
+		}
+		total += len(result.Models)
+		hidden += result.Hidden
+	}
+	w.Flush()
+
+	console.Println()
+	for _, result := range results {
+		if result.Err != nil {
+			console.Printf("❌ %s: %v\n", result.Provider, result.Err)
+		} else {
+			console.Printf("✅ %s: %d models\n", result.Provider, len(result.Models))
+		}
+	}
+	console.Printf("📊 Summary: %d models in total\n", total)
+	if hidden > 0 {
+		console.Printf("ℹ️  Hid %d models that cannot be used for chat (use --all to show them)\n", hidden)
+	}
+}
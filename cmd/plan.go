@@ -0,0 +1,89 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/landanqrew/mermaid-agent-documenter/internal/agent"
+	"github.com/landanqrew/mermaid-agent-documenter/internal/config"
+	"github.com/landanqrew/mermaid-agent-documenter/internal/console"
+	"github.com/spf13/cobra"
+)
+
+// planCmd represents the plan command
+var planCmd = &cobra.Command{
+	Use:   "plan [transcript]",
+	Short: "Show the files and diagrams the agent would generate",
+	Long: `Ask the agent for a plan of the documentation it would generate from a transcript,
+without writing any files.
+
+This runs the same planning phase as 'mad run --plan' and stops once the plan is shown.
+Transcript paths are resolved the same way as for 'mad run'.
+
+Examples:
+  mad plan transcript.txt
+  mad plan transcript.txt --clean
+  mad run transcript.txt --plan --yes     # Plan, then execute without prompting`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		clean, _ := cmd.Flags().GetBool("clean")
+		chunk, _ := cmd.Flags().GetBool("chunk")
+
+		// Load global config
+		cfg, err := config.Load()
+		if err != nil {
+			console.Printf("Error loading config: %v\n", err)
+			os.Exit(1)
+		}
+
+		requireProvider(cfg)
+		apiKey := requireAPIKey(cfg)
+		noteDefaultModel(cfg)
+		registerExternalTools()
+
+		segments, err := prepareTranscript(cmd.Context(), args[0], cfg, clean, chunk, nil)
+		if err != nil {
+			console.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		agentConfig := newAgentConfig(cfg, apiKey, nil)
+		agentConfig.PlanOnly = true
+
+		console.Printf("Planning documentation for transcript: %s\n", args[0])
+		console.Printf("Provider: %s, Model: %s\n", cfg.Provider, agentConfig.Model)
+
+		for i, segment := range segments {
+			if len(segments) > 1 {
+				console.Printf("━━━ Segment %d of %d ━━━\n", i+1, len(segments))
+			}
+
+			planAgent := agent.NewMermaidDocumenterAgent(agentConfig)
+			planAgent.SetTranscript(segment)
+			if len(segments) > 1 {
+				planAgent.SetChunk(i+1, len(segments))
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), time.Duration(cfg.Limits.RunTimeoutSec)*time.Second)
+			result, err := planAgent.Run(ctx)
+			cancel()
+			if err != nil {
+				console.Printf("❌ Planning failed: %v\n", err)
+				os.Exit(1)
+			}
+			console.Printf("Estimated tokens: %d, estimated cost: $%.4f\n", result.TotalTokens(), result.EstimatedCostUsd)
+		}
+
+		console.Println("💡 Run 'mad run " + args[0] + " --plan' to review and execute this plan.")
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(planCmd)
+	planCmd.Flags().Bool("clean", false, "Strip chat markup from the transcript before planning")
+	planCmd.Flags().Bool("chunk", false, "Split transcripts above limits.maxTranscriptChars into overlapping segments and plan each")
+}
@@ -0,0 +1,61 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"os"
+	"os/exec"
+
+	"github.com/landanqrew/mermaid-agent-documenter/internal/console"
+)
+
+// stdinIsTerminal reports whether stdin is an interactive terminal
+func stdinIsTerminal() bool {
+	info, err := os.Stdin.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// readSecret prompts for a line of input without echoing it. Where echo can't
+// be disabled (no stty, e.g. on Windows) the input is read visibly.
+func readSecret(prompt string) string {
+	console.Print(prompt)
+
+	hide := exec.Command("stty", "-echo")
+	hide.Stdin = os.Stdin
+	if err := hide.Run(); err == nil {
+		defer func() {
+			show := exec.Command("stty", "echo")
+			show.Stdin = os.Stdin
+			show.Run()
+			console.Println()
+		}()
+	}
+
+	return readLine()
+}
+
+// promptForAPIKey offers to store an API key for provider right away. It
+// returns false when the user skipped it or the key could not be stored.
+func promptForAPIKey(provider string, cfg *Config) bool {
+	console.Printf("🔑 No API key configured for '%s'.\n", provider)
+	apiKey := readSecret("   Paste it now to save it (input hidden, Enter to skip): ")
+	if apiKey == "" {
+		return false
+	}
+
+	backend, err := getSecretBackend(cfg.SecretsBackend, cfg)
+	if err != nil {
+		console.Printf("⚠️  %v\n", err)
+		return false
+	}
+	if err := backend.Set(provider, apiKey); err != nil {
+		console.Printf("⚠️  Failed to store API key: %v\n", err)
+		return false
+	}
+	console.Printf("✅ API key for '%s' saved (%s backend)\n", provider, backend.Name())
+	return true
+}
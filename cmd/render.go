@@ -0,0 +1,140 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/landanqrew/mermaid-agent-documenter/internal/config"
+	"github.com/landanqrew/mermaid-agent-documenter/internal/console"
+	"github.com/landanqrew/mermaid-agent-documenter/internal/manifest"
+	"github.com/landanqrew/mermaid-agent-documenter/internal/mermaid"
+	"github.com/landanqrew/mermaid-agent-documenter/internal/tools"
+	"github.com/spf13/cobra"
+)
+
+// renderCmd represents the render command
+var renderCmd = &cobra.Command{
+	Use:   "render <file-or-manifest>",
+	Short: "Re-render diagram images from Markdown, .mmd, or a manifest without the agent",
+	Long: `Render the Mermaid diagrams of a Markdown or .mmd file to images with the Mermaid CLI,
+without calling a model. Use it after editing generated documentation by hand.
+
+Given a manifest.json, every Markdown and .mmd file it lists is rendered. Images are
+written next to their source in the output directory: <name>.svg for a single diagram,
+and <name>-1.svg, <name>-2.svg, ... for a Markdown file with several. Markdown files
+without mermaid blocks are skipped.
+
+If a current project is set, paths that do not exist as given are resolved relative
+to the project's out/ directory.
+
+Examples:
+  mad render login.md                 # Re-render the diagrams in out/login.md
+  mad render manifest.json            # Re-render every document of the last run
+  mad render flows/checkout.mmd --format png`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		format, _ := cmd.Flags().GetString("format")
+		format = strings.ToLower(format)
+		if format != "svg" && format != "png" && format != "pdf" {
+			console.Printf("Error: --format must be svg, png, or pdf, got '%s'\n", format)
+			os.Exit(1)
+		}
+
+		cfg, err := config.Load()
+		if err != nil {
+			console.Printf("Error loading config: %v\n", err)
+			os.Exit(1)
+		}
+		path := resolveOutputPath(args[0], cfg)
+
+		var sources []string
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".json":
+			sources, err = manifest.DiagramSources(path)
+			if err != nil {
+				console.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+		case ".md", ".mmd":
+			if _, err := os.Stat(path); err != nil {
+				console.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+			sources = []string{path}
+		default:
+			console.Printf("Error: %s is not a Markdown (.md), Mermaid (.mmd), or manifest (.json) file\n", path)
+			os.Exit(1)
+		}
+		if len(sources) == 0 {
+			console.Println("✨ No Markdown or .mmd files to render")
+			return
+		}
+
+		if !tools.MermaidCLIInstalled() {
+			console.Printf("Error: %s\n", tools.MissingMermaidCLIMessage())
+			os.Exit(1)
+		}
+
+		imageTool := &tools.GenerateMermaidImageTool{}
+		rendered, failed := 0, 0
+		for _, source := range sources {
+			if !hasDiagrams(source) {
+				console.Printf("⏭️  %s: no mermaid blocks\n", source)
+				continue
+			}
+			result := imageTool.Execute(map[string]interface{}{
+				"inputFile":  source,
+				"outputFile": strings.TrimSuffix(source, filepath.Ext(source)),
+				"format":     format,
+			})
+			if !result.Success {
+				console.Printf("❌ %s: %s\n", source, result.Error)
+				failed++
+				continue
+			}
+			outputs := renderedImages(result)
+			console.Printf("🖼️  %s → %s\n", source, strings.Join(outputs, ", "))
+			rendered += len(outputs)
+		}
+
+		console.Printf("✅ Rendered %d images", rendered)
+		if failed > 0 {
+			console.Printf(", %d files failed\n", failed)
+			os.Exit(1)
+		}
+		console.Println()
+	},
+}
+
+// hasDiagrams reports whether a source file has something to render: a .mmd
+// file always does, Markdown only with a mermaid block. Unreadable files are
+// rendered anyway so the error is reported.
+func hasDiagrams(path string) bool {
+	if strings.EqualFold(filepath.Ext(path), ".mmd") {
+		return true
+	}
+	data, err := os.ReadFile(path)
+	return err != nil || len(mermaid.ExtractBlocks(string(data))) > 0
+}
+
+// renderedImages lists the images a generateMermaidImage result reports
+func renderedImages(result tools.ToolResult) []string {
+	data, _ := result.Data.(map[string]interface{})
+	if outputs, ok := data["outputFiles"].([]string); ok {
+		return outputs
+	}
+	if output, ok := data["outputFile"].(string); ok {
+		return []string{output}
+	}
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(renderCmd)
+
+	renderCmd.Flags().String("format", "svg", "Image format: svg, png, or pdf")
+}
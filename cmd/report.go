@@ -0,0 +1,73 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+
+	"github.com/landanqrew/mermaid-agent-documenter/internal/agent"
+	"github.com/landanqrew/mermaid-agent-documenter/internal/console"
+)
+
+// runReport is the machine-readable result printed by 'mad run --json-output'
+type runReport struct {
+	Status           string             `json:"status"` // "success" or "error"
+	RunID            string             `json:"runId,omitempty"`
+	Artifacts        []string           `json:"artifacts"`
+	PromptTokens     int                `json:"promptTokens"`
+	CompletionTokens int                `json:"completionTokens"`
+	TotalTokens      int                `json:"totalTokens"`
+	EstimatedCostUsd float64            `json:"estimatedCostUsd"`
+	Errors           []string           `json:"errors"`
+	Runs             []*agent.RunResult `json:"runs"` // one per transcript segment
+}
+
+// newRunReport totals the results of every segment into one report
+func newRunReport(results []*agent.RunResult, runErr error) runReport {
+	report := runReport{
+		Status:    "success",
+		Artifacts: []string{},
+		Errors:    []string{},
+		Runs:      results,
+	}
+	if report.Runs == nil {
+		report.Runs = []*agent.RunResult{}
+	}
+	if len(results) > 0 {
+		report.RunID = results[0].RunID
+	}
+	for _, result := range results {
+		report.Artifacts = append(report.Artifacts, result.Artifacts...)
+		report.PromptTokens += result.PromptTokens
+		report.CompletionTokens += result.CompletionTokens
+		report.EstimatedCostUsd += result.EstimatedCostUsd
+	}
+	report.TotalTokens = report.PromptTokens + report.CompletionTokens
+	if runErr != nil {
+		report.Status = "error"
+		report.Errors = append(report.Errors, runErr.Error())
+	}
+	return report
+}
+
+// writeRunReport writes the JSON report for a run to w
+func writeRunReport(w io.Writer, results []*agent.RunResult, runErr error) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(newRunReport(results, runErr))
+}
+
+// exitRun prints err and exits with status 1. With --json-output an error
+// report is written to w first, so stdout always ends with a report.
+func exitRun(w io.Writer, jsonOutput bool, err error) {
+	console.Printf("Error: %v\n", err)
+	if jsonOutput {
+		if reportErr := writeRunReport(w, nil, err); reportErr != nil {
+			console.Printf("Error writing JSON report: %v\n", reportErr)
+		}
+	}
+	os.Exit(1)
+}
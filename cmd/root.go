@@ -5,7 +5,9 @@ package cmd
 
 import (
 	"os"
+	"time"
 
+	"github.com/landanqrew/mermaid-agent-documenter/internal/console"
 	"github.com/spf13/cobra"
 )
 
@@ -14,11 +16,26 @@ var rootCmd = &cobra.Command{
 	Use:   "mad",
 	Short: "Mermaid Agent Documenter CLI",
 	Long:  `A CLI tool for generating Mermaid diagrams and documentation from application transcripts.`,
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		console.SetASCII(asciiOutput || console.WantsASCII())
+	},
 }
 
+// Version is the release version, set at build time with
+// -ldflags "-X github.com/landanqrew/mermaid-agent-documenter/cmd.Version=v1.2.3"
+var Version = "dev"
+
+// asciiOutput replaces emoji in output with plain markers such as [OK] and
+// [FAIL]. It is also on when NO_COLOR is set or stdout is not a terminal.
+var asciiOutput bool
+
+// providerTimeout overrides limits.requestTimeoutSec for every provider call when set
+var providerTimeout time.Duration
+
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute() {
+	rootCmd.Version = Version
 	err := rootCmd.Execute()
 	if err != nil {
 		os.Exit(1)
@@ -31,4 +48,7 @@ func init() {
 	// will be global for your application.
 
 	// rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.mermaid-agent-documenter.yaml)")
+	rootCmd.PersistentFlags().BoolVar(&asciiOutput, "ascii", false, "Print plain ASCII markers like [OK] and [FAIL] instead of emoji (also on when NO_COLOR is set or output is not a terminal)")
+	rootCmd.PersistentFlags().BoolVar(&asciiOutput, "no-color", false, "Same as --ascii")
+	rootCmd.PersistentFlags().DurationVar(&providerTimeout, "provider-timeout", 0, "Timeout for each LLM provider request, e.g. 45s or 2m (overrides limits.requestTimeoutSec)")
 }
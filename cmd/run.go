@@ -5,41 +5,46 @@ package cmd
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"slices"
 	"strings"
+	"syscall"
 	"time"
 
 	"github.com/landanqrew/mermaid-agent-documenter/internal/agent"
+	"github.com/landanqrew/mermaid-agent-documenter/internal/config"
+	"github.com/landanqrew/mermaid-agent-documenter/internal/console"
+	"github.com/landanqrew/mermaid-agent-documenter/internal/logs"
+	"github.com/landanqrew/mermaid-agent-documenter/internal/manifest"
+	"github.com/landanqrew/mermaid-agent-documenter/internal/output"
+	"github.com/landanqrew/mermaid-agent-documenter/internal/providers"
+	"github.com/landanqrew/mermaid-agent-documenter/internal/secrets"
+	"github.com/landanqrew/mermaid-agent-documenter/internal/tools"
+	"github.com/landanqrew/mermaid-agent-documenter/internal/transcript"
+	"github.com/landanqrew/mermaid-agent-documenter/internal/watch"
 	"github.com/spf13/cobra"
 )
 
-func loadConfig() (*Config, error) {
-	// Always load from global config
-	configDir := getConfigDir()
-	configPath := filepath.Join(configDir, "config.json")
-
-	if _, err := os.Stat(configPath); os.IsNotExist(err) {
-		return defaultConfig(), nil
-	}
-
-	data, err := os.ReadFile(configPath)
-	if err != nil {
-		return nil, err
+func getAPIKey(provider string, cfg *Config) string {
+	// Check the OS keychain first when it has been selected as a backend
+	if cfg.SecretsBackend == "keychain" {
+		keychain := &secrets.KeychainBackend{}
+		if key, err := keychain.Get(provider); err == nil && key != "" {
+			return key
+		}
 	}
 
-	var config Config
-	err = json.Unmarshal(data, &config)
-	return &config, err
-}
-
-func getAPIKey(provider string, config *Config) string {
-	// First check config for stored API keys
-	if config.Secrets != nil {
-		if key, exists := config.Secrets[provider]; exists && key != "" {
-			return key
+	// Then check config for stored API keys (expanding ${VAR} references)
+	if cfg.Secrets != nil {
+		if key, exists := cfg.Secrets[provider]; exists && key != "" {
+			if expanded, ok := expandSecret(key); ok {
+				return expanded
+			}
 		}
 	}
 
@@ -56,12 +61,123 @@ func getAPIKey(provider string, config *Config) string {
 	}
 }
 
-func readTranscript(path string, config *Config) (string, error) {
+// isSecretReference reports whether a stored secret contains ${VAR} references
+func isSecretReference(value string) bool {
+	return strings.Contains(value, "${")
+}
+
+// expandSecret resolves ${VAR} references in a stored secret from the environment.
+// It reports false if any referenced variable is unset or the result is empty.
+func expandSecret(value string) (string, bool) {
+	if !isSecretReference(value) {
+		return value, true
+	}
+
+	resolved := true
+	expanded := os.Expand(value, func(name string) string {
+		v, ok := os.LookupEnv(name)
+		if !ok || v == "" {
+			resolved = false
+		}
+		return v
+	})
+
+	if !resolved || expanded == "" {
+		return "", false
+	}
+	return expanded, true
+}
+
+// providerOptions builds provider-specific options from the config and the
+// global --provider-timeout flag
+func providerOptions(cfg *Config) providers.ProviderOptions {
+	requestTimeout := time.Duration(cfg.Limits.RequestTimeoutSec) * time.Second
+	if providerTimeout > 0 {
+		requestTimeout = providerTimeout
+	}
+
+	options := providers.ProviderOptions{
+		VertexProject:     cfg.VertexProject,
+		VertexLocation:    cfg.VertexLocation,
+		RequestTimeout:    requestTimeout,
+		RequestsPerMinute: cfg.RateLimits[cfg.Provider],
+		MaxOutputTokens:   cfg.Limits.MaxOutputTokens,
+		UserAgent:         userAgent(cfg),
+	}
+	if cfg.Anthropic != nil {
+		options.AnthropicVersion = strings.TrimSpace(cfg.Anthropic.Version)
+		for _, beta := range strings.Split(cfg.Anthropic.Beta, ",") {
+			if beta = strings.TrimSpace(beta); beta != "" {
+				options.AnthropicBeta = append(options.AnthropicBeta, beta)
+			}
+		}
+	}
+	return options
+}
+
+// userAgent returns the configured userAgent, or mermaid-agent-documenter/<version>
+func userAgent(cfg *Config) string {
+	if value := strings.TrimSpace(cfg.UserAgent); value != "" {
+		return value
+	}
+	return providers.DefaultUserAgent + "/" + Version
+}
+
+// resolveModel returns the model configured for a provider with aliases such
+// as "sonnet" resolved, falling back to the provider's built-in default when
+// none is set
+func resolveModel(cfg *Config, provider string) string {
+	if model := cfg.Models[provider]; model != "" {
+		return cfg.ResolveModelAlias(model)
+	}
+	return defaultModel(provider)
+}
+
+// defaultModel returns the built-in model for a provider
+func defaultModel(provider string) string {
+	return config.DefaultModel(provider)
+}
+
+// conversationWindow returns the configured conversationWindow, or the zero
+// window that sends the whole conversation when none (or an unknown strategy)
+// is configured
+func conversationWindow(cfg *Config) agent.ConversationWindow {
+	if cfg.ConversationWindow == nil {
+		return agent.ConversationWindow{}
+	}
+	if _, err := agent.ParseWindowStrategy(cfg.ConversationWindow.Strategy); err != nil {
+		console.Printf("⚠️  %v; sending the whole conversation\n", err)
+		return agent.ConversationWindow{}
+	}
+	return agent.ConversationWindow{
+		Strategy: cfg.ConversationWindow.Strategy,
+		MaxTurns: cfg.ConversationWindow.MaxTurns,
+	}
+}
+
+// noteDefaultModel says when a run falls back to the provider's built-in model
+// because the config has none for it, e.g. after switching providers
+func noteDefaultModel(cfg *Config) {
+	if cfg.Models[cfg.Provider] != "" {
+		return
+	}
+	if model := defaultModel(cfg.Provider); model != "" {
+		console.Printf("ℹ️  No model is set for %s; using the built-in default %s (change it with 'mad config model set <model>')\n", cfg.Provider, model)
+	}
+}
+
+// usesVertex reports whether the Google provider should authenticate through Vertex AI
+func usesVertex(provider string, cfg *Config) bool {
+	return provider == "google" && cfg.VertexProject != "" && cfg.VertexLocation != ""
+}
+
+// resolveTranscriptPath resolves a transcript argument against the current project
+func resolveTranscriptPath(path string, cfg *Config) (string, error) {
 	var fullPath string
 
-	if config.CurrentProject != nil {
+	if cfg.CurrentProject != nil {
 		// Use current project's directory as base
-		projectRoot := config.CurrentProject.RootDir
+		projectRoot := cfg.CurrentProject.RootDir
 
 		// Handle different path formats
 		if filepath.IsAbs(path) {
@@ -91,15 +207,74 @@ func readTranscript(path string, config *Config) (string, error) {
 		fullPath = strings.Replace(fullPath, "~", home, 1)
 	}
 
+	return fullPath, nil
+}
+
+func readTranscript(ctx context.Context, path string, cfg *Config) (string, error) {
+	if transcript.IsURL(path) {
+		return downloadTranscript(ctx, path, cfg)
+	}
+
+	fullPath, err := resolveTranscriptPath(path, cfg)
+	if err != nil {
+		return "", err
+	}
+
 	data, err := os.ReadFile(fullPath)
 	if err != nil {
-		if config.CurrentProject != nil && strings.Contains(err.Error(), "no such file") {
-			return "", fmt.Errorf("transcript file not found at '%s'. When using a project, files are looked for in '%s/transcripts/' directory. You can also specify full paths like 'transcripts/%s' or absolute paths", fullPath, config.CurrentProject.RootDir, path)
+		if cfg.CurrentProject != nil && strings.Contains(err.Error(), "no such file") {
+			return "", fmt.Errorf("transcript file not found at '%s'. When using a project, files are looked for in '%s/transcripts/' directory. You can also specify full paths like 'transcripts/%s' or absolute paths", fullPath, cfg.CurrentProject.RootDir, path)
 		}
 		return "", err
 	}
 
-	return string(data), nil
+	// Subtitle and Whisper JSON transcripts are reduced to plain dialogue
+	text, format, err := transcript.Normalize(fullPath, data)
+	if err != nil {
+		return "", err
+	}
+	if format != transcript.FormatText {
+		console.Printf("📝 Converted %s transcript to plain dialogue (%d → %d characters)\n", format, len(data), len(text))
+	}
+	return text, nil
+}
+
+// downloadTranscript fetches a transcript from an http(s) URL, with the
+// transcript.bearerToken config when set. Cancelling ctx stops the download.
+func downloadTranscript(ctx context.Context, url string, cfg *Config) (string, error) {
+	token := cfg.Transcript.BearerToken
+	if token != "" {
+		expanded, ok := expandSecret(token)
+		if !ok {
+			return "", fmt.Errorf("transcript.bearerToken references an unset environment variable")
+		}
+		token = expanded
+	}
+
+	data, err := transcript.Fetch(ctx, url, token, cfg.Transcript.MaxDownloadBytes)
+	if err != nil {
+		return "", err
+	}
+	console.Printf("🌐 Downloaded transcript (%d bytes)\n", len(data))
+
+	text, format, err := transcript.Normalize(transcript.URLFileName(url), data)
+	if err != nil {
+		return "", err
+	}
+	if format != transcript.FormatText {
+		console.Printf("📝 Converted %s transcript to plain dialogue (%d → %d characters)\n", format, len(data), len(text))
+	}
+	return text, nil
+}
+
+// transcriptName returns a transcript argument's file name without its
+// extension, e.g. for naming output files
+func transcriptName(arg string) string {
+	name := filepath.Base(arg)
+	if transcript.IsURL(arg) {
+		name = transcript.URLFileName(arg)
+	}
+	return strings.TrimSuffix(name, filepath.Ext(name))
 }
 
 // runCmd represents the run command
@@ -115,164 +290,1075 @@ PATH RESOLUTION:
 When a current project is set, the command automatically looks for transcripts in the project's
 transcripts/ directory. You can specify just the filename and it will be resolved automatically.
 
+Before the agent starts, a summary of the provider, model, estimated tokens and cost, and output
+directory is shown and must be confirmed; --yes skips the question.
+
 Examples:
   mad run transcript.txt                    # Looks in <project>/transcripts/transcript.txt
   mad run transcripts/my-file.txt          # Explicit path: <project>/transcripts/my-file.txt
   mad run /full/path/to/file.txt           # Absolute path (works with/without project)
-  mad run ../other/file.txt               # Relative to project root (when project is set)`,
+  mad run ../other/file.txt               # Relative to project root (when project is set)
+  mad run https://example.com/calls/standup.vtt  # Download over HTTP(S) (transcript.bearerToken)
+  mad run transcript.txt --watch          # Re-run on every save until Ctrl-C
+  mad run transcript.txt --no-image       # Markdown only, for wikis that render Mermaid
+  mad run transcript.txt --disable-tool fetchMermaidDocumentation  # No network lookups
+  mad run transcript.txt --interactive    # Ask for tweaks after the run and apply them
+  mad run transcript.txt --single-file    # One documentation.md for wikis that take a single page
+  mad run transcript.txt --compare        # Benchmark every provider with a key
+  mad run transcript.txt --doc-types "User Flow Diagrams,Data Models" --yes  # No prompts (CI)`,
 	Args: cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		clean, _ := cmd.Flags().GetBool("clean")
+		chunk, _ := cmd.Flags().GetBool("chunk")
+		nonInteractive, _ := cmd.Flags().GetBool("non-interactive")
+		watchMode, _ := cmd.Flags().GetBool("watch")
+		deterministic, _ := cmd.Flags().GetBool("deterministic")
+		planFirst, _ := cmd.Flags().GetBool("plan")
+		autoApprove, _ := cmd.Flags().GetBool("yes")
+		force, _ := cmd.Flags().GetBool("force")
+		fromSummary, _ := cmd.Flags().GetBool("from-summary")
+		lang, _ := cmd.Flags().GetString("lang")
+		instructionsFile, _ := cmd.Flags().GetString("instructions-file")
+		quiet, _ := cmd.Flags().GetBool("quiet")
+		noImage, _ := cmd.Flags().GetBool("no-image")
+		jsonOutput, _ := cmd.Flags().GetBool("json-output")
+		compare, _ := cmd.Flags().GetBool("compare")
+		semantic, _ := cmd.Flags().GetBool("semantic-filter")
+		autoInstall, _ := cmd.Flags().GetBool("auto-install")
+		appendLogs, _ := cmd.Flags().GetBool("append")
+		printPrompt, _ := cmd.Flags().GetBool("print-prompt")
+		interactive, _ := cmd.Flags().GetBool("interactive")
+		singleFile, _ := cmd.Flags().GetBool("single-file")
+		disableToolFlags, _ := cmd.Flags().GetStringSlice("disable-tool")
+		dumpResponses, _ := cmd.Flags().GetBool("dump-responses")
+		preflight, _ := cmd.Flags().GetBool("preflight")
+		keepGoing, _ := cmd.Flags().GetBool("keep-going")
+
+		// Human-readable output moves to stderr so stdout carries only the JSON
+		// report (or the prompt, with --print-prompt), which is written even when
+		// the run fails before it starts
+		reportOut := os.Stdout
+		if jsonOutput || printPrompt {
+			console.SetOutput(os.Stderr)
+		}
+		fail := func(err error) {
+			exitRun(reportOut, jsonOutput, err)
+		}
+
+		if jsonOutput && (watchMode || dryRun) {
+			fail(fmt.Errorf("--json-output cannot be combined with --watch or --dry-run"))
+		}
+		if compare && (watchMode || dryRun || jsonOutput) {
+			fail(fmt.Errorf("--compare cannot be combined with --watch, --dry-run, or --json-output"))
+		}
+		if printPrompt && (watchMode || compare || jsonOutput || semantic) {
+			fail(fmt.Errorf("--print-prompt cannot be combined with --watch, --compare, --json-output, or --semantic-filter"))
+		}
+		if interactive && (watchMode || dryRun || compare || jsonOutput || printPrompt || nonInteractive) {
+			fail(fmt.Errorf("--interactive cannot be combined with --watch, --dry-run, --compare, --json-output, --print-prompt, or --non-interactive"))
+		}
+		if transcript.IsURL(args[0]) && (watchMode || fromSummary) {
+			fail(fmt.Errorf("--watch and --from-summary need a transcript file, not a URL"))
+		}
+		if interactive && !stdinIsTerminal() {
+			fail(fmt.Errorf("--interactive needs a terminal to read refinement instructions"))
+		}
+		if jsonOutput {
+			nonInteractive = true
+		}
+		maxSteps, _ := cmd.Flags().GetInt("max-steps")
+		if cmd.Flags().Changed("max-steps") && maxSteps <= 0 {
+			fail(fmt.Errorf("--max-steps must be positive, got %d", maxSteps))
+		}
+		timeoutSec, _ := cmd.Flags().GetInt("timeout")
+		if cmd.Flags().Changed("timeout") && timeoutSec <= 0 {
+			fail(fmt.Errorf("--timeout must be a positive number of seconds, got %d", timeoutSec))
+		}
+
+		selectedDocTypes, docTypesSet, err := docTypesFromFlags(cmd)
+		if err != nil {
+			fail(err)
+		}
 
 		// Load global config
-		config, err := loadConfig()
+		cfg, err := config.Load()
 		if err != nil {
-			fmt.Printf("Error loading config: %v\n", err)
-			os.Exit(1)
+			fail(fmt.Errorf("loading config: %w", err))
+		}
+		if cmd.Flags().Changed("timeout") {
+			cfg.Limits.RunTimeoutSec = timeoutSec
 		}
 
-		// Get API key from config or environment
-		apiKey := getAPIKey(config.Provider, config)
-		if apiKey == "" {
-			fmt.Printf("Error: API key for provider '%s' not found\n", config.Provider)
-			fmt.Printf("Configure it using: mad config secrets set %s \"your-api-key\"\n", config.Provider)
-			fmt.Printf("Or set environment variable: %s_API_KEY\n", strings.ToUpper(config.Provider))
-			os.Exit(1)
+		// Get API key from config or environment; --compare picks up each provider's own key
+		apiKey := ""
+		if !compare {
+			if err := checkProvider(cfg); err != nil {
+				fail(err)
+			}
+		}
+		if !compare && !printPrompt {
+			if apiKey, err = checkAPIKey(cfg); err != nil {
+				fail(err)
+			}
+		}
+		if !compare {
+			noteDefaultModel(cfg)
+		}
+		// Catch a rejected key or an unreachable provider before reading the transcript
+		if preflight && !compare && !printPrompt && !dryRun {
+			if err := pingProvider(context.Background(), cfg, apiKey); err != nil {
+				fail(fmt.Errorf("%s preflight failed: %w", cfg.Provider, err))
+			}
+			console.Printf("✅ %s is reachable and accepted the API key\n", cfg.Provider)
+		}
+		registerExternalTools()
+		disabledTools, err := disableTools(cfg, disableToolFlags)
+		if err != nil {
+			fail(err)
+		}
+		// Without these tools the run skips images or never prompts
+		if slices.Contains(disabledTools, "generateMermaidImage") {
+			noImage = true
+		}
+		if slices.Contains(disabledTools, "getUserInput") {
+			nonInteractive = true
+		}
+		applyFetchOptions(cfg)
+
+		// Fail before spending tokens when the results could not be saved
+		if !dryRun && !printPrompt {
+			if err := checkOutputDir(cfg); err != nil {
+				fail(err)
+			}
 		}
 
-		// Read transcript (project-aware)
-		transcript, err := readTranscript(args[0], config)
+		// Ctrl-C cancels a transcript download, or the run so the agent can write
+		// its partial manifest and summary (and stops watching); a second Ctrl-C
+		// exits immediately
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		go func() {
+			<-ctx.Done()
+			stop()
+		}()
+
+		// Document the condensed summary written by 'mad summarize' instead of the raw transcript
+		transcriptArg := args[0]
+		if fromSummary {
+			transcriptArg = transcript.SummaryPath(args[0])
+			if summaryPath, err := resolveTranscriptPath(transcriptArg, cfg); err == nil {
+				if _, err := os.Stat(summaryPath); os.IsNotExist(err) {
+					fail(fmt.Errorf("no summary found at %s\nCreate one first with: mad summarize %s", summaryPath, args[0]))
+				}
+			}
+		}
+
+		// The semantic filter compares the transcript against the documentation
+		// types, so they are chosen before it is read
+		var filter transcriptFilter
+		promptDocTypes := !dryRun && !docTypesSet && !jsonOutput
+		if semantic {
+			if promptDocTypes {
+				selectedDocTypes = getDocumentationTypePreferences()
+				promptDocTypes = false
+			}
+			filter = semanticFilter(ctx, cfg, selectedDocTypes)
+		}
+
+		// Read and prepare the transcript (project-aware)
+		segments, err := prepareTranscript(ctx, transcriptArg, cfg, clean, chunk, filter)
 		if err != nil {
-			fmt.Printf("Error reading transcript: %v\n", err)
-			os.Exit(1)
+			fail(err)
 		}
 
-		// Determine output and logs directories - use project-specific if available
-		outputDir := config.OutDir
-		logsDir := filepath.Join(getConfigDir(), "logs") // default global logs
-		if config.CurrentProject != nil {
-			outputDir = filepath.Join(config.CurrentProject.RootDir, "out")
-			logsDir = filepath.Join(config.CurrentProject.RootDir, "logs")
+		if interactive && len(segments) > 1 {
+			fail(fmt.Errorf("--interactive refines a single run, but the transcript was split into %d segments; drop --chunk or raise limits.maxTranscriptChars", len(segments)))
 		}
 
-		// Ask user about documentation types (unless dry run)
-		var selectedDocTypes []string
-		if !dryRun {
+		// Ask user about documentation types (unless dry run, chosen by flag, or non-interactive JSON output)
+		if promptDocTypes {
 			selectedDocTypes = getDocumentationTypePreferences()
 		}
 
 		// Create agent config
-		agentConfig := &agent.AgentConfig{
-			Provider:            config.Provider,
-			Model:               config.Models[config.Provider],
-			APIKey:              apiKey,
-			MaxSteps:            config.Limits.MaxSteps,
-			TimeoutSec:          config.Limits.RunTimeoutSec,
-			TokenBudget:         config.Limits.TokenBudget,
-			CostCeilingUsd:      config.Limits.CostCeilingUsd,
-			ConfidenceThreshold: config.ConfidenceThreshold,
-			OutputDir:           outputDir,
-			LogsDir:             logsDir,
-			RedactPII:           config.Safety.PIIRedaction,
-			StoreChainOfThought: config.Log.StoreChainOfThought,
-			DocumentationTypes:  selectedDocTypes,
+		agentConfig := newAgentConfig(cfg, apiKey, selectedDocTypes)
+		agentConfig.NonInteractive = nonInteractive
+		agentConfig.PlanFirst = planFirst
+		agentConfig.AutoApprovePlan = autoApprove
+		agentConfig.TranscriptName = transcriptName(args[0])
+		agentConfig.Force = force
+		agentConfig.ShowProgress = !quiet
+		agentConfig.SkipImages = noImage
+		agentConfig.SingleFile = singleFile
+		agentConfig.DisabledTools = disabledTools
+		agentConfig.DumpResponses = dumpResponses
+		agentConfig.KeepGoing = keepGoing
+		if appendLogs {
+			agentConfig.RunLogFile = false
+			agentConfig.SkipSharedLog = false
+		}
+		if cmd.Flags().Changed("max-steps") {
+			agentConfig.MaxSteps = maxSteps
+		}
+		if instructionsFile != "" {
+			instructions, err := os.ReadFile(instructionsFile)
+			if err != nil {
+				fail(fmt.Errorf("reading instructions file: %w", err))
+			}
+			agentConfig.SystemPromptExtra = strings.TrimSpace(agentConfig.SystemPromptExtra + "\n" + string(instructions))
+		}
+		if cmd.Flags().Changed("lang") {
+			language, err := agent.ParseLanguage(lang)
+			if err != nil {
+				fail(err)
+			}
+			agentConfig.Language = language
+		}
+		outputDir := agentConfig.OutputDir
+		if deterministic {
+			agentConfig.ProviderOptions = agentConfig.ProviderOptions.Deterministic()
 		}
 
-		// Create and run agent
-		mermaidAgent := agent.NewMermaidDocumenterAgent(agentConfig)
-		mermaidAgent.SetTranscript(transcript)
-
-		ctx, cancel := context.WithTimeout(context.Background(), time.Duration(config.Limits.RunTimeoutSec)*time.Second)
-		defer cancel()
+		if printPrompt {
+			printInitialPrompts(reportOut, segments, agentConfig)
+			return
+		}
 
-		if config.CurrentProject != nil {
-			fmt.Printf("Running Mermaid Documenter Agent on project: %s\n", config.CurrentProject.Name)
-			fmt.Printf("Transcript: transcripts/%s\n", args[0])
+		if cfg.CurrentProject != nil {
+			console.Printf("Running Mermaid Documenter Agent on project: %s\n", cfg.CurrentProject.Name)
+			if transcript.IsURL(transcriptArg) {
+				console.Printf("Transcript: %s\n", transcriptArg)
+			} else {
+				console.Printf("Transcript: transcripts/%s\n", transcriptArg)
+			}
 		} else {
-			fmt.Printf("Running Mermaid Documenter Agent on transcript: %s\n", args[0])
+			console.Printf("Running Mermaid Documenter Agent on transcript: %s\n", transcriptArg)
+		}
+		if !compare {
+			console.Printf("Provider: %s, Model: %s\n", cfg.Provider, agentConfig.Model)
+		}
+		if usesVertex(cfg.Provider, cfg) {
+			console.Printf("Backend: Vertex AI (project: %s, location: %s)\n", cfg.VertexProject, cfg.VertexLocation)
+		}
+		if agentConfig.Language != "" && agentConfig.Language != agent.DefaultLanguage {
+			console.Printf("Language: %s\n", agent.LanguageName(agentConfig.Language))
+		}
+		if docTypesSet {
+			console.Printf("Documentation types: %s\n", strings.Join(selectedDocTypes, ", "))
+		}
+		if cmd.Flags().Changed("max-steps") {
+			console.Printf("Max steps: %d\n", agentConfig.MaxSteps)
+		}
+		if noImage {
+			console.Println("Images: skipped (Markdown with mermaid blocks only)")
+		}
+		if len(disabledTools) > 0 {
+			console.Printf("Disabled tools: %s\n", strings.Join(disabledTools, ", "))
+		}
+		if singleFile {
+			console.Println("Output: a single Markdown document with a section per documentation type")
+		}
+		if deterministic {
+			console.Printf("Deterministic mode: temperature 0, seed %d (best-effort)\n", providers.DeterministicSeed)
 		}
-		fmt.Printf("Provider: %s, Model: %s\n", config.Provider, agentConfig.Model)
 		if len(outputDir) > 60 {
 			// Truncate long paths for display
-			fmt.Printf("Output directory: ...%s\n", outputDir[len(outputDir)-57:])
+			console.Printf("Output directory: ...%s\n", outputDir[len(outputDir)-57:])
 		} else {
-			fmt.Printf("Output directory: %s\n", outputDir)
+			console.Printf("Output directory: %s\n", outputDir)
 		}
 		if dryRun {
-			fmt.Println("🔍 Dry run mode - agent execution skipped.")
+			console.Println("🔍 Dry run mode - agent execution skipped.")
+		} else {
+			// Last chance to abort before tokens are spent or mmdc is installed;
+			// --yes and non-terminals skip the question
+			if !confirmRun(segments, agentConfig, compare, !autoApprove && !nonInteractive && stdinIsTerminal()) {
+				console.Println("Cancelled; nothing was sent to the provider.")
+				return
+			}
+			if !noImage {
+				if err := ensureMermaidCLI(autoInstall); err != nil {
+					fail(err)
+				}
+			}
 		}
 
-		if !dryRun {
-			fmt.Println("🤖 Starting Mermaid Documenter Agent...")
-			fmt.Println()
-
-			err = mermaidAgent.Run(ctx)
+		if compare {
+			comparisons, err := runComparison(ctx, segments, agentConfig, cfg, outputDir)
+			if err != nil && !errors.Is(err, context.Canceled) {
+				fail(err)
+			}
+			printComparison(comparisons, outputDir)
 			if err != nil {
-				fmt.Printf("❌ Agent execution failed: %v\n", err)
+				console.Println("⏹️  Comparison interrupted")
+				os.Exit(130)
+			}
+			if !anyProviderSucceeded(comparisons) {
 				os.Exit(1)
 			}
+			return
+		}
 
-			fmt.Println("✅ Agent execution completed successfully!")
+		baseName := agentConfig.TranscriptName
+		if !dryRun {
+			var results []*agent.RunResult
+			if interactive {
+				results, err = runInteractive(ctx, segments[0], agentConfig, cfg.Limits.RunTimeoutSec)
+			} else {
+				results, err = runSegments(ctx, segments, agentConfig, cfg.Limits.RunTimeoutSec, baseName)
+			}
+			interrupted := err != nil && errors.Is(ctx.Err(), context.Canceled)
+			if interrupted {
+				printInterrupted(results, outputDir)
+			} else if err != nil {
+				console.Printf("❌ Agent execution failed: %v\n", err)
+			}
+			if jsonOutput {
+				if reportErr := writeRunReport(reportOut, results, err); reportErr != nil {
+					console.Printf("Error writing JSON report: %v\n", reportErr)
+					os.Exit(1)
+				}
+			}
+			if interrupted {
+				os.Exit(130) // 128 + SIGINT, as shells report it
+			}
+			if err != nil && !watchMode {
+				os.Exit(1)
+			}
 		} else {
-			fmt.Println("🔍 Dry run mode - agent execution skipped.")
+			printDryRunEstimate(segments, agentConfig)
+		}
+
+		if !watchMode {
+			return
+		}
+
+		transcriptPath, err := resolveTranscriptPath(transcriptArg, cfg)
+		if err != nil {
+			console.Printf("Error resolving transcript path: %v\n", err)
+			os.Exit(1)
 		}
+
+		console.Printf("👀 Watching %s for changes (Ctrl-C to stop)...\n", transcriptPath)
+		runNumber := 1
+		watch.File(ctx, transcriptPath, watch.Options{Interval: watch.DefaultInterval, Debounce: watch.DefaultDebounce}, func() {
+			runNumber++
+			console.Println()
+			console.Printf("━━━━━━━━━━ Change detected · run #%d · %s ━━━━━━━━━━\n", runNumber, time.Now().Format("15:04:05"))
+
+			segments, err := prepareTranscript(ctx, transcriptArg, cfg, clean, chunk, filter)
+			if err != nil {
+				console.Printf("Error: %v\n", err)
+			} else if dryRun {
+				printDryRunEstimate(segments, agentConfig)
+			} else if _, err := runSegments(ctx, segments, agentConfig, cfg.Limits.RunTimeoutSec, baseName); err != nil {
+				console.Printf("❌ Agent execution failed: %v\n", err)
+			}
+			console.Printf("👀 Watching %s for changes (Ctrl-C to stop)...\n", transcriptPath)
+		})
+		console.Println()
+		console.Println("👋 Stopped watching.")
 	},
 }
 
+// requireProvider exits with setup instructions when the configured provider
+// is empty or unsupported, instead of silently falling back to OpenAI
+func requireProvider(cfg *Config) {
+	if err := checkProvider(cfg); err != nil {
+		console.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// checkProvider returns an error with setup instructions when the configured
+// provider is empty or unsupported
+func checkProvider(cfg *Config) error {
+	if err := providers.ValidateProvider(cfg.Provider); err != nil {
+		return fmt.Errorf("%w\nChoose one with: mad config provider set <%s>", err, strings.Join(providers.SupportedProviders, "|"))
+	}
+	return nil
+}
+
+// requireAPIKey returns the API key for the configured provider, exiting with
+// setup instructions when none is available
+func requireAPIKey(cfg *Config) string {
+	apiKey, err := checkAPIKey(cfg)
+	if err != nil {
+		console.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	return apiKey
+}
+
+// checkAPIKey returns the API key for the configured provider, or an error
+// with setup instructions when none is available
+func checkAPIKey(cfg *Config) (string, error) {
+	apiKey := getAPIKey(cfg.Provider, cfg)
+	if apiKey == "" && !usesVertex(cfg.Provider, cfg) {
+		return "", fmt.Errorf("API key for provider '%s' not found\nConfigure it using: mad config secrets set %s \"your-api-key\"\nOr set environment variable: %s_API_KEY",
+			cfg.Provider, cfg.Provider, strings.ToUpper(cfg.Provider))
+	}
+	return apiKey, nil
+}
+
+// ensureMermaidCLI warns before the run when mmdc is missing, or installs it
+// with --auto-install, returning an error when the install fails
+func ensureMermaidCLI(autoInstall bool) error {
+	if tools.MermaidCLIInstalled() {
+		return nil
+	}
+	if !autoInstall {
+		console.Printf("⚠️  %s\n", tools.MissingMermaidCLIMessage())
+		console.Println("   Re-run with --auto-install to install it now, or with --no-image to skip images.")
+		return nil
+	}
+
+	console.Printf("📦 Installing Mermaid CLI (npm install -g %s)...\n", tools.MermaidCLIPackage)
+	if err := tools.InstallMermaidCLI(console.Output()); err != nil {
+		return fmt.Errorf("installing Mermaid CLI: %w\nInstall it manually, or re-run with --no-image to skip images", err)
+	}
+	console.Println("✅ Mermaid CLI installed")
+	return nil
+}
+
+// registerExternalTools makes the tools described in ~/mermaid-agent-documenter/tools/
+// available to the agent, warning about manifests that could not be loaded
+func registerExternalTools() {
+	registered, errs := tools.RegisterExternalTools(filepath.Join(config.Dir(), "tools"))
+	for _, err := range errs {
+		console.Printf("⚠️  Skipping external tool %v\n", err)
+	}
+	for _, tool := range registered {
+		console.Printf("🔌 External tool: %s\n", tool.Name())
+	}
+}
+
+// disableTools blocks the tools named in the disabledTools config and the
+// --disable-tool flags for this run, returning their names
+func disableTools(cfg *Config, flagged []string) ([]string, error) {
+	var names []string
+	for _, name := range append(append([]string{}, cfg.DisabledTools...), flagged...) {
+		if name = strings.TrimSpace(name); name != "" && !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	if err := tools.ValidateDisabledTools(names); err != nil {
+		return nil, err
+	}
+	tools.SetDisabledTools(names)
+	return names, nil
+}
+
+// applyFetchOptions sets how fetchMermaidDocumentation downloads pages from
+// limits.fetchTimeoutSec, limits.fetchRetries and limits.fetchBackoffMs
+func applyFetchOptions(cfg *Config) {
+	tools.SetFetchOptions(tools.FetchOptions{
+		Timeout: time.Duration(cfg.Limits.FetchTimeoutSec) * time.Second,
+		Retries: cfg.Limits.FetchRetries,
+		Backoff: time.Duration(cfg.Limits.FetchBackoffMs) * time.Millisecond,
+	})
+}
+
+// runDirectories returns the output and logs directories, using the current
+// project's out/ and logs/ when one is set
+func runDirectories(cfg *Config) (string, string) {
+	outputDir := cfg.OutDir
+	logsDir := filepath.Join(config.Dir(), "logs") // default global logs
+	if cfg.CurrentProject != nil {
+		outputDir = filepath.Join(cfg.CurrentProject.RootDir, "out")
+		logsDir = filepath.Join(cfg.CurrentProject.RootDir, "logs")
+	}
+	return outputDir, logsDir
+}
+
+// checkOutputDir confirms the run's output directory exists (creating it if
+// needed) and accepts new files
+func checkOutputDir(cfg *Config) error {
+	outputDir, _ := runDirectories(cfg)
+	if strings.HasPrefix(outputDir, "~") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return err
+		}
+		outputDir = strings.Replace(outputDir, "~", home, 1)
+	}
+	return output.CheckWritable(outputDir)
+}
+
+// newAgentConfig builds the agent configuration shared by run and plan
+func newAgentConfig(cfg *Config, apiKey string, docTypes []string) *agent.AgentConfig {
+	outputDir, logsDir := runDirectories(cfg)
+	maintainLogs(logsDir, cfg.Log)
+
+	return &agent.AgentConfig{
+		Provider:               cfg.Provider,
+		Model:                  resolveModel(cfg, cfg.Provider),
+		APIKey:                 apiKey,
+		ProviderOptions:        providerOptions(cfg),
+		MaxSteps:               cfg.Limits.MaxSteps,
+		MaxConsecutiveFailures: cfg.Limits.MaxConsecutiveFailures,
+		MaxParseRetries:        cfg.Limits.MaxParseRetries,
+		NativeJSON:             cfg.UsesNativeJSON(),
+		MaxOutputTokensCeiling: cfg.Limits.MaxOutputTokensCeiling,
+		TimeoutSec:             cfg.Limits.RunTimeoutSec,
+		TokenBudget:            cfg.Limits.TokenBudget,
+		CostCeilingUsd:         cfg.Limits.CostCeilingUsd,
+		ConfidenceThreshold:    cfg.ConfidenceThreshold,
+		OutputDir:              outputDir,
+		ArchiveRuns:            cfg.ArchiveRuns,
+		LogsDir:                logsDir,
+		RunLogFile:             cfg.Log.WritesRunFiles(),
+		SkipSharedLog:          !cfg.Log.WritesSharedLog(),
+		RedactPII:              cfg.Safety.RedactsPII(),
+		StrictSafety:           cfg.Safety.Strict(),
+		StoreChainOfThought:    cfg.Log.StoreChainOfThought,
+		MaxLoggedResponseChars: cfg.Log.MaxLoggedResponseChars,
+		StoreLastTurnOnly:      cfg.Log.StoreLastTurnOnly,
+		DocumentationTypes:     docTypes,
+		OutputFormat:           cfg.OutputFormat,
+		EmbedImages:            cfg.EmbedImages,
+		Language:               cfg.Language,
+		SystemPromptExtra:      cfg.SystemPromptExtra,
+		PreferSimpleDiagrams:   cfg.PrefersSimpleDiagrams(),
+		ShowProgress:           true,
+		ImageFormatFallback:    cfg.ImageFormatFallback,
+		AutoFixMermaid:         cfg.AutoFixMermaid,
+		ConversationWindow:     conversationWindow(cfg),
+		FileNameTemplate:       cfg.FileNameTemplate,
+	}
+}
+
+// prepareTranscript reads a transcript, optionally cleans and filters it, and splits it
+// into segments when it exceeds limits.maxTranscriptChars and chunking is enabled
+func prepareTranscript(ctx context.Context, path string, cfg *Config, clean, chunk bool, filter transcriptFilter) ([]string, error) {
+	transcriptText, err := readTranscript(ctx, path, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read transcript: %w", err)
+	}
+
+	// Optionally strip chat markup before sending
+	if clean {
+		cleaned, err := transcript.Clean(transcriptText, transcript.CleanOptions{
+			StripTimestamps:  cfg.Transcript.StripTimestamps,
+			TimestampPattern: cfg.Transcript.TimestampPattern,
+			StripSpeakers:    cfg.Transcript.StripSpeakers,
+			SpeakerPattern:   cfg.Transcript.SpeakerPattern,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to clean transcript: %w", err)
+		}
+		transcriptText = cleaned.Text
+		console.Printf("🧹 Cleaned transcript: removed %d bytes (%d → %d)\n", cleaned.BytesRemoved(), cleaned.OriginalBytes, cleaned.CleanedBytes)
+	}
+
+	// Keep personal data out of what is sent to the model (safety.piiRedaction, always in strict mode)
+	if cfg.Safety.RedactsPII() {
+		redacted, count := transcript.RedactPII(transcriptText)
+		if count > 0 {
+			transcriptText = redacted
+			console.Printf("🔒 Redacted %d PII matches (emails, phone and card numbers) from the transcript\n", count)
+		}
+	}
+
+	// Refuse to spend a run on an empty transcript, and flag accidentally short ones
+	short, err := transcript.Check(transcriptText, cfg.Limits.MinTranscriptChars)
+	if errors.Is(err, transcript.ErrEmpty) {
+		if clean {
+			return nil, fmt.Errorf("%w after cleaning: nothing is left to document in %s", err, path)
+		}
+		return nil, fmt.Errorf("%w or only whitespace: nothing to document in %s", err, path)
+	}
+	if short {
+		console.Printf("⚠️  Transcript is only %d characters; check that %s is the file you meant (limits.minTranscriptChars)\n",
+			len([]rune(strings.TrimSpace(transcriptText))), path)
+	}
+
+	if filter != nil {
+		filtered, err := filter(transcriptText)
+		if err != nil {
+			return nil, err
+		}
+		transcriptText = filtered
+	}
+
+	// Guard against transcripts that would blow the context window
+	segments := []string{transcriptText}
+	if maxChars := cfg.Limits.MaxTranscriptChars; maxChars > 0 && len(transcriptText) > maxChars {
+		console.Printf("⚠️  Transcript is %d characters (~%d tokens), above the limit of %d characters\n",
+			len(transcriptText), providers.EstimateTokens(transcriptText), maxChars)
+		if !chunk {
+			console.Println("   Large transcripts can exceed the model's context window and fail mid-run.")
+			console.Println("   Options:")
+			console.Println("   • Re-run with --chunk to process the transcript in overlapping segments")
+			console.Println("   • Re-run with --clean to strip chat markup")
+			console.Println("   • Raise limits.maxTranscriptChars in config.json")
+			return nil, fmt.Errorf("transcript exceeds limits.maxTranscriptChars (%d)", maxChars)
+		}
+		segments = transcript.Chunk(transcriptText, maxChars, chunkOverlapChars)
+		console.Printf("✂️  Split transcript into %d overlapping segments\n", len(segments))
+	}
+
+	return segments, nil
+}
+
+// runSegments runs the agent over each transcript segment and merges the
+// documentation when there is more than one
+func runSegments(ctx context.Context, segments []string, agentConfig *agent.AgentConfig, runTimeoutSec int, baseName string) ([]*agent.RunResult, error) {
+	console.Println("🤖 Starting Mermaid Documenter Agent...")
+	console.Println()
+
+	var results []*agent.RunResult
+	outputDir := agentConfig.OutputDir
+	segmentConfig := agentConfig
+	for i, segment := range segments {
+		if len(segments) > 1 {
+			console.Printf("━━━ Segment %d of %d ━━━\n", i+1, len(segments))
+		}
+
+		// Create and run agent
+		mermaidAgent := agent.NewMermaidDocumenterAgent(segmentConfig)
+		mermaidAgent.SetTranscript(segment)
+		if len(segments) > 1 {
+			mermaidAgent.SetChunk(i+1, len(segments))
+			if segmentConfig.ArchiveRuns {
+				// The later segments and the merged document go in the first
+				// segment's archive directory, so the chunked run is archived as one
+				shared := *mermaidAgent.Config
+				shared.ArchiveRuns = false
+				segmentConfig = &shared
+				outputDir = shared.OutputDir
+			}
+		}
+
+		runCtx, cancel := context.WithTimeout(ctx, time.Duration(runTimeoutSec)*time.Second)
+		result, err := mermaidAgent.Run(runCtx)
+		cancel()
+		printRunSummary(result)
+		if result != nil {
+			results = append(results, result)
+		}
+		if err != nil {
+			return results, err
+		}
+	}
+
+	if len(results) > 1 {
+		mergedPath, err := mergeChunkDocumentation(results, outputDir, baseName)
+		if err != nil {
+			console.Printf("⚠️  Failed to merge segment documentation: %v\n", err)
+		} else if mergedPath != "" {
+			console.Printf("📚 Merged documentation: %s\n", mergedPath)
+		}
+	}
+
+	console.Println("✅ Agent execution completed successfully!")
+	return results, nil
+}
+
+// runInteractive runs the agent on a transcript and then reads refinement
+// instructions until an empty line, "done", or Ctrl-C. Each refinement
+// continues the same conversation and updates the run's files.
+func runInteractive(ctx context.Context, segment string, agentConfig *agent.AgentConfig, runTimeoutSec int) ([]*agent.RunResult, error) {
+	console.Println("🤖 Starting Mermaid Documenter Agent...")
+	console.Println()
+
+	mermaidAgent := agent.NewMermaidDocumenterAgent(agentConfig)
+	mermaidAgent.SetTranscript(segment)
+
+	runCtx, cancel := context.WithTimeout(ctx, time.Duration(runTimeoutSec)*time.Second)
+	result, err := mermaidAgent.Run(runCtx)
+	cancel()
+	printRunSummary(result)
+	if err != nil {
+		return []*agent.RunResult{result}, err
+	}
+
+	result, err = refineLoop(ctx, mermaidAgent, result, runTimeoutSec)
+	if err != nil {
+		return []*agent.RunResult{result}, err
+	}
+
+	console.Println("✅ Agent execution completed successfully!")
+	return []*agent.RunResult{result}, nil
+}
+
+// refineLoop reads refinement instructions until an empty line, "done", or
+// Ctrl-C and applies each to the agent's run, returning the latest result.
+// A failed refinement is reported and the loop goes on; the error is only
+// returned when the session was interrupted.
+func refineLoop(ctx context.Context, mermaidAgent *agent.MermaidDocumenterAgent, result *agent.RunResult, runTimeoutSec int) (*agent.RunResult, error) {
+	console.Println("💬 Describe a change to refine the documentation (e.g. \"make the sequence diagram include error paths\").")
+	console.Println("   Press Enter on an empty line or type 'done' to finish.")
+	for ctx.Err() == nil {
+		console.Print("✏️  Refine> ")
+		instruction := readLine()
+		if instruction == "" || strings.EqualFold(instruction, "done") || strings.EqualFold(instruction, "exit") {
+			break
+		}
+
+		runCtx, cancel := context.WithTimeout(ctx, time.Duration(runTimeoutSec)*time.Second)
+		var err error
+		result, err = mermaidAgent.Refine(runCtx, instruction)
+		cancel()
+		printRunSummary(result)
+		if err != nil {
+			if ctx.Err() != nil {
+				return result, err
+			}
+			console.Printf("❌ Refinement failed: %v\n", err)
+		}
+	}
+	return result, nil
+}
+
+// printInitialPrompts prints the prompt the first model call would send for
+// each transcript segment, without calling the provider
+func printInitialPrompts(out io.Writer, segments []string, agentConfig *agent.AgentConfig) {
+	for i, segment := range segments {
+		mermaidAgent := agent.NewMermaidDocumenterAgent(agentConfig)
+		mermaidAgent.SetTranscript(segment)
+		if len(segments) > 1 {
+			mermaidAgent.SetChunk(i+1, len(segments))
+			fmt.Fprintf(out, "━━━ Segment %d of %d ━━━\n", i+1, len(segments))
+		}
+		prompt := mermaidAgent.InitialPrompt()
+		fmt.Fprint(out, prompt)
+		console.Printf("📏 Segment %d: %d characters, ~%d tokens\n", i+1, len(prompt), providers.EstimateTokens(prompt))
+	}
+	console.Println("🔍 Prompt printed; the provider was not called.")
+}
+
+// printInterrupted reports what an interrupted run completed before it stopped
+func printInterrupted(results []*agent.RunResult, outputDir string) {
+	var artifacts []string
+	for _, result := range results {
+		artifacts = append(artifacts, result.Artifacts...)
+	}
+
+	console.Println()
+	console.Println("⏹️  Run interrupted")
+	if len(artifacts) == 0 {
+		console.Println("No files were completed before the interruption.")
+		return
+	}
+	// Archived runs record their manifest in their own directory
+	if last := results[len(results)-1]; last.OutputDir != "" {
+		outputDir = last.OutputDir
+	}
+	console.Printf("Completed %d files before the interruption (recorded in %s):\n", len(artifacts), filepath.Join(outputDir, manifest.FileName))
+	for _, artifact := range artifacts {
+		console.Printf("  📄 %s\n", artifact)
+	}
+	console.Println("Re-run the same command to regenerate the documentation in full.")
+}
+
+// estimateSegments adds up the estimates of running the agent on each segment
+func estimateSegments(segments []string, agentConfig *agent.AgentConfig) agent.Estimate {
+	var total agent.Estimate
+	for _, segment := range segments {
+		mermaidAgent := agent.NewMermaidDocumenterAgent(agentConfig)
+		mermaidAgent.SetTranscript(segment)
+		estimate := mermaidAgent.Estimate()
+
+		total.PromptTokens += estimate.PromptTokens
+		total.MinTokens += estimate.MinTokens
+		total.MaxTokens += estimate.MaxTokens
+		total.MinCostUsd += estimate.MinCostUsd
+		total.MaxCostUsd += estimate.MaxCostUsd
+		total.MinSteps, total.MaxSteps, total.Priced = estimate.MinSteps, estimate.MaxSteps, estimate.Priced
+	}
+	return total
+}
+
+// confirmRun prints what a run is about to spend and where it writes, and asks
+// to proceed when ask is set. It reports false when the user declines.
+func confirmRun(segments []string, agentConfig *agent.AgentConfig, compare, ask bool) bool {
+	total := estimateSegments(segments, agentConfig)
+
+	console.Println()
+	console.Println("📋 About to run")
+	if compare {
+		console.Println("  Providers:       every provider with an API key")
+	} else {
+		console.Printf("  Provider:        %s\n", agentConfig.Provider)
+		console.Printf("  Model:           %s\n", agentConfig.Model)
+	}
+	console.Printf("  Prompt tokens:   ~%d (system prompt + transcript", total.PromptTokens)
+	if len(segments) > 1 {
+		console.Printf(", %d segments", len(segments))
+	}
+	console.Println(")")
+	switch {
+	case compare:
+		// each provider prices its own model
+	case total.Priced:
+		console.Printf("  Estimated cost:  up to $%.4f (%d steps per run)\n", total.MaxCostUsd, total.MaxSteps)
+	default:
+		console.Printf("  Estimated cost:  unknown (no pricing for model %s)\n", agentConfig.Model)
+	}
+	if agentConfig.CostCeilingUsd > 0 {
+		console.Printf("  Cost ceiling:    $%.2f (limits.costCeilingUsd)\n", agentConfig.CostCeilingUsd)
+	}
+	console.Printf("  Output:          %s\n", agentConfig.OutputDir)
+
+	if !ask {
+		return true
+	}
+	console.Print("Proceed? (y/N): ")
+	return isYes(readLine())
+}
+
+// printDryRunEstimate prints the projected token usage and cost of running
+// the agent on each transcript segment
+func printDryRunEstimate(segments []string, agentConfig *agent.AgentConfig) {
+	total := estimateSegments(segments, agentConfig)
+
+	console.Println()
+	console.Println("💰 Estimate")
+	console.Printf("  Initial prompt:  ~%d tokens (system prompt + transcript)\n", total.PromptTokens)
+	if len(segments) > 1 {
+		console.Printf("  Segments:        %d (one agent run each)\n", len(segments))
+	}
+	console.Printf("  Projected usage: ~%d – %d tokens (%d–%d steps per run)\n", total.MinTokens, total.MaxTokens, total.MinSteps, total.MaxSteps)
+	if !total.Priced {
+		console.Printf("  Projected cost:  unknown (no pricing for model %s)\n", agentConfig.Model)
+		return
+	}
+	console.Printf("  Projected cost:  $%.4f – $%.4f with %s\n", total.MinCostUsd, total.MaxCostUsd, agentConfig.Model)
+
+	if agentConfig.CostCeilingUsd > 0 && total.MaxCostUsd > agentConfig.CostCeilingUsd {
+		console.Printf("⚠️  The upper estimate exceeds limits.costCeilingUsd ($%.2f)\n", agentConfig.CostCeilingUsd)
+	}
+	if agentConfig.TokenBudget > 0 && total.MaxTokens > agentConfig.TokenBudget {
+		console.Printf("⚠️  The upper estimate exceeds limits.tokenBudget (%d tokens)\n", agentConfig.TokenBudget)
+	}
+}
+
+// chunkOverlapChars is how much text consecutive transcript segments share
+const chunkOverlapChars = 1000
+
+// mergeChunkDocumentation concatenates the Markdown produced for each transcript
+// segment into a single <name>_merged.md in the output directory
+func mergeChunkDocumentation(results []*agent.RunResult, outputDir, name string) (string, error) {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("# %s\n\n", name))
+	sb.WriteString(fmt.Sprintf("Merged from %d transcript segments.\n", len(results)))
+
+	merged := 0
+	for i, result := range results {
+		for _, artifact := range result.Artifacts {
+			if filepath.Ext(artifact) != ".md" {
+				continue
+			}
+			data, err := os.ReadFile(artifact)
+			if err != nil {
+				return "", fmt.Errorf("failed to read %s: %w", artifact, err)
+			}
+			sb.WriteString(fmt.Sprintf("\n## Part %d: %s\n\n", i+1, filepath.Base(artifact)))
+			sb.Write(data)
+			sb.WriteString("\n")
+			merged++
+		}
+	}
+
+	if merged == 0 {
+		return "", nil
+	}
+
+	if strings.HasPrefix(outputDir, "~") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		outputDir = strings.Replace(outputDir, "~", home, 1)
+	}
+
+	mergedPath := filepath.Join(outputDir, name+"_merged.md")
+	if err := os.WriteFile(mergedPath, []byte(sb.String()), 0644); err != nil {
+		return "", err
+	}
+	return mergedPath, nil
+}
+
+// printRunSummary prints a human-readable summary of an agent run
+func printRunSummary(result *agent.RunResult) {
+	if result == nil {
+		return
+	}
+
+	console.Println()
+	console.Println("📊 Run Summary")
+	console.Println("══════════════")
+	console.Printf("Run ID: %s\n", result.RunID)
+	console.Printf("Steps: %d\n", result.Steps)
+	if result.Refinements > 0 {
+		console.Printf("Refinements: %d\n", result.Refinements)
+	}
+	console.Printf("Termination: %s\n", result.TerminationReason)
+	console.Printf("Duration: %s\n", result.Duration().Round(time.Second))
+	console.Printf("Estimated tokens: %d (prompt %d, completion %d)\n", result.TotalTokens(), result.PromptTokens, result.CompletionTokens)
+	console.Printf("Estimated cost: $%.4f\n", result.EstimatedCostUsd)
+	printConfidenceStats(logs.SummarizeConfidence(result.Confidences))
+	printLatencyStats(logs.SummarizeLatency(result.LatenciesMs))
+	if len(result.Artifacts) == 0 {
+		console.Println("Artifacts: none")
+	} else {
+		console.Printf("Artifacts (%d):\n", len(result.Artifacts))
+		for _, artifact := range result.Artifacts {
+			console.Printf("  📄 %s\n", artifact)
+		}
+	}
+	console.Println()
+}
+
 func init() {
 	rootCmd.AddCommand(runCmd)
-	runCmd.Flags().Bool("dry-run", false, "Print planned actions without executing")
+	runCmd.Flags().Bool("dry-run", false, "Print planned actions and a token/cost estimate without executing")
+	runCmd.Flags().String("doc-types", "", "Comma-separated documentation types to generate, skipping the prompt (e.g. \"User Flow Diagrams,Data Models\")")
+	runCmd.Flags().Bool("all-doc-types", false, "Generate every documentation type, skipping the prompt")
+	runCmd.Flags().String("instructions-file", "", "File of extra instructions (house style) appended to the system prompt, after systemPromptExtra")
+	runCmd.Flags().Int("max-steps", 0, "Maximum agent steps for this run; overrides limits.maxSteps")
+	runCmd.Flags().Bool("keep-going", false, "Keep running after repeated tool failures, up to the step limit, instead of stopping at limits.maxConsecutiveFailures")
+	runCmd.Flags().Int("timeout", 0, "Overall timeout in seconds for this run; overrides limits.runTimeoutSec")
+	runCmd.Flags().String("lang", "", "Write documentation in this language (e.g. es, fr, pt-br); overrides the language config")
+	runCmd.Flags().Bool("chunk", false, "Split transcripts above limits.maxTranscriptChars into overlapping segments and merge the results")
+	runCmd.Flags().Bool("json-output", false, "Print a single JSON report (status, run ID, artifacts, tokens, cost, errors) on stdout; other output goes to stderr")
+	runCmd.Flags().Bool("semantic-filter", false, "Embed the transcript in chunks and send only those most relevant to the selected documentation types")
+	runCmd.Flags().Bool("compare", false, "Run the transcript through every provider with an API key into out/<provider>/ and print a side-by-side summary")
+	runCmd.Flags().Bool("auto-install", false, "Install Mermaid CLI with npm before the run when mmdc is missing")
+	runCmd.Flags().Bool("print-prompt", false, "Print the system and user prompt the first model call would send, then exit without calling the provider")
+	runCmd.Flags().Bool("single-file", false, "Write one Markdown document with an H2 section per documentation type, rendering each diagram to its own image")
+	runCmd.Flags().Bool("interactive", false, "After the run, read refinement instructions and apply them to the same files, continuing the conversation")
+	runCmd.Flags().Bool("append", false, "Append step logs to the shared logs.jsonl only, without a per-run logs/<run-id>.jsonl")
+	runCmd.Flags().Bool("preflight", false, "Check that the provider is reachable and accepts the API key before starting (see 'mad doctor')")
+	runCmd.Flags().Bool("dump-responses", false, "Save each step's raw model response, before cleaning or parsing, to logs/<run-id>/step-N.txt")
+	runCmd.Flags().StringSlice("disable-tool", nil, "Refuse this agent tool for the run, e.g. fetchMermaidDocumentation (repeatable or comma-separated; adds to disabledTools)")
+	runCmd.Flags().Bool("no-image", false, "Write Markdown with mermaid blocks only and skip SVG generation (no mmdc needed)")
+	runCmd.Flags().BoolP("quiet", "q", false, "Hide the spinner and status lines shown while waiting on the model")
+	runCmd.Flags().Bool("non-interactive", false, "Abort instead of prompting when the agent asks for clarification (for CI)")
+	runCmd.Flags().Bool("plan", false, "Have the agent propose a plan of files and diagram types for approval before executing")
+	runCmd.Flags().BoolP("yes", "y", false, "Start without the confirmation prompt, and auto-approve the plan (with --plan)")
+	runCmd.Flags().Bool("from-summary", false, "Document <name>.summary.txt written by 'mad summarize' instead of the full transcript")
+	runCmd.Flags().Bool("force", false, "Regenerate everything, even when the transcript and diagrams are unchanged since the last run")
+	runCmd.Flags().Bool("deterministic", false, "Use temperature 0 and a fixed seed for reproducible output (best-effort)")
+	runCmd.Flags().Bool("watch", false, "Watch the transcript and re-run the agent whenever it is saved (Ctrl-C to stop)")
+	runCmd.Flags().Bool("clean", false, "Strip chat markup (timestamps, quote markers, blank runs) from the transcript before sending")
+}
+
+// documentationTypes is the canonical list of documentation types a run can target
+var documentationTypes = []string{
+	"User Flow Diagrams",
+	"System Architecture",
+	"Data Models (ER Diagrams)",
+	"API Documentation",
+	"Database Schema",
+	"Deployment Diagrams",
+	"Security Analysis",
+	"Performance Considerations",
+	"Error Handling",
+	"Integration Guides",
+}
+
+// parseDocTypes resolves a comma-separated list of documentation type names
+// against the canonical list. Names match case-insensitively, with or without
+// a parenthetical or "Diagrams" suffix (e.g. "data models", "user-flow").
+func parseDocTypes(value string) ([]string, error) {
+	var selected []string
+	seen := map[string]bool{}
+	for _, name := range strings.Split(value, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+
+		match := ""
+		for _, docType := range documentationTypes {
+			if strings.EqualFold(docType, name) || agent.DocTypeDir(docType) == agent.DocTypeDir(name) {
+				match = docType
+				break
+			}
+		}
+		if match == "" {
+			return nil, fmt.Errorf("unknown documentation type %q (valid: %s)", name, strings.Join(documentationTypes, ", "))
+		}
+		if !seen[match] {
+			seen[match] = true
+			selected = append(selected, match)
+		}
+	}
+
+	if len(selected) == 0 {
+		return nil, fmt.Errorf("--doc-types needs at least one documentation type")
+	}
+	return selected, nil
+}
+
+// docTypesFromFlags returns the documentation types chosen with --doc-types or
+// --all-doc-types, and whether either flag was given
+func docTypesFromFlags(cmd *cobra.Command) ([]string, bool, error) {
+	docTypes, _ := cmd.Flags().GetString("doc-types")
+	allDocTypes, _ := cmd.Flags().GetBool("all-doc-types")
+
+	switch {
+	case allDocTypes && cmd.Flags().Changed("doc-types"):
+		return nil, false, fmt.Errorf("--doc-types and --all-doc-types cannot be used together")
+	case allDocTypes:
+		return append([]string(nil), documentationTypes...), true, nil
+	case cmd.Flags().Changed("doc-types"):
+		selected, err := parseDocTypes(docTypes)
+		return selected, true, err
+	}
+	return nil, false, nil
+}
+
+// readLine reads a full line of input, including spaces, without the trailing
+// newline. It shares console's stdin reader with the agent's getUserInput tool.
+func readLine() string {
+	line, _ := console.ReadLine()
+	return line
+}
+
+// isYes reports whether an answer is y or yes, in any case
+func isYes(answer string) bool {
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
 }
 
 // getDocumentationTypePreferences prompts the user to select documentation types
 func getDocumentationTypePreferences() []string {
-	fmt.Println("📋 Documentation Types")
-	fmt.Println("═══════════════════════")
-	fmt.Println("Would you like to specify the types of documentation to generate?")
-	fmt.Print("(y/N): ")
+	console.Println("📋 Documentation Types")
+	console.Println("═══════════════════════")
+	console.Println("Would you like to specify the types of documentation to generate?")
+	console.Print("(y/N): ")
 
-	var response string
-	fmt.Scanln(&response)
-
-	if response != "y" && response != "Y" {
-		fmt.Println("ℹ️  Agent will generate relevant documentation automatically.")
-		fmt.Println()
+	if !isYes(readLine()) {
+		console.Println("ℹ️  Agent will generate relevant documentation automatically.")
+		console.Println()
 		return []string{}
 	}
 
 	// Show available documentation types
-	docTypes := []string{
-		"User Flow Diagrams",
-		"System Architecture",
-		"Data Models (ER Diagrams)",
-		"API Documentation",
-		"Database Schema",
-		"Deployment Diagrams",
-		"Security Analysis",
-		"Performance Considerations",
-		"Error Handling",
-		"Integration Guides",
-	}
-
-	fmt.Println()
-	fmt.Println("Available Documentation Types:")
-	fmt.Println("══════════════════════════════")
+	docTypes := documentationTypes
+
+	console.Println()
+	console.Println("Available Documentation Types:")
+	console.Println("══════════════════════════════")
 
 	for i, docType := range docTypes {
-		fmt.Printf("%d. %s\n", i+1, docType)
+		console.Printf("%d. %s\n", i+1, docType)
 	}
 
-	fmt.Println()
-	fmt.Println("Enter numbers separated by commas (e.g., 1,3,5)")
-	fmt.Println("Or press Enter for all types:")
-	fmt.Print("Selection: ")
+	console.Println()
+	console.Println("Enter numbers separated by commas (e.g., 1,3,5)")
+	console.Println("Or press Enter for all types:")
+	console.Print("Selection: ")
 
-	var selection string
-	fmt.Scanln(&selection)
+	selection := readLine()
 
 	if strings.TrimSpace(selection) == "" {
-		fmt.Println("ℹ️  Generating all documentation types.")
-		fmt.Println()
+		console.Println("ℹ️  Generating all documentation types.")
+		console.Println()
 		return docTypes
 	}
 
@@ -294,21 +1380,21 @@ func getDocumentationTypePreferences() []string {
 			if index >= 0 && index < len(docTypes) {
 				selectedTypes = append(selectedTypes, docTypes[index])
 			} else {
-				fmt.Printf("⚠️  Invalid selection: %s (must be 1-%d)\n", part, len(docTypes))
+				console.Printf("⚠️  Invalid selection: %s (must be 1-%d)\n", part, len(docTypes))
 			}
 		} else {
-			fmt.Printf("⚠️  Invalid input: %s\n", part)
+			console.Printf("⚠️  Invalid input: %s\n", part)
 		}
 	}
 
 	if len(selectedTypes) == 0 {
-		fmt.Println("ℹ️  No valid selections made. Agent will generate relevant documentation automatically.")
-		fmt.Println()
+		console.Println("ℹ️  No valid selections made. Agent will generate relevant documentation automatically.")
+		console.Println()
 		return []string{}
 	}
 
-	fmt.Printf("✅ Selected documentation types: %s\n", strings.Join(selectedTypes, ", "))
-	fmt.Println()
+	console.Printf("✅ Selected documentation types: %s\n", strings.Join(selectedTypes, ", "))
+	console.Println()
 
 	return selectedTypes
 }
@@ -0,0 +1,239 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/landanqrew/mermaid-agent-documenter/internal/agent"
+	"github.com/landanqrew/mermaid-agent-documenter/internal/config"
+	"github.com/landanqrew/mermaid-agent-documenter/internal/console"
+	"github.com/landanqrew/mermaid-agent-documenter/internal/runs"
+	"github.com/spf13/cobra"
+)
+
+// runsCmd represents the runs command
+var runsCmd = &cobra.Command{
+	Use:   "runs",
+	Short: "Inspect previous runs",
+	Long: `Inspect the runs recorded in logs/runs/<run-id>/, the snapshot each run saves of
+its Markdown and Mermaid outputs along with a run.json record and the conversation
+'mad runs refine' continues.`,
+}
+
+// runsListCmd represents the runs list command
+var runsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List previous runs, newest first",
+	Long: `List the runs recorded for the current project (or, without one, in
+~/mermaid-agent-documenter/logs), newest first, with each run's ID, start time,
+transcript, provider and model, status, and artifact count.
+
+Runs archived in the output directory (archiveRuns) whose snapshot is missing are
+listed from their manifest.json, with '-' for what only the snapshot records.
+
+Use the run IDs with 'mad diff', 'mad export-bundle --run', 'mad logs show --run',
+and 'mad runs refine'; a unique prefix is accepted.
+
+Examples:
+  mad runs list
+  mad runs list --project ../my-auth-app`,
+	Run: func(cmd *cobra.Command, args []string) {
+		projectFlag, _ := cmd.Flags().GetString("project")
+
+		cfg, err := config.Load()
+		if err != nil {
+			console.Printf("Error loading config: %v\n", err)
+			os.Exit(1)
+		}
+		outputDir, logsDir, err := runsDirectories(cfg, projectFlag)
+		if err != nil {
+			console.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		snapshots, errs := runs.ListAll(logsDir, outputDir)
+		for _, err := range errs {
+			console.Printf("⚠️  Skipping run %v\n", err)
+		}
+		if len(snapshots) == 0 {
+			console.Printf("No runs found in %s or archived in %s\n", filepath.Join(logsDir, runs.DirName), outputDir)
+			return
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "RUN ID\tSTARTED\tTRANSCRIPT\tPROVIDER/MODEL\tSTATUS\tARTIFACTS")
+		for _, snapshot := range snapshots {
+			providerModel := snapshot.Provider + "/" + snapshot.Model
+			if snapshot.Provider == "" {
+				providerModel = "-" // archived run without a snapshot
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%d\n",
+				snapshot.RunID, snapshot.StartedAt.Local().Format(time.DateTime), orDash(snapshot.Transcript),
+				providerModel, orDash(snapshot.TerminationReason), snapshot.ArtifactCount())
+		}
+		w.Flush()
+
+		snapshotsDir := filepath.Join(logsDir, runs.DirName)
+		archived := 0
+		for _, snapshot := range snapshots {
+			if filepath.Dir(snapshot.Dir) != snapshotsDir {
+				archived++
+			}
+		}
+		if archived > 0 {
+			console.Printf("\n🗂️  %d runs in %s, %d more archived in %s\n", len(snapshots)-archived, snapshotsDir, archived, outputDir)
+		} else {
+			console.Printf("\n🗂️  %d runs in %s\n", len(snapshots), snapshotsDir)
+		}
+	},
+}
+
+// runsRefineCmd represents the runs refine command
+var runsRefineCmd = &cobra.Command{
+	Use:   "refine <run-id> [instruction]",
+	Short: "Continue a previous run with refinement instructions",
+	Long: `Continue the conversation saved with a previous run and apply refinement
+instructions to the files it wrote, in the run's output directory. The model sees
+the transcript, what it wrote, and earlier refinements, as with 'mad run --interactive'.
+
+With an instruction argument it is applied once; without one, instructions are read
+until an empty line or 'done'. The run's provider and model are used, and its
+snapshot and saved conversation are updated after each refinement. A unique prefix
+of the run ID is accepted.
+
+Examples:
+  mad runs refine 3f2a9c "make the sequence diagram include error paths"
+  mad runs refine 3f2a9c
+  mad runs refine 3f2a9c --project ../my-auth-app`,
+	Args: cobra.RangeArgs(1, 2),
+	Run: func(cmd *cobra.Command, args []string) {
+		projectFlag, _ := cmd.Flags().GetString("project")
+
+		cfg, err := config.Load()
+		if err != nil {
+			console.Printf("Error loading config: %v\n", err)
+			os.Exit(1)
+		}
+		_, logsDir, err := runsDirectories(cfg, projectFlag)
+		if err != nil {
+			console.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		snapshot, err := runs.Load(logsDir, args[0])
+		if err != nil {
+			console.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		if len(args) == 1 && !stdinIsTerminal() {
+			console.Println("Error: Pass the refinement instruction as an argument when not running in a terminal")
+			os.Exit(1)
+		}
+
+		// Continue with the provider and model that wrote the conversation
+		cfg.Provider = snapshot.Provider
+		requireProvider(cfg)
+		apiKey := requireAPIKey(cfg)
+		agentConfig := newAgentConfig(cfg, apiKey, nil)
+		agentConfig.Model = snapshot.Model
+		agentConfig.LogsDir = logsDir
+
+		mermaidAgent := agent.NewMermaidDocumenterAgent(agentConfig)
+		if err := mermaidAgent.Resume(snapshot); err != nil {
+			console.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		if err := ensureMermaidCLI(false); err != nil {
+			console.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		console.Printf("🔁 Refining run %s (%s/%s)\n", snapshot.RunID, snapshot.Provider, snapshot.Model)
+		console.Printf("Output directory: %s\n", mermaidAgent.Config.OutputDir)
+
+		// Ctrl-C stops the current refinement; a second Ctrl-C exits immediately
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		go func() {
+			<-ctx.Done()
+			stop()
+		}()
+
+		runTimeout := time.Duration(cfg.Limits.RunTimeoutSec) * time.Second
+		if len(args) == 2 {
+			runCtx, cancel := context.WithTimeout(ctx, runTimeout)
+			result, err := mermaidAgent.Refine(runCtx, args[1])
+			cancel()
+			printRunSummary(result)
+			if err != nil {
+				console.Printf("❌ Refinement failed: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		}
+
+		if _, err := refineLoop(ctx, mermaidAgent, nil, cfg.Limits.RunTimeoutSec); err != nil {
+			os.Exit(130) // 128 + SIGINT, as shells report it
+		}
+	},
+}
+
+// orDash returns value, or "-" when it is empty
+func orDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
+
+// runsDirectories returns the output and logs directories holding the runs of
+// the current project, or of the project given with --project
+func runsDirectories(cfg *Config, project string) (string, string, error) {
+	if project != "" {
+		rootDir, err := projectRootDir(cfg, project)
+		if err != nil {
+			return "", "", err
+		}
+		return filepath.Join(rootDir, "out"), filepath.Join(rootDir, "logs"), nil
+	}
+	outputDir, logsDir := runDirectories(cfg)
+	if strings.HasPrefix(outputDir, "~") {
+		if home, err := os.UserHomeDir(); err == nil {
+			outputDir = strings.Replace(outputDir, "~", home, 1)
+		}
+	}
+	return outputDir, logsDir, nil
+}
+
+// projectRootDir returns the root directory of a project given its directory
+// or the current project's name
+func projectRootDir(cfg *Config, project string) (string, error) {
+	if cfg.CurrentProject != nil && project == cfg.CurrentProject.Name {
+		return cfg.CurrentProject.RootDir, nil
+	}
+	rootDir, err := filepath.Abs(project)
+	if err != nil {
+		return "", err
+	}
+	if info, err := os.Stat(rootDir); err != nil || !info.IsDir() {
+		return "", fmt.Errorf("project directory %s not found", rootDir)
+	}
+	return rootDir, nil
+}
+
+func init() {
+	rootCmd.AddCommand(runsCmd)
+	runsCmd.AddCommand(runsListCmd)
+	runsCmd.AddCommand(runsRefineCmd)
+
+	runsListCmd.Flags().String("project", "", "List the runs of this project directory (or the current project's name) instead")
+	runsRefineCmd.Flags().String("project", "", "Refine a run of this project directory (or the current project's name) instead")
+}
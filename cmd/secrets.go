@@ -0,0 +1,55 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/landanqrew/mermaid-agent-documenter/internal/secrets"
+)
+
+// fileSecretBackend stores secrets in config.json. Changes are made to the
+// in-memory config; callers are responsible for saving it.
+type fileSecretBackend struct {
+	config *Config
+}
+
+func (b *fileSecretBackend) Name() string {
+	return "file"
+}
+
+func (b *fileSecretBackend) Get(provider string) (string, error) {
+	if b.config.Secrets == nil || b.config.Secrets[provider] == "" {
+		return "", secrets.ErrNotFound
+	}
+	return b.config.Secrets[provider], nil
+}
+
+func (b *fileSecretBackend) Set(provider, secret string) error {
+	if b.config.Secrets == nil {
+		b.config.Secrets = make(map[string]string)
+	}
+	b.config.Secrets[provider] = secret
+	return nil
+}
+
+func (b *fileSecretBackend) Delete(provider string) error {
+	if b.config.Secrets == nil || b.config.Secrets[provider] == "" {
+		return secrets.ErrNotFound
+	}
+	delete(b.config.Secrets, provider)
+	return nil
+}
+
+// getSecretBackend returns the named secret backend
+func getSecretBackend(name string, cfg *Config) (secrets.Backend, error) {
+	switch name {
+	case "", "file":
+		return &fileSecretBackend{config: cfg}, nil
+	case "keychain":
+		return &secrets.KeychainBackend{}, nil
+	default:
+		return nil, fmt.Errorf("unsupported secret backend '%s'. Supported backends: file, keychain", name)
+	}
+}
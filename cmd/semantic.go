@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/landanqrew/mermaid-agent-documenter/internal/console"
+	"github.com/landanqrew/mermaid-agent-documenter/internal/providers"
+	"github.com/landanqrew/mermaid-agent-documenter/internal/transcript"
+)
+
+// semanticChunkChars is the size of the transcript chunks the semantic filter scores
+const semanticChunkChars = 2000
+
+// transcriptFilter reduces a transcript before it is checked against the size limit
+type transcriptFilter func(text string) (string, error)
+
+// semanticFilter returns a filter that keeps the transcript chunks most
+// similar to the selected documentation types
+func semanticFilter(ctx context.Context, cfg *Config, docTypes []string) transcriptFilter {
+	return func(text string) (string, error) {
+		if len(docTypes) == 0 {
+			console.Println("ℹ️  Semantic filter skipped: no documentation types selected to compare against")
+			return text, nil
+		}
+		chunks := transcript.Chunk(text, semanticChunkChars, 0)
+		if len(chunks) < 2 {
+			return text, nil
+		}
+
+		provider, err := providers.NewProvider(cfg.Provider, providerOptions(cfg))
+		if err != nil {
+			return "", err
+		}
+		embedder, ok := provider.(providers.Embedder)
+		if !ok {
+			return "", fmt.Errorf("provider '%s' has no embeddings endpoint; use --semantic-filter with openai or google", cfg.Provider)
+		}
+		model := cfg.EmbeddingModel
+		if model == "" {
+			model = providers.DefaultEmbeddingModel(cfg.Provider)
+		}
+
+		vectors, err := embedder.Embed(ctx, append(append([]string{}, docTypes...), chunks...), model, getAPIKey(cfg.Provider, cfg))
+		if err != nil {
+			return "", fmt.Errorf("failed to embed transcript with %s: %w", model, err)
+		}
+		queryVectors, chunkVectors := vectors[:len(docTypes)], vectors[len(docTypes):]
+
+		var kept []string
+		for _, i := range transcript.SelectRelevant(chunkVectors, queryVectors, transcript.DefaultRelevantFraction) {
+			kept = append(kept, chunks[i])
+		}
+		filtered := strings.Join(kept, "")
+		console.Printf("🔎 Semantic filter kept %d of %d chunks (%d → %d characters)\n", len(kept), len(chunks), len(text), len(filtered))
+		return filtered, nil
+	}
+}
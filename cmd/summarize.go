@@ -0,0 +1,126 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/landanqrew/mermaid-agent-documenter/internal/config"
+	"github.com/landanqrew/mermaid-agent-documenter/internal/console"
+	"github.com/landanqrew/mermaid-agent-documenter/internal/providers"
+	"github.com/landanqrew/mermaid-agent-documenter/internal/transcript"
+	"github.com/spf13/cobra"
+)
+
+// summarizeCmd represents the summarize command
+var summarizeCmd = &cobra.Command{
+	Use:   "summarize [transcript]",
+	Short: "Condense a transcript into a structured summary",
+	Long: `Condense a long transcript into a structured summary before running the documentation agent.
+
+The summary is written next to the transcript as <name>.summary.txt (for projects, in the
+transcripts/ directory) and can be documented with 'mad run <transcript> --from-summary'.
+Use --model to pick a cheaper model for this step. Transcripts above
+limits.maxTranscriptChars are summarized in parts.
+
+Examples:
+  mad summarize transcript.txt
+  mad summarize transcript.txt --model gpt-5-nano
+  mad run transcript.txt --from-summary`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		model, _ := cmd.Flags().GetString("model")
+		clean, _ := cmd.Flags().GetBool("clean")
+
+		// Load global config
+		cfg, err := config.Load()
+		if err != nil {
+			console.Printf("Error loading config: %v\n", err)
+			os.Exit(1)
+		}
+
+		requireProvider(cfg)
+		apiKey := requireAPIKey(cfg)
+		if model == "" {
+			noteDefaultModel(cfg)
+			model = resolveModel(cfg, cfg.Provider)
+		} else {
+			model = cfg.ResolveModelAlias(model)
+		}
+
+		// The summary is written next to the transcript, so it has to be a file
+		if transcript.IsURL(args[0]) {
+			console.Println("Error: summarize writes <name>.summary.txt next to the transcript; download it first")
+			os.Exit(1)
+		}
+		transcriptPath, err := resolveTranscriptPath(args[0], cfg)
+		if err != nil {
+			console.Printf("Error resolving transcript path: %v\n", err)
+			os.Exit(1)
+		}
+
+		// Always summarize in parts rather than refusing large transcripts
+		segments, err := prepareTranscript(cmd.Context(), args[0], cfg, clean, true, nil)
+		if err != nil {
+			console.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		console.Printf("📝 Summarizing %s with %s/%s...\n", args[0], cfg.Provider, model)
+
+		provider, err := providers.NewProvider(cfg.Provider, providerOptions(cfg))
+		if err != nil {
+			console.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), time.Duration(cfg.Limits.RunTimeoutSec)*time.Second)
+		defer cancel()
+
+		var summary strings.Builder
+		promptTokens, completionTokens := 0, 0
+		for i, segment := range segments {
+			if len(segments) > 1 {
+				console.Printf("   Part %d of %d...\n", i+1, len(segments))
+			}
+
+			prompt := transcript.SummaryPrompt(segment, i+1, len(segments))
+			response, err := provider.GenerateContent(ctx, prompt, model, apiKey)
+			if errors.Is(err, providers.ErrOutputTruncated) {
+				console.Printf("⚠️  The summary was cut off at the output limit; raise limits.maxOutputTokens for a complete one\n")
+			} else if err != nil {
+				console.Printf("❌ Summarization failed: %v\n", err)
+				os.Exit(1)
+			}
+			promptTokens += providers.EstimateTokens(prompt)
+			completionTokens += providers.EstimateTokens(response)
+
+			if len(segments) > 1 {
+				summary.WriteString(fmt.Sprintf("=== PART %d OF %d ===\n", i+1, len(segments)))
+			}
+			summary.WriteString(strings.TrimSpace(response))
+			summary.WriteString("\n\n")
+		}
+
+		summaryPath := transcript.SummaryPath(transcriptPath)
+		if err := os.WriteFile(summaryPath, []byte(summary.String()), 0644); err != nil {
+			console.Printf("Error writing summary: %v\n", err)
+			os.Exit(1)
+		}
+
+		console.Printf("✅ Summary written to: %s (%d characters)\n", summaryPath, summary.Len())
+		console.Printf("   Estimated cost: $%.4f\n", providers.EstimateCost(model, promptTokens, completionTokens))
+		console.Printf("💡 Generate documentation from it with: mad run %s --from-summary\n", args[0])
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(summarizeCmd)
+	summarizeCmd.Flags().String("model", "", "Model or model alias to summarize with (default: the current provider's model)")
+	summarizeCmd.Flags().Bool("clean", false, "Strip chat markup from the transcript before summarizing")
+}
@@ -6,7 +6,13 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 
+	"github.com/landanqrew/mermaid-agent-documenter/internal/config"
+	"github.com/landanqrew/mermaid-agent-documenter/internal/console"
+	"github.com/landanqrew/mermaid-agent-documenter/internal/manifest"
+	"github.com/landanqrew/mermaid-agent-documenter/internal/mermaid"
 	"github.com/spf13/cobra"
 )
 
@@ -16,29 +22,130 @@ var validateCmd = &cobra.Command{
 	Short: "Validate a manifest or Mermaid file",
 	Long: `Validate a generated manifest or Mermaid file for syntax correctness.
 
+For a Markdown or .mmd file, each Mermaid diagram is listed with its line range and type,
+and diagrams that are unclosed, empty, or do not open with a known diagram type
+(flowchart, sequenceDiagram, erDiagram, ...) are reported.
+
 If a current project is set in the global config, the path will be resolved relative to the project's out/ directory.
 
+For a manifest.json written by a run, every listed artifact is checked (entries may be a
+status string or an object with "status" and "type"): statuses must be
+one of created, generated, updated, unchanged, skipped, or failed; files that are claimed
+to exist must be on disk; and SVGs must be non-empty with an <svg> root element.
+
 Examples:
   mad validate docs/diagrams/auth/sequence-login.md    # Global validation
-  mad validate auth/sequence-login.md                 # Project-specific validation`,
+  mad validate auth/sequence-login.md                 # Project-specific validation
+  mad validate manifest.json                          # Check a run's manifest`,
 	Args: cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Printf("Validating: %s\n", args[0])
+		console.Printf("Validating: %s\n", args[0])
 
 		// Load global config to check current project
-		config, err := loadConfig()
+		cfg, err := config.Load()
 		if err != nil {
-			fmt.Printf("Error loading config: %v\n", err)
+			console.Printf("Error loading config: %v\n", err)
 			os.Exit(1)
 		}
 
-		if config.CurrentProject != nil {
-			fmt.Printf("Project: %s\n", config.CurrentProject.Name)
+		if cfg.CurrentProject != nil {
+			console.Printf("Project: %s\n", cfg.CurrentProject.Name)
 		}
-		fmt.Println("Validation feature - checks Mermaid syntax and manifests (TODO: implement)")
+		path := resolveOutputPath(args[0], cfg)
+
+		if strings.EqualFold(filepath.Ext(path), ".json") {
+			validateManifest(path)
+			return
+		}
+
+		validateDiagrams(path)
 	},
 }
 
+// validateDiagrams checks the Mermaid diagrams of a Markdown or .mmd file:
+// each must be closed, non-empty, and open with a known diagram type. It
+// exits non-zero when a diagram has a problem or none are found.
+func validateDiagrams(path string) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		console.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	var blocks []mermaid.Block
+	if strings.EqualFold(filepath.Ext(path), ".mmd") {
+		content := strings.TrimSpace(string(data))
+		blocks = []mermaid.Block{{
+			Type:      mermaid.DetectDiagramType(content),
+			Content:   content,
+			StartLine: 1,
+			EndLine:   strings.Count(strings.TrimRight(string(data), "\n"), "\n") + 1,
+			Closed:    true,
+		}}
+	} else {
+		blocks = mermaid.ExtractBlocks(string(data))
+	}
+	if len(blocks) == 0 {
+		console.Printf("❌ No Mermaid diagrams found in %s (expected ```mermaid blocks)\n", path)
+		os.Exit(1)
+	}
+
+	problems := 0
+	for i, block := range blocks {
+		location := fmt.Sprintf("Diagram %d (lines %d-%d)", i+1, block.StartLine, block.EndLine)
+		switch {
+		case !block.Closed:
+			console.Printf("❌ %s: missing the closing ``` fence\n", location)
+		case block.Content == "":
+			console.Printf("❌ %s: empty diagram\n", location)
+		case block.Type == "":
+			console.Printf("❌ %s: unknown diagram type %q\n", location, strings.Fields(block.Content)[0])
+		default:
+			console.Printf("✅ %s: %s\n", location, block.Type)
+			continue
+		}
+		problems++
+	}
+
+	if problems > 0 {
+		console.Printf("Found problems in %d of %d diagrams\n", problems, len(blocks))
+		os.Exit(1)
+	}
+	console.Printf("✅ %d diagrams look valid (render them with 'mad render %s' for a full syntax check)\n", len(blocks), path)
+}
+
+// resolveOutputPath resolves a generated file's path: one that does not exist
+// as given is looked for in the current project's out/ directory
+func resolveOutputPath(path string, cfg *Config) string {
+	if cfg.CurrentProject == nil || filepath.IsAbs(path) {
+		return path
+	}
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return filepath.Join(cfg.CurrentProject.RootDir, "out", path)
+	}
+	return path
+}
+
+// validateManifest checks a run manifest and exits non-zero on discrepancies
+func validateManifest(path string) {
+	count, issues, err := manifest.Validate(path)
+	if err != nil {
+		console.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if len(issues) == 0 {
+		console.Printf("✅ Manifest is valid: %d artifacts checked\n", count)
+		return
+	}
+
+	console.Printf("❌ Found %d discrepancies in %d artifacts:\n", len(issues), count)
+	for _, issue := range issues {
+		console.Printf("  • %s\n", issue)
+	}
+	os.Exit(1)
+}
+
 func init() {
 	rootCmd.AddCommand(validateCmd)
 }
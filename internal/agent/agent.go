@@ -3,13 +3,21 @@ package agent
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/landanqrew/mermaid-agent-documenter/internal/console"
+	"github.com/landanqrew/mermaid-agent-documenter/internal/jsonl"
+	"github.com/landanqrew/mermaid-agent-documenter/internal/logs"
+	"github.com/landanqrew/mermaid-agent-documenter/internal/manifest"
+	"github.com/landanqrew/mermaid-agent-documenter/internal/mermaid"
+	"github.com/landanqrew/mermaid-agent-documenter/internal/output"
 	"github.com/landanqrew/mermaid-agent-documenter/internal/providers"
 	"github.com/landanqrew/mermaid-agent-documenter/internal/tools"
 )
@@ -21,49 +29,141 @@ const (
 	OutputTypeToolCall      OutputType = "tool_call"
 	OutputTypeFinal         OutputType = "final"
 	OutputTypeClarification OutputType = "clarification"
+	OutputTypePlan          OutputType = "plan"
 )
 
+// TerminationReason describes why an agent run stopped
+type TerminationReason string
+
+const (
+	TerminationCompleted           TerminationReason = "completed"
+	TerminationMaxSteps            TerminationReason = "max_steps"
+	TerminationConsecutiveFailures TerminationReason = "consecutive_failures"
+	TerminationTimeout             TerminationReason = "timeout"
+	TerminationClarification       TerminationReason = "clarification"
+	TerminationError               TerminationReason = "error"
+	TerminationPlanned             TerminationReason = "planned"
+	TerminationPlanRejected        TerminationReason = "plan_rejected"
+	TerminationUnchanged           TerminationReason = "unchanged"
+	TerminationInterrupted         TerminationReason = "interrupted"
+)
+
+// DefaultMaxConsecutiveFailures is used when the config does not set a limit
+const DefaultMaxConsecutiveFailures = 3
+
+// DefaultMaxParseRetries is how many times a malformed response is re-requested
+// when the config does not set a limit
+const DefaultMaxParseRetries = 2
+
+// jsonRepairPrompt asks the model to resend its last response as valid JSON
+const jsonRepairPrompt = "Your last response was not valid JSON (%v). Respond again with a single valid JSON object matching the required output format, with no surrounding text or code fences."
+
 type StructuredOutput struct {
 	Type       OutputType             `json:"type"`
 	Tool       string                 `json:"tool,omitempty"`
 	Args       map[string]interface{} `json:"args,omitempty"`
 	Manifest   map[string]interface{} `json:"manifest,omitempty"`
 	Questions  []string               `json:"questions,omitempty"`
+	Plan       []PlanItem             `json:"plan,omitempty"`
 	Confidence float64                `json:"confidence"`
 	Rationale  string                 `json:"rationale"`
 }
 
 type MermaidDocumenterAgent struct {
-	Provider         providers.LLMProvider
-	Config           *AgentConfig
-	RunID            string
-	StepCount        int
-	Transcript       string
-	consecutiveFails int
+	Provider          providers.LLMProvider
+	Config            *AgentConfig
+	RunID             string
+	StepCount         int
+	Transcript        string
+	TerminationReason TerminationReason
+	consecutiveFails  int
+	parseRetries      int
+	splitRetries      int  // times the model was asked for a smaller response after truncation
+	emptyRetries      int  // times the step was retried after a response with no content
+	recoveredPartial  bool // the last parsed response was completed by closing its braces
+	result            *RunResult
+	chunkIndex        int
+	chunkTotal        int
+	renderedImages    map[string]string
+	artifactTypes     map[string]string        // artifact path -> documentation type
+	conversation      []map[string]interface{} // kept after a run so Refine can continue it
+	lastLatency       time.Duration            // how long the latest model call took
+	ownedFiles        map[string]bool          // files this run created or may overwrite in strict safety mode
+	providerErr       error                    // why Provider could not be created; returned by the first model call
+	fileName          string                   // rendered FileNameTemplate, kept so one run uses one name
+	jsonModeRejected  bool                     // the provider returned 400 for a JSON mode request
 }
 
 type AgentConfig struct {
-	Provider            string
-	Model               string
-	APIKey              string
-	MaxSteps            int
-	TimeoutSec          int
-	TokenBudget         int
-	CostCeilingUsd      float64
-	ConfidenceThreshold float64
-	OutputDir           string
-	LogsDir             string
-	RedactPII           bool
-	StoreChainOfThought bool
-	DocumentationTypes  []string
+	Provider               string
+	Model                  string
+	APIKey                 string
+	ProviderOptions        providers.ProviderOptions
+	MaxSteps               int
+	MaxConsecutiveFailures int
+	KeepGoing              bool // ignore MaxConsecutiveFailures and keep going until MaxSteps
+	MaxParseRetries        int
+	NativeJSON             bool // ask providers with a JSON output mode for JSON responses
+	MaxOutputTokensCeiling int  // largest max output tokens a truncated step is retried with; 0 uses the default
+	TimeoutSec             int
+	TokenBudget            int
+	CostCeilingUsd         float64
+	ConfidenceThreshold    float64
+	OutputDir              string
+	ArchiveRuns            bool // nest the output in OutputDir/<YYYY-MM-DD_HH-MM>_<run-id>/ (see ArchiveDir)
+	LogsDir                string
+	RunLogFile             bool // also write step logs to <LogsDir>/<run-id>.jsonl
+	SkipSharedLog          bool // leave step logs out of the shared logs.jsonl
+	DumpResponses          bool // write each raw model response to <LogsDir>/<run-id>/step-N.txt
+	RedactPII              bool
+	StrictSafety           bool     // block network tools, keep files in OutputDir, and confirm overwrites
+	DisabledTools          []string // tools refused for this run (see tools.SetDisabledTools)
+	StoreChainOfThought    bool
+	MaxLoggedResponseChars int  // cap on each stored turn and response; 0 uses the default, negative is unlimited
+	StoreLastTurnOnly      bool // store only the latest conversation turn with the chain of thought
+	DocumentationTypes     []string
+	ConversationWindow     ConversationWindow // how much history is sent on each step; the zero value sends everything
+	NonInteractive         bool
+	OutputFormat           string
+	EmbedImages            bool
+	Language               string // documentation language code; "" or "en" for English
+	SystemPromptExtra      string // team instructions appended to the system prompt
+	PreferSimpleDiagrams   bool   // restrict diagrams to sequence/flowchart and lint typed ER attributes
+	ShowProgress           bool   // show a spinner or status lines while waiting on the model
+	SkipImages             bool   // write Markdown only and never call generateMermaidImage
+	ImageFormatFallback    bool   // retry renders whose output file was not created in another format
+	AutoFixMermaid         bool   // correct common diagram mistakes in the source before rendering
+	FileNameTemplate       string // text/template for output file names, e.g. {{.Type}}-{{.Date}}
+	SingleFile             bool   // write one Markdown file with a section per documentation type
+	PlanFirst              bool   // request and approve a plan before executing
+	AutoApprovePlan        bool   // skip the plan approval prompt
+	PlanOnly               bool   // stop after the plan has been produced
+	TranscriptName         string
+	Force                  bool // regenerate even when inputs are unchanged
+}
+
+// newRunID returns a random UUID, falling back to a timestamp-based ID when
+// the system's random source fails (uuid.New would panic)
+func newRunID() string {
+	id, err := uuid.NewRandom()
+	if err != nil {
+		return fmt.Sprintf("run-%d", time.Now().UnixNano())
+	}
+	return id.String()
 }
 
 func NewMermaidDocumenterAgent(config *AgentConfig) *MermaidDocumenterAgent {
+	runID := newRunID()
+	if config.ArchiveRuns && config.OutputDir != "" {
+		config = archiveConfig(config, time.Now(), runID)
+	}
+	provider, err := providers.NewProvider(config.Provider, config.ProviderOptions)
 	return &MermaidDocumenterAgent{
-		Provider:  providers.GetProvider(config.Provider),
-		Config:    config,
-		RunID:     uuid.New().String(),
-		StepCount: 0,
+		Provider:    provider,
+		Config:      config,
+		RunID:       runID,
+		StepCount:   0,
+		providerErr: err,
 	}
 }
 
@@ -71,41 +171,141 @@ func (a *MermaidDocumenterAgent) SetTranscript(transcript string) {
 	a.Transcript = transcript
 }
 
-func (a *MermaidDocumenterAgent) Run(ctx context.Context) error {
-	systemPrompt := a.buildSystemPrompt()
+// SetChunk marks the transcript as part index (1-based) of total segments of a longer transcript
+func (a *MermaidDocumenterAgent) SetChunk(index, total int) {
+	a.chunkIndex = index
+	a.chunkTotal = total
+}
 
-	conversation := []map[string]interface{}{
-		{
-			"role":    "system",
-			"content": systemPrompt,
-		},
-		{
-			"role":    "user",
-			"content": fmt.Sprintf("Please analyze this application transcript and generate Mermaid documentation:\n\n%s", a.Transcript),
-		},
+// Run executes the agent loop. The returned RunResult is always non-nil and
+// describes whatever progress was made, even when an error is returned.
+func (a *MermaidDocumenterAgent) Run(ctx context.Context) (*RunResult, error) {
+	a.result = &RunResult{
+		RunID:     a.RunID,
+		Provider:  a.Config.Provider,
+		Model:     a.Config.Model,
+		Artifacts: []string{},
+		OutputDir: a.Config.OutputDir,
+		StartedAt: time.Now(),
+	}
+	if a.Config.ArchiveRuns && a.Config.OutputDir != "" {
+		console.Printf("🗄️  Archiving this run's output in %s\n", a.Config.OutputDir)
 	}
 
-	for a.StepCount < a.Config.MaxSteps {
+	if warning := a.thresholdWarning(); warning != "" {
+		console.Printf("⚠️  %s\n", warning)
+	}
+
+	conversation := a.initialConversation()
+	a.conversation = conversation
+
+	// Skip sections whose inputs and outputs match the previous run
+	if !a.Config.PlanOnly {
+		if artifacts, unchanged := a.compareSection(); unchanged {
+			for _, artifact := range artifacts {
+				a.result.addArtifact(artifact)
+			}
+			console.Printf("⏭️  Transcript unchanged since the last run; reusing %d artifacts (use --force to regenerate)\n", len(artifacts))
+			a.finish(TerminationUnchanged)
+			return a.result, nil
+		}
+	}
+
+	if a.Config.PlanFirst || a.Config.PlanOnly {
+		stop, err := a.planPhase(ctx, &conversation)
+		a.conversation = conversation
+		if stop {
+			return a.result, err
+		}
+	}
+
+	return a.loop(ctx)
+}
+
+// loop runs steps on the stored conversation until the model returns a
+// final manifest or a limit is reached, allowing MaxSteps more steps
+func (a *MermaidDocumenterAgent) loop(ctx context.Context) (*RunResult, error) {
+	conversation := a.conversation
+	defer func() {
+		a.conversation = conversation
+		a.saveConversation()
+	}()
+
+	stepLimit := a.StepCount + a.Config.MaxSteps
+	for a.StepCount < stepLimit {
 		select {
 		case <-ctx.Done():
-			return ctx.Err()
+			a.finish(a.contextTerminationReason(ctx))
+			return a.result, ctx.Err()
 		default:
 		}
 
-		// Build the conversation string for the LLM
-		conversationStr := a.buildConversationString(conversation)
+		// Build the conversation string for the LLM from the configured window of it
+		conversationStr := a.buildConversationString(windowConversation(conversation, a.Config.ConversationWindow))
 
-		// Call the LLM
-		response, err := a.Provider.GenerateContent(ctx, conversationStr, a.Config.Model, a.Config.APIKey)
+		// Call the LLM; a truncated response still comes back with its text
+		response, err := a.generate(ctx, conversationStr)
+		providerTruncated := errors.Is(err, providers.ErrOutputTruncated)
+		if providerTruncated {
+			err = nil
+		}
 		if err != nil {
-			return fmt.Errorf("LLM call failed: %w", err)
+			// Drop the oldest turns and retry the same step when the prompt outgrew the context window
+			if errors.Is(err, providers.ErrContextLengthExceeded) && ctx.Err() == nil {
+				if trimmed, dropped := trimConversation(conversation); dropped > 0 {
+					conversation = trimmed
+					console.Printf("✂️  Context length exceeded, dropped %d oldest conversation turns and retrying\n", dropped)
+					continue
+				}
+			}
+			if errors.Is(err, providers.ErrEmptyResponse) && ctx.Err() == nil && a.handleEmptyResponse(&conversation, err) {
+				continue
+			}
+			if ctx.Err() != nil {
+				a.finish(a.contextTerminationReason(ctx))
+			} else {
+				a.finish(TerminationError)
+			}
+			if errors.Is(err, providers.ErrProviderInit) {
+				return a.result, fmt.Errorf("%s provider: %w", a.Config.Provider, err)
+			}
+			return a.result, fmt.Errorf("LLM call failed: %w", err)
 		}
+		a.emptyRetries = 0
+		a.recordUsage(conversationStr, response)
 
 		// Parse the structured output
 		output, err := a.parseStructuredOutput(response)
+
+		// A response cut off at the output limit is never acted on: its file
+		// contents or manifest would be incomplete
+		if a.recoveredPartial || (providerTruncated && err != nil) {
+			if a.handleTruncation(&conversation, response) {
+				continue
+			}
+			a.finish(TerminationError)
+			return a.result, fmt.Errorf("%w: the model's responses still did not fit after %d attempts to shorten them", providers.ErrOutputTruncated, a.splitRetries)
+		}
 		if err != nil {
-			return fmt.Errorf("failed to parse LLM response: %w", err)
+			// Ask the model to correct itself rather than abandoning the run
+			if a.parseRetries < a.maxParseRetries() && !a.isAPIErrorResponse(strings.TrimSpace(response)) {
+				a.parseRetries++
+				console.Printf("⚠️  Response was not valid JSON, asking the model to retry (%d/%d)\n", a.parseRetries, a.maxParseRetries())
+				conversation = append(conversation, map[string]interface{}{
+					"role":    "assistant",
+					"content": response,
+				})
+				conversation = append(conversation, map[string]interface{}{
+					"role":    "user",
+					"content": fmt.Sprintf(jsonRepairPrompt, err),
+				})
+				continue
+			}
+			a.finish(TerminationError)
+			return a.result, fmt.Errorf("failed to parse LLM response: %w", err)
 		}
+		a.parseRetries = 0
+		a.splitRetries = 0
 
 		// Log the interaction
 		a.logInteraction(conversation, response, output)
@@ -127,22 +327,42 @@ func (a *MermaidDocumenterAgent) Run(ctx context.Context) error {
 			}
 
 			// Modify file paths to use output directory if they're relative
-			modifiedArgs := a.modifyFilePaths(output.Args)
+			docType := a.docTypeFor(output.Args)
+			modifiedArgs := a.modifyFilePaths(output.Args, docType)
+			a.applyFileName(output.Tool, modifiedArgs)
+			if output.Tool == "logEvent" && a.Config.RunLogFile {
+				modifiedArgs["runId"] = a.RunID // route events to this run's log file too
+			}
 
-			// Execute the tool
-			result := tools.ExecuteTool(output.Tool, a.argsToJSON(modifiedArgs))
+			// Execute the tool, reusing images whose diagram source is unchanged
+			result, cached, rejected := tools.ToolResult{}, false, false
+			if result, rejected = a.strictToolResult(output.Tool, modifiedArgs); rejected {
+				console.Printf("🛡️  Strict safety mode blocked %s\n", output.Tool)
+			} else if result, rejected = a.skippedImageResult(output.Tool); rejected {
+				console.Printf("⏭️  Image generation disabled, skipping %s\n", output.Tool)
+			} else if output.Tool == "generateMermaidImage" {
+				a.addFallbackFormat(modifiedArgs)
+				a.autoFixDiagramSource(modifiedArgs)
+				if result, rejected = a.lintDiagramSource(modifiedArgs); !rejected {
+					result, cached = a.cachedRender(modifiedArgs)
+				}
+			}
+			if cached {
+				console.Printf("⏭️  Diagram source unchanged, reusing existing image\n")
+			} else if !rejected {
+				result = tools.ExecuteToolContext(ctx, output.Tool, a.argsToJSON(modifiedArgs))
+			}
 
 			if result.Success && result.Data != nil {
-				fmt.Printf("✅ Tool completed successfully\n")
+				console.Printf("✅ Tool completed successfully\n")
 				a.consecutiveFails = 0 // Reset failure counter on success
+				a.recordArtifacts(output.Tool, result, docType)
 			} else if !result.Success {
-				fmt.Printf("❌ Tool failed: %s\n", result.Error)
-				a.consecutiveFails++
-
 				// If too many consecutive failures, force final manifest
-				if a.consecutiveFails >= 3 {
-					fmt.Printf("⚠️  Too many consecutive failures (%d), forcing final manifest\n", a.consecutiveFails)
-					return nil // This will trigger final manifest processing
+				if a.recordToolFailure(output.Tool, result.Error) {
+					console.Printf("⚠️  Too many consecutive failures (%d), forcing final manifest\n", a.consecutiveFails)
+					a.finish(TerminationConsecutiveFailures)
+					return a.result, nil // This will trigger final manifest processing
 				}
 
 				// If the tool failed, add error context to guide the next action
@@ -158,7 +378,7 @@ func (a *MermaidDocumenterAgent) Run(ctx context.Context) error {
 				})
 			}
 
-			resultStr := fmt.Sprintf("Tool result: %v", result)
+			resultStr := formatToolResult(output.Tool, result)
 
 			conversation = append(conversation, map[string]interface{}{
 				"role":    "assistant",
@@ -171,9 +391,16 @@ func (a *MermaidDocumenterAgent) Run(ctx context.Context) error {
 
 		case OutputTypeFinal:
 			if output.Confidence >= a.Config.ConfidenceThreshold {
+				// Keep the manifest in the conversation for later refinements
+				conversation = append(conversation, map[string]interface{}{
+					"role":    "assistant",
+					"content": response,
+				})
+
 				// Process the final manifest
 				a.processFinalManifest(output.Manifest)
-				return nil
+				a.finish(TerminationCompleted)
+				return a.result, nil
 			} else {
 				// Ask for clarification
 				conversation = append(conversation, map[string]interface{}{
@@ -189,76 +416,300 @@ func (a *MermaidDocumenterAgent) Run(ctx context.Context) error {
 
 		case OutputTypeClarification:
 			// Handle clarification request
-			fmt.Printf("Agent needs clarification:\n")
+			console.Printf("Agent needs clarification:\n")
 			for _, question := range output.Questions {
-				fmt.Printf("- %s\n", question)
+				console.Printf("- %s\n", question)
+			}
+
+			// Without a user to answer, abort as before
+			if a.Config.NonInteractive || !stdinIsTerminal() {
+				a.finish(TerminationClarification)
+				return a.result, fmt.Errorf("clarification needed")
+			}
+
+			answers, err := a.askClarifications(output.Questions)
+			if err != nil {
+				a.finish(TerminationClarification)
+				return a.result, fmt.Errorf("clarification needed: %w", err)
 			}
-			return fmt.Errorf("clarification needed")
+
+			conversation = append(conversation, map[string]interface{}{
+				"role":    "assistant",
+				"content": response,
+			})
+			conversation = append(conversation, map[string]interface{}{
+				"role":    "user",
+				"content": answers,
+			})
 
 		default:
-			fmt.Printf("⚠️  Unknown output type: %s\n", output.Type)
+			console.Printf("⚠️  Unknown output type: %s\n", output.Type)
 			// For unknown types, try to continue with the next step
-			fmt.Printf("🔄 Continuing with next step...\n")
+			console.Printf("🔄 Continuing with next step...\n")
 			continue
 		}
 
 		a.StepCount++
 	}
 
-	return fmt.Errorf("maximum steps (%d) exceeded", a.Config.MaxSteps)
+	a.finish(TerminationMaxSteps)
+	return a.result, fmt.Errorf("maximum steps (%d) exceeded", a.Config.MaxSteps)
 }
 
-func (a *MermaidDocumenterAgent) buildSystemPrompt() string {
-	content := "## Summary\\n\\nThe transcript describes a GoCarWash application.\\n\\n```mermaid\\ngraph TD\\n    A[User] --> B[App]\\n```"
+// recordUsage adds estimated token usage and cost for one LLM call to the run result
+func (a *MermaidDocumenterAgent) recordUsage(prompt, response string) {
+	a.result.PromptTokens += providers.EstimateTokens(prompt)
+	a.result.CompletionTokens += providers.EstimateTokens(response)
+	a.result.EstimatedCostUsd = providers.EstimateCost(a.Config.Model, a.result.PromptTokens, a.result.CompletionTokens)
+}
 
-	basePrompt := `You are Mermaid Documenter Agent.
+// recordArtifacts tracks files produced by successful tool calls and the
+// documentation type they were written for
+func (a *MermaidDocumenterAgent) recordArtifacts(toolName string, result tools.ToolResult, docType string) {
+	data, ok := result.Data.(map[string]interface{})
+	if !ok {
+		return
+	}
 
-TASK: Create documentation with Mermaid diagrams and generate SVG images.
+	switch toolName {
+	case "writeFileContents", "writeMermaidDiagram":
+		if path, ok := data["path"].(string); ok {
+			a.result.addArtifact(path)
+			a.recordArtifactType(path, docType)
+		}
+	case "generateMermaidImage":
+		a.recordRender(result)
+		if path, ok := data["outputFile"].(string); ok {
+			images := []string{path}
+			if split, ok := data["outputFiles"].([]string); ok && len(split) > 0 {
+				images = split // one image per diagram of a Markdown file
+			}
+			for _, image := range images {
+				a.result.addArtifact(image)
+				a.recordArtifactType(image, docType)
+			}
+			if input, ok := data["inputFile"].(string); ok && filepath.Ext(input) == ".md" {
+				if a.renderedImages == nil {
+					a.renderedImages = make(map[string]string)
+				}
+				a.renderedImages[input] = path
+			}
+		}
+	}
+}
 
-REQUIRED SEQUENCE:
-1. FIRST: Use writeFileContents to create summary.md with VALID Mermaid diagrams
-2. SECOND: Use generateMermaidImage to convert the Markdown file to SVG images
-3. THIRD: Return final manifest ONLY after both files are created
+// askClarifications prompts the user for an answer to each question and
+// formats them as a message for the conversation
+func (a *MermaidDocumenterAgent) askClarifications(questions []string) (string, error) {
+	inputTool := tools.GetTool("getUserInput")
+	if inputTool == nil {
+		return "", fmt.Errorf("getUserInput tool is not available")
+	}
 
-FILE PATH REQUIREMENTS:
-- ALWAYS use the EXACT filename you created in writeFileContents (e.g., "summary.md")
-- Do NOT use relative paths or modify the filename
+	var sb strings.Builder
+	sb.WriteString("Answers to your clarification questions:\n")
+	for i, question := range questions {
+		result := inputTool.Execute(map[string]interface{}{
+			"prompt": fmt.Sprintf("❓ %s\n>", question),
+		})
+		if !result.Success {
+			return "", fmt.Errorf("%s", result.Error)
+		}
 
-MERMAID SYNTAX RULES:
-- For ER diagrams: Use simple attribute names without types: Site {id; name}
-- Avoid complex ER relationships - use simple ||--o{ syntax
-- For sequence diagrams: Use simple participant names without spaces
-- Keep syntax simple and avoid special characters
-- Test syntax mentally: Would this parse correctly?
+		answer := ""
+		if data, ok := result.Data.(map[string]interface{}); ok {
+			answer, _ = data["answer"].(string)
+		}
+		if answer == "" {
+			answer = "(no answer - use your best judgment)"
+		}
+		sb.WriteString(fmt.Sprintf("%d. Q: %s\n   A: %s\n", i+1, question, answer))
+	}
+	sb.WriteString("Continue with the documentation using these answers. You MUST respond with valid JSON tool calls or final manifest.")
 
-ERROR HANDLING:
-- If generateMermaidImage fails, the error message will contain specific syntax issues
-- Fix the identified syntax problems and try again
-- Focus on the sequence diagram first if ER diagram fails
+	return sb.String(), nil
+}
+
+// stdinIsTerminal reports whether stdin is an interactive terminal
+func stdinIsTerminal() bool {
+	info, err := os.Stdin.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// maxConsecutiveFailures returns the configured failure limit, falling back to the default
+func (a *MermaidDocumenterAgent) maxConsecutiveFailures() int {
+	if a.Config.MaxConsecutiveFailures > 0 {
+		return a.Config.MaxConsecutiveFailures
+	}
+	return DefaultMaxConsecutiveFailures
+}
+
+// maxParseRetries returns the configured JSON repair limit, falling back to the default
+func (a *MermaidDocumenterAgent) maxParseRetries() int {
+	if a.Config.MaxParseRetries > 0 {
+		return a.Config.MaxParseRetries
+	}
+	return DefaultMaxParseRetries
+}
 
-IMPORTANT: You MUST call generateMermaidImage as a separate tool call after creating the Markdown file. Do NOT claim SVG generation in the final manifest unless you actually called the generateMermaidImage tool.
+// contextTerminationReason maps a finished context to a termination reason
+func (a *MermaidDocumenterAgent) contextTerminationReason(ctx context.Context) TerminationReason {
+	switch ctx.Err() {
+	case context.DeadlineExceeded:
+		return TerminationTimeout
+	case context.Canceled:
+		return TerminationInterrupted
+	}
+	return TerminationError
+}
+
+// finish records why the run ended and prints a short summary
+func (a *MermaidDocumenterAgent) finish(reason TerminationReason) {
+	a.TerminationReason = reason
+	if a.result != nil {
+		a.result.Steps = a.StepCount
+		a.result.TerminationReason = reason
+		a.result.FinishedAt = time.Now()
+	}
+	if reason == TerminationInterrupted {
+		a.writePartialManifest()
+	}
+	a.saveRunSnapshot()
+
+	var detail string
+	switch reason {
+	case TerminationCompleted:
+		detail = "final manifest accepted"
+	case TerminationMaxSteps:
+		detail = fmt.Sprintf("reached maximum steps (%d)", a.Config.MaxSteps)
+	case TerminationConsecutiveFailures:
+		detail = fmt.Sprintf("%d consecutive tool failures (limit %d)", a.consecutiveFails, a.maxConsecutiveFailures())
+	case TerminationTimeout:
+		detail = fmt.Sprintf("run timeout exceeded (%ds)", a.Config.TimeoutSec)
+	case TerminationClarification:
+		detail = "agent requested clarification"
+	case TerminationPlanned:
+		detail = "plan produced, execution skipped"
+	case TerminationPlanRejected:
+		detail = "plan was not approved"
+	case TerminationUnchanged:
+		detail = "inputs unchanged, nothing regenerated"
+	case TerminationInterrupted:
+		detail = "interrupted, partial manifest written"
+	default:
+		detail = "stopped on error"
+	}
+
+	console.Printf("🏁 Run ended after %d steps: %s (%s)\n", a.StepCount, reason, detail)
+}
+
+func (a *MermaidDocumenterAgent) buildSystemPrompt() string {
+	content := "## Summary\\n\\nThe transcript describes a GoCarWash application.\\n\\n```mermaid\\ngraph TD\\n    A[User] --> B[App]\\n```"
+
+	basePrompt := `You are Mermaid Documenter Agent.
+
+` + a.taskInstructions() + `
 
 MERMAID DIAGRAM BEST PRACTICES:
 - Use simple sequence diagrams when possible - they are most reliable
 - Avoid complex ER diagrams with data types (use simple attribute names only)
 - Limit files to ONE diagram type to avoid parsing conflicts
+- For a diagram that should be rendered on its own, use writeMermaidDiagram to write a raw .mmd file (no fences) and pass that .mmd file to generateMermaidImage
 - For ER diagrams: Use format "Entity { attribute1 attribute2 }" without types or semicolons
 - For relationships: Use simple "Entity1 -- Entity2 : description" format
 - Test diagrams mentally: Would this parse correctly in Mermaid?`
 
 	// Add OpenAI-specific instructions for tool calling sequence
 	if a.Config.Provider == "openai" {
+		sequence := "writeFileContents -> generateMermaidImage -> final manifest"
+		imageRules := `
+- NEVER call generateMermaidImage before creating the file with writeFileContents
+- If you receive an error about file not existing, create the file first before generating images`
+		if a.Config.SkipImages {
+			sequence, imageRules = "writeFileContents -> final manifest", ""
+		}
 		basePrompt += `
 
 OPENAI-SPECIFIC INSTRUCTIONS:
-- ALWAYS follow this EXACT sequence: writeFileContents -> generateMermaidImage -> final manifest
-- NEVER call generateMermaidImage before creating the file with writeFileContents
-- NEVER skip steps or combine tool calls in a single response
-- If you receive an error about file not existing, create the file first before generating images
+- ALWAYS follow this EXACT sequence: ` + sequence + `
+- NEVER skip steps or combine tool calls in a single response` + imageRules + `
 - Wait for tool results before proceeding to the next step`
 	}
 
-	basePrompt += `
+	// Multi-type runs keep each documentation type in its own subdirectory,
+	// unless everything goes into one file
+	if len(a.Config.DocumentationTypes) > 1 && !a.Config.SingleFile {
+		var dirs strings.Builder
+		for _, docType := range a.Config.DocumentationTypes {
+			dirs.WriteString(fmt.Sprintf("\n- %s -> %s/", docType, DocTypeDir(docType)))
+		}
+		basePrompt += `
+
+DOCUMENTATION TYPES:
+- Add a "docType" argument naming the documentation type to every writeFileContents, writeMermaidDiagram, and generateMermaidImage call
+- Files for each type are placed in its own subdirectory; list them in the final manifest with that subdirectory:` + dirs.String()
+	}
+
+	if a.Config.PreferSimpleDiagrams {
+		basePrompt += a.simpleDiagramInstructions()
+	}
+	if a.Config.StrictSafety {
+		basePrompt += strictSafetyInstructions
+	}
+	if len(a.Config.DisabledTools) > 0 {
+		basePrompt += `
+
+DISABLED TOOLS (not available in this run; calls to them fail, so do not use them):
+- ` + strings.Join(a.Config.DisabledTools, "\n- ")
+	}
+	basePrompt += a.diagramGuidance()
+
+	if !isDefaultLanguage(a.Config.Language) {
+		basePrompt += languageInstructions(a.Config.Language)
+	}
+
+	// Tools installed in ~/mermaid-agent-documenter/tools/, unless disabled
+	var external []*tools.ExternalTool
+	for _, tool := range tools.ExternalTools() {
+		if !slices.Contains(a.Config.DisabledTools, tool.Name()) {
+			external = append(external, tool)
+		}
+	}
+	if len(external) > 0 {
+		basePrompt += `
+
+ADDITIONAL TOOLS (call them like any other tool; only when the task calls for it):`
+		for _, tool := range external {
+			schema, _ := json.Marshal(tool.Schema())
+			basePrompt += fmt.Sprintf("\n- %s: %s. Args schema: %s", tool.Name(), tool.Description(), schema)
+		}
+	}
+
+	// Non-Markdown formats are produced by converting the Markdown after the run
+	if format, err := output.ParseFormat(a.Config.OutputFormat); err == nil && format != output.FormatMarkdown {
+		basePrompt += fmt.Sprintf(`
+
+OUTPUT FORMAT:
+- Still write documentation as Markdown (.md) files with `+"```mermaid"+` code blocks
+- The .md files are converted to %s after the run, so stick to headings, paragraphs, bullet lists, and fenced code blocks
+- Avoid tables, raw HTML, and nested lists`, strings.ToUpper(string(format)))
+	}
+
+	// Team instructions come before the output format so it has the last word
+	if extra := strings.TrimSpace(a.Config.SystemPromptExtra); extra != "" {
+		basePrompt += `
+
+ADDITIONAL INSTRUCTIONS (follow these unless they conflict with the required tool sequence or JSON output format):
+` + extra
+	}
+
+	if a.Config.SkipImages {
+		basePrompt += fmt.Sprintf(noImageExamples, content)
+	} else {
+		basePrompt += `
 
 Return ONLY JSON:
 
@@ -270,14 +721,102 @@ TOOL CALL 2 (generate images):
 
 FINAL RESULT (only after both steps complete):
 {"type":"final","manifest":{"summary.md":"created","summary.svg":"generated"},"confidence":0.95,"rationale":"documentation complete"}`
+	}
 
-	if len(a.Config.DocumentationTypes) > 0 {
-		basePrompt = strings.Replace(basePrompt, "summary", strings.Join(a.Config.DocumentationTypes, "_"), 1)
+	// A configured naming template renames every example file; otherwise the
+	// documentation types name the first one
+	if a.Config.SingleFile {
+		name := a.outputFileName()
+		if name == "" {
+			name = singleFileName
+		}
+		basePrompt = renameExampleFiles(basePrompt, name) + a.singleFileInstructions(name)
+	} else if name := a.outputFileName(); name != "" {
+		basePrompt = renameExampleFiles(basePrompt, name) + fileNamingInstructions(name)
+	} else if len(a.Config.DocumentationTypes) > 0 {
+		basePrompt = strings.Replace(basePrompt, defaultFileName, strings.Join(a.Config.DocumentationTypes, "_"), 1)
 	}
 
 	return basePrompt
 }
 
+// fileAndSyntaxRules are the file path and Mermaid syntax rules shared by
+// every task description
+const fileAndSyntaxRules = `FILE PATH REQUIREMENTS:
+- ALWAYS use the EXACT filename you created in writeFileContents (e.g., "summary.md")
+- Do NOT use relative paths or modify the filename
+
+MERMAID SYNTAX RULES:
+- For ER diagrams: Use simple attribute names without types: Site {id; name}
+- Avoid complex ER relationships - use simple ||--o{ syntax
+- For sequence diagrams: Use simple participant names without spaces
+- Keep syntax simple and avoid special characters
+- Test syntax mentally: Would this parse correctly?`
+
+// imageTaskInstructions describe the default write-then-render sequence
+const imageTaskInstructions = `TASK: Create documentation with Mermaid diagrams and generate SVG images.
+
+REQUIRED SEQUENCE:
+1. FIRST: Use writeFileContents to create summary.md with VALID Mermaid diagrams
+2. SECOND: Use generateMermaidImage to convert the Markdown file to SVG images
+3. THIRD: Return final manifest ONLY after both files are created
+
+` + fileAndSyntaxRules + `
+
+ERROR HANDLING:
+- If generateMermaidImage fails, the error message will contain specific syntax issues
+- Fix the identified syntax problems and try again
+- Focus on the sequence diagram first if ER diagram fails
+
+IMPORTANT: You MUST call generateMermaidImage as a separate tool call after creating the Markdown file. Do NOT claim SVG generation in the final manifest unless you actually called the generateMermaidImage tool.`
+
+// taskInstructions returns the task and required tool sequence, without the
+// image step when SkipImages is set
+func (a *MermaidDocumenterAgent) taskInstructions() string {
+	if a.Config.SkipImages {
+		return noImageTaskInstructions
+	}
+	return imageTaskInstructions
+}
+
+// initialConversation returns the system prompt and the user turn with the transcript
+func (a *MermaidDocumenterAgent) initialConversation() []map[string]interface{} {
+	return []map[string]interface{}{
+		{
+			"role":    "system",
+			"content": a.buildSystemPrompt(),
+		},
+		{
+			"role":    "user",
+			"content": a.buildUserMessage(),
+		},
+	}
+}
+
+// InitialPrompt returns the prompt the first model call of Run would send,
+// including the request for a plan when PlanFirst or PlanOnly is set
+func (a *MermaidDocumenterAgent) InitialPrompt() string {
+	conversation := a.initialConversation()
+	if a.Config.PlanFirst || a.Config.PlanOnly {
+		conversation = append(conversation, map[string]interface{}{
+			"role":    "user",
+			"content": planInstruction,
+		})
+	}
+	return a.buildConversationString(conversation)
+}
+
+// buildUserMessage builds the initial user turn containing the transcript
+func (a *MermaidDocumenterAgent) buildUserMessage() string {
+	if a.chunkTotal > 1 {
+		return fmt.Sprintf("This is part %d of %d of a longer transcript (segments overlap slightly). "+
+			"Document only what this part covers and add the suffix \"_part%d\" to every file name you create.\n\n"+
+			"Please analyze this application transcript and generate Mermaid documentation:\n\n%s",
+			a.chunkIndex, a.chunkTotal, a.chunkIndex, a.Transcript)
+	}
+	return fmt.Sprintf("Please analyze this application transcript and generate Mermaid documentation:\n\n%s", a.Transcript)
+}
+
 func (a *MermaidDocumenterAgent) buildConversationString(conversation []map[string]interface{}) string {
 	var sb strings.Builder
 	for _, msg := range conversation {
@@ -288,6 +827,7 @@ func (a *MermaidDocumenterAgent) buildConversationString(conversation []map[stri
 
 func (a *MermaidDocumenterAgent) parseStructuredOutput(response string) (*StructuredOutput, error) {
 	response = strings.TrimSpace(response)
+	a.recoveredPartial = false
 
 	// First, try to detect if this is an API error response
 	if a.isAPIErrorResponse(response) {
@@ -299,6 +839,16 @@ func (a *MermaidDocumenterAgent) parseStructuredOutput(response string) (*Struct
 
 	// Try to extract the first valid JSON object from the response
 	jsonObjects := a.extractJSONObject(response)
+	if len(jsonObjects) == 0 {
+		// Responses cut off at max_tokens end mid-object; try closing it
+		if start := strings.Index(response, "{"); start >= 0 {
+			if completed := a.completePartialJSONObject(response[start:]); completed != "" {
+				console.Printf("⚠️  Response appears truncated, recovered a partial JSON object\n")
+				jsonObjects = []string{completed}
+				a.recoveredPartial = true
+			}
+		}
+	}
 	if len(jsonObjects) == 0 {
 		return nil, fmt.Errorf("no valid JSON objects found in response: %s", response)
 	}
@@ -312,11 +862,11 @@ func (a *MermaidDocumenterAgent) parseStructuredOutput(response string) (*Struct
 
 	if err := json.Unmarshal([]byte(firstObject), &output); err != nil {
 		// If JSON parsing fails, provide more context and debugging info
-		fmt.Printf("🔍 JSON Parsing Debug:\n")
-		fmt.Printf("  📄 Raw response length: %d characters\n", len(response))
-		fmt.Printf("  📄 First object length: %d characters\n", len(firstObject))
-		fmt.Printf("  📄 First object preview: %s...\n", firstObject[:min(200, len(firstObject))])
-		fmt.Printf("  ❌ JSON Error: %v\n", err)
+		console.Printf("🔍 JSON Parsing Debug:\n")
+		console.Printf("  📄 Raw response length: %d characters\n", len(response))
+		console.Printf("  📄 First object length: %d characters\n", len(firstObject))
+		console.Printf("  📄 First object preview: %s...\n", firstObject[:min(200, len(firstObject))])
+		console.Printf("  ❌ JSON Error: %v\n", err)
 
 		return nil, fmt.Errorf("failed to parse response as structured output JSON: %w. First object: %s", err, firstObject)
 	}
@@ -412,23 +962,31 @@ func min(a, b int) int {
 	return b
 }
 
-// modifyFilePaths modifies file paths in tool arguments to use the output directory
-func (a *MermaidDocumenterAgent) modifyFilePaths(args map[string]interface{}) map[string]interface{} {
+// modifyFilePaths modifies file paths in tool arguments to use the output
+// directory, or the documentation type's subdirectory of it when docType is set
+func (a *MermaidDocumenterAgent) modifyFilePaths(args map[string]interface{}, docType string) map[string]interface{} {
 	modifiedArgs := make(map[string]interface{})
 
 	// Copy all original args
 	for k, v := range args {
 		modifiedArgs[k] = v
 	}
+	delete(modifiedArgs, "docType")
 
-	// Check for path arguments that need modification (handles both "path" and "inputFile")
+	// Check for path arguments that need modification (handles "path" and "inputFile",
+	// plus "outputFile" when the file belongs to a documentation type, strict
+	// safety mode keeps every file in the output directory, or the run is
+	// archived, so images land in the archive directory with their Markdown)
 	pathArgs := []string{"path", "inputFile"}
+	if docType != "" || a.Config.StrictSafety || a.Config.ArchiveRuns {
+		pathArgs = append(pathArgs, "outputFile")
+	}
 	for _, argName := range pathArgs {
 		if pathVal, exists := args[argName]; exists {
 			if pathStr, ok := pathVal.(string); ok {
 				// If path is relative (doesn't start with / or ~), prepend output directory
 				if !strings.HasPrefix(pathStr, "/") && !strings.HasPrefix(pathStr, "~") && !filepath.IsAbs(pathStr) {
-					modifiedPath := filepath.Join(a.Config.OutputDir, pathStr)
+					modifiedPath := filepath.Join(a.Config.OutputDir, placeInDocTypeDir(pathStr, docType))
 					modifiedArgs[argName] = modifiedPath
 				}
 			}
@@ -483,20 +1041,62 @@ func (a *MermaidDocumenterAgent) extractJSONObjectBraceCounting(response string)
 	return objects
 }
 
-// completePartialJSONObject attempts to complete a partial JSON object
+// completePartialJSONObject attempts to complete a partial JSON object by
+// closing any open string, arrays, and objects
 func (a *MermaidDocumenterAgent) completePartialJSONObject(partial string) string {
-	// Count braces to see what's missing
-	openBraces := strings.Count(partial, "{")
-	closeBraces := strings.Count(partial, "}")
+	var closers []rune
+	inString := false
+	escapeNext := false
+
+	for _, char := range partial {
+		if escapeNext {
+			escapeNext = false
+			continue
+		}
+		switch char {
+		case '\\':
+			if inString {
+				escapeNext = true
+			}
+		case '"':
+			inString = !inString
+		case '{', '[':
+			if !inString {
+				if char == '{' {
+					closers = append(closers, '}')
+				} else {
+					closers = append(closers, ']')
+				}
+			}
+		case '}', ']':
+			if !inString && len(closers) > 0 {
+				closers = closers[:len(closers)-1]
+			}
+		}
+	}
 
-	if openBraces <= closeBraces {
+	if len(closers) == 0 {
 		return "" // Not a partial object or already complete
 	}
 
-	// Add missing closing braces
 	completed := partial
-	for i := 0; i < openBraces-closeBraces; i++ {
-		completed += "}"
+	if escapeNext {
+		completed = completed[:len(completed)-1] // Drop a dangling escape
+	}
+	if inString {
+		completed += `"`
+	}
+
+	// A value cut off after its key or separator cannot be recovered
+	completed = strings.TrimRight(completed, " \t\r\n")
+	completed = strings.TrimSuffix(completed, ",")
+	if strings.HasSuffix(completed, ":") {
+		completed += "null"
+	}
+
+	// Add missing closing brackets and braces, innermost first
+	for i := len(closers) - 1; i >= 0; i-- {
+		completed += string(closers[i])
 	}
 
 	// Test if it's now valid JSON
@@ -558,19 +1158,16 @@ func (a *MermaidDocumenterAgent) argsToJSON(args map[string]interface{}) string
 }
 
 func (a *MermaidDocumenterAgent) logInteraction(conversation []map[string]interface{}, response string, output *StructuredOutput) {
-	fmt.Printf("Step %d: %s (confidence: %.2f)\n", a.StepCount+1, output.Type, output.Confidence)
+	console.Printf("Step %d: %s (confidence: %.2f)\n", a.StepCount+1, output.Type, output.Confidence)
+	if a.result != nil {
+		a.result.Confidences = append(a.result.Confidences, output.Confidence)
+	}
 
 	// Skip logging if LogsDir is not set
 	if a.Config.LogsDir == "" {
 		return
 	}
 
-	// Create logs directory if it doesn't exist
-	if err := os.MkdirAll(a.Config.LogsDir, 0755); err != nil {
-		fmt.Printf("Warning: Failed to create logs directory: %v\n", err)
-		return
-	}
-
 	// Create log entry
 	logEntry := map[string]interface{}{
 		"timestamp":   time.Now().Format(time.RFC3339),
@@ -581,12 +1178,12 @@ func (a *MermaidDocumenterAgent) logInteraction(conversation []map[string]interf
 		"output_type": output.Type,
 		"confidence":  output.Confidence,
 		"rationale":   output.Rationale,
+		"latencyMs":   a.lastLatency.Milliseconds(),
 	}
 
 	// Add chain of thought if enabled
 	if a.Config.StoreChainOfThought {
-		logEntry["conversation"] = conversation
-		logEntry["response"] = response
+		a.addChainOfThought(logEntry, conversation, response)
 	}
 
 	// Add tool information if applicable
@@ -600,28 +1197,200 @@ func (a *MermaidDocumenterAgent) logInteraction(conversation []map[string]interf
 		logEntry["manifest"] = output.Manifest
 	}
 
+	// Add the plan if applicable
+	if output.Type == OutputTypePlan {
+		logEntry["plan"] = output.Plan
+	}
+
+	a.appendLogEntry(logEntry)
+}
+
+// appendLogEntry writes an entry to the shared logs.jsonl and/or the run's own file
+func (a *MermaidDocumenterAgent) appendLogEntry(logEntry map[string]interface{}) {
+	if a.Config.LogsDir == "" {
+		return
+	}
+
+	// Create logs directory if it doesn't exist
+	if err := os.MkdirAll(a.Config.LogsDir, 0755); err != nil {
+		console.Printf("Warning: Failed to create logs directory: %v\n", err)
+		return
+	}
+
 	// Marshal to JSON
 	jsonData, err := json.Marshal(logEntry)
 	if err != nil {
-		fmt.Printf("Warning: Failed to marshal log entry: %v\n", err)
+		console.Printf("Warning: Failed to marshal log entry: %v\n", err)
+		return
+	}
+
+	// Write to the shared logs.jsonl file and/or the run's own file
+	if !a.Config.SkipSharedLog {
+		logFilePath := filepath.Join(a.Config.LogsDir, logs.FileName)
+		if err := jsonl.AppendLine(logFilePath, jsonData); err != nil {
+			console.Printf("Warning: %v\n", err)
+		}
+	}
+	if a.Config.RunLogFile {
+		if err := jsonl.AppendLine(logs.RunFile(a.Config.LogsDir, a.RunID), jsonData); err != nil {
+			console.Printf("Warning: %v\n", err)
+		}
+	}
+}
+
+func (a *MermaidDocumenterAgent) processFinalManifest(manifest map[string]interface{}) {
+	console.Printf("Processing final manifest: %v\n", manifest)
+
+	if a.result == nil {
+		return
+	}
+	manifest = a.markSkippedImages(a.placeManifestEntries(a.renameManifestEntries(manifest)))
+	a.result.Manifest = manifest
+
+	// Manifest keys name the files the agent claims to have produced
+	for name := range manifest {
+		path := name
+		if !filepath.IsAbs(path) && a.Config.OutputDir != "" {
+			path = filepath.Join(a.Config.OutputDir, path)
+		}
+		if _, err := os.Stat(path); err == nil {
+			a.result.addArtifact(path)
+		}
+	}
+
+	if a.Config.EmbedImages {
+		a.embedRenderedImages()
+	}
+	a.convertOutputFormat()
+	a.writeManifest(manifest)
+	a.writeIndex()
+	a.recordSection()
+}
+
+// writeManifest saves the agent's final manifest as manifest.json in the output
+// directory so 'mad validate' can check it. Artifacts written for a documentation
+// type are recorded with that type. Later transcript segments add to
+// the manifest written by earlier ones.
+func (a *MermaidDocumenterAgent) writeManifest(entries map[string]interface{}) {
+	if a.Config.OutputDir == "" || len(entries) == 0 {
+		return
+	}
+	dir := expandHome(a.Config.OutputDir)
+
+	merged := map[string]interface{}{}
+	if a.chunkTotal > 1 && a.chunkIndex > 1 {
+		if existing, err := manifest.Read(filepath.Join(dir, manifest.FileName)); err == nil {
+			merged = existing
+		}
+	}
+	for artifact, status := range entries {
+		if docType := a.artifactType(artifact); docType != "" {
+			if s, ok := status.(string); ok {
+				merged[artifact] = manifest.Entry{Status: s, Type: docType}
+				continue
+			}
+		}
+		merged[artifact] = status
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		console.Printf("⚠️  Failed to create output directory: %v\n", err)
+		return
+	}
+	if _, err := manifest.Write(dir, merged); err != nil {
+		console.Printf("⚠️  %v\n", err)
+	}
+}
+
+// writeIndex saves index.md in the output directory, linking each document
+// listed in manifest.json and its rendered images, grouped by documentation type.
+// It is not recorded as an artifact since every run rewrites it.
+func (a *MermaidDocumenterAgent) writeIndex() {
+	if a.Config.OutputDir == "" {
+		return
+	}
+	dir := expandHome(a.Config.OutputDir)
+	entries, err := manifest.Read(filepath.Join(dir, manifest.FileName))
+	if err != nil {
 		return
 	}
 
-	// Write to logs.jsonl file
-	logFilePath := filepath.Join(a.Config.LogsDir, "logs.jsonl")
-	file, err := os.OpenFile(logFilePath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	var docs []output.IndexEntry
+	for artifact, value := range entries {
+		entry, ok := manifest.ParseEntry(value)
+		if !ok || !entry.Exists() || filepath.Ext(artifact) != ".md" {
+			continue
+		}
+		path := a.manifestPath(artifact)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+
+		diagrams := len(mermaid.ExtractBlocks(string(data)))
+		var images []string
+		if image, ok := a.renderedImages[path]; ok {
+			for _, found := range output.FindRenderedImages(image, diagrams) {
+				if found != "" {
+					images = append(images, found)
+				}
+			}
+		} else {
+			images = output.GuessRenderedImages(path, diagrams)
+		}
+		docs = append(docs, output.IndexEntry{Path: path, Type: entry.Type, Images: images})
+	}
+
+	index, err := output.WriteIndex(dir, docs, output.IndexInfo{
+		Provider:    a.Config.Provider,
+		Model:       a.Config.Model,
+		GeneratedAt: time.Now(),
+	})
 	if err != nil {
-		fmt.Printf("Warning: Failed to open log file: %v\n", err)
+		console.Printf("⚠️  %v\n", err)
 		return
 	}
-	defer file.Close()
+	console.Printf("🗂️  Index: %s\n", index)
+}
 
-	if _, err := file.WriteString(string(jsonData) + "\n"); err != nil {
-		fmt.Printf("Warning: Failed to write to log file: %v\n", err)
+// embedRenderedImages writes a companion Markdown file linking the images
+// rendered from each documentation file
+func (a *MermaidDocumenterAgent) embedRenderedImages() {
+	for markdownPath, imagePath := range a.renderedImages {
+		companion, err := output.WriteEmbeddedCompanion(markdownPath, imagePath)
+		if err != nil {
+			console.Printf("⚠️  Failed to embed images for %s: %v\n", markdownPath, err)
+			continue
+		}
+		if companion == "" {
+			continue
+		}
+		console.Printf("🖼️  Embedded rendered images → %s\n", filepath.Base(companion))
+		a.result.addArtifact(companion)
 	}
 }
 
-func (a *MermaidDocumenterAgent) processFinalManifest(manifest map[string]interface{}) {
-	// TODO: Process and validate the final manifest
-	fmt.Printf("Processing final manifest: %v\n", manifest)
+// convertOutputFormat converts produced Markdown files to the configured output format
+func (a *MermaidDocumenterAgent) convertOutputFormat() {
+	format, err := output.ParseFormat(a.Config.OutputFormat)
+	if err != nil {
+		console.Printf("⚠️  %v\n", err)
+		return
+	}
+	if format == output.FormatMarkdown {
+		return
+	}
+
+	for _, artifact := range a.result.Artifacts {
+		if filepath.Ext(artifact) != ".md" {
+			continue
+		}
+		converted, err := output.ConvertFile(artifact, format)
+		if err != nil {
+			console.Printf("⚠️  Failed to convert %s to %s: %v\n", artifact, format, err)
+			continue
+		}
+		console.Printf("📄 Converted %s → %s\n", filepath.Base(artifact), filepath.Base(converted))
+		a.result.addArtifact(converted)
+	}
 }
@@ -0,0 +1,797 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/landanqrew/mermaid-agent-documenter/internal/manifest"
+	"github.com/landanqrew/mermaid-agent-documenter/internal/providers"
+	"github.com/landanqrew/mermaid-agent-documenter/internal/tools"
+)
+
+const testFinalResponse = `{"type":"final","manifest":{},"confidence":0.95,"rationale":"done"}`
+
+func TestRun_RetriesMalformedJSON(t *testing.T) {
+	a, provider := newTestAgent(&AgentConfig{
+		MaxSteps:            5,
+		ConfidenceThreshold: 0.9,
+		OutputDir:           t.TempDir(),
+		LogsDir:             t.TempDir(),
+	}, "Sure! Here is the manifest: `done`", testFinalResponse)
+
+	result, err := a.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.TerminationReason != TerminationCompleted {
+		t.Errorf("Expected termination %q, got %q", TerminationCompleted, result.TerminationReason)
+	}
+	if provider.calls != 2 {
+		t.Errorf("Expected one retry after the malformed response, got %d calls", provider.calls)
+	}
+	if a.StepCount != 0 {
+		t.Errorf("Expected retries not to count as steps, got %d", a.StepCount)
+	}
+}
+
+func TestRun_GivesUpAfterParseRetries(t *testing.T) {
+	a, provider := newTestAgent(&AgentConfig{
+		MaxSteps:            5,
+		MaxParseRetries:     2,
+		ConfidenceThreshold: 0.9,
+		OutputDir:           t.TempDir(),
+		LogsDir:             t.TempDir(),
+	}, "not json", "still not json", "nope", testFinalResponse)
+
+	result, err := a.Run(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "failed to parse LLM response") {
+		t.Fatalf("Expected parse failure after retries, got %v", err)
+	}
+	if result.TerminationReason != TerminationError {
+		t.Errorf("Expected termination %q, got %q", TerminationError, result.TerminationReason)
+	}
+	if provider.calls != 3 {
+		t.Errorf("Expected the original call plus 2 retries, got %d calls", provider.calls)
+	}
+}
+
+func TestParseStructuredOutput_TruncatedToolCall(t *testing.T) {
+	a := NewMermaidDocumenterAgent(&AgentConfig{})
+
+	tests := []struct {
+		name     string
+		response string
+		content  string
+	}{
+		{
+			name:     "missing_closing_braces",
+			response: `{"type":"tool_call","tool":"writeFileContents","args":{"path":"login.md","content":"# Login"}`,
+			content:  "# Login",
+		},
+		{
+			name:     "cut_inside_string",
+			response: "```json\n" + `{"type":"tool_call","tool":"writeFileContents","args":{"path":"login.md","content":"sequenceDiagram\n  User->>App: lo`,
+			content:  "sequenceDiagram\n  User->>App: lo",
+		},
+		{
+			name:     "cut_after_escape",
+			response: `{"type":"tool_call","tool":"writeFileContents","args":{"path":"login.md","content":"say \"hi\" \`,
+			content:  `say "hi" `,
+		},
+		{
+			name:     "cut_after_key",
+			response: `{"type":"tool_call","tool":"writeFileContents","args":{"path":"login.md","content":`,
+			content:  "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, err := a.parseStructuredOutput(tt.response)
+			if err != nil {
+				t.Fatalf("Expected truncated response to be recovered, got: %v", err)
+			}
+			if output.Type != OutputTypeToolCall || output.Tool != "writeFileContents" {
+				t.Errorf("Unexpected output: %+v", output)
+			}
+			if output.Args["path"] != "login.md" {
+				t.Errorf("Expected path 'login.md', got %v", output.Args["path"])
+			}
+			content, _ := output.Args["content"].(string)
+			if content != tt.content {
+				t.Errorf("Expected content %q, got %q", tt.content, content)
+			}
+		})
+	}
+}
+
+func TestCompletePartialJSONObject_Complete(t *testing.T) {
+	a := NewMermaidDocumenterAgent(&AgentConfig{})
+	if got := a.completePartialJSONObject(`{"type":"final"}`); got != "" {
+		t.Errorf("Expected complete object to be left alone, got %q", got)
+	}
+}
+
+func TestDocTypeDir(t *testing.T) {
+	tests := map[string]string{
+		"User Flow Diagrams":         "user-flow",
+		"Data Models (ER Diagrams)":  "data-models",
+		"System Architecture":        "system-architecture",
+		"Performance Considerations": "performance-considerations",
+	}
+	for docType, want := range tests {
+		if got := DocTypeDir(docType); got != want {
+			t.Errorf("DocTypeDir(%q) = %q, want %q", docType, got, want)
+		}
+	}
+}
+
+func TestModifyFilePaths_DocTypeSubdirectories(t *testing.T) {
+	outputDir := t.TempDir()
+	a := NewMermaidDocumenterAgent(&AgentConfig{
+		OutputDir:          outputDir,
+		DocumentationTypes: []string{"User Flow Diagrams", "Data Models (ER Diagrams)"},
+	})
+
+	args := map[string]interface{}{
+		"inputFile":  "login.md",
+		"outputFile": "login",
+		"docType":    "user flow diagrams",
+	}
+	docType := a.docTypeFor(args)
+	if docType != "User Flow Diagrams" {
+		t.Fatalf("Expected docType to resolve to 'User Flow Diagrams', got %q", docType)
+	}
+
+	modified := a.modifyFilePaths(args, docType)
+	if want := filepath.Join(outputDir, "user-flow", "login.md"); modified["inputFile"] != want {
+		t.Errorf("Expected inputFile %q, got %v", want, modified["inputFile"])
+	}
+	if want := filepath.Join(outputDir, "user-flow", "login"); modified["outputFile"] != want {
+		t.Errorf("Expected outputFile %q, got %v", want, modified["outputFile"])
+	}
+	if _, ok := modified["docType"]; ok {
+		t.Error("Expected docType to be removed from tool arguments")
+	}
+
+	// Paths that already name the subdirectory are not nested again
+	modified = a.modifyFilePaths(map[string]interface{}{"path": "data-models/users.md"}, "Data Models (ER Diagrams)")
+	if want := filepath.Join(outputDir, "data-models", "users.md"); modified["path"] != want {
+		t.Errorf("Expected path %q, got %v", want, modified["path"])
+	}
+
+	// Unknown types and untyped calls keep the flat layout
+	if got := a.docTypeFor(map[string]interface{}{"docType": "Security Analysis"}); got != "" {
+		t.Errorf("Expected unselected docType to be ignored, got %q", got)
+	}
+	modified = a.modifyFilePaths(map[string]interface{}{"path": "notes.md", "outputFile": "notes"}, "")
+	if want := filepath.Join(outputDir, "notes.md"); modified["path"] != want {
+		t.Errorf("Expected path %q, got %v", want, modified["path"])
+	}
+	if modified["outputFile"] != "notes" {
+		t.Errorf("Expected outputFile to be left alone without a docType, got %v", modified["outputFile"])
+	}
+}
+
+func TestWriteManifest_RecordsDocTypes(t *testing.T) {
+	outputDir := t.TempDir()
+	a := NewMermaidDocumenterAgent(&AgentConfig{
+		OutputDir:          outputDir,
+		DocumentationTypes: []string{"User Flow Diagrams", "System Architecture"},
+	})
+	a.result = &RunResult{}
+
+	loginPath := filepath.Join(outputDir, "user-flow", "login.md")
+	if err := os.MkdirAll(filepath.Dir(loginPath), 0755); err != nil {
+		t.Fatalf("Failed to create subdirectory: %v", err)
+	}
+	if err := os.WriteFile(loginPath, []byte("# Login"), 0644); err != nil {
+		t.Fatalf("Failed to write artifact: %v", err)
+	}
+	a.recordArtifactType(loginPath, "User Flow Diagrams")
+
+	// The model lists the file by its bare name
+	a.processFinalManifest(map[string]interface{}{"login.md": "created", "overview.md": "skipped"})
+
+	entries, err := manifest.Read(filepath.Join(outputDir, manifest.FileName))
+	if err != nil {
+		t.Fatalf("Failed to read manifest: %v", err)
+	}
+	entry, ok := entries["user-flow/login.md"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("Expected typed entry for user-flow/login.md, got %v", entries)
+	}
+	if entry["status"] != "created" || entry["type"] != "User Flow Diagrams" {
+		t.Errorf("Unexpected entry: %v", entry)
+	}
+	if entries["overview.md"] != "skipped" {
+		t.Errorf("Expected untyped entry to stay a plain status, got %v", entries["overview.md"])
+	}
+}
+
+func TestEstimate(t *testing.T) {
+	a := NewMermaidDocumenterAgent(&AgentConfig{Model: "gpt-5-mini", MaxSteps: 25})
+	a.SetTranscript(strings.Repeat("User logs in. ", 1000))
+
+	estimate := a.Estimate()
+	if estimate.PromptTokens <= providers.EstimateTokens(a.Transcript) {
+		t.Errorf("Expected prompt estimate to include the system prompt, got %d", estimate.PromptTokens)
+	}
+	if estimate.MinSteps != EstimateMinSteps || estimate.MaxSteps != EstimateTypicalSteps {
+		t.Errorf("Unexpected step range %d-%d", estimate.MinSteps, estimate.MaxSteps)
+	}
+	if !estimate.Priced || estimate.MinCostUsd <= 0 || estimate.MaxCostUsd <= estimate.MinCostUsd {
+		t.Errorf("Expected an increasing cost range, got %+v", estimate)
+	}
+
+	// The step range never exceeds the configured limit
+	a.Config.MaxSteps = 2
+	if estimate := a.Estimate(); estimate.MinSteps != 2 || estimate.MaxSteps != 2 {
+		t.Errorf("Expected steps capped at 2, got %d-%d", estimate.MinSteps, estimate.MaxSteps)
+	}
+
+	a.Config.Model = "unknown-model"
+	if estimate := a.Estimate(); estimate.Priced || estimate.MaxCostUsd != 0 {
+		t.Errorf("Expected unpriced estimate for unknown model, got %+v", estimate)
+	}
+}
+
+func TestProcessFinalManifest_WritesIndex(t *testing.T) {
+	outputDir := t.TempDir()
+	a := NewMermaidDocumenterAgent(&AgentConfig{Provider: "openai", Model: "gpt-5-mini", OutputDir: outputDir})
+	a.result = &RunResult{}
+
+	markdownPath := filepath.Join(outputDir, "login.md")
+	if err := os.WriteFile(markdownPath, []byte("# Login\n\n```mermaid\nsequenceDiagram\n```\n"), 0644); err != nil {
+		t.Fatalf("Failed to write document: %v", err)
+	}
+	imagePath := filepath.Join(outputDir, "login-flow.svg")
+	if err := os.WriteFile(imagePath, []byte("<svg></svg>"), 0644); err != nil {
+		t.Fatalf("Failed to write image: %v", err)
+	}
+	a.renderedImages = map[string]string{markdownPath: imagePath}
+
+	a.processFinalManifest(map[string]interface{}{
+		"login.md":       "created",
+		"login-flow.svg": "generated",
+		"later.md":       "skipped",
+	})
+
+	data, err := os.ReadFile(filepath.Join(outputDir, "index.md"))
+	if err != nil {
+		t.Fatalf("Expected index.md to be written: %v", err)
+	}
+	index := string(data)
+	if !strings.Contains(index, "- [login](login.md) · [diagram 1](login-flow.svg)") {
+		t.Errorf("Expected login document with its image, got:\n%s", index)
+	}
+	if !strings.Contains(index, "openai/gpt-5-mini") {
+		t.Errorf("Expected the model in the index, got:\n%s", index)
+	}
+	if strings.Contains(index, "later") {
+		t.Errorf("Expected skipped documents to be left out, got:\n%s", index)
+	}
+}
+
+func TestFinish_InterruptedWritesPartialManifest(t *testing.T) {
+	outputDir := t.TempDir()
+	a := NewMermaidDocumenterAgent(&AgentConfig{OutputDir: outputDir})
+	markdownPath := filepath.Join(outputDir, "login.md")
+	if err := os.WriteFile(markdownPath, []byte("# Login\n"), 0644); err != nil {
+		t.Fatalf("Failed to write document: %v", err)
+	}
+	a.result = &RunResult{Artifacts: []string{markdownPath}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	a.finish(a.contextTerminationReason(ctx))
+
+	if a.result.TerminationReason != TerminationInterrupted {
+		t.Fatalf("Expected termination %q, got %q", TerminationInterrupted, a.result.TerminationReason)
+	}
+	entries, err := manifest.Read(filepath.Join(outputDir, manifest.FileName))
+	if err != nil {
+		t.Fatalf("Expected manifest.json to be written: %v", err)
+	}
+	if entries["login.md"] != "created" || len(entries) != 1 {
+		t.Errorf("Expected the completed document in the manifest, got %v", entries)
+	}
+}
+
+func TestThresholdWarning(t *testing.T) {
+	for threshold, expected := range map[float64]string{0.9: "", 0: "", 1: "", 5: "above 1", -1: "below 0", math.NaN(): "above 1"} {
+		a := NewMermaidDocumenterAgent(&AgentConfig{ConfidenceThreshold: threshold})
+		warning := a.thresholdWarning()
+		if expected == "" && warning != "" || !strings.Contains(warning, expected) {
+			t.Errorf("Threshold %v: expected a warning containing %q, got %q", threshold, expected, warning)
+		}
+	}
+}
+
+func TestAddFallbackFormat(t *testing.T) {
+	a := NewMermaidDocumenterAgent(&AgentConfig{ImageFormatFallback: true})
+	for format, expected := range map[string]string{"": "png", "svg": "png", "png": "svg", "pdf": "png"} {
+		args := map[string]interface{}{"inputFile": "flow.md"}
+		if format != "" {
+			args["format"] = format
+		}
+		a.addFallbackFormat(args)
+		if args["fallbackFormat"] != expected {
+			t.Errorf("Format %q: expected fallback %s, got %v", format, expected, args["fallbackFormat"])
+		}
+	}
+
+	args := map[string]interface{}{"fallbackFormat": "pdf"}
+	a.addFallbackFormat(args)
+	if args["fallbackFormat"] != "pdf" {
+		t.Errorf("Expected the model's fallback to be kept, got %v", args["fallbackFormat"])
+	}
+
+	args = map[string]interface{}{}
+	NewMermaidDocumenterAgent(&AgentConfig{}).addFallbackFormat(args)
+	if _, set := args["fallbackFormat"]; set {
+		t.Error("Expected no fallback unless ImageFormatFallback is on")
+	}
+}
+
+func TestParseLanguage(t *testing.T) {
+	tests := map[string]string{
+		"es":      "es",
+		"Spanish": "es",
+		" PT-BR ": "pt-br",
+		"en":      "en",
+	}
+	for input, want := range tests {
+		got, err := ParseLanguage(input)
+		if err != nil || got != want {
+			t.Errorf("ParseLanguage(%q) = %q, %v; want %q", input, got, err, want)
+		}
+	}
+	for _, input := range []string{"", "not a language", "e$"} {
+		if _, err := ParseLanguage(input); err == nil {
+			t.Errorf("Expected error for %q", input)
+		}
+	}
+}
+
+func TestBuildSystemPrompt_Language(t *testing.T) {
+	a := NewMermaidDocumenterAgent(&AgentConfig{})
+	if strings.Contains(a.buildSystemPrompt(), "LANGUAGE:") {
+		t.Error("Expected no language instructions by default")
+	}
+
+	a.Config.Language = "es"
+	prompt := a.buildSystemPrompt()
+	if !strings.Contains(prompt, "LANGUAGE:") || !strings.Contains(prompt, "in Spanish") {
+		t.Errorf("Expected Spanish instructions, got:\n%s", prompt)
+	}
+	if !strings.Contains(prompt, "Keep Mermaid keywords and syntax unchanged") {
+		t.Error("Expected instructions to keep Mermaid syntax valid")
+	}
+}
+
+func TestBuildSystemPrompt_ExtraInstructions(t *testing.T) {
+	a := NewMermaidDocumenterAgent(&AgentConfig{SystemPromptExtra: "  Use British spelling.\nAlways include a class diagram.  "})
+	prompt := a.buildSystemPrompt()
+
+	extra := strings.Index(prompt, "ADDITIONAL INSTRUCTIONS")
+	format := strings.Index(prompt, "Return ONLY JSON:")
+	if extra < 0 || !strings.Contains(prompt, "Use British spelling.\nAlways include a class diagram.") {
+		t.Fatalf("Expected extra instructions in prompt, got:\n%s", prompt)
+	}
+	if format < extra {
+		t.Error("Expected the JSON output format to follow the extra instructions")
+	}
+	if !strings.Contains(prompt, "REQUIRED SEQUENCE:") {
+		t.Error("Expected the required tool sequence to be kept")
+	}
+}
+
+func TestLintDiagramSource_RejectsTypedERAttributes(t *testing.T) {
+	dir := t.TempDir()
+	typed := filepath.Join(dir, "data_models.md")
+	if err := os.WriteFile(typed, []byte("```mermaid\nerDiagram\n    Site {\n        string id PK\n    }\n```\n"), 0644); err != nil {
+		t.Fatalf("Failed to write diagram: %v", err)
+	}
+	args := map[string]interface{}{"inputFile": typed}
+
+	a := NewMermaidDocumenterAgent(&AgentConfig{PreferSimpleDiagrams: true})
+	result, rejected := a.lintDiagramSource(args)
+	if !rejected || result.Success {
+		t.Fatalf("Expected typed ER attributes to be rejected, got %+v", result)
+	}
+	if !strings.Contains(result.Error, "line 4") {
+		t.Errorf("Expected the error to name the offending line, got: %s", result.Error)
+	}
+	if !strings.Contains(a.buildSystemPrompt(), "SIMPLE DIAGRAMS") {
+		t.Error("Expected the system prompt to restrict diagram types")
+	}
+
+	a = NewMermaidDocumenterAgent(&AgentConfig{})
+	if _, rejected := a.lintDiagramSource(args); rejected {
+		t.Error("Expected no linting when PreferSimpleDiagrams is off")
+	}
+	if strings.Contains(a.buildSystemPrompt(), "SIMPLE DIAGRAMS") {
+		t.Error("Expected no diagram restrictions when PreferSimpleDiagrams is off")
+	}
+}
+
+func TestAutoFixDiagramSource(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "data_models.md")
+	if err := os.WriteFile(source, []byte("```mermaid\nerDiagram\n    Site {\n        string id PK\n    }\n```\n"), 0644); err != nil {
+		t.Fatalf("Failed to write diagram: %v", err)
+	}
+	args := map[string]interface{}{"inputFile": source}
+
+	NewMermaidDocumenterAgent(&AgentConfig{}).autoFixDiagramSource(args)
+	if data, _ := os.ReadFile(source); !strings.Contains(string(data), "string id PK") {
+		t.Fatal("Expected no fixes when AutoFixMermaid is off")
+	}
+
+	a := NewMermaidDocumenterAgent(&AgentConfig{PreferSimpleDiagrams: true, AutoFixMermaid: true})
+	a.autoFixDiagramSource(args)
+	data, err := os.ReadFile(source)
+	if err != nil {
+		t.Fatalf("Failed to read diagram: %v", err)
+	}
+	if strings.Contains(string(data), "string") || !strings.Contains(string(data), "        id\n") {
+		t.Errorf("Expected the attribute type to be removed, got:\n%s", data)
+	}
+	if result, rejected := a.lintDiagramSource(args); rejected {
+		t.Errorf("Expected the fixed diagram to pass the lint, got %+v", result)
+	}
+}
+
+func TestDiagramGuidance(t *testing.T) {
+	prompt := NewMermaidDocumenterAgent(&AgentConfig{DocumentationTypes: []string{"System Architecture"}}).buildSystemPrompt()
+	if !strings.Contains(prompt, "CLASS DIAGRAMS") || strings.Contains(prompt, "STATE DIAGRAMS") {
+		t.Error("Expected class diagram guidance only for System Architecture")
+	}
+
+	prompt = NewMermaidDocumenterAgent(&AgentConfig{DocumentationTypes: []string{"Error Handling", "Data Models (ER Diagrams)"}}).buildSystemPrompt()
+	if !strings.Contains(prompt, "CLASS DIAGRAMS") || !strings.Contains(prompt, "stateDiagram-v2") {
+		t.Error("Expected class and state diagram guidance")
+	}
+
+	prompt = NewMermaidDocumenterAgent(&AgentConfig{DocumentationTypes: []string{"Security Analysis"}}).buildSystemPrompt()
+	if strings.Contains(prompt, "CLASS DIAGRAMS") || strings.Contains(prompt, "STATE DIAGRAMS") {
+		t.Error("Expected no class or state guidance for unrelated documentation types")
+	}
+
+	// Simple-diagram mode is on by default, so it must not drop the guidance
+	prompt = NewMermaidDocumenterAgent(&AgentConfig{DocumentationTypes: []string{"System Architecture"}, PreferSimpleDiagrams: true}).buildSystemPrompt()
+	if !strings.Contains(prompt, "CLASS DIAGRAMS") || !strings.Contains(prompt, "Use ONLY sequenceDiagram, flowchart (graph TD / flowchart LR), and classDiagram diagrams") {
+		t.Error("Expected class diagram guidance and classDiagram to be allowed when simple diagrams are preferred")
+	}
+	if !strings.Contains(prompt, "Do NOT use stateDiagram, gantt, or other diagram types") {
+		t.Error("Expected state diagrams to stay forbidden when no selected type calls for them")
+	}
+}
+
+func TestSkipImages(t *testing.T) {
+	outputDir := t.TempDir()
+	a := NewMermaidDocumenterAgent(&AgentConfig{Provider: "openai", OutputDir: outputDir, SkipImages: true})
+
+	prompt := a.buildSystemPrompt()
+	if strings.Contains(prompt, "TOOL CALL 2") || !strings.Contains(prompt, "Do NOT call generateMermaidImage") {
+		t.Errorf("Expected the prompt to drop the image step, got:\n%s", prompt)
+	}
+	if !strings.Contains(prompt, "EXACT sequence: writeFileContents -> final manifest") || strings.Contains(prompt, "before generating images") {
+		t.Error("Expected the OpenAI instructions without the image step")
+	}
+	if strings.Count(prompt, "FILE PATH REQUIREMENTS") != 1 || strings.Count(prompt, "MERMAID SYNTAX RULES") != 1 {
+		t.Error("Expected the file path and syntax rules once")
+	}
+	if result, rejected := a.skippedImageResult("generateMermaidImage"); !rejected || result.Success {
+		t.Errorf("Expected generateMermaidImage to be rejected, got %+v", result)
+	}
+	if _, rejected := a.skippedImageResult("writeFileContents"); rejected {
+		t.Error("Expected other tools to run")
+	}
+
+	entries := a.markSkippedImages(map[string]interface{}{
+		"summary.md":  "created",
+		"summary.svg": "generated",
+		"flow.png":    map[string]interface{}{"status": "generated", "type": "User Flow Diagrams"},
+	})
+	if entries["summary.md"] != "created" || entries["summary.svg"] != "skipped" {
+		t.Errorf("Expected the missing SVG to be marked skipped, got %v", entries)
+	}
+	if entry := entries["flow.png"].(map[string]interface{}); entry["status"] != "skipped" {
+		t.Errorf("Expected the typed entry to be marked skipped, got %v", entry)
+	}
+
+	a = NewMermaidDocumenterAgent(&AgentConfig{Provider: "openai"})
+	if !strings.Contains(a.buildSystemPrompt(), "TOOL CALL 2 (generate images)") {
+		t.Error("Expected the image step by default")
+	}
+	if _, rejected := a.skippedImageResult("generateMermaidImage"); rejected {
+		t.Error("Expected image generation by default")
+	}
+}
+
+// contextLimitProvider rejects the prompt once with ErrContextLengthExceeded
+// before replaying the scripted responses
+type contextLimitProvider struct {
+	scriptedProvider
+	rejectAt int
+	prompts  []string
+}
+
+func (p *contextLimitProvider) GenerateContent(ctx context.Context, prompt string, model string, apiKey string) (string, error) {
+	p.prompts = append(p.prompts, prompt)
+	if len(p.prompts) == p.rejectAt {
+		return "", fmt.Errorf("API error: 400 Bad Request: %w", providers.ErrContextLengthExceeded)
+	}
+	return p.scriptedProvider.GenerateContent(ctx, prompt, model, apiKey)
+}
+
+func TestRun_TrimsConversationWhenContextLengthExceeded(t *testing.T) {
+	lowConfidence := `{"type":"tool_call","tool":"readDirectories","args":{},"confidence":0.1,"rationale":"unsure %d"}`
+	provider := &contextLimitProvider{
+		scriptedProvider: scriptedProvider{responses: []string{
+			fmt.Sprintf(lowConfidence, 1),
+			fmt.Sprintf(lowConfidence, 2),
+			testFinalResponse,
+		}},
+		rejectAt: 3,
+	}
+	a, _ := newTestAgent(&AgentConfig{
+		MaxSteps:            5,
+		ConfidenceThreshold: 0.9,
+		OutputDir:           t.TempDir(),
+		LogsDir:             t.TempDir(),
+	})
+	a.Provider = provider
+
+	result, err := a.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.TerminationReason != TerminationCompleted {
+		t.Errorf("Expected termination %q, got %q", TerminationCompleted, result.TerminationReason)
+	}
+	if len(provider.prompts) != 4 {
+		t.Fatalf("Expected the rejected step to be retried once, got %d calls", len(provider.prompts))
+	}
+	retried := provider.prompts[3]
+	if strings.Contains(retried, "unsure 1") || !strings.Contains(retried, "unsure 2") {
+		t.Errorf("Expected the oldest turn to be dropped and the latest kept, got:\n%s", retried)
+	}
+	if !strings.Contains(retried, "User logs in with email and password.") {
+		t.Error("Expected the transcript to survive trimming")
+	}
+}
+
+func TestRun_FailsWhenConversationCannotBeTrimmed(t *testing.T) {
+	a, _ := newTestAgent(&AgentConfig{
+		MaxSteps:            5,
+		ConfidenceThreshold: 0.9,
+		OutputDir:           t.TempDir(),
+		LogsDir:             t.TempDir(),
+	})
+	a.Provider = &contextLimitProvider{rejectAt: 1}
+
+	_, err := a.Run(context.Background())
+	if !errors.Is(err, providers.ErrContextLengthExceeded) {
+		t.Fatalf("Expected ErrContextLengthExceeded, got %v", err)
+	}
+}
+
+func TestTrimConversation(t *testing.T) {
+	turn := func(role, content string) map[string]interface{} {
+		return map[string]interface{}{"role": role, "content": content}
+	}
+	conversation := []map[string]interface{}{
+		turn("system", "prompt"),
+		turn("user", "transcript"),
+		turn("assistant", "a1"),
+		turn("user", "r1"),
+		turn("system", "tool failed"),
+		turn("assistant", "a2"),
+		turn("user", "r2"),
+		turn("assistant", "a3"),
+		turn("user", "r3"),
+	}
+
+	trimmed, dropped := trimConversation(conversation)
+	if dropped != 2 {
+		t.Fatalf("Expected 2 turns dropped, got %d", dropped)
+	}
+	var contents []string
+	for _, turn := range trimmed {
+		contents = append(contents, turn["content"].(string))
+	}
+	if got := strings.Join(contents, ","); got != "prompt,transcript,tool failed,a2,r2,a3,r3" {
+		t.Errorf("Unexpected trimmed conversation: %s", got)
+	}
+
+	if _, dropped := trimConversation(conversation[:4]); dropped != 0 {
+		t.Errorf("Expected the most recent turns to be kept, dropped %d", dropped)
+	}
+}
+
+func TestAddChainOfThought_CapsStoredText(t *testing.T) {
+	conversation := []map[string]interface{}{
+		{"role": "system", "content": "prompt"},
+		{"role": "user", "content": strings.Repeat("é", 30)},
+	}
+
+	a := NewMermaidDocumenterAgent(&AgentConfig{MaxLoggedResponseChars: 10})
+	entry := map[string]interface{}{}
+	a.addChainOfThought(entry, conversation, "short")
+
+	logged := entry["conversation"].([]map[string]interface{})
+	if len(logged) != 2 || logged[0]["content"] != "prompt" {
+		t.Fatalf("Expected the full conversation with short turns untouched, got %v", logged)
+	}
+	if got := logged[1]["content"]; got != strings.Repeat("é", 10)+"… [truncated 20 chars]" {
+		t.Errorf("Unexpected truncated turn: %q", got)
+	}
+	if conversation[1]["content"] != strings.Repeat("é", 30) {
+		t.Error("Expected the live conversation not to be modified")
+	}
+	truncated, ok := entry["truncated"].(map[string]interface{})
+	if !ok || truncated["conversationTurns"] != 1 || truncated["response"] != false {
+		t.Errorf("Expected the truncation to be recorded, got %v", entry["truncated"])
+	}
+
+	a = NewMermaidDocumenterAgent(&AgentConfig{StoreLastTurnOnly: true})
+	entry = map[string]interface{}{}
+	a.addChainOfThought(entry, conversation, "short")
+	if logged := entry["conversation"].([]map[string]interface{}); len(logged) != 1 || logged[0]["role"] != "user" {
+		t.Errorf("Expected only the last turn, got %v", logged)
+	}
+	if _, ok := entry["truncated"]; ok {
+		t.Error("Expected no truncation under the default cap")
+	}
+}
+
+func TestFormatToolResult(t *testing.T) {
+	formatted := formatToolResult("generateMermaidImage", tools.ToolResult{
+		Success: true,
+		Data:    map[string]interface{}{"outputFile": "out/flow.svg", "commandOutput": "Generating single mermaid chart"},
+	})
+	if !strings.HasPrefix(formatted, "Tool result: ") {
+		t.Fatalf("Expected a 'Tool result:' prefix, got %q", formatted)
+	}
+	var message map[string]interface{}
+	if err := json.Unmarshal([]byte(strings.TrimPrefix(formatted, "Tool result: ")), &message); err != nil {
+		t.Fatalf("Expected JSON, got %q: %v", formatted, err)
+	}
+	if message["tool"] != "generateMermaidImage" || message["success"] != true {
+		t.Errorf("Expected tool and success fields, got %v", message)
+	}
+	if _, ok := message["error"]; ok {
+		t.Errorf("Expected no error field on success, got %v", message)
+	}
+	data, _ := message["data"].(map[string]interface{})
+	if data["outputFile"] != "out/flow.svg" {
+		t.Errorf("Expected the output path to be kept, got %v", data)
+	}
+	if _, ok := data["commandOutput"]; ok {
+		t.Errorf("Expected raw command output to be dropped, got %v", data)
+	}
+
+	formatted = formatToolResult("writeFile", tools.ToolResult{Success: false, Error: "permission denied"})
+	if formatted != `Tool result: {"error":"permission denied","success":false,"tool":"writeFile"}` {
+		t.Errorf("Unexpected failure formatting: %s", formatted)
+	}
+	// Diagram source is not HTML-escaped
+	formatted = formatToolResult("readFileContents", tools.ToolResult{Success: true, Data: map[string]interface{}{"content": "A --> B & <<interface>>"}})
+	if !strings.Contains(formatted, `"content":"A --> B & <<interface>>"`) {
+		t.Errorf("Expected Mermaid source unescaped, got %s", formatted)
+	}
+}
+
+// initFailureProvider fails every call as if its client could not be created
+type initFailureProvider struct {
+	scriptedProvider
+}
+
+func (p *initFailureProvider) GenerateContent(ctx context.Context, prompt string, model string, apiKey string) (string, error) {
+	p.calls++
+	return "", fmt.Errorf("%w: api key is required", providers.ErrProviderInit)
+}
+
+func TestRun_ProviderInitFailure(t *testing.T) {
+	a, _ := newTestAgent(&AgentConfig{
+		Provider:            "google",
+		MaxSteps:            5,
+		ConfidenceThreshold: 0.9,
+		OutputDir:           t.TempDir(),
+		LogsDir:             t.TempDir(),
+	})
+	provider := &initFailureProvider{}
+	a.Provider = provider
+
+	result, err := a.Run(context.Background())
+	if !errors.Is(err, providers.ErrProviderInit) {
+		t.Fatalf("Expected ErrProviderInit, got %v", err)
+	}
+	if err.Error() != "google provider: failed to initialize provider: api key is required" {
+		t.Errorf("Unexpected error message: %v", err)
+	}
+	if result == nil || result.TerminationReason != TerminationError {
+		t.Errorf("Expected an error termination, got %+v", result)
+	}
+	if provider.calls != 1 {
+		t.Errorf("Expected no retries after an init failure, got %d calls", provider.calls)
+	}
+}
+
+func TestRun_WritesPerRunLogFile(t *testing.T) {
+	tests := []struct {
+		name       string
+		runLogFile bool
+		skipShared bool
+		wantRun    bool
+		wantShared bool
+	}{
+		{"shared only", false, false, false, true},
+		{"both", true, false, true, true},
+		{"run file only", true, true, true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logsDir := t.TempDir()
+			a, _ := newTestAgent(&AgentConfig{
+				MaxSteps:            5,
+				ConfidenceThreshold: 0.9,
+				OutputDir:           t.TempDir(),
+				LogsDir:             logsDir,
+				RunLogFile:          tt.runLogFile,
+				SkipSharedLog:       tt.skipShared,
+			}, testFinalResponse)
+
+			if _, err := a.Run(context.Background()); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			_, runErr := os.Stat(filepath.Join(logsDir, a.RunID+".jsonl"))
+			if (runErr == nil) != tt.wantRun {
+				t.Errorf("Per-run log exists = %v, want %v", runErr == nil, tt.wantRun)
+			}
+			_, sharedErr := os.Stat(filepath.Join(logsDir, "logs.jsonl"))
+			if (sharedErr == nil) != tt.wantShared {
+				t.Errorf("Shared log exists = %v, want %v", sharedErr == nil, tt.wantShared)
+			}
+		})
+	}
+}
+
+func TestBuildSystemPrompt_DisabledTools(t *testing.T) {
+	a := NewMermaidDocumenterAgent(&AgentConfig{DisabledTools: []string{"fetchMermaidDocumentation"}})
+	prompt := a.buildSystemPrompt()
+	if !strings.Contains(prompt, "DISABLED TOOLS") || !strings.Contains(prompt, "- fetchMermaidDocumentation") {
+		t.Errorf("Expected the disabled tool to be listed in the system prompt")
+	}
+
+	a.Config.DisabledTools = nil
+	if strings.Contains(a.buildSystemPrompt(), "DISABLED TOOLS") {
+		t.Error("Expected no disabled tools section by default")
+	}
+}
+
+func TestRun_UnknownProviderFails(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	for _, name := range []string{"", "azure"} {
+		a := NewMermaidDocumenterAgent(&AgentConfig{Provider: name, MaxSteps: 3, ConfidenceThreshold: 0.9})
+		a.SetTranscript("User logs in with email and password.")
+
+		result, err := a.Run(context.Background())
+		if !errors.Is(err, providers.ErrUnknownProvider) {
+			t.Errorf("Expected ErrUnknownProvider for %q, got %v", name, err)
+		}
+		if result == nil || result.TerminationReason != TerminationError {
+			t.Errorf("Expected the run for %q to end with an error, got %+v", name, result)
+		}
+	}
+}
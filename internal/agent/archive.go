@@ -0,0 +1,24 @@
+package agent
+
+import (
+	"path/filepath"
+	"time"
+
+	"github.com/landanqrew/mermaid-agent-documenter/internal/runs"
+)
+
+// ArchiveDir returns the directory a run's output is nested in when
+// ArchiveRuns is set: <outputDir>/<YYYY-MM-DD_HH-MM>_<run-id>
+func ArchiveDir(outputDir string, started time.Time, runID string) string {
+	return filepath.Join(outputDir, runs.ArchiveName(started, runID))
+}
+
+// archiveConfig returns a copy of config whose OutputDir is the run's dated
+// archive directory, so every path the agent resolves (files, images, the
+// manifest and index) lands inside it. The caller's config is left alone so
+// later runs get their own directory.
+func archiveConfig(config *AgentConfig, started time.Time, runID string) *AgentConfig {
+	archived := *config
+	archived.OutputDir = ArchiveDir(config.OutputDir, started, runID)
+	return &archived
+}
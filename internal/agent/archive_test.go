@@ -0,0 +1,55 @@
+package agent
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestArchiveDir(t *testing.T) {
+	started := time.Date(2025, 1, 31, 14, 5, 9, 0, time.UTC)
+	got := ArchiveDir("/tmp/out", started, "abc123")
+	if want := filepath.Join("/tmp/out", "2025-01-31_14-05_abc123"); got != want {
+		t.Errorf("Expected %s, got %s", want, got)
+	}
+}
+
+func TestRun_ArchiveRuns(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	outputDir := filepath.Join(home, "mermaid-agent-documenter", "output")
+	config := &AgentConfig{MaxSteps: 5, ConfidenceThreshold: 0.9, OutputDir: outputDir, ArchiveRuns: true}
+	a, _ := newTestAgent(config, testWriteResponse, testFinalResponse)
+
+	result, err := a.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if config.OutputDir != outputDir {
+		t.Errorf("Expected the caller's config to keep %s, got %s", outputDir, config.OutputDir)
+	}
+	if filepath.Dir(result.OutputDir) != outputDir || !strings.HasSuffix(result.OutputDir, "_"+a.RunID) {
+		t.Fatalf("Expected a dated directory for run %s in %s, got %s", a.RunID, outputDir, result.OutputDir)
+	}
+	if _, err := os.Stat(filepath.Join(result.OutputDir, "login.md")); err != nil {
+		t.Errorf("Expected login.md inside the archive directory: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(outputDir, "login.md")); !os.IsNotExist(err) {
+		t.Errorf("Expected nothing written directly to %s, got %v", outputDir, err)
+	}
+
+	// Images are rendered next to their Markdown inside the archive directory
+	args := a.modifyFilePaths(map[string]interface{}{"inputFile": "login.md", "outputFile": "login"}, "")
+	if want := filepath.Join(result.OutputDir, "login"); args["outputFile"] != want {
+		t.Errorf("Expected outputFile %s, got %v", want, args["outputFile"])
+	}
+
+	// A second run gets a directory of its own
+	next := NewMermaidDocumenterAgent(config)
+	if next.Config.OutputDir == result.OutputDir {
+		t.Errorf("Expected a new archive directory for the next run, got %s again", next.Config.OutputDir)
+	}
+}
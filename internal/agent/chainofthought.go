@@ -0,0 +1,71 @@
+package agent
+
+import (
+	"fmt"
+	"unicode/utf8"
+)
+
+// DefaultMaxLoggedResponseChars caps each response and conversation turn
+// stored with StoreChainOfThought when no limit is configured
+const DefaultMaxLoggedResponseChars = 20000
+
+// maxLoggedResponseChars returns the configured cap, 0 meaning unlimited
+func (a *MermaidDocumenterAgent) maxLoggedResponseChars() int {
+	switch {
+	case a.Config.MaxLoggedResponseChars < 0:
+		return 0
+	case a.Config.MaxLoggedResponseChars == 0:
+		return DefaultMaxLoggedResponseChars
+	default:
+		return a.Config.MaxLoggedResponseChars
+	}
+}
+
+// truncateForLog shortens text to at most limit characters, noting how much
+// was dropped. A limit of 0 leaves the text unchanged.
+func truncateForLog(text string, limit int) (string, bool) {
+	if limit <= 0 || utf8.RuneCountInString(text) <= limit {
+		return text, false
+	}
+	runes := []rune(text)
+	return fmt.Sprintf("%s… [truncated %d chars]", string(runes[:limit]), len(runes)-limit), true
+}
+
+// addChainOfThought stores the conversation and raw response in a log entry,
+// capping their size and recording what was truncated
+func (a *MermaidDocumenterAgent) addChainOfThought(logEntry map[string]interface{}, conversation []map[string]interface{}, response string) {
+	limit := a.maxLoggedResponseChars()
+
+	turns := conversation
+	if a.Config.StoreLastTurnOnly && len(turns) > 0 {
+		turns = turns[len(turns)-1:]
+	}
+
+	logged := make([]map[string]interface{}, 0, len(turns))
+	truncatedTurns := 0
+	for _, turn := range turns {
+		content, _ := turn["content"].(string)
+		capped, truncated := truncateForLog(content, limit)
+		if truncated {
+			truncatedTurns++
+		}
+		copied := make(map[string]interface{}, len(turn))
+		for key, value := range turn {
+			copied[key] = value
+		}
+		copied["content"] = capped
+		logged = append(logged, copied)
+	}
+	logEntry["conversation"] = logged
+
+	cappedResponse, responseTruncated := truncateForLog(response, limit)
+	logEntry["response"] = cappedResponse
+
+	if truncatedTurns > 0 || responseTruncated {
+		logEntry["truncated"] = map[string]interface{}{
+			"maxChars":          limit,
+			"conversationTurns": truncatedTurns,
+			"response":          responseTruncated,
+		}
+	}
+}
@@ -0,0 +1,232 @@
+package agent
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// conversationPreamble is the number of leading turns that are never trimmed:
+// the system prompt and the user message carrying the transcript
+const conversationPreamble = 2
+
+// minRecentTurns is the number of most recent turns kept when trimming, so the
+// model still sees its last action and the result it produced
+const minRecentTurns = 2
+
+// pinKey marks turns that trimming and windowing keep in place: pinPlan for
+// the plan instruction, the plan, and its approval, and pinRefinement for
+// refinement instructions, of which only the latest is kept
+const (
+	pinKey        = "pin"
+	pinPlan       = "plan"
+	pinRefinement = "refinement"
+)
+
+// pinnedTurns returns the indexes of the turns that are never trimmed or
+// windowed out: the plan turns and the latest refinement instruction
+func pinnedTurns(conversation []map[string]interface{}) map[int]bool {
+	pinned := make(map[int]bool)
+	latestRefinement := -1
+	for i, turn := range conversation {
+		switch turn[pinKey] {
+		case pinPlan:
+			pinned[i] = true
+		case pinRefinement:
+			latestRefinement = i
+		}
+	}
+	if latestRefinement >= 0 {
+		pinned[latestRefinement] = true
+	}
+	return pinned
+}
+
+// trimConversation drops the oldest half of the trimmable turns to shrink a
+// prompt that exceeded the model's context window. System turns, the
+// preamble, pinned turns, and the most recent turns are kept. It returns the trimmed
+// conversation and how many turns were dropped.
+func trimConversation(conversation []map[string]interface{}) ([]map[string]interface{}, int) {
+	pinned := pinnedTurns(conversation)
+	var candidates []int
+	for i := conversationPreamble; i < len(conversation); i++ {
+		if role, _ := conversation[i]["role"].(string); role != "system" && !pinned[i] {
+			candidates = append(candidates, i)
+		}
+	}
+	if len(candidates) <= minRecentTurns {
+		return conversation, 0
+	}
+
+	trimmable := candidates[:len(candidates)-minRecentTurns]
+	dropCount := (len(trimmable) + 1) / 2
+	drop := make(map[int]bool, dropCount)
+	for _, i := range trimmable[:dropCount] {
+		drop[i] = true
+	}
+
+	trimmed := make([]map[string]interface{}, 0, len(conversation)-dropCount)
+	for i, turn := range conversation {
+		if !drop[i] {
+			trimmed = append(trimmed, turn)
+		}
+	}
+	return trimmed, dropCount
+}
+
+// Conversation window strategies (see ConversationWindow)
+const (
+	WindowFull      = "full"      // send the whole conversation every step
+	WindowLastK     = "last-k"    // send the preamble and the last MaxTurns turns
+	WindowSummarize = "summarize" // like last-k, with older turns replaced by a compact note
+)
+
+// DefaultWindowTurns is the number of recent turns a window sends when MaxTurns is 0
+const DefaultWindowTurns = 8
+
+// summaryLineChars caps each line of the note that replaces older turns
+const summaryLineChars = 160
+
+// ConversationWindow limits how much history is sent to the model on each
+// step. The stored conversation is kept whole for logs and refinements.
+type ConversationWindow struct {
+	Strategy string // WindowFull (or ""), WindowLastK, or WindowSummarize
+	MaxTurns int    // recent turns sent verbatim; 0 uses DefaultWindowTurns
+}
+
+// ParseWindowStrategy validates a conversation window strategy, returning WindowFull for ""
+func ParseWindowStrategy(value string) (string, error) {
+	switch strategy := strings.ToLower(strings.TrimSpace(value)); strategy {
+	case "", WindowFull:
+		return WindowFull, nil
+	case WindowLastK, WindowSummarize:
+		return strategy, nil
+	}
+	return "", fmt.Errorf("unknown conversation window strategy '%s' (use %s, %s, or %s)", value, WindowFull, WindowLastK, WindowSummarize)
+}
+
+// windowConversation returns the turns to send for the next step: the
+// preamble and the most recent turns, preceded under WindowSummarize by a
+// note describing the turns left out. The window never starts on a tool
+// result whose call was left out, so the current step's call and result are
+// always sent together. Pinned turns (the approved plan and the latest
+// refinement instruction) are always sent, right after the preamble. Older
+// system turns (tool failure feedback) are left out with the steps they
+// belong to.
+func windowConversation(conversation []map[string]interface{}, window ConversationWindow) []map[string]interface{} {
+	strategy, err := ParseWindowStrategy(window.Strategy)
+	if err != nil || strategy == WindowFull {
+		return conversation
+	}
+	maxTurns := window.MaxTurns
+	if maxTurns <= 0 {
+		maxTurns = DefaultWindowTurns
+	}
+	maxTurns = max(maxTurns, minRecentTurns)
+
+	pinned := pinnedTurns(conversation)
+	var candidates []int
+	for i := conversationPreamble; i < len(conversation); i++ {
+		if role, _ := conversation[i]["role"].(string); role != "system" && !pinned[i] {
+			candidates = append(candidates, i)
+		}
+	}
+	if len(candidates) <= maxTurns {
+		return conversation
+	}
+
+	keepFrom := candidates[len(candidates)-maxTurns]
+	if turnRole(conversation[keepFrom]) == "user" && keepFrom > conversationPreamble && turnRole(conversation[keepFrom-1]) == "assistant" {
+		keepFrom--
+	}
+	if keepFrom <= conversationPreamble {
+		return conversation
+	}
+
+	windowed := make([]map[string]interface{}, 0, conversationPreamble+len(pinned)+1+len(conversation)-keepFrom)
+	windowed = append(windowed, conversation[:conversationPreamble]...)
+	var omitted []map[string]interface{}
+	for i := conversationPreamble; i < keepFrom; i++ {
+		if pinned[i] {
+			windowed = append(windowed, conversation[i])
+		} else {
+			omitted = append(omitted, conversation[i])
+		}
+	}
+	if strategy == WindowSummarize && len(omitted) > 0 {
+		windowed = append(windowed, map[string]interface{}{
+			"role":    "system",
+			"content": summarizeTurns(omitted),
+		})
+	}
+	return append(windowed, conversation[keepFrom:]...)
+}
+
+// summarizeTurns describes left-out turns in a compact note, one line per turn
+func summarizeTurns(turns []map[string]interface{}) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Summary of %d earlier conversation turns, omitted to save tokens (files they wrote still exist):", len(turns))
+	for _, turn := range turns {
+		content, _ := turn["content"].(string)
+		sb.WriteString("\n- ")
+		sb.WriteString(truncateRunes(summarizeTurn(turnRole(turn), content), summaryLineChars))
+	}
+	return sb.String()
+}
+
+// summarizeTurn describes one turn: the tool an assistant called, the outcome
+// of a tool result, or the start of any other message
+func summarizeTurn(role, content string) string {
+	switch role {
+	case "assistant":
+		var output StructuredOutput
+		if err := json.Unmarshal([]byte(strings.TrimSpace(content)), &output); err == nil {
+			if output.Type == OutputTypeToolCall && output.Tool != "" {
+				return fmt.Sprintf("assistant called %s%s", output.Tool, summarizeArgs(output.Args))
+			}
+			if output.Type != "" {
+				return fmt.Sprintf("assistant returned a %s", output.Type)
+			}
+		}
+	case "user":
+		if encoded, ok := strings.CutPrefix(content, "Tool result: "); ok {
+			var result struct {
+				Tool    string `json:"tool"`
+				Success bool   `json:"success"`
+				Error   string `json:"error"`
+			}
+			if err := json.Unmarshal([]byte(encoded), &result); err == nil {
+				if result.Success {
+					return fmt.Sprintf("%s succeeded", result.Tool)
+				}
+				return fmt.Sprintf("%s failed: %s", result.Tool, result.Error)
+			}
+		}
+	}
+	return fmt.Sprintf("%s: %s", role, strings.Join(strings.Fields(content), " "))
+}
+
+// summarizeArgs names the file a tool call worked on, if any
+func summarizeArgs(args map[string]interface{}) string {
+	for _, key := range []string{"path", "outputFile", "inputFile"} {
+		if value, ok := args[key].(string); ok && value != "" {
+			return fmt.Sprintf(" (%s)", value)
+		}
+	}
+	return ""
+}
+
+// turnRole returns a conversation turn's role
+func turnRole(turn map[string]interface{}) string {
+	role, _ := turn["role"].(string)
+	return role
+}
+
+// truncateRunes shortens s to at most limit runes, marking the cut with "..."
+func truncateRunes(s string, limit int) string {
+	runes := []rune(s)
+	if len(runes) <= limit {
+		return s
+	}
+	return string(runes[:limit-3]) + "..."
+}
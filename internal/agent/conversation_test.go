@@ -0,0 +1,184 @@
+package agent
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+// windowTestConversation is a preamble followed by three steps, the first of which failed
+func windowTestConversation() []map[string]interface{} {
+	turn := func(role, content string) map[string]interface{} {
+		return map[string]interface{}{"role": role, "content": content}
+	}
+	return []map[string]interface{}{
+		turn("system", "prompt"),
+		turn("user", "transcript"),
+		turn("system", "tool failed"),
+		turn("assistant", `{"type":"tool_call","tool":"readFileContents","args":{"path":"notes.md"}}`),
+		turn("user", `Tool result: {"error":"file not found","success":false,"tool":"readFileContents"}`),
+		turn("assistant", `{"type":"tool_call","tool":"writeFileContents","args":{"path":"login.md","content":"..."}}`),
+		turn("user", `Tool result: {"success":true,"tool":"writeFileContents"}`),
+		turn("assistant", "a3"),
+		turn("user", "r3"),
+	}
+}
+
+// windowContents returns the conversation's contents, with summary notes shortened to "summary"
+func windowContents(conversation []map[string]interface{}) string {
+	var contents []string
+	for _, turn := range conversation {
+		content := turn["content"].(string)
+		if strings.HasPrefix(content, "Summary of") {
+			content = "summary"
+		}
+		if len(content) > 12 {
+			content = content[:12]
+		}
+		contents = append(contents, content)
+	}
+	return strings.Join(contents, ",")
+}
+
+func TestWindowConversation_LastK(t *testing.T) {
+	conversation := windowTestConversation()
+
+	// Three turns would start on a tool result, so its call is sent too
+	windowed := windowConversation(conversation, ConversationWindow{Strategy: WindowLastK, MaxTurns: 3})
+	if got := windowContents(windowed); got != `prompt,transcript,{"type":"too,Tool result:,a3,r3` {
+		t.Errorf("Unexpected window: %s", got)
+	}
+	if len(conversation) != 9 {
+		t.Error("Expected the stored conversation to be left alone")
+	}
+
+	// The current step's call and result are always sent
+	windowed = windowConversation(conversation, ConversationWindow{Strategy: WindowLastK, MaxTurns: 1})
+	if got := windowContents(windowed); got != "prompt,transcript,a3,r3" {
+		t.Errorf("Unexpected window: %s", got)
+	}
+
+	for _, window := range []ConversationWindow{{}, {Strategy: WindowFull, MaxTurns: 2}, {Strategy: WindowLastK, MaxTurns: 6}} {
+		if windowed := windowConversation(conversation, window); len(windowed) != len(conversation) {
+			t.Errorf("Expected %+v to send the whole conversation, got %d turns", window, len(windowed))
+		}
+	}
+}
+
+func TestWindowConversation_Summarize(t *testing.T) {
+	windowed := windowConversation(windowTestConversation(), ConversationWindow{Strategy: WindowSummarize, MaxTurns: 2})
+	if got := windowContents(windowed); got != "prompt,transcript,summary,a3,r3" {
+		t.Fatalf("Unexpected window: %s", got)
+	}
+
+	note := windowed[2]["content"].(string)
+	for _, line := range []string{
+		"Summary of 5 earlier conversation turns",
+		"- system: tool failed",
+		"- assistant called readFileContents (notes.md)",
+		"- readFileContents failed: file not found",
+		"- assistant called writeFileContents (login.md)",
+		"- writeFileContents succeeded",
+	} {
+		if !strings.Contains(note, line) {
+			t.Errorf("Expected the summary to contain %q, got:\n%s", line, note)
+		}
+	}
+}
+
+func TestWindowConversation_KeepsPinnedTurns(t *testing.T) {
+	pin := func(turn map[string]interface{}, kind string) map[string]interface{} {
+		turn[pinKey] = kind
+		return turn
+	}
+	base := windowTestConversation()
+	conversation := append([]map[string]interface{}{}, base[:2]...)
+	conversation = append(conversation,
+		pin(map[string]interface{}{"role": "user", "content": "plan please"}, pinPlan),
+		pin(map[string]interface{}{"role": "assistant", "content": "the plan"}, pinPlan),
+		pin(map[string]interface{}{"role": "user", "content": "approved"}, pinPlan),
+	)
+	conversation = append(conversation, base[2:5]...)
+	conversation = append(conversation, pin(map[string]interface{}{"role": "user", "content": "refine once"}, pinRefinement))
+	conversation = append(conversation, base[5:7]...)
+	conversation = append(conversation, pin(map[string]interface{}{"role": "user", "content": "refine twice"}, pinRefinement))
+	conversation = append(conversation, base[7:]...)
+
+	windowed := windowConversation(conversation, ConversationWindow{Strategy: WindowSummarize, MaxTurns: 2})
+	if got := windowContents(windowed); got != "prompt,transcript,plan please,the plan,approved,refine twice,summary,a3,r3" {
+		t.Fatalf("Unexpected window: %s", got)
+	}
+	if note := windowed[6]["content"].(string); !strings.Contains(note, "Summary of 6 earlier conversation turns") || strings.Contains(note, "the plan") {
+		t.Errorf("Expected only unpinned turns to be summarized, got:\n%s", note)
+	}
+
+	trimmed, dropped := trimConversation(conversation)
+	if dropped == 0 || !strings.Contains(windowContents(trimmed), "plan please,the plan,approved") || !strings.Contains(windowContents(trimmed), "refine twice") {
+		t.Errorf("Expected trimming to keep pinned turns, got %s", windowContents(trimmed))
+	}
+}
+
+func TestParseWindowStrategy(t *testing.T) {
+	for value, want := range map[string]string{"": WindowFull, "Last-K": WindowLastK, "summarize": WindowSummarize} {
+		if got, err := ParseWindowStrategy(value); err != nil || got != want {
+			t.Errorf("ParseWindowStrategy(%q) = %q, %v; want %q", value, got, err, want)
+		}
+	}
+	if _, err := ParseWindowStrategy("sliding"); err == nil {
+		t.Error("Expected an unknown strategy to be rejected")
+	}
+}
+
+func TestRun_ConversationWindowLimitsPrompt(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	a, provider := newTestAgent(&AgentConfig{
+		MaxSteps:            10,
+		ConfidenceThreshold: 0.9,
+		KeepGoing:           true,
+		ConversationWindow:  ConversationWindow{Strategy: WindowSummarize, MaxTurns: 2},
+	}, testFailingResponse, testFailingResponse, testFailingResponse, testFinalResponse)
+
+	if _, err := a.Run(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	last := provider.prompts[len(provider.prompts)-1]
+	if !strings.Contains(last, "Summary of") || !strings.Contains(last, "readFileContents failed") {
+		t.Errorf("Expected older steps to be summarized, got:\n%s", last)
+	}
+	if !strings.Contains(last, "User logs in with email and password.") {
+		t.Error("Expected the transcript to always be sent")
+	}
+	if count := strings.Count(last, "assistant: "+testFailingResponse); count != 1 {
+		t.Errorf("Expected only the latest tool call verbatim, found %d", count)
+	}
+	if stored := a.buildConversationString(a.conversation); strings.Count(stored, testFailingResponse) != 3 {
+		t.Error("Expected the stored conversation to keep every turn")
+	}
+}
+
+func TestRun_ConversationWindowKeepsApprovedPlan(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	a, provider := newTestAgent(&AgentConfig{
+		MaxSteps:            10,
+		ConfidenceThreshold: 0.9,
+		KeepGoing:           true,
+		PlanFirst:           true,
+		AutoApprovePlan:     true,
+		ConversationWindow:  ConversationWindow{Strategy: WindowLastK, MaxTurns: 2},
+	}, testPlanResponse, testFailingResponse, testFailingResponse, testFailingResponse, testFinalResponse)
+
+	if _, err := a.Run(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	last := provider.prompts[len(provider.prompts)-1]
+	for _, want := range []string{planInstruction, testPlanResponse, planApprovedMessage} {
+		if !strings.Contains(last, want) {
+			t.Errorf("Expected the windowed prompt to keep %q, got:\n%s", want, last)
+		}
+	}
+	if count := strings.Count(last, "assistant: "+testFailingResponse); count != 1 {
+		t.Errorf("Expected only the latest tool call verbatim, found %d", count)
+	}
+}
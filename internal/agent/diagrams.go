@@ -0,0 +1,214 @@
+package agent
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/landanqrew/mermaid-agent-documenter/internal/console"
+	"github.com/landanqrew/mermaid-agent-documenter/internal/tools"
+)
+
+// simpleDiagramInstructions restricts the model to the diagram types that
+// render reliably, plus class and state diagrams when the selected
+// documentation types call for them (see diagramGuidance)
+func (a *MermaidDocumenterAgent) simpleDiagramInstructions() string {
+	class, state := a.diagramKinds()
+	allowed := []string{"sequenceDiagram", "flowchart (graph TD / flowchart LR)"}
+	var forbidden []string
+	if class {
+		allowed = append(allowed, "classDiagram")
+	} else {
+		forbidden = append(forbidden, "classDiagram")
+	}
+	if state {
+		allowed = append(allowed, "stateDiagram-v2")
+	} else {
+		forbidden = append(forbidden, "stateDiagram")
+	}
+	forbidden = append(forbidden, "gantt")
+
+	return fmt.Sprintf(`
+
+SIMPLE DIAGRAMS (required):
+- Use ONLY %s diagrams
+- Do NOT use %s, or other diagram types
+- Avoid erDiagram; describe data models with a flowchart instead. If an ER diagram is unavoidable, list attribute names only
+- NEVER write typed ER attributes such as "string id PK" or "int count": diagrams with type annotations are rejected before rendering`,
+		joinList(allowed, "and"), strings.Join(forbidden, ", "))
+}
+
+// joinList joins items as "a, b, and c" (or "a and b")
+func joinList(items []string, conjunction string) string {
+	switch len(items) {
+	case 0:
+		return ""
+	case 1:
+		return items[0]
+	case 2:
+		return items[0] + " " + conjunction + " " + items[1]
+	}
+	return strings.Join(items[:len(items)-1], ", ") + ", " + conjunction + " " + items[len(items)-1]
+}
+
+// lintDiagramSource checks the diagram source passed to generateMermaidImage
+// and returns a failed result when it contains typed ER attributes
+func (a *MermaidDocumenterAgent) lintDiagramSource(args map[string]interface{}) (tools.ToolResult, bool) {
+	if !a.Config.PreferSimpleDiagrams {
+		return tools.ToolResult{}, false
+	}
+
+	inputFile, ok := args["inputFile"].(string)
+	if !ok {
+		return tools.ToolResult{}, false
+	}
+	// A missing file is reported by the tool itself
+	data, err := os.ReadFile(expandHome(inputFile))
+	if err != nil {
+		return tools.ToolResult{}, false
+	}
+
+	issues := tools.LintERDiagrams(string(data))
+	if len(issues) == 0 {
+		return tools.ToolResult{}, false
+	}
+	return tools.ToolResult{
+		Success: false,
+		Error: fmt.Sprintf("Diagram lint failed: ER attributes must not have type annotations. Rewrite %s with attribute names only (or use a flowchart) and render again. Issues: %s",
+			inputFile, strings.Join(issues, "; ")),
+	}, true
+}
+
+// autoFixDiagramSource rewrites the diagram source passed to generateMermaidImage
+// with tools.AutoFixMermaid when AutoFixMermaid is on, so common mistakes are
+// corrected before the lint and the render see them. Each fix is logged.
+func (a *MermaidDocumenterAgent) autoFixDiagramSource(args map[string]interface{}) {
+	if !a.Config.AutoFixMermaid {
+		return
+	}
+
+	inputFile, ok := args["inputFile"].(string)
+	if !ok {
+		return
+	}
+	path := expandHome(inputFile)
+	// A missing file is reported by the tool itself
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return
+	}
+
+	fixed, fixes := tools.AutoFixMermaid(string(data))
+	if len(fixes) == 0 {
+		return
+	}
+	if err := os.WriteFile(path, []byte(fixed), 0644); err != nil {
+		console.Printf("⚠️  Could not save Mermaid fixes to %s: %v\n", inputFile, err)
+		return
+	}
+
+	console.Printf("🔧 Auto-fixed %d Mermaid issue(s) in %s\n", len(fixes), inputFile)
+	for _, fix := range fixes {
+		console.Printf("   - %s\n", fix)
+	}
+	a.appendLogEntry(map[string]interface{}{
+		"timestamp":   time.Now().Format(time.RFC3339),
+		"run_id":      a.RunID,
+		"step":        a.StepCount + 1,
+		"provider":    a.Config.Provider,
+		"model":       a.Config.Model,
+		"output_type": "mermaid_autofix",
+		"file":        inputFile,
+		"fixes":       fixes,
+	})
+}
+
+// fallbackFormats pairs each image format with the one to retry when the
+// renderer fails to create the output file
+var fallbackFormats = map[string]string{"svg": "png", "png": "svg", "pdf": "png"}
+
+// addFallbackFormat asks generateMermaidImage to retry in another format when
+// ImageFormatFallback is on and the model did not choose a fallback itself
+func (a *MermaidDocumenterAgent) addFallbackFormat(args map[string]interface{}) {
+	if !a.Config.ImageFormatFallback {
+		return
+	}
+	if _, set := args["fallbackFormat"]; set {
+		return
+	}
+	format, _ := args["format"].(string)
+	if format == "" {
+		format = "svg"
+	}
+	if fallback, ok := fallbackFormats[format]; ok {
+		args["fallbackFormat"] = fallback
+	}
+}
+
+// classDiagramDocTypes and stateDiagramDocTypes are the documentation types
+// that call for class and state diagrams
+var (
+	classDiagramDocTypes = map[string]bool{"System Architecture": true, "Data Models (ER Diagrams)": true, "API Documentation": true}
+	stateDiagramDocTypes = map[string]bool{"User Flow Diagrams": true, "Error Handling": true}
+)
+
+// classDiagramInstructions covers classDiagram syntax for current Mermaid releases
+const classDiagramInstructions = `
+
+CLASS DIAGRAMS (classDiagram):
+- Declare members inside braces, one per line, and close the brace on its own line:
+  class Order {
+    +String id
+    -Decimal total
+    +submit() bool
+  }
+- Visibility is a prefix on the member: + public, - private, # protected, ~ package. Never put it after the name
+- Mark methods abstract with a trailing * and static with a trailing $: +validate()* or +create()$
+- Write generics with tildes, not angle brackets: List~Order~
+- Relationships: <|-- inheritance, *-- composition, o-- aggregation, --> association, ..> dependency, ..|> realization
+- Put cardinality in quotes on either side and a label after a colon: Customer "1" --> "*" Order : places
+- Annotations go on their own line inside the class: <<interface>>
+- Class names cannot contain spaces or hyphens; use class Payment["Payment Gateway"] for a display label`
+
+// stateDiagramInstructions covers stateDiagram-v2 syntax for current Mermaid releases
+const stateDiagramInstructions = `
+
+STATE DIAGRAMS (stateDiagram-v2):
+- Start with stateDiagram-v2, not stateDiagram
+- Use [*] for the start and end states: [*] --> Idle and Done --> [*]
+- Write one transition per line with the event after a colon: Idle --> Processing : submit
+- State IDs cannot contain spaces; give long names with: state "Waiting for payment" as Waiting
+- Nest states in a block with its own [*] start, opening and closing the braces on their own lines:
+  state Processing {
+    [*] --> Validating
+    Validating --> Charging : valid
+  }
+- Branch with a choice state: state check <<choice>>, then check --> Approved : valid
+- Add notes with: note right of Idle : waiting for input
+- Do not use "end" as a state ID; it is a reserved word`
+
+// diagramKinds reports whether the selected documentation types call for
+// class and state diagrams
+func (a *MermaidDocumenterAgent) diagramKinds() (class, state bool) {
+	for _, docType := range a.Config.DocumentationTypes {
+		class = class || classDiagramDocTypes[docType]
+		state = state || stateDiagramDocTypes[docType]
+	}
+	return class, state
+}
+
+// diagramGuidance returns syntax guidance for the class and state diagrams the
+// selected documentation types call for
+func (a *MermaidDocumenterAgent) diagramGuidance() string {
+	class, state := a.diagramKinds()
+
+	var guidance string
+	if class {
+		guidance += classDiagramInstructions
+	}
+	if state {
+		guidance += stateDiagramInstructions
+	}
+	return guidance
+}
@@ -0,0 +1,103 @@
+package agent
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+var (
+	parentheticalPattern = regexp.MustCompile(`\([^)]*\)`)
+	nonSlugPattern       = regexp.MustCompile(`[^a-z0-9]+`)
+)
+
+// DocTypeDir returns the output subdirectory for a documentation type, e.g.
+// "User Flow Diagrams" -> "user-flow" and "Data Models (ER Diagrams)" -> "data-models"
+func DocTypeDir(docType string) string {
+	name := strings.ToLower(parentheticalPattern.ReplaceAllString(docType, ""))
+	name = strings.TrimSpace(name)
+	for _, suffix := range []string{" diagrams", " diagram"} {
+		name = strings.TrimSuffix(name, suffix)
+	}
+	return strings.Trim(nonSlugPattern.ReplaceAllString(name, "-"), "-")
+}
+
+// docTypeFor returns the selected documentation type named by a tool call's
+// docType argument, matching either the full name or its directory name
+func (a *MermaidDocumenterAgent) docTypeFor(args map[string]interface{}) string {
+	requested, ok := args["docType"].(string)
+	if !ok || strings.TrimSpace(requested) == "" {
+		return ""
+	}
+	requested = strings.TrimSpace(requested)
+	for _, docType := range a.Config.DocumentationTypes {
+		if strings.EqualFold(docType, requested) || DocTypeDir(docType) == DocTypeDir(requested) {
+			return docType
+		}
+	}
+	return ""
+}
+
+// placeInDocTypeDir prefixes a relative path with the documentation type's
+// subdirectory unless the model already included it
+func placeInDocTypeDir(path, docType string) string {
+	dir := DocTypeDir(docType)
+	if docType == "" || dir == "" {
+		return path
+	}
+	first := strings.SplitN(filepath.ToSlash(filepath.Clean(path)), "/", 2)[0]
+	if first == dir {
+		return path
+	}
+	return filepath.Join(dir, path)
+}
+
+// recordArtifactType remembers which documentation type produced a file
+func (a *MermaidDocumenterAgent) recordArtifactType(path, docType string) {
+	if docType == "" {
+		return
+	}
+	if a.artifactTypes == nil {
+		a.artifactTypes = make(map[string]string)
+	}
+	a.artifactTypes[filepath.Clean(expandHome(path))] = docType
+}
+
+// artifactType returns the documentation type recorded for a manifest key
+func (a *MermaidDocumenterAgent) artifactType(artifact string) string {
+	return a.artifactTypes[filepath.Clean(a.manifestPath(artifact))]
+}
+
+// manifestPath resolves a manifest key against the output directory
+func (a *MermaidDocumenterAgent) manifestPath(artifact string) string {
+	path := expandHome(artifact)
+	if !filepath.IsAbs(path) && a.Config.OutputDir != "" {
+		path = filepath.Join(expandHome(a.Config.OutputDir), path)
+	}
+	return path
+}
+
+// placeManifestEntries rewrites manifest keys that name a file by its bare
+// name when it was actually written to a documentation type subdirectory
+func (a *MermaidDocumenterAgent) placeManifestEntries(entries map[string]interface{}) map[string]interface{} {
+	if len(a.artifactTypes) == 0 || a.Config.OutputDir == "" {
+		return entries
+	}
+	outputDir := expandHome(a.Config.OutputDir)
+
+	placed := make(map[string]interface{}, len(entries))
+	for artifact, status := range entries {
+		key := artifact
+		if _, typed := a.artifactTypes[filepath.Clean(a.manifestPath(artifact))]; !typed && !filepath.IsAbs(artifact) {
+			for path := range a.artifactTypes {
+				rel, err := filepath.Rel(outputDir, path)
+				if err == nil && filepath.Base(rel) == filepath.Base(artifact) && filepath.Dir(rel) == DocTypeDir(a.artifactTypes[path]) {
+					key = filepath.ToSlash(rel)
+					break
+				}
+			}
+		}
+		placed[key] = status
+	}
+	return placed
+}
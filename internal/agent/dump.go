@@ -0,0 +1,44 @@
+package agent
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/landanqrew/mermaid-agent-documenter/internal/console"
+)
+
+// ResponseDumpDir is where DumpResponses writes a run's raw responses
+func ResponseDumpDir(logsDir, runID string) string {
+	return filepath.Join(logsDir, runID)
+}
+
+// dumpResponse writes a model response exactly as the provider returned it,
+// before any cleaning or parsing, to <LogsDir>/<run-id>/step-N.txt. Retries
+// within the same step go to step-N-2.txt, step-N-3.txt, and so on. Failures
+// only print a warning: the dump is a debugging aid and never stops a run.
+func (a *MermaidDocumenterAgent) dumpResponse(response string) {
+	if !a.Config.DumpResponses || a.Config.LogsDir == "" {
+		return
+	}
+	dir := ResponseDumpDir(a.Config.LogsDir, a.RunID)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		console.Printf("⚠️  Could not save the raw response: %v\n", err)
+		return
+	}
+	path := dumpPath(dir, a.StepCount+1)
+	if err := os.WriteFile(path, []byte(response), 0644); err != nil {
+		console.Printf("⚠️  Could not save the raw response: %v\n", err)
+	}
+}
+
+// dumpPath returns the first unused step file name for step in dir
+func dumpPath(dir string, step int) string {
+	path := filepath.Join(dir, fmt.Sprintf("step-%d.txt", step))
+	for attempt := 2; ; attempt++ {
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			return path
+		}
+		path = filepath.Join(dir, fmt.Sprintf("step-%d-%d.txt", step, attempt))
+	}
+}
@@ -0,0 +1,51 @@
+package agent
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRun_DumpResponses(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	logsDir := t.TempDir()
+	fenced := "```json\n" + testFinalResponse + "\n```"
+	a, _ := newTestAgent(&AgentConfig{
+		MaxSteps:            5,
+		ConfidenceThreshold: 0.9,
+		LogsDir:             logsDir,
+		DumpResponses:       true,
+	}, "not json at all", fenced)
+
+	if _, err := a.Run(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	dir := ResponseDumpDir(logsDir, a.RunID)
+	for name, want := range map[string]string{
+		"step-1.txt":   "not json at all",
+		"step-1-2.txt": fenced,
+	} {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("Expected %s to be written: %v", name, err)
+		}
+		if string(data) != want {
+			t.Errorf("Expected %s to hold the raw response %q, got %q", name, want, data)
+		}
+	}
+}
+
+func TestRun_DumpResponsesOffByDefault(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	logsDir := t.TempDir()
+	a, _ := newTestAgent(&AgentConfig{MaxSteps: 5, ConfidenceThreshold: 0.9, LogsDir: logsDir}, testFinalResponse)
+
+	if _, err := a.Run(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := os.Stat(ResponseDumpDir(logsDir, a.RunID)); !os.IsNotExist(err) {
+		t.Errorf("Expected no response dump directory, got %v", err)
+	}
+}
@@ -0,0 +1,50 @@
+package agent
+
+import (
+	"time"
+
+	"github.com/landanqrew/mermaid-agent-documenter/internal/console"
+)
+
+// maxEmptyRetries is how many times a step is retried after the provider
+// returned no content before the run fails
+const maxEmptyRetries = 2
+
+// emptyResponsePrompt nudges the model after an empty response was retried once
+const emptyResponsePrompt = "Your last response was empty, so it could not be used. " +
+	"Respond again with the next step as a single JSON object in the required format."
+
+// handleEmptyResponse retries a step whose response had no content (see
+// providers.ErrEmptyResponse), which is usually a transient provider or
+// safety-filter hiccup. The first retry repeats the request; later ones add a
+// nudge to the conversation. It logs the occurrence and reports false once the
+// retries are used up.
+func (a *MermaidDocumenterAgent) handleEmptyResponse(conversation *[]map[string]interface{}, err error) bool {
+	entry := map[string]interface{}{
+		"timestamp":   time.Now().Format(time.RFC3339),
+		"run_id":      a.RunID,
+		"step":        a.StepCount + 1,
+		"provider":    a.Config.Provider,
+		"model":       a.Config.Model,
+		"output_type": "empty_response",
+		"error":       err.Error(),
+	}
+	defer a.appendLogEntry(entry)
+
+	if a.emptyRetries >= maxEmptyRetries {
+		console.Printf("❌ The model returned no content %d times in a row\n", a.emptyRetries+1)
+		entry["action"] = "give_up"
+		return false
+	}
+	a.emptyRetries++
+	entry["action"] = "retry"
+	if a.emptyRetries > 1 {
+		entry["action"] = "retry_with_nudge"
+		*conversation = append(*conversation, map[string]interface{}{
+			"role":    "user",
+			"content": emptyResponsePrompt,
+		})
+	}
+	console.Printf("⚠️  The model returned no content, retrying the step (%d/%d)\n", a.emptyRetries, maxEmptyRetries)
+	return true
+}
@@ -0,0 +1,67 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/landanqrew/mermaid-agent-documenter/internal/providers"
+)
+
+// emptyProvider returns no content for its first empty calls
+type emptyProvider struct {
+	scriptedProvider
+	empty   int
+	prompts []string
+}
+
+func (p *emptyProvider) GenerateContent(ctx context.Context, prompt string, model string, apiKey string) (string, error) {
+	p.prompts = append(p.prompts, prompt)
+	if len(p.prompts) <= p.empty {
+		return "", providers.ErrEmptyResponse
+	}
+	return p.scriptedProvider.GenerateContent(ctx, prompt, model, apiKey)
+}
+
+func TestRun_RetriesEmptyResponses(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	provider := &emptyProvider{scriptedProvider: scriptedProvider{responses: []string{testFinalResponse}}, empty: 2}
+	a, _ := newTestAgent(&AgentConfig{MaxSteps: 5, ConfidenceThreshold: 0.9})
+	a.Provider = provider
+
+	result, err := a.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.TerminationReason != TerminationCompleted {
+		t.Errorf("Expected termination %q, got %q", TerminationCompleted, result.TerminationReason)
+	}
+	if len(provider.prompts) != 3 {
+		t.Fatalf("Expected 3 calls, got %d", len(provider.prompts))
+	}
+	if strings.Contains(provider.prompts[1], "last response was empty") {
+		t.Error("Expected the first retry to repeat the request unchanged")
+	}
+	if !strings.Contains(provider.prompts[2], "last response was empty") {
+		t.Error("Expected the second retry to nudge the model")
+	}
+}
+
+func TestRun_FailsAfterRepeatedEmptyResponses(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	provider := &emptyProvider{empty: maxEmptyRetries + 1}
+	a, _ := newTestAgent(&AgentConfig{MaxSteps: 5, ConfidenceThreshold: 0.9})
+	a.Provider = provider
+
+	result, err := a.Run(context.Background())
+	if !errors.Is(err, providers.ErrEmptyResponse) {
+		t.Fatalf("Expected an empty response error, got %v", err)
+	}
+	if result.TerminationReason != TerminationError {
+		t.Errorf("Expected termination %q, got %q", TerminationError, result.TerminationReason)
+	}
+	if len(provider.prompts) != maxEmptyRetries+1 {
+		t.Errorf("Expected %d calls, got %d", maxEmptyRetries+1, len(provider.prompts))
+	}
+}
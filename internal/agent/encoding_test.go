@@ -0,0 +1,35 @@
+package agent
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+// mojibakeSequences are what ✅, ❌ and ⚠️ become when their UTF-8 bytes are
+// decoded as Mac Roman and saved again
+var mojibakeSequences = []string{"‚úÖ", "‚ùå", "‚ö†", "Ô∏è"}
+
+func TestSourceStatusStringsAreValidUTF8(t *testing.T) {
+	files, err := filepath.Glob("*.go")
+	if err != nil || len(files) == 0 {
+		t.Fatalf("Failed to list the package sources: %v", err)
+	}
+
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", file, err)
+		}
+		if !utf8.Valid(data) {
+			t.Errorf("%s is not valid UTF-8", file)
+		}
+		for _, sequence := range mojibakeSequences {
+			if strings.Contains(string(data), sequence) && file != "encoding_test.go" {
+				t.Errorf("%s contains the mojibake sequence %q; use the intended emoji", file, sequence)
+			}
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package agent
+
+import (
+	"github.com/landanqrew/mermaid-agent-documenter/internal/providers"
+)
+
+// Assumptions used to project the cost of a run before it starts
+const (
+	EstimateMinSteps         = 3   // write documentation, render it, final manifest
+	EstimateTypicalSteps     = 8   // a few extra steps to fix tool or syntax errors
+	estimateResponseTokens   = 800 // tool calls carry the generated Markdown
+	estimateToolResultTokens = 150
+)
+
+// Estimate is a projection of a run's token usage and cost
+type Estimate struct {
+	PromptTokens int // system prompt and transcript sent on the first call
+	MinSteps     int
+	MaxSteps     int
+	MinTokens    int
+	MaxTokens    int
+	MinCostUsd   float64
+	MaxCostUsd   float64
+	Priced       bool // false when the model has no pricing entry
+}
+
+// Estimate projects token usage and cost for running the agent on its
+// transcript. Every step resends the whole conversation, which grows by one
+// response and one tool result per step.
+func (a *MermaidDocumenterAgent) Estimate() Estimate {
+	initial := providers.EstimateTokens(a.buildSystemPrompt()) + providers.EstimateTokens(a.buildUserMessage())
+
+	minSteps, maxSteps := EstimateMinSteps, EstimateTypicalSteps
+	if a.Config.SkipImages {
+		minSteps-- // no render step
+	}
+	if a.Config.MaxSteps > 0 {
+		if maxSteps > a.Config.MaxSteps {
+			maxSteps = a.Config.MaxSteps
+		}
+		if minSteps > maxSteps {
+			minSteps = maxSteps
+		}
+	}
+
+	_, priced := providers.LookupPricing(a.Config.Model)
+	minPrompt, minCompletion := projectTokens(initial, minSteps)
+	maxPrompt, maxCompletion := projectTokens(initial, maxSteps)
+
+	return Estimate{
+		PromptTokens: initial,
+		MinSteps:     minSteps,
+		MaxSteps:     maxSteps,
+		MinTokens:    minPrompt + minCompletion,
+		MaxTokens:    maxPrompt + maxCompletion,
+		MinCostUsd:   providers.EstimateCost(a.Config.Model, minPrompt, minCompletion),
+		MaxCostUsd:   providers.EstimateCost(a.Config.Model, maxPrompt, maxCompletion),
+		Priced:       priced,
+	}
+}
+
+// projectTokens returns total prompt and completion tokens for a run of the
+// given number of steps starting from an initial prompt size
+func projectTokens(initial, steps int) (int, int) {
+	growth := estimateResponseTokens + estimateToolResultTokens
+	prompt := steps*initial + growth*steps*(steps-1)/2
+	return prompt, steps * estimateResponseTokens
+}
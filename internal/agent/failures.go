@@ -0,0 +1,42 @@
+package agent
+
+import (
+	"time"
+
+	"github.com/landanqrew/mermaid-agent-documenter/internal/console"
+)
+
+// recordToolFailure counts a failed tool call, prints and logs why it failed,
+// and reports whether the run should stop because the consecutive-failure
+// limit was reached. With KeepGoing the limit is ignored and the run
+// continues until MaxSteps.
+func (a *MermaidDocumenterAgent) recordToolFailure(tool, reason string) bool {
+	a.consecutiveFails++
+	limit := a.maxConsecutiveFailures()
+
+	entry := map[string]interface{}{
+		"timestamp":            time.Now().Format(time.RFC3339),
+		"run_id":               a.RunID,
+		"step":                 a.StepCount + 1,
+		"provider":             a.Config.Provider,
+		"model":                a.Config.Model,
+		"output_type":          "tool_failure",
+		"tool":                 tool,
+		"error":                reason,
+		"consecutive_failures": a.consecutiveFails,
+	}
+	defer a.appendLogEntry(entry)
+
+	if a.Config.KeepGoing {
+		console.Printf("❌ %s failed (%d in a row, --keep-going): %s\n", tool, a.consecutiveFails, reason)
+		entry["action"] = "keep_going"
+		return false
+	}
+	console.Printf("❌ %s failed (%d/%d in a row): %s\n", tool, a.consecutiveFails, limit, reason)
+	if a.consecutiveFails >= limit {
+		entry["action"] = "stop"
+		return true
+	}
+	entry["action"] = "retry"
+	return false
+}
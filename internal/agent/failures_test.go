@@ -0,0 +1,43 @@
+package agent
+
+import (
+	"context"
+	"testing"
+)
+
+// testFailingResponse calls a tool with a file that does not exist
+const testFailingResponse = `{"type":"tool_call","tool":"readFileContents","args":{"path":"missing.md"},"confidence":0.95,"rationale":"read"}`
+
+func TestRun_ConsecutiveFailuresStopRun(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	a, provider := newTestAgent(&AgentConfig{MaxSteps: 10, ConfidenceThreshold: 0.9, MaxConsecutiveFailures: 2},
+		testFailingResponse, testFailingResponse, testFinalResponse)
+
+	result, err := a.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.TerminationReason != TerminationConsecutiveFailures {
+		t.Errorf("Expected termination %q, got %q", TerminationConsecutiveFailures, result.TerminationReason)
+	}
+	if provider.calls != 2 {
+		t.Errorf("Expected the run to stop after 2 calls, got %d", provider.calls)
+	}
+}
+
+func TestRun_KeepGoingIgnoresFailureLimit(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	a, provider := newTestAgent(&AgentConfig{MaxSteps: 10, ConfidenceThreshold: 0.9, MaxConsecutiveFailures: 2, KeepGoing: true},
+		testFailingResponse, testFailingResponse, testFailingResponse, testFinalResponse)
+
+	result, err := a.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.TerminationReason != TerminationCompleted {
+		t.Errorf("Expected termination %q, got %q", TerminationCompleted, result.TerminationReason)
+	}
+	if provider.calls != 4 {
+		t.Errorf("Expected the run to continue past the failures, got %d calls", provider.calls)
+	}
+}
@@ -0,0 +1,191 @@
+package agent
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+	"unicode"
+
+	"github.com/landanqrew/mermaid-agent-documenter/internal/console"
+)
+
+// defaultFileName is the base name used in the prompt when nothing better is known
+const defaultFileName = "summary"
+
+// FileNameData holds the variables available to a file name template
+type FileNameData struct {
+	Type       string // selected documentation types joined with "_", or "summary"
+	Transcript string // transcript file name without extension
+	Date       string // run date, 2006-01-02
+	Time       string // run time, 150405
+	Provider   string
+	Model      string
+	RunID      string // first 8 characters of the run ID
+}
+
+// FileNameVariables lists the template variables, for help text and errors
+var FileNameVariables = []string{".Type", ".Transcript", ".Date", ".Time", ".Provider", ".Model", ".RunID"}
+
+// ParseFileNameTemplate checks that a file name template is valid and only
+// uses the available variables
+func ParseFileNameTemplate(text string) (*template.Template, error) {
+	tmpl, err := template.New("fileName").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid file name template: %w", err)
+	}
+	if err := tmpl.Execute(&strings.Builder{}, FileNameData{}); err != nil {
+		return nil, fmt.Errorf("invalid file name template (available variables: %s): %w", strings.Join(FileNameVariables, ", "), err)
+	}
+	return tmpl, nil
+}
+
+// RenderFileName executes a file name template and slugifies the result
+func RenderFileName(text string, data FileNameData) (string, error) {
+	tmpl, err := ParseFileNameTemplate(text)
+	if err != nil {
+		return "", err
+	}
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, data); err != nil {
+		return "", fmt.Errorf("failed to render file name: %w", err)
+	}
+	return Slugify(sb.String()), nil
+}
+
+// Slugify lowercases a name and replaces every run of characters other than
+// letters, digits, "-" and "_" with a single "-". An empty result becomes "summary".
+func Slugify(name string) string {
+	var sb strings.Builder
+	pendingDash := false
+	for _, r := range strings.ToLower(name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_' {
+			if pendingDash && sb.Len() > 0 {
+				sb.WriteRune('-')
+			}
+			pendingDash = false
+			sb.WriteRune(r)
+			continue
+		}
+		pendingDash = true
+	}
+	slug := strings.Trim(sb.String(), "-_")
+	if slug == "" {
+		return defaultFileName
+	}
+	return slug
+}
+
+// fileNameData gathers the template variables for this run
+func (a *MermaidDocumenterAgent) fileNameData(now time.Time) FileNameData {
+	docType := defaultFileName
+	if len(a.Config.DocumentationTypes) > 0 {
+		docType = strings.Join(a.Config.DocumentationTypes, "_")
+	}
+	runID := a.RunID
+	if len(runID) > 8 {
+		runID = runID[:8]
+	}
+	return FileNameData{
+		Type:       docType,
+		Transcript: a.Config.TranscriptName,
+		Date:       now.Format("2006-01-02"),
+		Time:       now.Format("150405"),
+		Provider:   a.Config.Provider,
+		Model:      a.Config.Model,
+		RunID:      runID,
+	}
+}
+
+// outputFileName returns the base name output files are given, or "" when no
+// FileNameTemplate is configured. It is rendered once, so the prompt and the
+// files written later agree even when the template uses the time.
+func (a *MermaidDocumenterAgent) outputFileName() string {
+	if a.Config.FileNameTemplate == "" {
+		return ""
+	}
+	if a.fileName == "" {
+		name, err := RenderFileName(a.Config.FileNameTemplate, a.fileNameData(time.Now()))
+		if err != nil {
+			console.Printf("⚠️  Ignoring fileNameTemplate: %v\n", err)
+			return ""
+		}
+		a.fileName = name
+	}
+	return a.fileName
+}
+
+// fileNameArgs are the path arguments renamed by FileNameTemplate, per tool
+var fileNameArgs = map[string][]string{
+	"writeFileContents":    {"path"},
+	"writeMermaidDiagram":  {"path"},
+	"generateMermaidImage": {"inputFile", "outputFile"},
+}
+
+// applyFileName rewrites the file paths of a write or render call to follow
+// FileNameTemplate, so the files are named by the template even when the
+// model ignores the naming instructions
+func (a *MermaidDocumenterAgent) applyFileName(toolName string, args map[string]interface{}) {
+	name := a.outputFileName()
+	if name == "" {
+		return
+	}
+	for _, argName := range fileNameArgs[toolName] {
+		if path, ok := args[argName].(string); ok && path != "" {
+			args[argName] = templatedPath(path, name)
+		}
+	}
+}
+
+// renameManifestEntries renames the final manifest's files the same way
+// applyFileName renamed them when they were written
+func (a *MermaidDocumenterAgent) renameManifestEntries(manifest map[string]interface{}) map[string]interface{} {
+	name := a.outputFileName()
+	if name == "" {
+		return manifest
+	}
+	renamed := make(map[string]interface{}, len(manifest))
+	for entry, status := range manifest {
+		renamed[templatedPath(entry, name)] = status
+	}
+	return renamed
+}
+
+// templatedPath gives path's file the base name name, keeping its directory
+// and extension. summary.md becomes <name>.md, a file already named
+// <name> or <name>-<suffix> is kept, and any other file becomes
+// <name>-<old name>, so several files stay distinct.
+func templatedPath(path, name string) string {
+	dir, base := filepath.Split(path)
+	ext := filepath.Ext(base)
+	stem := strings.TrimSuffix(base, ext)
+	switch {
+	case stem == name || strings.HasPrefix(stem, name+"-"):
+		return path
+	case stem == defaultFileName:
+		stem = name
+	default:
+		stem = name + "-" + Slugify(stem)
+	}
+	return dir + stem + ext
+}
+
+// renameExampleFiles replaces the summary.md, summary.svg, and "summary"
+// output file names in the prompt's examples, leaving other text alone
+func renameExampleFiles(prompt, name string) string {
+	return strings.NewReplacer(
+		defaultFileName+".", name+".",
+		`"`+defaultFileName+`"`, `"`+name+`"`,
+	).Replace(prompt)
+}
+
+// fileNamingInstructions asks the model to keep the configured naming scheme
+// when it writes more than one file
+func fileNamingInstructions(name string) string {
+	return fmt.Sprintf(`
+
+FILE NAMING:
+- Name the documentation file %[1]s.md and its images %[1]s (generateMermaidImage adds the extension)
+- When writing several files, keep the prefix and add a short lowercase suffix: %[1]s-<topic>.md or %[1]s-<topic>.mmd`, name)
+}
@@ -0,0 +1,120 @@
+package agent
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSlugify(t *testing.T) {
+	cases := map[string]string{
+		"User Flow Diagrams_Data Models (ER Diagrams)": "user-flow-diagrams_data-models-er-diagrams",
+		"  Login / Sign-up!  ":                         "login-sign-up",
+		"Año 2025":                                     "año-2025",
+		"???":                                          "summary",
+	}
+	for input, expected := range cases {
+		if got := Slugify(input); got != expected {
+			t.Errorf("Slugify(%q) = %q, expected %q", input, got, expected)
+		}
+	}
+}
+
+func TestRenderFileName(t *testing.T) {
+	data := FileNameData{Type: "System Architecture", Date: "2025-01-02", Transcript: "Kickoff Call"}
+	name, err := RenderFileName("{{.Type}}-{{.Date}}", data)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if name != "system-architecture-2025-01-02" {
+		t.Errorf("Unexpected name %q", name)
+	}
+
+	if name, _ := RenderFileName("{{.Transcript}}", data); name != "kickoff-call" {
+		t.Errorf("Unexpected name %q", name)
+	}
+
+	_, err = RenderFileName("{{.Author}}", data)
+	if err == nil || !strings.Contains(err.Error(), ".Transcript") {
+		t.Errorf("Expected an unknown variable to list the available ones, got %v", err)
+	}
+	if _, err := ParseFileNameTemplate("{{.Type"); err == nil {
+		t.Error("Expected a syntax error")
+	}
+}
+
+func TestFileNameTemplatePrompt(t *testing.T) {
+	a := NewMermaidDocumenterAgent(&AgentConfig{
+		DocumentationTypes: []string{"User Flow Diagrams"},
+		FileNameTemplate:   "{{.Type}}-{{.Date}}",
+		SystemPromptExtra:  "Keep the summary short.",
+	})
+	name := "user-flow-diagrams-" + time.Now().Format("2006-01-02")
+
+	prompt := a.buildSystemPrompt()
+	for _, expected := range []string{name + ".md", `"outputFile":"` + name + `"`, name + ".svg", "FILE NAMING"} {
+		if !strings.Contains(prompt, expected) {
+			t.Errorf("Expected the prompt to contain %q", expected)
+		}
+	}
+	if strings.Contains(prompt, "summary.md") {
+		t.Error("Expected every example file to be renamed")
+	}
+	if !strings.Contains(prompt, "Keep the summary short.") {
+		t.Error("Expected other uses of 'summary' to be left alone")
+	}
+
+	prompt = NewMermaidDocumenterAgent(&AgentConfig{DocumentationTypes: []string{"User Flow Diagrams"}}).buildSystemPrompt()
+	if strings.Contains(prompt, "FILE NAMING") || !strings.Contains(prompt, "User Flow Diagrams.md") {
+		t.Error("Expected the default naming without a template")
+	}
+}
+
+func TestTemplatedPath(t *testing.T) {
+	cases := map[string]string{
+		"/out/summary.md":        "/out/architecture.md",
+		"/out/summary":           "/out/architecture",
+		"/out/Login Flow.md":     "/out/architecture-login-flow.md",
+		"/out/architecture.svg":  "/out/architecture.svg",
+		"/out/architecture-1.md": "/out/architecture-1.md",
+		"summary.svg":            "architecture.svg",
+	}
+	for path, want := range cases {
+		if got := templatedPath(path, "architecture"); got != want {
+			t.Errorf("templatedPath(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestRun_FileNameTemplateRenamesWrites(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home) // writeFileContents only writes under ~/mermaid-agent-documenter
+	outputDir := filepath.Join(home, "mermaid-agent-documenter", "output")
+	final := `{"type":"final","manifest":{"login.md":"created"},"confidence":0.95,"rationale":"done"}`
+	a, _ := newTestAgent(&AgentConfig{
+		MaxSteps:            5,
+		ConfidenceThreshold: 0.9,
+		OutputDir:           outputDir,
+		FileNameTemplate:    "{{.Transcript}}-docs",
+		TranscriptName:      "standup",
+	}, testWriteResponse, final)
+
+	result, err := a.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	want := filepath.Join(outputDir, "standup-docs-login.md")
+	if _, err := os.Stat(want); err != nil {
+		t.Errorf("Expected the file to be written as %s: %v", want, err)
+	}
+	if _, err := os.Stat(filepath.Join(outputDir, "login.md")); err == nil {
+		t.Error("Expected the model's file name to be replaced")
+	}
+	if _, ok := result.Manifest["standup-docs-login.md"]; !ok {
+		t.Errorf("Expected the manifest to use the templated name, got %v", result.Manifest)
+	}
+}
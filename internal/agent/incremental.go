@@ -0,0 +1,303 @@
+package agent
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/landanqrew/mermaid-agent-documenter/internal/console"
+	"github.com/landanqrew/mermaid-agent-documenter/internal/tools"
+)
+
+// HashManifestFile is the file in the output directory that records content
+// hashes used to skip unchanged work on re-runs
+const HashManifestFile = ".mad-manifest.json"
+
+// HashManifest records what was generated from which inputs
+type HashManifest struct {
+	// Sections maps a transcript section ID to the hash of its inputs and the
+	// artifacts generated from it
+	Sections map[string]SectionRecord `json:"sections"`
+	// Renders maps a diagram source file to the image rendered from it
+	Renders map[string]RenderRecord `json:"renders"`
+}
+
+// SectionRecord describes the output of one transcript section
+type SectionRecord struct {
+	InputHash   string            `json:"inputHash"`
+	Artifacts   map[string]string `json:"artifacts"` // path -> content hash
+	GeneratedAt time.Time         `json:"generatedAt"`
+}
+
+// RenderRecord describes an image rendered from a diagram source file
+type RenderRecord struct {
+	InputHash  string `json:"inputHash"`
+	OutputFile string `json:"outputFile"`
+	OutputHash string `json:"outputHash"`
+	Format     string `json:"format"`
+}
+
+// hashBytes returns the hex SHA-256 of data
+func hashBytes(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// hashFile returns the hex SHA-256 of a file's contents
+func hashFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return hashBytes(data), nil
+}
+
+// expandHome replaces a leading ~ with the user's home directory
+func expandHome(path string) string {
+	if !strings.HasPrefix(path, "~") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return strings.Replace(path, "~", home, 1)
+}
+
+// hashManifestPath returns where the hash manifest lives, or "" without an output directory
+func (a *MermaidDocumenterAgent) hashManifestPath() string {
+	if a.Config.OutputDir == "" {
+		return ""
+	}
+	return filepath.Join(expandHome(a.Config.OutputDir), HashManifestFile)
+}
+
+// loadHashManifest reads the hash manifest, returning an empty one if it does not exist
+func (a *MermaidDocumenterAgent) loadHashManifest() *HashManifest {
+	manifest := &HashManifest{
+		Sections: map[string]SectionRecord{},
+		Renders:  map[string]RenderRecord{},
+	}
+
+	path := a.hashManifestPath()
+	if path == "" {
+		return manifest
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return manifest
+	}
+	if err := json.Unmarshal(data, manifest); err != nil {
+		console.Printf("⚠️  Ignoring unreadable %s: %v\n", HashManifestFile, err)
+		return &HashManifest{Sections: map[string]SectionRecord{}, Renders: map[string]RenderRecord{}}
+	}
+	if manifest.Sections == nil {
+		manifest.Sections = map[string]SectionRecord{}
+	}
+	if manifest.Renders == nil {
+		manifest.Renders = map[string]RenderRecord{}
+	}
+	return manifest
+}
+
+// saveHashManifest writes the hash manifest to the output directory
+func (a *MermaidDocumenterAgent) saveHashManifest(manifest *HashManifest) {
+	path := a.hashManifestPath()
+	if path == "" {
+		return
+	}
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		console.Printf("⚠️  Failed to encode %s: %v\n", HashManifestFile, err)
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		console.Printf("⚠️  Failed to create output directory: %v\n", err)
+		return
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		console.Printf("⚠️  Failed to save %s: %v\n", HashManifestFile, err)
+	}
+}
+
+// sectionID identifies the transcript section this agent works on, or "" when
+// the run has no transcript name and cannot be tracked
+func (a *MermaidDocumenterAgent) sectionID() string {
+	if a.Config.TranscriptName == "" {
+		return ""
+	}
+	if a.chunkTotal > 1 {
+		return fmt.Sprintf("%s#%d/%d", a.Config.TranscriptName, a.chunkIndex, a.chunkTotal)
+	}
+	return a.Config.TranscriptName
+}
+
+// sectionInputHash hashes everything that shapes the generated documentation
+func (a *MermaidDocumenterAgent) sectionInputHash() string {
+	parts := []string{
+		a.Transcript,
+		a.Config.Provider,
+		a.Config.Model,
+		a.Config.OutputFormat,
+		strings.Join(a.Config.DocumentationTypes, ","),
+	}
+	// Optional settings are only included when set so existing hashes stay valid
+	if !isDefaultLanguage(a.Config.Language) {
+		parts = append(parts, a.Config.Language)
+	}
+	if extra := strings.TrimSpace(a.Config.SystemPromptExtra); extra != "" {
+		parts = append(parts, extra)
+	}
+	if a.Config.PreferSimpleDiagrams {
+		parts = append(parts, "simple-diagrams")
+	}
+	if a.Config.SkipImages {
+		parts = append(parts, "no-image")
+	}
+	if a.Config.FileNameTemplate != "" {
+		parts = append(parts, a.Config.FileNameTemplate)
+	}
+	return hashBytes([]byte(strings.Join(parts, "\x00")))
+}
+
+// unchangedArtifacts reports whether every recorded artifact still exists with the recorded content
+func unchangedArtifacts(artifacts map[string]string) bool {
+	if len(artifacts) == 0 {
+		return false
+	}
+	for path, expected := range artifacts {
+		actual, err := hashFile(path)
+		if err != nil || actual != expected {
+			return false
+		}
+	}
+	return true
+}
+
+// compareSection checks the hash manifest for an up-to-date record of this
+// section. It returns the recorded artifacts when the section can be skipped.
+func (a *MermaidDocumenterAgent) compareSection() ([]string, bool) {
+	id := a.sectionID()
+	if a.Config.Force || id == "" {
+		return nil, false
+	}
+
+	record, ok := a.loadHashManifest().Sections[id]
+	if !ok || record.InputHash != a.sectionInputHash() || !unchangedArtifacts(record.Artifacts) {
+		return nil, false
+	}
+
+	artifacts := make([]string, 0, len(record.Artifacts))
+	for path := range record.Artifacts {
+		artifacts = append(artifacts, path)
+	}
+	return artifacts, true
+}
+
+// recordSection stores the input hash and artifact hashes for this section
+func (a *MermaidDocumenterAgent) recordSection() {
+	id := a.sectionID()
+	if id == "" {
+		return
+	}
+
+	artifacts := map[string]string{}
+	for _, path := range a.result.Artifacts {
+		if hash, err := hashFile(path); err == nil {
+			artifacts[path] = hash
+		}
+	}
+
+	manifest := a.loadHashManifest()
+	manifest.Sections[id] = SectionRecord{
+		InputHash:   a.sectionInputHash(),
+		Artifacts:   artifacts,
+		GeneratedAt: time.Now(),
+	}
+	a.saveHashManifest(manifest)
+}
+
+// cachedRender returns a successful result for generateMermaidImage when the
+// diagram source is unchanged and its image is still on disk
+func (a *MermaidDocumenterAgent) cachedRender(args map[string]interface{}) (tools.ToolResult, bool) {
+	if a.Config.Force {
+		return tools.ToolResult{}, false
+	}
+
+	inputFile, ok := args["inputFile"].(string)
+	if !ok {
+		return tools.ToolResult{}, false
+	}
+	inputFile = expandHome(inputFile)
+
+	format := "svg"
+	if f, ok := args["format"].(string); ok && f != "" {
+		format = f
+	}
+
+	// A render that previously fell back is still current for the same request
+	fallback, _ := args["fallbackFormat"].(string)
+	record, ok := a.loadHashManifest().Renders[inputFile]
+	if !ok || (record.Format != format && (fallback == "" || record.Format != fallback)) {
+		return tools.ToolResult{}, false
+	}
+	inputHash, err := hashFile(inputFile)
+	if err != nil || inputHash != record.InputHash {
+		return tools.ToolResult{}, false
+	}
+	outputHash, err := hashFile(record.OutputFile)
+	if err != nil || outputHash != record.OutputHash {
+		return tools.ToolResult{}, false
+	}
+
+	return tools.ToolResult{
+		Success: true,
+		Data: map[string]interface{}{
+			"inputFile":  inputFile,
+			"outputFile": record.OutputFile,
+			"format":     record.Format,
+			"skipped":    true,
+		},
+	}, true
+}
+
+// recordRender stores the hashes of a freshly rendered image
+func (a *MermaidDocumenterAgent) recordRender(result tools.ToolResult) {
+	data, ok := result.Data.(map[string]interface{})
+	if !ok {
+		return
+	}
+	if skipped, _ := data["skipped"].(bool); skipped {
+		return
+	}
+
+	inputFile, _ := data["inputFile"].(string)
+	outputFile, _ := data["outputFile"].(string)
+	format, _ := data["format"].(string)
+	if inputFile == "" || outputFile == "" {
+		return
+	}
+
+	inputHash, err := hashFile(inputFile)
+	if err != nil {
+		return
+	}
+	outputHash, err := hashFile(outputFile)
+	if err != nil {
+		return
+	}
+
+	manifest := a.loadHashManifest()
+	manifest.Renders[inputFile] = RenderRecord{
+		InputHash:  inputHash,
+		OutputFile: outputFile,
+		OutputHash: outputHash,
+		Format:     format,
+	}
+	a.saveHashManifest(manifest)
+}
@@ -0,0 +1,100 @@
+package agent
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/landanqrew/mermaid-agent-documenter/internal/tools"
+)
+
+func newIncrementalAgent(outputDir, transcript string) *MermaidDocumenterAgent {
+	a := NewMermaidDocumenterAgent(&AgentConfig{
+		Provider:       "openai",
+		Model:          "gpt-4o",
+		OutputDir:      outputDir,
+		TranscriptName: "meeting",
+	})
+	a.SetTranscript(transcript)
+	a.result = &RunResult{}
+	return a
+}
+
+func TestCompareSection(t *testing.T) {
+	dir := t.TempDir()
+	artifact := filepath.Join(dir, "meeting_summary.md")
+	if err := os.WriteFile(artifact, []byte("# Summary"), 0644); err != nil {
+		t.Fatalf("Failed to write artifact: %v", err)
+	}
+
+	first := newIncrementalAgent(dir, "we discussed login")
+	first.result.addArtifact(artifact)
+	first.recordSection()
+
+	if _, err := os.Stat(filepath.Join(dir, HashManifestFile)); err != nil {
+		t.Fatalf("Expected hash manifest to be written: %v", err)
+	}
+
+	artifacts, unchanged := newIncrementalAgent(dir, "we discussed login").compareSection()
+	if !unchanged || len(artifacts) != 1 || artifacts[0] != artifact {
+		t.Errorf("Expected unchanged section with recorded artifact, got %v, %v", artifacts, unchanged)
+	}
+
+	if _, unchanged := newIncrementalAgent(dir, "we discussed logout").compareSection(); unchanged {
+		t.Error("Expected a changed transcript to be regenerated")
+	}
+
+	forced := newIncrementalAgent(dir, "we discussed login")
+	forced.Config.Force = true
+	if _, unchanged := forced.compareSection(); unchanged {
+		t.Error("Expected --force to bypass the comparison")
+	}
+
+	if err := os.WriteFile(artifact, []byte("# Edited by hand"), 0644); err != nil {
+		t.Fatalf("Failed to modify artifact: %v", err)
+	}
+	if _, unchanged := newIncrementalAgent(dir, "we discussed login").compareSection(); unchanged {
+		t.Error("Expected a modified artifact to be regenerated")
+	}
+}
+
+func TestCachedRender(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "flow.md")
+	image := filepath.Join(dir, "flow.svg")
+	for path, content := range map[string]string{input: "```mermaid\ngraph TD\n```", image: "<svg></svg>"} {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", path, err)
+		}
+	}
+
+	a := newIncrementalAgent(dir, "transcript")
+	args := map[string]interface{}{"inputFile": input, "format": "svg"}
+	if _, ok := a.cachedRender(args); ok {
+		t.Fatal("Expected no cached render before recording one")
+	}
+
+	a.recordRender(toolResultFor(input, image))
+	result, ok := a.cachedRender(args)
+	if !ok || !result.Success {
+		t.Fatal("Expected cached render for unchanged source")
+	}
+
+	if err := os.WriteFile(input, []byte("```mermaid\ngraph LR\n```"), 0644); err != nil {
+		t.Fatalf("Failed to modify input: %v", err)
+	}
+	if _, ok := a.cachedRender(args); ok {
+		t.Error("Expected a changed diagram source to be re-rendered")
+	}
+}
+
+func toolResultFor(input, output string) tools.ToolResult {
+	return tools.ToolResult{
+		Success: true,
+		Data: map[string]interface{}{
+			"inputFile":  input,
+			"outputFile": output,
+			"format":     "svg",
+		},
+	}
+}
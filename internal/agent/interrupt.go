@@ -0,0 +1,33 @@
+package agent
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// writePartialManifest records the artifacts written before the run was
+// interrupted in manifest.json and index.md. The section hash is not
+// recorded, so the next run regenerates the documentation in full.
+func (a *MermaidDocumenterAgent) writePartialManifest() {
+	if a.result == nil || len(a.result.Artifacts) == 0 {
+		return
+	}
+
+	outputDir := expandHome(a.Config.OutputDir)
+	entries := make(map[string]interface{}, len(a.result.Artifacts))
+	for _, artifact := range a.result.Artifacts {
+		key := expandHome(artifact)
+		if rel, err := filepath.Rel(outputDir, key); err == nil && !strings.HasPrefix(rel, "..") {
+			key = filepath.ToSlash(rel)
+		}
+		status := "created"
+		if imageExtensions[strings.ToLower(filepath.Ext(artifact))] {
+			status = "generated"
+		}
+		entries[key] = status
+	}
+
+	a.result.Manifest = entries
+	a.writeManifest(entries)
+	a.writeIndex()
+}
@@ -0,0 +1,74 @@
+package agent
+
+import (
+	"context"
+	"errors"
+
+	"github.com/landanqrew/mermaid-agent-documenter/internal/console"
+	"github.com/landanqrew/mermaid-agent-documenter/internal/providers"
+)
+
+// structuredOutputSchema is the JSON Schema for StructuredOutput sent to
+// providers with a native JSON mode. args and manifest stay free-form because
+// their shape depends on the tool and the documents written.
+func structuredOutputSchema() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"type": map[string]interface{}{
+				"type": "string",
+				"enum": []string{
+					string(OutputTypeToolCall),
+					string(OutputTypeFinal),
+					string(OutputTypeClarification),
+					string(OutputTypePlan),
+				},
+			},
+			"tool":     map[string]interface{}{"type": "string"},
+			"args":     map[string]interface{}{"type": "object"},
+			"manifest": map[string]interface{}{"type": "object"},
+			"questions": map[string]interface{}{
+				"type":  "array",
+				"items": map[string]interface{}{"type": "string"},
+			},
+			"plan": map[string]interface{}{
+				"type": "array",
+				"items": map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"file":        map[string]interface{}{"type": "string"},
+						"diagramType": map[string]interface{}{"type": "string"},
+						"description": map[string]interface{}{"type": "string"},
+					},
+					"required": []string{"file", "diagramType"},
+				},
+			},
+			"confidence": map[string]interface{}{"type": "number", "minimum": 0, "maximum": 1},
+			"rationale":  map[string]interface{}{"type": "string"},
+		},
+		"required": []string{"type", "confidence", "rationale"},
+	}
+}
+
+// responseContext returns ctx with the StructuredOutput schema attached when
+// NativeJSON is set, so providers with a JSON mode (OpenAI, Gemini) return
+// JSON. Responses are still parsed and repaired as text, so providers without
+// one (Anthropic) work as before.
+func (a *MermaidDocumenterAgent) responseContext(ctx context.Context) context.Context {
+	if !a.Config.NativeJSON || a.jsonModeRejected {
+		return ctx
+	}
+	return providers.WithResponseSchema(ctx, structuredOutputSchema())
+}
+
+// rejectJSONMode reports whether err is the provider refusing a JSON mode
+// request. JSON mode is then turned off for the rest of the run, so the
+// request can be retried as text.
+func (a *MermaidDocumenterAgent) rejectJSONMode(err error) bool {
+	if !a.Config.NativeJSON || a.jsonModeRejected || !errors.Is(err, providers.ErrBadRequest) {
+		return false
+	}
+	a.jsonModeRejected = true
+	console.Printf("⚠️  %s rejected the JSON response mode; continuing with text responses (%v)\n", a.Config.Provider, err)
+	return true
+}
@@ -0,0 +1,76 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/landanqrew/mermaid-agent-documenter/internal/providers"
+)
+
+// jsonProvider records the response schema of each request, and rejects
+// requests with a schema when rejectJSON is set
+type jsonProvider struct {
+	scriptedProvider
+	schemas    []map[string]interface{}
+	rejectJSON bool
+}
+
+func (p *jsonProvider) GenerateContent(ctx context.Context, prompt string, model string, apiKey string) (string, error) {
+	schema := providers.ResponseSchema(ctx)
+	p.schemas = append(p.schemas, schema)
+	if schema != nil && p.rejectJSON {
+		return "", fmt.Errorf("%w: API error: 400 Bad Request, body: response schema not supported", providers.ErrBadRequest)
+	}
+	return p.scriptedProvider.GenerateContent(ctx, prompt, model, apiKey)
+}
+
+func TestRun_EnablesNativeJSONMode(t *testing.T) {
+	for _, enabled := range []bool{true, false} {
+		provider := &jsonProvider{scriptedProvider: scriptedProvider{responses: []string{testPlanResponse}}}
+		a := NewMermaidDocumenterAgent(&AgentConfig{
+			MaxSteps:            5,
+			ConfidenceThreshold: 0.9,
+			PlanOnly:            true,
+			NativeJSON:          enabled,
+		})
+		a.Provider = provider
+		a.SetTranscript("User logs in with email and password.")
+
+		if _, err := a.Run(context.Background()); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if got := len(provider.schemas) == 1 && provider.schemas[0] != nil; got != enabled {
+			t.Errorf("NativeJSON %v: expected schema sent %v, got %v", enabled, enabled, got)
+		}
+	}
+}
+
+func TestRun_FallsBackToTextWhenJSONModeRejected(t *testing.T) {
+	provider := &jsonProvider{scriptedProvider: scriptedProvider{responses: []string{testFailingResponse, testFinalResponse}}, rejectJSON: true}
+	a := NewMermaidDocumenterAgent(&AgentConfig{MaxSteps: 5, ConfidenceThreshold: 0.9, NativeJSON: true})
+	a.Provider = provider
+	a.SetTranscript("User logs in with email and password.")
+
+	result, err := a.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Expected the run to fall back to text mode, got %v", err)
+	}
+	if result.TerminationReason != TerminationCompleted {
+		t.Errorf("Expected a completed run, got %q", result.TerminationReason)
+	}
+	// One rejected JSON request, then every request in text mode
+	if len(provider.schemas) != 3 || provider.schemas[0] == nil || provider.schemas[1] != nil || provider.schemas[2] != nil {
+		t.Errorf("Expected one JSON request and then text requests, got %v", provider.schemas)
+	}
+}
+
+func TestStructuredOutputSchema_CoversOutputFields(t *testing.T) {
+	schema := structuredOutputSchema()
+	properties := schema["properties"].(map[string]interface{})
+	for _, field := range []string{"type", "tool", "args", "manifest", "questions", "plan", "confidence", "rationale"} {
+		if _, ok := properties[field]; !ok {
+			t.Errorf("Expected schema property %q", field)
+		}
+	}
+}
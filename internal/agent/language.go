@@ -0,0 +1,79 @@
+package agent
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// DefaultLanguage is the language documentation is written in unless configured otherwise
+const DefaultLanguage = "en"
+
+// languageNames maps common language codes to the names used in the system prompt
+var languageNames = map[string]string{
+	"en": "English",
+	"es": "Spanish",
+	"fr": "French",
+	"de": "German",
+	"it": "Italian",
+	"pt": "Portuguese",
+	"nl": "Dutch",
+	"pl": "Polish",
+	"sv": "Swedish",
+	"ja": "Japanese",
+	"ko": "Korean",
+	"zh": "Chinese",
+	"hi": "Hindi",
+	"ar": "Arabic",
+	"ru": "Russian",
+	"tr": "Turkish",
+}
+
+var languageTagPattern = regexp.MustCompile(`^[a-z]{2,3}(-[a-z0-9]{2,8})*$`)
+
+// ParseLanguage normalizes a language code (es, pt-BR) or English name (Spanish)
+// to a lower-case code
+func ParseLanguage(value string) (string, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	for code, name := range languageNames {
+		if value == strings.ToLower(name) {
+			return code, nil
+		}
+	}
+	if !languageTagPattern.MatchString(value) {
+		return "", fmt.Errorf("invalid language %q (use a code such as es, fr, pt-br, or a name such as Spanish)", value)
+	}
+	return value, nil
+}
+
+// LanguageName returns the display name for a language code, falling back to the code
+func LanguageName(code string) string {
+	code = strings.ToLower(code)
+	if name, ok := languageNames[code]; ok {
+		return name
+	}
+	base := strings.SplitN(code, "-", 2)[0]
+	if name, ok := languageNames[base]; ok {
+		return fmt.Sprintf("%s (%s)", name, code)
+	}
+	return code
+}
+
+// isDefaultLanguage reports whether documentation should be written in English
+func isDefaultLanguage(code string) bool {
+	return code == "" || strings.EqualFold(code, DefaultLanguage)
+}
+
+// languageInstructions tells the model to write prose and diagram labels in
+// another language while keeping Mermaid syntax valid
+func languageInstructions(code string) string {
+	name := LanguageName(code)
+	return fmt.Sprintf(`
+
+LANGUAGE:
+- Write all prose (headings, paragraphs, lists) in %[1]s
+- Translate diagram node labels, edge labels, participant aliases, and notes into %[1]s
+- Keep Mermaid keywords and syntax unchanged (graph TD, sequenceDiagram, participant, erDiagram, -->, ||--o{, etc.)
+- Wrap translated labels that contain spaces, accents, or punctuation in double quotes, e.g. A["Inicio de sesión"]; use participant aliases (participant U as "Usuario") instead of translated identifiers
+- Keep file names, JSON keys, and tool arguments in English`, name)
+}
@@ -0,0 +1,151 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/landanqrew/mermaid-agent-documenter/internal/console"
+	"github.com/landanqrew/mermaid-agent-documenter/internal/providers"
+	"github.com/landanqrew/mermaid-agent-documenter/internal/tools"
+)
+
+// PlanItem is one file the agent intends to produce
+type PlanItem struct {
+	File        string `json:"file"`
+	DiagramType string `json:"diagramType"`
+	Description string `json:"description,omitempty"`
+}
+
+const planInstruction = `PLANNING PHASE: Do NOT call any tools yet.
+Return ONLY a plan listing, in order, every file you intend to create and the Mermaid diagram type it will contain:
+{"type":"plan","plan":[{"file":"login_flow.md","diagramType":"sequenceDiagram","description":"login request/response flow"},{"file":"login_flow.svg","diagramType":"image","description":"rendered from login_flow.md"}],"confidence":0.9,"rationale":"why this set of documents"}`
+
+const planApprovedMessage = "The plan is approved. Execute it now, one tool call at a time, and return the final manifest when every planned file exists. You MUST respond with valid JSON tool calls or final manifest."
+
+// planPhase asks the LLM for a plan, shows it to the user, and waits for
+// approval. It returns stop=true when Run should end (plan-only runs,
+// rejected plans, and errors); otherwise the approved plan is appended to
+// the conversation.
+func (a *MermaidDocumenterAgent) planPhase(ctx context.Context, conversation *[]map[string]interface{}) (bool, error) {
+	planConversation := append(append([]map[string]interface{}{}, *conversation...), map[string]interface{}{
+		"role":    "user",
+		"content": planInstruction,
+		pinKey:    pinPlan,
+	})
+
+	prompt := a.buildConversationString(planConversation)
+	response, err := a.generate(ctx, prompt)
+	for errors.Is(err, providers.ErrOutputTruncated) {
+		from, to, raised := a.raiseOutputLimit()
+		if !raised {
+			err = nil // a partial plan can still be recovered and reviewed
+			break
+		}
+		a.recordUsage(prompt, response)
+		console.Printf("✂️  Plan was truncated at %d output tokens, asking again with %d\n", from, to)
+		response, err = a.generate(ctx, prompt)
+	}
+	if err != nil {
+		if ctx.Err() != nil {
+			a.finish(a.contextTerminationReason(ctx))
+		} else {
+			a.finish(TerminationError)
+		}
+		return true, fmt.Errorf("planning LLM call failed: %w", err)
+	}
+	a.recordUsage(prompt, response)
+
+	output, err := a.parseStructuredOutput(response)
+	if err != nil {
+		a.finish(TerminationError)
+		return true, fmt.Errorf("failed to parse plan: %w", err)
+	}
+
+	// The log entry records the plan alongside the rest of the run
+	a.logInteraction(planConversation, response, output)
+
+	if output.Type != OutputTypePlan || len(output.Plan) == 0 {
+		a.finish(TerminationError)
+		return true, fmt.Errorf("expected a plan from the agent, got %q", output.Type)
+	}
+
+	a.result.Plan = output.Plan
+	a.StepCount++
+	printPlan(output.Plan)
+
+	if a.Config.PlanOnly {
+		a.finish(TerminationPlanned)
+		return true, nil
+	}
+
+	approved, err := a.approvePlan()
+	if err != nil {
+		a.finish(TerminationPlanRejected)
+		return true, err
+	}
+	if !approved {
+		a.finish(TerminationPlanRejected)
+		return true, fmt.Errorf("plan rejected")
+	}
+
+	*conversation = append(planConversation,
+		map[string]interface{}{
+			"role":    "assistant",
+			"content": response,
+			pinKey:    pinPlan,
+		},
+		map[string]interface{}{
+			"role":    "user",
+			"content": planApprovedMessage,
+			pinKey:    pinPlan,
+		},
+	)
+	return false, nil
+}
+
+// approvePlan asks the user to confirm the plan unless auto-approval is enabled
+func (a *MermaidDocumenterAgent) approvePlan() (bool, error) {
+	if a.Config.AutoApprovePlan {
+		console.Println("✅ Plan auto-approved (--yes)")
+		return true, nil
+	}
+	if a.Config.NonInteractive || !stdinIsTerminal() {
+		return false, fmt.Errorf("plan approval needs a terminal; pass --yes to auto-approve")
+	}
+
+	inputTool := tools.GetTool("getUserInput")
+	if inputTool == nil {
+		return false, fmt.Errorf("getUserInput tool is not available")
+	}
+
+	result := inputTool.Execute(map[string]interface{}{
+		"prompt": "Proceed with this plan? (y/N):",
+	})
+	if !result.Success {
+		return false, fmt.Errorf("%s", result.Error)
+	}
+
+	answer := ""
+	if data, ok := result.Data.(map[string]interface{}); ok {
+		answer, _ = data["answer"].(string)
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes", nil
+}
+
+// printPlan shows the planned files and diagram types
+func printPlan(plan []PlanItem) {
+	console.Println()
+	console.Println("🗺️  Plan")
+	console.Println("═══════")
+	for i, item := range plan {
+		console.Printf("%d. %s (%s)", i+1, item.File, item.DiagramType)
+		if item.Description != "" {
+			console.Printf(" - %s", item.Description)
+		}
+		console.Println()
+	}
+	console.Println()
+}
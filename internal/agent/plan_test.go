@@ -0,0 +1,123 @@
+package agent
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/landanqrew/mermaid-agent-documenter/internal/providers"
+)
+
+// scriptedProvider returns canned responses in order and records the prompts it was sent
+type scriptedProvider struct {
+	responses []string
+	prompts   []string
+	calls     int
+}
+
+func (p *scriptedProvider) GenerateContent(ctx context.Context, prompt string, model string, apiKey string) (string, error) {
+	p.prompts = append(p.prompts, prompt)
+	response := p.responses[p.calls]
+	p.calls++
+	return response, nil
+}
+
+func (p *scriptedProvider) ListModels(ctx context.Context, apiKey string) ([]providers.ModelInfo, error) {
+	return nil, nil
+}
+
+func (p *scriptedProvider) Ping(ctx context.Context, apiKey string) error {
+	return nil
+}
+
+const testPlanResponse = `{"type":"plan","plan":[{"file":"login.md","diagramType":"sequenceDiagram"}],"confidence":0.95,"rationale":"one flow"}`
+
+func newTestAgent(config *AgentConfig, responses ...string) (*MermaidDocumenterAgent, *scriptedProvider) {
+	provider := &scriptedProvider{responses: responses}
+	a := NewMermaidDocumenterAgent(config)
+	a.Provider = provider
+	a.SetTranscript("User logs in with email and password.")
+	return a, provider
+}
+
+func TestRun_PlanOnly(t *testing.T) {
+	a, provider := newTestAgent(&AgentConfig{
+		MaxSteps:            5,
+		ConfidenceThreshold: 0.9,
+		PlanOnly:            true,
+	}, testPlanResponse)
+
+	result, err := a.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.TerminationReason != TerminationPlanned {
+		t.Errorf("Expected termination %q, got %q", TerminationPlanned, result.TerminationReason)
+	}
+	if len(result.Plan) != 1 || result.Plan[0].File != "login.md" {
+		t.Errorf("Expected plan to be recorded, got %+v", result.Plan)
+	}
+	if provider.calls != 1 {
+		t.Errorf("Expected a single LLM call, got %d", provider.calls)
+	}
+}
+
+func TestRun_PlanAutoApproved(t *testing.T) {
+	a, provider := newTestAgent(&AgentConfig{
+		MaxSteps:            5,
+		ConfidenceThreshold: 0.9,
+		PlanFirst:           true,
+		AutoApprovePlan:     true,
+	}, testPlanResponse, `{"type":"final","manifest":{},"confidence":0.95,"rationale":"done"}`)
+
+	result, err := a.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.TerminationReason != TerminationCompleted {
+		t.Errorf("Expected termination %q, got %q", TerminationCompleted, result.TerminationReason)
+	}
+	if provider.calls != 2 {
+		t.Errorf("Expected plan and execution calls, got %d", provider.calls)
+	}
+}
+
+func TestRun_PlanRequiresApproval(t *testing.T) {
+	a, _ := newTestAgent(&AgentConfig{
+		MaxSteps:            5,
+		ConfidenceThreshold: 0.9,
+		PlanFirst:           true,
+		NonInteractive:      true,
+	}, testPlanResponse)
+
+	result, err := a.Run(context.Background())
+	if err == nil {
+		t.Fatal("Expected an error when the plan cannot be approved")
+	}
+	if result.TerminationReason != TerminationPlanRejected {
+		t.Errorf("Expected termination %q, got %q", TerminationPlanRejected, result.TerminationReason)
+	}
+}
+
+func TestInitialPrompt(t *testing.T) {
+	a, provider := newTestAgent(&AgentConfig{MaxSteps: 5, DocumentationTypes: []string{"System Architecture"}})
+
+	prompt := a.InitialPrompt()
+	if !strings.HasPrefix(prompt, "system: "+a.buildSystemPrompt()) {
+		t.Error("Expected the prompt to start with the system prompt")
+	}
+	if !strings.Contains(prompt, "user: "+a.buildUserMessage()) {
+		t.Error("Expected the prompt to contain the user message with the transcript")
+	}
+	if strings.Contains(prompt, planInstruction) {
+		t.Error("Expected no planning request without PlanFirst")
+	}
+	if provider.calls != 0 {
+		t.Errorf("Expected the provider not to be called, got %d calls", provider.calls)
+	}
+
+	a.Config.PlanFirst = true
+	if !strings.HasSuffix(strings.TrimSpace(a.InitialPrompt()), strings.TrimSpace(planInstruction)) {
+		t.Error("Expected the planning request to end the prompt with PlanFirst")
+	}
+}
@@ -0,0 +1,114 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/landanqrew/mermaid-agent-documenter/internal/console"
+	"github.com/landanqrew/mermaid-agent-documenter/internal/providers"
+)
+
+// spinnerInterval is how often the terminal spinner redraws
+const spinnerInterval = 100 * time.Millisecond
+
+// progressStatusInterval is how often a plain status line is printed when
+// console output is not a terminal
+const progressStatusInterval = 15 * time.Second
+
+var spinnerFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+// outputIsTerminal reports whether console output goes to an interactive terminal
+func outputIsTerminal() bool {
+	file, ok := console.Output().(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := file.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// generate calls the provider, showing progress while waiting for the response
+func (a *MermaidDocumenterAgent) generate(ctx context.Context, prompt string) (string, error) {
+	if a.Provider == nil {
+		return "", fmt.Errorf("%w: %w", providers.ErrProviderInit, a.providerErr)
+	}
+	if a.Config.ShowProgress {
+		// ASCII mode also drops the spinner, whose frames and escape codes garble logs
+		tty := outputIsTerminal() && !console.ASCII()
+		interval := progressStatusInterval
+		if tty {
+			interval = spinnerInterval
+		}
+		stop := startProgress(console.Output(), tty, interval, a.progressStatus)
+		defer stop()
+	}
+
+	// Tag the request so gateway logs can be traced back to this run and step
+	ctx = providers.WithRequestID(ctx, a.requestID())
+	started := time.Now()
+	response, err := a.Provider.GenerateContent(a.responseContext(ctx), prompt, a.Config.Model, a.Config.APIKey)
+	if a.rejectJSONMode(err) {
+		response, err = a.Provider.GenerateContent(ctx, prompt, a.Config.Model, a.Config.APIKey)
+	}
+	a.lastLatency = time.Since(started)
+	if response != "" {
+		a.dumpResponse(response)
+	}
+	if a.result != nil {
+		a.result.LatenciesMs = append(a.result.LatenciesMs, a.lastLatency.Milliseconds())
+	}
+	return response, err
+}
+
+// requestID correlates a provider request with the run and step that made it
+func (a *MermaidDocumenterAgent) requestID() string {
+	return fmt.Sprintf("%s-step-%d", a.RunID, a.StepCount+1)
+}
+
+// progressStatus describes the current wait for the progress indicator
+func (a *MermaidDocumenterAgent) progressStatus(elapsed time.Duration) string {
+	return fmt.Sprintf("Waiting for %s (step %d/%d, %s elapsed)", a.Config.Model, a.StepCount+1, a.Config.MaxSteps, elapsed.Truncate(time.Second))
+}
+
+// startProgress redraws a spinner on a terminal, or prints a plain status line
+// every interval otherwise, until the returned function is called
+func startProgress(w io.Writer, tty bool, interval time.Duration, status func(time.Duration) string) func() {
+	started := time.Now()
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for frame := 0; ; frame++ {
+			select {
+			case <-done:
+				if tty {
+					fmt.Fprint(w, "\r\033[K")
+				}
+				return
+			case <-ticker.C:
+				if tty {
+					fmt.Fprintf(w, "\r\033[K%s %s", spinnerFrames[frame%len(spinnerFrames)], status(time.Since(started)))
+				} else {
+					console.Fprintf(w, "⏳ %s\n", status(time.Since(started)))
+				}
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		wg.Wait()
+	}
+}
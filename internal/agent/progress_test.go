@@ -0,0 +1,70 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/landanqrew/mermaid-agent-documenter/internal/logs"
+)
+
+func TestStartProgress(t *testing.T) {
+	status := func(elapsed time.Duration) string { return "Waiting for model" }
+
+	t.Run("plain_status_lines", func(t *testing.T) {
+		var buf bytes.Buffer
+		stop := startProgress(&buf, false, 10*time.Millisecond, status)
+		time.Sleep(55 * time.Millisecond)
+		stop()
+
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		if len(lines) < 2 || lines[0] != "⏳ Waiting for model" {
+			t.Errorf("Expected repeated status lines, got %q", buf.String())
+		}
+		if strings.Contains(buf.String(), "\r") {
+			t.Errorf("Expected no carriage returns without a terminal, got %q", buf.String())
+		}
+	})
+
+	t.Run("terminal_spinner", func(t *testing.T) {
+		var buf bytes.Buffer
+		stop := startProgress(&buf, true, 10*time.Millisecond, status)
+		time.Sleep(35 * time.Millisecond)
+		stop()
+
+		out := buf.String()
+		if !strings.Contains(out, "\r\033[K"+spinnerFrames[0]+" Waiting for model") {
+			t.Errorf("Expected the spinner to redraw in place, got %q", out)
+		}
+		if !strings.HasSuffix(out, "\r\033[K") {
+			t.Errorf("Expected the spinner line to be cleared on stop, got %q", out)
+		}
+	})
+}
+
+func TestRun_RecordsLatency(t *testing.T) {
+	logsDir := t.TempDir()
+	a, _ := newTestAgent(&AgentConfig{
+		MaxSteps:            5,
+		ConfidenceThreshold: 0.9,
+		LogsDir:             logsDir,
+	}, "not json", testFinalResponse)
+
+	result, err := a.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(result.LatenciesMs) != 2 {
+		t.Errorf("Expected a latency for each model call, got %v", result.LatenciesMs)
+	}
+
+	entries, err := logs.Read(logsDir, time.Time{})
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("Expected one logged step, got %v (%v)", entries, err)
+	}
+	if _, ok := entries[0]["latencyMs"].(float64); !ok {
+		t.Errorf("Expected latencyMs in the log entry, got %v", entries[0])
+	}
+}
@@ -0,0 +1,78 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/landanqrew/mermaid-agent-documenter/internal/runs"
+)
+
+// refinePrompt asks the model to revise the documentation it already wrote
+const refinePrompt = `REFINEMENT REQUEST: The user reviewed the documentation and asks for this change:
+%s
+
+Update the existing files in place (%s) with the tools, keeping their paths, re-render any diagram images you change, and then return a final manifest.`
+
+// Refine continues a finished run with a refinement instruction from the
+// user, appended to the run's conversation so each refinement builds on the
+// previous ones. The run's result is updated and returned, with up to
+// MaxSteps more steps.
+func (a *MermaidDocumenterAgent) Refine(ctx context.Context, instruction string) (*RunResult, error) {
+	if a.result == nil || a.conversation == nil {
+		return nil, fmt.Errorf("there is no run to refine")
+	}
+	instruction = strings.TrimSpace(instruction)
+	if instruction == "" {
+		return a.result, fmt.Errorf("refinement instruction is empty")
+	}
+
+	files := "no files were written yet"
+	if len(a.result.Artifacts) > 0 {
+		files = strings.Join(a.result.Artifacts, ", ")
+	}
+	a.conversation = append(a.conversation, map[string]interface{}{
+		"role":    "user",
+		"content": fmt.Sprintf(refinePrompt, instruction, files),
+		pinKey:    pinRefinement,
+	})
+	a.consecutiveFails = 0
+	a.parseRetries = 0
+	a.splitRetries = 0
+	a.emptyRetries = 0
+	a.result.Refinements++
+
+	return a.loop(ctx)
+}
+
+// Resume restores a finished run from its snapshot so Refine can continue its
+// saved conversation in a later invocation. The run keeps its ID and writes to
+// the output directory it used, so its snapshot is updated in place.
+func (a *MermaidDocumenterAgent) Resume(snapshot *runs.Snapshot) error {
+	conversation, err := snapshot.Conversation()
+	if err != nil {
+		return err
+	}
+
+	resumed := *a.Config
+	resumed.OutputDir = conversation.OutputDir
+	resumed.ArchiveRuns = false
+	a.Config = &resumed
+
+	a.RunID = snapshot.RunID
+	a.StepCount = conversation.Steps
+	a.conversation = conversation.Turns
+	a.result = &RunResult{
+		RunID:             snapshot.RunID,
+		Provider:          a.Config.Provider,
+		Model:             a.Config.Model,
+		Steps:             conversation.Steps,
+		Artifacts:         append([]string{}, conversation.Artifacts...),
+		OutputDir:         conversation.OutputDir,
+		Refinements:       conversation.Refinements,
+		TerminationReason: TerminationReason(snapshot.TerminationReason),
+		StartedAt:         snapshot.StartedAt,
+		FinishedAt:        snapshot.FinishedAt,
+	}
+	return nil
+}
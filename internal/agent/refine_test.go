@@ -0,0 +1,111 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/landanqrew/mermaid-agent-documenter/internal/runs"
+)
+
+// promptRecorder records the prompt of each call to a scripted provider
+type promptRecorder struct {
+	scriptedProvider
+	prompts []string
+}
+
+func (p *promptRecorder) GenerateContent(ctx context.Context, prompt string, model string, apiKey string) (string, error) {
+	p.prompts = append(p.prompts, prompt)
+	return p.scriptedProvider.GenerateContent(ctx, prompt, model, apiKey)
+}
+
+func TestRefine_ContinuesConversation(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home) // writeFileContents only writes under ~/mermaid-agent-documenter
+	outputDir := filepath.Join(home, "mermaid-agent-documenter", "output")
+	refinedWrite := `{"type":"tool_call","tool":"writeFileContents","args":{"path":"login.md","content":"# Login\n\nWrong passwords show an error."},"confidence":0.95,"rationale":"add error path"}`
+	provider := &promptRecorder{scriptedProvider: scriptedProvider{responses: []string{
+		testWriteResponse, testFinalResponse, refinedWrite, testFinalResponse,
+	}}}
+	a, _ := newTestAgent(&AgentConfig{MaxSteps: 5, ConfidenceThreshold: 0.9, OutputDir: outputDir})
+	a.Provider = provider
+
+	if _, err := a.Run(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	result, err := a.Refine(context.Background(), "include error paths")
+	if err != nil {
+		t.Fatalf("Unexpected refine error: %v", err)
+	}
+
+	if result.Refinements != 1 || result.Steps != 2 || result.TerminationReason != TerminationCompleted {
+		t.Errorf("Expected one completed refinement after 2 tool steps, got %+v", result)
+	}
+	prompt := provider.prompts[2]
+	if !strings.Contains(prompt, "include error paths") || !strings.Contains(prompt, "email and password") || !strings.Contains(prompt, `"type":"final"`) {
+		t.Errorf("Expected the refinement to follow the earlier conversation, got %q", prompt)
+	}
+	data, err := os.ReadFile(filepath.Join(outputDir, "login.md"))
+	if err != nil || !strings.Contains(string(data), "Wrong passwords") {
+		t.Errorf("Expected the file to be updated, got %q (%v)", data, err)
+	}
+	if len(result.Artifacts) != 1 {
+		t.Errorf("Expected the updated file to be recorded once, got %v", result.Artifacts)
+	}
+}
+
+func TestRefine_ResumesSavedRun(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	outputDir := filepath.Join(home, "mermaid-agent-documenter", "output")
+	logsDir := filepath.Join(home, "mermaid-agent-documenter", "logs")
+	first, _ := newTestAgent(&AgentConfig{MaxSteps: 5, ConfidenceThreshold: 0.9, OutputDir: outputDir, LogsDir: logsDir},
+		testWriteResponse, testFinalResponse)
+	if _, err := first.Run(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// A later invocation continues the saved conversation in the same directory
+	snapshot, err := runs.Load(logsDir, first.RunID)
+	if err != nil {
+		t.Fatalf("Failed to load the run: %v", err)
+	}
+	refinedWrite := `{"type":"tool_call","tool":"writeFileContents","args":{"path":"login.md","content":"# Login\n\nWrong passwords show an error."},"confidence":0.95,"rationale":"add error path"}`
+	provider := &promptRecorder{scriptedProvider: scriptedProvider{responses: []string{refinedWrite, testFinalResponse}}}
+	second := NewMermaidDocumenterAgent(&AgentConfig{MaxSteps: 5, ConfidenceThreshold: 0.9, OutputDir: t.TempDir(), LogsDir: logsDir, ArchiveRuns: true})
+	second.Provider = provider
+	if err := second.Resume(snapshot); err != nil {
+		t.Fatalf("Unexpected resume error: %v", err)
+	}
+	result, err := second.Refine(context.Background(), "include error paths")
+	if err != nil {
+		t.Fatalf("Unexpected refine error: %v", err)
+	}
+
+	if result.RunID != first.RunID || result.Refinements != 1 || result.Steps != 2 {
+		t.Errorf("Expected the first run to be refined once after 2 steps, got %+v", result)
+	}
+	if prompt := provider.prompts[0]; !strings.Contains(prompt, "include error paths") || !strings.Contains(prompt, "email and password") {
+		t.Errorf("Expected the refinement to follow the saved conversation, got %q", prompt)
+	}
+	data, err := os.ReadFile(filepath.Join(outputDir, "login.md"))
+	if err != nil || !strings.Contains(string(data), "Wrong passwords") {
+		t.Errorf("Expected the original file to be updated, got %q (%v)", data, err)
+	}
+
+	// The refinement is saved too, so it can be continued again
+	conversation, err := snapshot.Conversation()
+	if err != nil || conversation.Refinements != 1 || !strings.Contains(fmt.Sprint(conversation.Turns), "include error paths") {
+		t.Errorf("Expected the refined conversation to be saved, got %+v (%v)", conversation, err)
+	}
+}
+
+func TestRefine_RequiresRun(t *testing.T) {
+	a, _ := newTestAgent(&AgentConfig{MaxSteps: 5, ConfidenceThreshold: 0.9})
+	if _, err := a.Refine(context.Background(), "more detail"); err == nil {
+		t.Error("Expected an error refining before a run")
+	}
+}
@@ -0,0 +1,52 @@
+package agent
+
+import (
+	"time"
+)
+
+// RunResult summarizes what happened during an agent run
+type RunResult struct {
+	RunID             string                 `json:"runId"`
+	Provider          string                 `json:"provider"`
+	Model             string                 `json:"model"`
+	Steps             int                    `json:"steps"`
+	Artifacts         []string               `json:"artifacts"`
+	OutputDir         string                 `json:"outputDir,omitempty"` // where the run wrote its files
+	Manifest          map[string]interface{} `json:"manifest,omitempty"`
+	Plan              []PlanItem             `json:"plan,omitempty"`
+	PromptTokens      int                    `json:"promptTokens"`
+	CompletionTokens  int                    `json:"completionTokens"`
+	EstimatedCostUsd  float64                `json:"estimatedCostUsd"`
+	Confidences       []float64              `json:"confidences,omitempty"` // confidence reported at each step
+	LatenciesMs       []int64                `json:"latenciesMs,omitempty"` // duration of each model call
+	Refinements       int                    `json:"refinements,omitempty"` // refinement requests applied with Refine
+	TerminationReason TerminationReason      `json:"terminationReason"`
+	StartedAt         time.Time              `json:"startedAt"`
+	FinishedAt        time.Time              `json:"finishedAt"`
+}
+
+// TotalTokens returns the combined prompt and completion token estimate
+func (r *RunResult) TotalTokens() int {
+	return r.PromptTokens + r.CompletionTokens
+}
+
+// Duration returns how long the run took
+func (r *RunResult) Duration() time.Duration {
+	if r.FinishedAt.IsZero() {
+		return time.Since(r.StartedAt)
+	}
+	return r.FinishedAt.Sub(r.StartedAt)
+}
+
+// addArtifact records a produced file path once
+func (r *RunResult) addArtifact(path string) {
+	if path == "" {
+		return
+	}
+	for _, existing := range r.Artifacts {
+		if existing == path {
+			return
+		}
+	}
+	r.Artifacts = append(r.Artifacts, path)
+}
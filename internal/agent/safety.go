@@ -0,0 +1,117 @@
+package agent
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/landanqrew/mermaid-agent-documenter/internal/tools"
+)
+
+// strictSafetyInstructions tell the model what strict safety mode blocks
+const strictSafetyInstructions = `
+
+STRICT SAFETY MODE:
+- fetchMermaidDocumentation is disabled; rely on the Mermaid syntax rules above
+- Read and write files only inside the output directory (relative paths are placed there)
+- Overwriting a file that existed before this run needs the user's confirmation; prefer new file names`
+
+// strictPathArgs are the tool arguments that name files
+var strictPathArgs = []string{"path", "inputFile", "outputFile"}
+
+// strictToolResult enforces strict safety mode on a tool call whose paths
+// were already resolved by modifyFilePaths: the network documentation tool is
+// blocked, files must be inside the output directory, and existing files the
+// run did not create are only overwritten or appended to after the user
+// confirms.
+func (a *MermaidDocumenterAgent) strictToolResult(toolName string, args map[string]interface{}) (tools.ToolResult, bool) {
+	if !a.Config.StrictSafety {
+		return tools.ToolResult{}, false
+	}
+	if toolName == "fetchMermaidDocumentation" {
+		return tools.ToolResult{
+			Success: false,
+			Error:   "fetchMermaidDocumentation is disabled in strict safety mode (no network access). Continue using the Mermaid syntax rules you were given",
+		}, true
+	}
+
+	for _, argName := range strictPathArgs {
+		path, ok := args[argName].(string)
+		if !ok || path == "" {
+			continue
+		}
+		if !a.insideOutputDir(path) {
+			return tools.ToolResult{
+				Success: false,
+				Error:   fmt.Sprintf("'%s' is outside the output directory, and strict safety mode only allows files inside it. Use a relative path such as %q", path, filepath.Base(path)),
+			}, true
+		}
+	}
+
+	if toolName == "writeFileContents" || toolName == "writeMermaidDiagram" {
+		path, _ := args["path"].(string)
+		if toolName == "writeMermaidDiagram" && filepath.Ext(path) != ".mmd" {
+			path = strings.TrimSuffix(path, filepath.Ext(path)) + ".mmd" // as the tool names it
+		}
+		if err := a.confirmOverwrite(expandHome(path)); err != nil {
+			return tools.ToolResult{Success: false, Error: err.Error()}, true
+		}
+	}
+	return tools.ToolResult{}, false
+}
+
+// insideOutputDir reports whether path is the output directory or inside it
+func (a *MermaidDocumenterAgent) insideOutputDir(path string) bool {
+	if a.Config.OutputDir == "" {
+		return false
+	}
+	outputDir, err := filepath.Abs(expandHome(a.Config.OutputDir))
+	if err != nil {
+		return false
+	}
+	absPath, err := filepath.Abs(expandHome(path))
+	if err != nil {
+		return false
+	}
+	rel, err := filepath.Rel(outputDir, absPath)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// confirmOverwrite lets the run write (or append to) path when it is new, was
+// written by this run, or the user agrees to change it. Without a terminal the
+// overwrite is refused.
+func (a *MermaidDocumenterAgent) confirmOverwrite(path string) error {
+	if a.ownedFiles[path] {
+		return nil
+	}
+	if a.ownedFiles == nil {
+		a.ownedFiles = make(map[string]bool)
+	}
+	if _, err := os.Stat(path); err != nil {
+		a.ownedFiles[path] = true // created by this run
+		return nil
+	}
+
+	refused := fmt.Errorf("'%s' already exists and strict safety mode needs the user's confirmation to overwrite it, which was not given. Write to a new file name instead", filepath.Base(path))
+	if a.Config.NonInteractive || !stdinIsTerminal() {
+		return refused
+	}
+	inputTool := tools.GetTool("getUserInput")
+	if inputTool == nil {
+		return refused
+	}
+	result := inputTool.Execute(map[string]interface{}{
+		"prompt": fmt.Sprintf("🛡️  Overwrite existing %s? (y/N):", path),
+	})
+	answer := ""
+	if data, ok := result.Data.(map[string]interface{}); ok {
+		answer, _ = data["answer"].(string)
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	if answer != "y" && answer != "yes" {
+		return refused
+	}
+	a.ownedFiles[path] = true
+	return nil
+}
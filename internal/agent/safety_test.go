@@ -0,0 +1,75 @@
+package agent
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestStrictToolResult(t *testing.T) {
+	outputDir := t.TempDir()
+	existing := filepath.Join(outputDir, "existing.md")
+	if err := os.WriteFile(existing, []byte("# Existing"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	a := NewMermaidDocumenterAgent(&AgentConfig{OutputDir: outputDir, StrictSafety: true, NonInteractive: true})
+
+	if result, rejected := a.strictToolResult("fetchMermaidDocumentation", map[string]interface{}{"topic": "erDiagram"}); !rejected || !strings.Contains(result.Error, "disabled") {
+		t.Errorf("Expected the documentation tool to be blocked, got %+v", result)
+	}
+
+	args := a.modifyFilePaths(map[string]interface{}{"path": "../escape.md", "content": "x"}, "")
+	if result, rejected := a.strictToolResult("writeFileContents", args); !rejected || !strings.Contains(result.Error, "outside the output directory") {
+		t.Errorf("Expected a path outside the output directory to be blocked, got %+v", result)
+	}
+	if _, rejected := a.strictToolResult("readFileContents", map[string]interface{}{"path": "/etc/hosts"}); !rejected {
+		t.Error("Expected reads outside the output directory to be blocked")
+	}
+
+	args = a.modifyFilePaths(map[string]interface{}{"inputFile": "new.md", "outputFile": "new"}, "")
+	if args["outputFile"] != filepath.Join(outputDir, "new") {
+		t.Errorf("Expected outputFile to be placed in the output directory, got %v", args["outputFile"])
+	}
+	if result, rejected := a.strictToolResult("generateMermaidImage", args); rejected {
+		t.Errorf("Expected a render inside the output directory to be allowed, got %+v", result)
+	}
+
+	args = a.modifyFilePaths(map[string]interface{}{"path": "existing.md", "content": "x"}, "")
+	if result, rejected := a.strictToolResult("writeFileContents", args); !rejected || !strings.Contains(result.Error, "confirmation") {
+		t.Errorf("Expected overwriting an existing file to need confirmation, got %+v", result)
+	}
+	args["append"] = true
+	if result, rejected := a.strictToolResult("writeFileContents", args); !rejected || !strings.Contains(result.Error, "confirmation") {
+		t.Errorf("Expected appending to an existing file to need confirmation too, got %+v", result)
+	}
+
+	args = a.modifyFilePaths(map[string]interface{}{"path": "fresh.md", "content": "x"}, "")
+	if _, rejected := a.strictToolResult("writeFileContents", args); rejected {
+		t.Error("Expected a new file to be allowed")
+	}
+	if err := os.WriteFile(filepath.Join(outputDir, "fresh.md"), []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, rejected := a.strictToolResult("writeFileContents", args); rejected {
+		t.Error("Expected a file created by this run to be rewritable")
+	}
+	args["append"] = true
+	if _, rejected := a.strictToolResult("writeFileContents", args); rejected {
+		t.Error("Expected a file created by this run to be appendable")
+	}
+}
+
+func TestStrictToolResult_StandardMode(t *testing.T) {
+	a := NewMermaidDocumenterAgent(&AgentConfig{OutputDir: t.TempDir()})
+	if _, rejected := a.strictToolResult("fetchMermaidDocumentation", map[string]interface{}{}); rejected {
+		t.Error("Expected standard mode to allow every tool")
+	}
+	if strings.Contains(a.buildSystemPrompt(), "STRICT SAFETY MODE") {
+		t.Error("Expected no strict instructions in standard mode")
+	}
+	a.Config.StrictSafety = true
+	if !strings.Contains(a.buildSystemPrompt(), "STRICT SAFETY MODE") {
+		t.Error("Expected strict instructions in the system prompt")
+	}
+}
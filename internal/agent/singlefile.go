@@ -0,0 +1,36 @@
+package agent
+
+import (
+	"fmt"
+	"strings"
+)
+
+// singleFileName is the document a single-file run writes when no
+// FileNameTemplate is configured
+const singleFileName = "documentation"
+
+// singleFileInstructions ask the model to put every documentation type in one
+// Markdown file, one H2 section each, rendered with a single image call
+func (a *MermaidDocumenterAgent) singleFileInstructions(name string) string {
+	sections := "one for each topic you document"
+	if len(a.Config.DocumentationTypes) > 0 {
+		sections = "one for each of: " + strings.Join(a.Config.DocumentationTypes, ", ")
+	}
+
+	instructions := fmt.Sprintf(`
+
+SINGLE FILE OUTPUT:
+- Write ALL documentation to ONE Markdown file, %[1]s.md, starting with a "# " title and a short overview
+- Give it a "## <documentation type>" section %[2]s, with that section's diagrams in `+"```mermaid"+` blocks inside it
+- Different diagram types in this file are expected; this overrides the one-diagram-type-per-file guidance
+- Do NOT write any other .md or .mmd files and do NOT add a "docType" argument`, name, sections)
+
+	if a.Config.SkipImages {
+		return instructions + fmt.Sprintf(`
+- List only %s.md in the final manifest`, name)
+	}
+	return instructions + fmt.Sprintf(`
+- Render the file ONCE with generateMermaidImage (inputFile "%[1]s.md", outputFile "%[1]s"): each diagram becomes its own image, %[1]s-1.svg, %[1]s-2.svg, ... in document order, returned in the tool result's outputFiles
+- If one diagram fails to render, fix it in %[1]s.md with writeFileContents and render the whole file again
+- List %[1]s.md and every image from outputFiles in the final manifest`, name)
+}
@@ -0,0 +1,47 @@
+package agent
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/landanqrew/mermaid-agent-documenter/internal/tools"
+)
+
+func TestBuildSystemPrompt_SingleFile(t *testing.T) {
+	a := NewMermaidDocumenterAgent(&AgentConfig{
+		DocumentationTypes: []string{"User Flow Diagrams", "Data Models (ER Diagrams)"},
+		SingleFile:         true,
+	})
+	prompt := a.buildSystemPrompt()
+
+	if !strings.Contains(prompt, "SINGLE FILE OUTPUT:") || !strings.Contains(prompt, "one for each of: User Flow Diagrams, Data Models (ER Diagrams)") {
+		t.Fatalf("Expected single file instructions naming each type, got:\n%s", prompt)
+	}
+	if strings.Contains(prompt, "DOCUMENTATION TYPES:") {
+		t.Error("Expected no per-type subdirectories in single file mode")
+	}
+	if !strings.Contains(prompt, `"path":"documentation.md"`) || !strings.Contains(prompt, "documentation-1.svg") {
+		t.Error("Expected the examples to use documentation.md and numbered images")
+	}
+
+	a.Config.SkipImages = true
+	if strings.Contains(a.buildSystemPrompt(), "documentation-1.svg") {
+		t.Error("Expected no render step with images disabled")
+	}
+}
+
+func TestRecordArtifacts_SplitImages(t *testing.T) {
+	a := NewMermaidDocumenterAgent(&AgentConfig{})
+	a.result = &RunResult{}
+	a.recordArtifacts("generateMermaidImage", tools.ToolResult{
+		Success: true,
+		Data: map[string]interface{}{
+			"outputFile":  "/out/documentation.svg",
+			"outputFiles": []string{"/out/documentation-1.svg", "/out/documentation-2.svg"},
+		},
+	}, "")
+
+	if got := a.result.Artifacts; len(got) != 2 || got[0] != "/out/documentation-1.svg" || got[1] != "/out/documentation-2.svg" {
+		t.Errorf("Expected each split image as an artifact, got %v", got)
+	}
+}
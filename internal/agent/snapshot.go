@@ -0,0 +1,50 @@
+package agent
+
+import (
+	"github.com/landanqrew/mermaid-agent-documenter/internal/console"
+	"github.com/landanqrew/mermaid-agent-documenter/internal/runs"
+)
+
+// saveRunSnapshot copies the run's Markdown and Mermaid outputs into the logs
+// directory so 'mad diff' can compare them with another run
+func (a *MermaidDocumenterAgent) saveRunSnapshot() {
+	if a.Config.LogsDir == "" || a.result == nil {
+		return
+	}
+
+	artifacts := make([]string, 0, len(a.result.Artifacts))
+	for _, artifact := range a.result.Artifacts {
+		artifacts = append(artifacts, expandHome(artifact))
+	}
+	record := runs.Record{
+		RunID:             a.RunID,
+		Transcript:        a.Config.TranscriptName,
+		Provider:          a.result.Provider,
+		Model:             a.result.Model,
+		TerminationReason: string(a.result.TerminationReason),
+		StartedAt:         a.result.StartedAt,
+		FinishedAt:        a.result.FinishedAt,
+	}
+	if err := runs.Save(expandHome(a.Config.LogsDir), expandHome(a.Config.OutputDir), record, artifacts); err != nil {
+		console.Printf("⚠️  Failed to save run snapshot: %v\n", err)
+	}
+}
+
+// saveConversation stores the run's conversation with its snapshot so a later
+// 'mad runs refine' can continue it
+func (a *MermaidDocumenterAgent) saveConversation() {
+	if a.Config.LogsDir == "" || a.result == nil || len(a.conversation) == 0 {
+		return
+	}
+
+	conversation := runs.Conversation{
+		OutputDir:   a.Config.OutputDir,
+		Artifacts:   a.result.Artifacts,
+		Steps:       a.StepCount,
+		Refinements: a.result.Refinements,
+		Turns:       a.conversation,
+	}
+	if err := runs.SaveConversation(expandHome(a.Config.LogsDir), a.RunID, conversation); err != nil {
+		console.Printf("⚠️  Failed to save run conversation: %v\n", err)
+	}
+}
@@ -0,0 +1,19 @@
+package agent
+
+import (
+	"fmt"
+	"math"
+)
+
+// thresholdWarning explains why a confidence threshold outside [0, 1] breaks
+// the run, or returns "" for a usable threshold
+func (a *MermaidDocumenterAgent) thresholdWarning() string {
+	threshold := a.Config.ConfidenceThreshold
+	switch {
+	case math.IsNaN(threshold) || threshold > 1:
+		return fmt.Sprintf("confidenceThreshold %v is above 1: no response can meet it, so every tool call and final manifest will be rejected until maxSteps is reached. Set it between 0 and 1 with 'mad config set confidenceThreshold 0.9'", threshold)
+	case threshold < 0:
+		return fmt.Sprintf("confidenceThreshold %v is below 0: every tool call and final manifest will be accepted regardless of confidence. Set it between 0 and 1 with 'mad config set confidenceThreshold 0.9'", threshold)
+	}
+	return ""
+}
@@ -0,0 +1,61 @@
+package agent
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/landanqrew/mermaid-agent-documenter/internal/tools"
+)
+
+// omittedResultFields are tool result data fields that only add noise for the model
+var omittedResultFields = map[string]bool{
+	"commandOutput": true, // raw Mermaid CLI output; failures are reported in error
+	"bytesWritten":  true,
+	"inputType":     true,
+}
+
+// formatToolResult serializes a tool result as compact JSON for the
+// conversation, keeping the outcome, any error, and the relevant data fields
+func formatToolResult(toolName string, result tools.ToolResult) string {
+	message := map[string]interface{}{
+		"tool":    toolName,
+		"success": result.Success,
+	}
+	if result.Error != "" {
+		message["error"] = result.Error
+	}
+
+	if data, ok := result.Data.(map[string]interface{}); ok {
+		relevant := map[string]interface{}{}
+		for key, value := range data {
+			if !omittedResultFields[key] {
+				relevant[key] = value
+			}
+		}
+		if len(relevant) > 0 {
+			message["data"] = relevant
+		}
+	} else if result.Data != nil {
+		message["data"] = result.Data
+	}
+
+	encoded, err := marshalUnescaped(message)
+	if err != nil {
+		// Data from external tools may not be serializable; the outcome still is
+		encoded, _ = marshalUnescaped(map[string]interface{}{"tool": toolName, "success": result.Success, "error": result.Error})
+	}
+	return fmt.Sprintf("Tool result: %s", encoded)
+}
+
+// marshalUnescaped encodes v as compact JSON without HTML escaping, so Mermaid
+// arrows such as --> and <<interface>> reach the model as written
+func marshalUnescaped(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
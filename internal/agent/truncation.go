@@ -0,0 +1,84 @@
+package agent
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/landanqrew/mermaid-agent-documenter/internal/console"
+	"github.com/landanqrew/mermaid-agent-documenter/internal/providers"
+)
+
+// DefaultMaxOutputTokensCeiling is the largest max output tokens a truncated
+// step is retried with when no ceiling is configured. It stays within the
+// output limit of the default models so a raised limit is not itself rejected.
+const DefaultMaxOutputTokensCeiling = 8192
+
+// splitOutputPrompt asks the model for a smaller response after one was cut off
+const splitOutputPrompt = "Your last response was cut off at the maximum output length (%d characters received), so none of it was used. " +
+	"Respond again with a smaller step: write large documents in parts, creating the file with writeFileContents and adding the remaining sections with further writeFileContents calls that set \"append\": true, " +
+	"keep each diagram in its own writeMermaidDiagram call, and keep the final manifest short."
+
+// maxOutputTokensCeiling returns the configured ceiling or the default
+func (a *MermaidDocumenterAgent) maxOutputTokensCeiling() int {
+	if a.Config.MaxOutputTokensCeiling > 0 {
+		return a.Config.MaxOutputTokensCeiling
+	}
+	return DefaultMaxOutputTokensCeiling
+}
+
+// raiseOutputLimit doubles the provider's max output tokens, up to the
+// ceiling. It reports false when the provider's limit cannot be changed, is
+// the model's own default, or is already at the ceiling.
+func (a *MermaidDocumenterAgent) raiseOutputLimit() (from, to int, raised bool) {
+	limiter, ok := a.Provider.(providers.OutputLimiter)
+	if !ok {
+		return 0, 0, false
+	}
+	from = limiter.MaxOutputTokens()
+	if from <= 0 {
+		return from, from, false // the model default may already exceed the ceiling
+	}
+	to = min(from*2, a.maxOutputTokensCeiling())
+	if to <= from {
+		return from, from, false
+	}
+	limiter.SetMaxOutputTokens(to)
+	return from, to, true
+}
+
+// handleTruncation retries a step whose response was cut off, first with a
+// higher output limit and then by asking the model to split its output. It
+// logs what was done and reports false once neither is possible.
+func (a *MermaidDocumenterAgent) handleTruncation(conversation *[]map[string]interface{}, response string) bool {
+	entry := map[string]interface{}{
+		"timestamp":      time.Now().Format(time.RFC3339),
+		"run_id":         a.RunID,
+		"step":           a.StepCount + 1,
+		"provider":       a.Config.Provider,
+		"model":          a.Config.Model,
+		"output_type":    "truncated",
+		"response_chars": len(response),
+	}
+	defer a.appendLogEntry(entry)
+
+	if from, to, raised := a.raiseOutputLimit(); raised {
+		console.Printf("✂️  Response was truncated at %d output tokens, retrying the step with %d\n", from, to)
+		entry["action"] = "raise_max_output_tokens"
+		entry["max_output_tokens"] = to
+		return true
+	}
+
+	if a.splitRetries >= a.maxParseRetries() {
+		console.Printf("❌ Response was truncated again after asking the model to split its output %d times\n", a.splitRetries)
+		entry["action"] = "give_up"
+		return false
+	}
+	a.splitRetries++
+	console.Printf("✂️  Response was truncated at the output limit, asking the model to split its output (%d/%d)\n", a.splitRetries, a.maxParseRetries())
+	entry["action"] = "split_output"
+	*conversation = append(*conversation,
+		map[string]interface{}{"role": "assistant", "content": response},
+		map[string]interface{}{"role": "user", "content": fmt.Sprintf(splitOutputPrompt, len(response))},
+	)
+	return true
+}
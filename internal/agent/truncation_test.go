@@ -0,0 +1,130 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/landanqrew/mermaid-agent-documenter/internal/providers"
+)
+
+// limitedProvider cuts responses in half, reporting truncation, while its
+// max output tokens are below needed
+type limitedProvider struct {
+	scriptedProvider
+	maxTokens int
+	needed    int
+	limits    []int // max output tokens at each call
+}
+
+func (p *limitedProvider) GenerateContent(ctx context.Context, prompt string, model string, apiKey string) (string, error) {
+	p.limits = append(p.limits, p.maxTokens)
+	response, _ := p.scriptedProvider.GenerateContent(ctx, prompt, model, apiKey)
+	if p.maxTokens < p.needed {
+		return response[:len(response)/2], providers.ErrOutputTruncated
+	}
+	return response, nil
+}
+
+func (p *limitedProvider) MaxOutputTokens() int          { return p.maxTokens }
+func (p *limitedProvider) SetMaxOutputTokens(tokens int) { p.maxTokens = tokens }
+
+const testWriteResponse = `{"type":"tool_call","tool":"writeFileContents","args":{"path":"login.md","content":"# Login\n\nUsers sign in with email and password."},"confidence":0.95,"rationale":"write"}`
+
+func TestRun_RaisesOutputLimitAfterTruncation(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home) // writeFileContents only writes under ~/mermaid-agent-documenter
+	outputDir := filepath.Join(home, "mermaid-agent-documenter", "output")
+	provider := &limitedProvider{
+		scriptedProvider: scriptedProvider{responses: []string{testWriteResponse, testWriteResponse, testWriteResponse, testFinalResponse}},
+		maxTokens:        1000,
+		needed:           4000,
+	}
+	a, _ := newTestAgent(&AgentConfig{
+		MaxSteps:               5,
+		ConfidenceThreshold:    0.9,
+		OutputDir:              outputDir,
+		MaxOutputTokensCeiling: 5000,
+	})
+	a.Provider = provider
+
+	result, err := a.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.TerminationReason != TerminationCompleted {
+		t.Errorf("Expected termination %q, got %q", TerminationCompleted, result.TerminationReason)
+	}
+	if got := provider.limits; len(got) != 4 || got[0] != 1000 || got[1] != 2000 || got[2] != 4000 || got[3] != 4000 {
+		t.Errorf("Expected the limit to double up to the ceiling, got %v", got)
+	}
+	data, err := os.ReadFile(filepath.Join(outputDir, "login.md"))
+	if err != nil || !strings.Contains(string(data), "email and password") {
+		t.Errorf("Expected the complete file to be written once the step fit, got %q (%v)", data, err)
+	}
+}
+
+func TestRun_AsksToSplitOutputAtCeiling(t *testing.T) {
+	outputDir := t.TempDir()
+	provider := &limitedProvider{
+		scriptedProvider: scriptedProvider{responses: []string{testWriteResponse, testWriteResponse, testWriteResponse}},
+		maxTokens:        4000,
+		needed:           8000,
+	}
+	a, _ := newTestAgent(&AgentConfig{
+		MaxSteps:               5,
+		MaxParseRetries:        2,
+		ConfidenceThreshold:    0.9,
+		OutputDir:              outputDir,
+		MaxOutputTokensCeiling: 4000,
+	})
+	a.Provider = provider
+
+	result, err := a.Run(context.Background())
+	if !errors.Is(err, providers.ErrOutputTruncated) {
+		t.Fatalf("Expected ErrOutputTruncated, got %v", err)
+	}
+	if result.TerminationReason != TerminationError {
+		t.Errorf("Expected termination %q, got %q", TerminationError, result.TerminationReason)
+	}
+	if provider.calls != 3 {
+		t.Errorf("Expected the original call plus 2 split requests, got %d calls", provider.calls)
+	}
+	if _, err := os.Stat(filepath.Join(outputDir, "login.md")); !os.IsNotExist(err) {
+		t.Error("Expected no file to be written from a truncated response")
+	}
+}
+
+func TestRun_DoesNotActOnRecoveredPartialJSON(t *testing.T) {
+	outputDir := t.TempDir()
+	// The cut-off response closes cleanly but is missing the rest of its content
+	partial := `{"type":"tool_call","tool":"writeFileContents","args":{"path":"login.md","content":"# Login"`
+	a, provider := newTestAgent(&AgentConfig{
+		MaxSteps:            5,
+		ConfidenceThreshold: 0.9,
+		OutputDir:           outputDir,
+		LogsDir:             t.TempDir(),
+	}, partial, testFinalResponse)
+
+	result, err := a.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.TerminationReason != TerminationCompleted {
+		t.Errorf("Expected termination %q, got %q", TerminationCompleted, result.TerminationReason)
+	}
+	if provider.calls != 2 {
+		t.Errorf("Expected the truncated step to be retried, got %d calls", provider.calls)
+	}
+	if _, err := os.Stat(filepath.Join(outputDir, "login.md")); !os.IsNotExist(err) {
+		t.Error("Expected the partial tool call not to be executed")
+	}
+
+	data, err := os.ReadFile(filepath.Join(a.Config.LogsDir, "logs.jsonl"))
+	if err != nil || !strings.Contains(string(data), `"output_type":"truncated"`) {
+		t.Errorf("Expected the truncation to be logged, got %q (%v)", data, err)
+	}
+}
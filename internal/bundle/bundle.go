@@ -0,0 +1,217 @@
+// Package bundle packs a documentation set into a zip archive for sharing.
+package bundle
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/landanqrew/mermaid-agent-documenter/internal/manifest"
+	"github.com/landanqrew/mermaid-agent-documenter/internal/mermaid"
+	"github.com/landanqrew/mermaid-agent-documenter/internal/output"
+	"github.com/landanqrew/mermaid-agent-documenter/internal/runs"
+)
+
+// File is one entry in a bundle
+type File struct {
+	Name string // slash-separated path inside the archive
+	Path string // file on disk to copy; when empty, Data is written
+	Data []byte
+}
+
+// documentExtensions and imageExtensions are the outputs included in a bundle
+var (
+	documentExtensions = map[string]bool{".md": true, ".mmd": true, ".adoc": true, ".html": true}
+	imageExtensions    = map[string]bool{".svg": true, ".png": true, ".pdf": true}
+)
+
+// FromOutputDir collects the documents, rendered images, and manifest in an
+// output directory, plus a fresh top-level index.md. Hidden files (such as
+// the hash manifest) and an existing top-level index are left out.
+func FromOutputDir(dir string, info output.IndexInfo) ([]File, error) {
+	var files []File
+	var documents []string
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if strings.HasPrefix(entry.Name(), ".") && path != dir {
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if entry.IsDir() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		name := filepath.ToSlash(rel)
+		ext := strings.ToLower(filepath.Ext(name))
+		switch {
+		case name == output.IndexFileName:
+			return nil
+		case name == manifest.FileName, documentExtensions[ext], imageExtensions[ext]:
+			files = append(files, File{Name: name, Path: path})
+		}
+		if ext == ".md" || ext == ".mmd" {
+			documents = append(documents, name)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read output directory: %w", err)
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no documentation found in %s", dir)
+	}
+
+	return withIndex(files, indexEntries(dir, documents, nil), info), nil
+}
+
+// FromSnapshot collects the documents saved for a run, the images in the
+// output directory rendered from them, and the run record, plus a top-level
+// index.md. Documents come from the snapshot, so they match the run even if
+// the output directory has changed since.
+func FromSnapshot(snapshot *runs.Snapshot, outputDir string) ([]File, error) {
+	if len(snapshot.Files) == 0 {
+		return nil, fmt.Errorf("run %s saved no documents", snapshot.RunID)
+	}
+
+	var files []File
+	contents := map[string]string{}
+	for _, name := range snapshot.Files {
+		content, err := snapshot.ReadFile(name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s from run snapshot: %w", name, err)
+		}
+		contents[name] = content
+		files = append(files, File{Name: name, Data: []byte(content)})
+	}
+	files = append(files, File{Name: runs.RecordFile, Path: filepath.Join(snapshot.Dir, runs.RecordFile)})
+
+	entries := indexEntries(outputDir, snapshot.Files, contents)
+	for _, entry := range entries {
+		for _, image := range entry.Images {
+			files = append(files, File{Name: image, Path: filepath.Join(outputDir, filepath.FromSlash(image))})
+		}
+	}
+
+	info := output.IndexInfo{Provider: snapshot.Provider, Model: snapshot.Model, GeneratedAt: snapshot.FinishedAt}
+	return withIndex(files, entries, info), nil
+}
+
+// indexEntries lists each document with the images rendered next to it in
+// dir. Document contents are read from dir unless given.
+func indexEntries(dir string, documents []string, contents map[string]string) []output.IndexEntry {
+	var entries []output.IndexEntry
+	for _, name := range documents {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		content, ok := contents[name]
+		if !ok {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				continue
+			}
+			content = string(data)
+		}
+
+		diagrams := len(mermaid.ExtractBlocks(content))
+		if strings.EqualFold(filepath.Ext(name), ".mmd") && strings.TrimSpace(content) != "" {
+			diagrams = 1
+		}
+		entry := output.IndexEntry{Path: name}
+		if i := strings.Index(name, "/"); i > 0 {
+			entry.Type = name[:i] // documentation type subdirectory
+		}
+		for _, image := range output.GuessRenderedImages(path, diagrams) {
+			if rel, err := filepath.Rel(dir, image); err == nil {
+				entry.Images = append(entry.Images, filepath.ToSlash(rel))
+			}
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// withIndex sorts the files and puts an index.md linking the documents first
+func withIndex(files []File, entries []output.IndexEntry, info output.IndexInfo) []File {
+	if info.GeneratedAt.IsZero() {
+		info.GeneratedAt = time.Now()
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Name < files[j].Name })
+
+	index := File{Name: output.IndexFileName, Data: []byte(output.BuildIndex(".", entries, info))}
+	return append([]File{index}, dedupe(files)...)
+}
+
+// dedupe drops repeated archive names from sorted files, keeping the first
+func dedupe(files []File) []File {
+	var unique []File
+	for i, file := range files {
+		if i > 0 && file.Name == files[i-1].Name {
+			continue
+		}
+		unique = append(unique, file)
+	}
+	return unique
+}
+
+// Write creates a zip archive at path holding the files in order
+func Write(path string, files []File) error {
+	out, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create bundle: %w", err)
+	}
+
+	archive := zip.NewWriter(out)
+	for _, file := range files {
+		if err := addFile(archive, file); err != nil {
+			archive.Close()
+			out.Close()
+			os.Remove(path)
+			return err
+		}
+	}
+	if err := archive.Close(); err != nil {
+		out.Close()
+		os.Remove(path)
+		return fmt.Errorf("failed to finish bundle: %w", err)
+	}
+	return out.Close()
+}
+
+// addFile writes one file into the archive
+func addFile(archive *zip.Writer, file File) error {
+	header := &zip.FileHeader{Name: file.Name, Method: zip.Deflate, Modified: time.Now()}
+	var source io.Reader = strings.NewReader(string(file.Data))
+	if file.Path != "" {
+		f, err := os.Open(file.Path)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", file.Path, err)
+		}
+		defer f.Close()
+		if info, err := f.Stat(); err == nil {
+			header.Modified = info.ModTime()
+		}
+		source = f
+	}
+
+	w, err := archive.CreateHeader(header)
+	if err != nil {
+		return fmt.Errorf("failed to add %s to bundle: %w", file.Name, err)
+	}
+	if _, err := io.Copy(w, source); err != nil {
+		return fmt.Errorf("failed to add %s to bundle: %w", file.Name, err)
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package bundle
+
+import (
+	"archive/zip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/landanqrew/mermaid-agent-documenter/internal/output"
+	"github.com/landanqrew/mermaid-agent-documenter/internal/runs"
+)
+
+// writeFiles creates files under dir
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create dir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+}
+
+// readZip returns the archive's entries by name, in order
+func readZip(t *testing.T, path string) ([]string, map[string]string) {
+	t.Helper()
+	reader, err := zip.OpenReader(path)
+	if err != nil {
+		t.Fatalf("Failed to open bundle: %v", err)
+	}
+	defer reader.Close()
+
+	var names []string
+	contents := map[string]string{}
+	for _, file := range reader.File {
+		rc, err := file.Open()
+		if err != nil {
+			t.Fatalf("Failed to open %s: %v", file.Name, err)
+		}
+		data, _ := io.ReadAll(rc)
+		rc.Close()
+		names = append(names, file.Name)
+		contents[file.Name] = string(data)
+	}
+	return names, contents
+}
+
+func TestFromOutputDir(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"user_flow_diagrams/login.md":  "# Login\n\n```mermaid\nsequenceDiagram\n  A->>B: hi\n```\n",
+		"user_flow_diagrams/login.svg": "<svg/>",
+		"manifest.json":                `{"artifacts":{}}`,
+		"index.md":                     "# Old index\n",
+		".mad-manifest.json":           "{}",
+		"notes.txt":                    "scratch",
+	})
+
+	files, err := FromOutputDir(dir, output.IndexInfo{Model: "test-model"})
+	if err != nil {
+		t.Fatalf("FromOutputDir failed: %v", err)
+	}
+	zipPath := filepath.Join(t.TempDir(), "docs.zip")
+	if err := Write(zipPath, files); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	names, contents := readZip(t, zipPath)
+	expected := "index.md manifest.json user_flow_diagrams/login.md user_flow_diagrams/login.svg"
+	if strings.Join(names, " ") != expected {
+		t.Errorf("Expected entries %q, got %q", expected, strings.Join(names, " "))
+	}
+	index := contents["index.md"]
+	if strings.Contains(index, "Old index") {
+		t.Error("Expected a fresh index, not the one from the output directory")
+	}
+	if !strings.Contains(index, "(user_flow_diagrams/login.md)") || !strings.Contains(index, "(user_flow_diagrams/login.svg)") {
+		t.Errorf("Expected the index to link the document and its image, got:\n%s", index)
+	}
+}
+
+func TestFromOutputDir_Empty(t *testing.T) {
+	if _, err := FromOutputDir(t.TempDir(), output.IndexInfo{}); err == nil {
+		t.Error("Expected an error for an empty output directory")
+	}
+}
+
+func TestFromSnapshot(t *testing.T) {
+	outputDir := t.TempDir()
+	logsDir := t.TempDir()
+	writeFiles(t, outputDir, map[string]string{
+		"flow.md":  "```mermaid\ngraph TD\n  A --> B\n```\n",
+		"flow.svg": "<svg/>",
+		"other.md": "# Not from this run\n",
+	})
+	record := runs.Record{RunID: "run-1", Provider: "openai", Model: "gpt-test"}
+	if err := runs.Save(logsDir, outputDir, record, []string{filepath.Join(outputDir, "flow.md")}); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	// The output directory changed after the run
+	writeFiles(t, outputDir, map[string]string{"flow.md": "edited later\n"})
+
+	snapshot, err := runs.Load(logsDir, "run-1")
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	files, err := FromSnapshot(snapshot, outputDir)
+	if err != nil {
+		t.Fatalf("FromSnapshot failed: %v", err)
+	}
+	zipPath := filepath.Join(t.TempDir(), "run.zip")
+	if err := Write(zipPath, files); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	names, contents := readZip(t, zipPath)
+	if strings.Join(names, " ") != "index.md flow.md flow.svg run.json" {
+		t.Errorf("Unexpected entries: %v", names)
+	}
+	if !strings.Contains(contents["flow.md"], "graph TD") {
+		t.Errorf("Expected the document as saved by the run, got %q", contents["flow.md"])
+	}
+	if !strings.Contains(contents["index.md"], "openai/gpt-test") {
+		t.Errorf("Expected the index to name the run's model, got:\n%s", contents["index.md"])
+	}
+}
@@ -0,0 +1,333 @@
+// Package config defines the global configuration stored in
+// ~/mermaid-agent-documenter/config.json and is the only place that reads
+// or writes that file.
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/landanqrew/mermaid-agent-documenter/internal/transcript"
+)
+
+// DirName is the directory under the user's home that holds config, logs, and output
+const DirName = "mermaid-agent-documenter"
+
+// FileName is the name of the config file inside Dir
+const FileName = "config.json"
+
+// FileMode is the permission for config.json, which may hold API keys
+const FileMode os.FileMode = 0600
+
+type ProjectConfig struct {
+	Name        string `json:"name"`
+	RootDir     string `json:"rootDir"`
+	Description string `json:"description,omitempty"`
+	CreatedAt   string `json:"createdAt,omitempty"`
+}
+
+type Config struct {
+	Provider             string            `json:"provider"`
+	Models               map[string]string `json:"models"`
+	ModelAliases         map[string]string `json:"modelAliases,omitempty"` // friendly name -> provider model ID; adds to and overrides the built-in aliases
+	Log                  LogConfig         `json:"log"`
+	Safety               SafetyConfig      `json:"safety"`
+	Limits               LimitsConfig      `json:"limits"`
+	Transcript           TranscriptConfig  `json:"transcript"`
+	ConfidenceThreshold  float64           `json:"confidenceThreshold"`
+	OutDir               string            `json:"outDir"`
+	ArchiveRuns          bool              `json:"archiveRuns,omitempty"` // nest each run's output in out/<YYYY-MM-DD_HH-MM>_<run-id>/
+	OutputFormat         string            `json:"outputFormat,omitempty"`
+	EmbedImages          bool              `json:"embedImages,omitempty"`
+	Language             string            `json:"language,omitempty"`
+	SystemPromptExtra    string            `json:"systemPromptExtra,omitempty"`
+	PreferSimpleDiagrams *bool             `json:"preferSimpleDiagrams,omitempty"` // nil means on
+	ImageFormatFallback  bool              `json:"imageFormatFallback,omitempty"`  // retry failed renders as PNG (or SVG)
+	AutoFixMermaid       bool              `json:"autoFixMermaid,omitempty"`       // correct common diagram mistakes before rendering
+	NativeJSON           *bool             `json:"nativeJson,omitempty"`           // use the provider's JSON output mode; nil means on
+	RateLimits           map[string]int    `json:"rateLimits,omitempty"`           // requests per minute by provider
+	EmbeddingModel       string            `json:"embeddingModel,omitempty"`       // for --semantic-filter; empty uses the provider default
+	FileNameTemplate     string            `json:"fileNameTemplate,omitempty"`     // e.g. {{.Type}}-{{.Date}}; see agent.FileNameVariables
+	DisabledTools        []string          `json:"disabledTools,omitempty"`        // agent tools refused on every run
+	UserAgent            string            `json:"userAgent,omitempty"`            // User-Agent of provider requests; empty uses mermaid-agent-documenter/<version>
+	Secrets              map[string]string `json:"secrets,omitempty"`
+	SecretsBackend       string            `json:"secretsBackend,omitempty"`
+	CurrentProject       *ProjectConfig    `json:"currentProject,omitempty"`
+	VertexProject        string            `json:"vertexProject,omitempty"`
+	VertexLocation       string            `json:"vertexLocation,omitempty"`
+	Anthropic            *AnthropicConfig  `json:"anthropic,omitempty"`
+	ConversationWindow   *WindowConfig     `json:"conversationWindow,omitempty"` // nil sends the whole conversation every step
+}
+
+// WindowConfig limits how much conversation history is sent on each step
+type WindowConfig struct {
+	Strategy string `json:"strategy,omitempty"` // full, last-k, or summarize
+	MaxTurns int    `json:"maxTurns,omitempty"` // recent turns sent verbatim; 0 uses the default
+}
+
+// AnthropicConfig sets the headers that select the Anthropic API version and
+// opt in to beta features
+type AnthropicConfig struct {
+	Version string `json:"version,omitempty"` // anthropic-version header; empty uses the built-in default
+	Beta    string `json:"beta,omitempty"`    // comma-separated anthropic-beta values
+}
+
+type LogConfig struct {
+	Level                  string `json:"level"`
+	Redact                 bool   `json:"redact"`
+	StoreChainOfThought    bool   `json:"storeChainOfThought"`
+	MaxLoggedResponseChars int    `json:"maxLoggedResponseChars,omitempty"` // 0 uses the default, negative is unlimited
+	StoreLastTurnOnly      bool   `json:"storeLastTurnOnly,omitempty"`
+	RetentionDays          int    `json:"retentionDays,omitempty"` // days to keep rotated logs; 0 uses the default, negative keeps all
+	PerRunFiles            *bool  `json:"perRunFiles,omitempty"`   // write logs/<run-id>.jsonl; nil means on
+	SharedLog              *bool  `json:"sharedLog,omitempty"`     // append to logs.jsonl; nil means on
+}
+
+type SafetyConfig struct {
+	Mode         string `json:"mode"` // standard, strict, or off; "" is standard
+	PIIRedaction bool   `json:"piiRedaction"`
+}
+
+// Safety modes for SafetyConfig.Mode
+const (
+	SafetyModeStandard = "standard"
+	SafetyModeStrict   = "strict"
+	SafetyModeOff      = "off"
+)
+
+type LimitsConfig struct {
+	MaxSteps               int     `json:"maxSteps"`
+	RunTimeoutSec          int     `json:"runTimeoutSec"`
+	TokenBudget            int     `json:"tokenBudget"`
+	CostCeilingUsd         float64 `json:"costCeilingUsd"`
+	MaxConsecutiveFailures int     `json:"maxConsecutiveFailures,omitempty"`
+	MaxParseRetries        int     `json:"maxParseRetries,omitempty"`
+	MaxTranscriptChars     int     `json:"maxTranscriptChars,omitempty"`
+	MinTranscriptChars     int     `json:"minTranscriptChars,omitempty"` // warn below this length; 0 uses the default, negative disables
+	RequestTimeoutSec      int     `json:"requestTimeoutSec,omitempty"`
+	MaxOutputTokens        int     `json:"maxOutputTokens,omitempty"`        // per response; 0 uses the provider default
+	MaxOutputTokensCeiling int     `json:"maxOutputTokensCeiling,omitempty"` // limit truncated steps are retried up to; 0 uses the default
+	FetchTimeoutSec        int     `json:"fetchTimeoutSec,omitempty"`        // per documentation download attempt; 0 uses the default
+	FetchRetries           int     `json:"fetchRetries,omitempty"`           // documentation download retries; 0 uses the default, negative disables
+	FetchBackoffMs         int     `json:"fetchBackoffMs,omitempty"`         // wait before the first retry, doubled after; 0 uses the default
+}
+
+// TranscriptConfig controls the optional --clean preprocessing of transcripts
+// and how transcripts given as URLs are downloaded
+type TranscriptConfig struct {
+	StripTimestamps  bool   `json:"stripTimestamps"`
+	TimestampPattern string `json:"timestampPattern,omitempty"`
+	StripSpeakers    bool   `json:"stripSpeakers"`
+	SpeakerPattern   string `json:"speakerPattern,omitempty"`
+	BearerToken      string `json:"bearerToken,omitempty"`      // sent when the transcript is an http(s) URL; ${VAR} is expanded
+	MaxDownloadBytes int64  `json:"maxDownloadBytes,omitempty"` // size limit for transcript URLs; 0 uses the default
+}
+
+// defaultModels are the built-in models used for providers without a configured model
+var defaultModels = map[string]string{
+	"openai":    "gpt-5-mini",
+	"anthropic": "claude-3-5-sonnet-20241022",
+	"google":    "gemini-2.5-flash",
+}
+
+// DefaultModel returns the built-in model for a provider, or "" for an unknown provider
+func DefaultModel(provider string) string {
+	return defaultModels[provider]
+}
+
+// builtinModelAliases are the friendly model names available without configuration
+var builtinModelAliases = map[string]string{
+	"sonnet": "claude-3-5-sonnet-20241022",
+	"haiku":  "claude-3-5-haiku-20241022",
+	"gpt4o":  "gpt-4o",
+	"flash":  "gemini-2.5-flash",
+}
+
+// BuiltinModelAliases returns a copy of the built-in model aliases
+func BuiltinModelAliases() map[string]string {
+	aliases := make(map[string]string, len(builtinModelAliases))
+	for alias, model := range builtinModelAliases {
+		aliases[alias] = model
+	}
+	return aliases
+}
+
+// ModelAliasMap returns the built-in aliases merged with the config's own,
+// which take precedence
+func (c *Config) ModelAliasMap() map[string]string {
+	aliases := BuiltinModelAliases()
+	for alias, model := range c.ModelAliases {
+		aliases[strings.ToLower(alias)] = model
+	}
+	return aliases
+}
+
+// ResolveModelAlias returns the provider model ID an alias stands for, or the
+// model unchanged when it is not an alias. Aliases are matched case-insensitively
+// and do not chain.
+func (c *Config) ResolveModelAlias(model string) string {
+	if resolved, ok := c.ModelAliasMap()[strings.ToLower(strings.TrimSpace(model))]; ok {
+		return resolved
+	}
+	return model
+}
+
+// Default returns the configuration used before config.json exists
+func Default() *Config {
+	models := make(map[string]string, len(defaultModels))
+	for provider, model := range defaultModels {
+		models[provider] = model
+	}
+	return &Config{
+		Provider: "openai",
+		Models:   models,
+		Log: LogConfig{
+			Level:               "info",
+			Redact:              true,
+			StoreChainOfThought: false,
+		},
+		Safety: SafetyConfig{
+			Mode:         "standard",
+			PIIRedaction: true,
+		},
+		Limits: LimitsConfig{
+			MaxSteps:               25,
+			RunTimeoutSec:          300,
+			TokenBudget:            100000,
+			CostCeilingUsd:         1.0,
+			MaxConsecutiveFailures: 3,
+			MaxParseRetries:        2,
+			MaxTranscriptChars:     100000,
+			RequestTimeoutSec:      120,
+		},
+		Transcript: TranscriptConfig{
+			StripTimestamps:  true,
+			TimestampPattern: transcript.DefaultTimestampPattern,
+			StripSpeakers:    false,
+			SpeakerPattern:   transcript.DefaultSpeakerPattern,
+		},
+		ConfidenceThreshold: 0.90,
+		OutDir:              "~/mermaid-agent-documenter/output",
+		OutputFormat:        "md",
+	}
+}
+
+// PrefersSimpleDiagrams reports whether diagrams are restricted to sequence and
+// flowchart types. PreferSimpleDiagrams is a pointer so configs written before
+// it existed default to on.
+func (c *Config) PrefersSimpleDiagrams() bool {
+	return c.PreferSimpleDiagrams == nil || *c.PreferSimpleDiagrams
+}
+
+// UsesNativeJSON reports whether providers with a JSON output mode (OpenAI,
+// Gemini) are asked for JSON responses. Configs written before the option
+// existed default to on.
+func (c *Config) UsesNativeJSON() bool {
+	return c.NativeJSON == nil || *c.NativeJSON
+}
+
+// WritesRunFiles reports whether each run writes its step logs to its own
+// logs/<run-id>.jsonl. Configs written before the option existed default to on.
+func (l LogConfig) WritesRunFiles() bool {
+	return l.PerRunFiles == nil || *l.PerRunFiles
+}
+
+// WritesSharedLog reports whether step logs are appended to the shared logs.jsonl
+func (l LogConfig) WritesSharedLog() bool {
+	return l.SharedLog == nil || *l.SharedLog
+}
+
+// Strict reports whether strict safety mode is on
+func (s SafetyConfig) Strict() bool {
+	return strings.EqualFold(s.Mode, SafetyModeStrict)
+}
+
+// RedactsPII reports whether transcripts are redacted before they are sent to
+// the model: always in strict mode, never when safety is off, and otherwise
+// as piiRedaction says
+func (s SafetyConfig) RedactsPII() bool {
+	switch strings.ToLower(s.Mode) {
+	case SafetyModeStrict:
+		return true
+	case SafetyModeOff:
+		return false
+	}
+	return s.PIIRedaction
+}
+
+// Dir returns ~/mermaid-agent-documenter
+func Dir() string {
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, DirName)
+}
+
+// Path returns the location of config.json
+func Path() string {
+	return filepath.Join(Dir(), FileName)
+}
+
+// Load reads config.json, returning the defaults when it does not exist
+func Load() (*Config, error) {
+	data, err := os.ReadFile(Path())
+	if os.IsNotExist(err) {
+		return Default(), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var config Config
+	err = json.Unmarshal(data, &config)
+	config.normalizeModelAliases()
+	return &config, err
+}
+
+// normalizeModelAliases lowercases the alias names in a hand-edited config so
+// they match the case-insensitive lookups. An alias that is already lowercase
+// wins over a differently cased duplicate.
+func (c *Config) normalizeModelAliases() {
+	for alias, model := range c.ModelAliases {
+		lower := strings.ToLower(alias)
+		if lower == alias {
+			continue
+		}
+		delete(c.ModelAliases, alias)
+		if _, ok := c.ModelAliases[lower]; !ok {
+			c.ModelAliases[lower] = model
+		}
+	}
+}
+
+// Save writes config.json, creating the config directory if needed
+func Save(config *Config) error {
+	if err := os.MkdirAll(Dir(), 0755); err != nil {
+		return fmt.Errorf("failed to create config dir: %w", err)
+	}
+	return WriteFile(Path(), config)
+}
+
+// WriteFile writes config as indented JSON with FileMode. The mode is also
+// applied to an existing file, since os.WriteFile keeps its old mode.
+func WriteFile(path string, config *Config) error {
+	data, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal config: %w", err)
+	}
+	if err := os.WriteFile(path, data, FileMode); err != nil {
+		return err
+	}
+	return os.Chmod(path, FileMode)
+}
+
+// CurrentProjectRoot returns the current project's root directory, or "" when
+// no project is set or the config cannot be read
+func CurrentProjectRoot() string {
+	config, err := Load()
+	if err != nil || config.CurrentProject == nil {
+		return ""
+	}
+	return config.CurrentProject.RootDir
+}
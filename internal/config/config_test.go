@@ -0,0 +1,166 @@
+package config
+
+import (
+	"os"
+	"testing"
+)
+
+func TestLoad_DefaultsWithoutConfigFile(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	config, err := Load()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if config.Provider != "openai" || config.Limits.MaxSteps != 25 {
+		t.Errorf("Expected the default config, got provider %q and maxSteps %d", config.Provider, config.Limits.MaxSteps)
+	}
+	if CurrentProjectRoot() != "" {
+		t.Errorf("Expected no current project, got %q", CurrentProjectRoot())
+	}
+}
+
+func TestSave_RoundTripsWithOwnerOnlyMode(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	// An older config written with a looser mode is tightened on save
+	if err := os.MkdirAll(Dir(), 0755); err != nil {
+		t.Fatalf("Failed to create config dir: %v", err)
+	}
+	if err := os.WriteFile(Path(), []byte(`{"provider":"google"}`), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	config, err := Load()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	config.CurrentProject = &ProjectConfig{Name: "shop", RootDir: "/work/shop"}
+	if err := Save(config); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	info, err := os.Stat(Path())
+	if err != nil {
+		t.Fatalf("Failed to stat config: %v", err)
+	}
+	if info.Mode().Perm() != FileMode {
+		t.Errorf("Expected mode %v, got %v", FileMode, info.Mode().Perm())
+	}
+	if got := CurrentProjectRoot(); got != "/work/shop" {
+		t.Errorf("Expected current project root /work/shop, got %q", got)
+	}
+}
+
+func TestLoad_LowercasesModelAliases(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	// Aliases added by hand may use any case; lookups and removal use lowercase
+	if err := os.MkdirAll(Dir(), 0755); err != nil {
+		t.Fatalf("Failed to create config dir: %v", err)
+	}
+	data := `{"modelAliases":{"Fast":"gpt-5-nano","Deep":"o3","deep":"o3-pro"}}`
+	if err := os.WriteFile(Path(), []byte(data), FileMode); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	config, err := Load()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := map[string]string{"fast": "gpt-5-nano", "deep": "o3-pro"}
+	if len(config.ModelAliases) != len(want) {
+		t.Fatalf("Expected aliases %v, got %v", want, config.ModelAliases)
+	}
+	for alias, model := range want {
+		if config.ModelAliases[alias] != model {
+			t.Errorf("Expected alias %q to be %q, got %q", alias, model, config.ModelAliases[alias])
+		}
+	}
+}
+
+func TestPrefersSimpleDiagrams(t *testing.T) {
+	disabled := false
+	if !(&Config{}).PrefersSimpleDiagrams() {
+		t.Error("Expected simple diagrams to default to on")
+	}
+	if (&Config{PreferSimpleDiagrams: &disabled}).PrefersSimpleDiagrams() {
+		t.Error("Expected simple diagrams to be off when disabled")
+	}
+}
+
+func TestUsesNativeJSON(t *testing.T) {
+	disabled := false
+	if !(&Config{}).UsesNativeJSON() {
+		t.Error("Expected native JSON mode to default to on")
+	}
+	if (&Config{NativeJSON: &disabled}).UsesNativeJSON() {
+		t.Error("Expected native JSON mode to be off when disabled")
+	}
+}
+
+func TestSafetyConfig_RedactsPII(t *testing.T) {
+	tests := []struct {
+		safety SafetyConfig
+		want   bool
+	}{
+		{SafetyConfig{Mode: SafetyModeStandard, PIIRedaction: true}, true},
+		{SafetyConfig{Mode: SafetyModeStandard}, false},
+		{SafetyConfig{PIIRedaction: true}, true},
+		{SafetyConfig{Mode: "Strict"}, true},
+		{SafetyConfig{Mode: SafetyModeOff, PIIRedaction: true}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.safety.RedactsPII(); got != tt.want {
+			t.Errorf("%+v: expected RedactsPII %v, got %v", tt.safety, tt.want, got)
+		}
+	}
+	if !(SafetyConfig{Mode: "STRICT"}).Strict() || (SafetyConfig{Mode: SafetyModeStandard}).Strict() {
+		t.Error("Expected Strict to match the strict mode case-insensitively")
+	}
+}
+
+func TestDefaultModel(t *testing.T) {
+	for _, provider := range []string{"openai", "anthropic", "google"} {
+		if DefaultModel(provider) == "" {
+			t.Errorf("Expected a built-in model for %s", provider)
+		}
+	}
+	if DefaultModel("mistral") != "" {
+		t.Error("Expected no default for an unknown provider")
+	}
+	// Anthropic model IDs use dashes and a release date
+	if got := DefaultModel("anthropic"); got != "claude-3-5-sonnet-20241022" {
+		t.Errorf("Expected a valid Anthropic model ID, got %q", got)
+	}
+
+	config := Default()
+	config.Models["openai"] = "gpt-4o"
+	if DefaultModel("openai") == "gpt-4o" {
+		t.Error("Expected changes to a config's models to leave the defaults alone")
+	}
+}
+
+func TestResolveModelAlias(t *testing.T) {
+	config := Default()
+	if got := config.ResolveModelAlias("sonnet"); got != "claude-3-5-sonnet-20241022" {
+		t.Errorf("Expected the built-in sonnet alias, got %q", got)
+	}
+	if got := config.ResolveModelAlias("Flash"); got != "gemini-2.5-flash" {
+		t.Errorf("Expected aliases to match case-insensitively, got %q", got)
+	}
+	if got := config.ResolveModelAlias("gpt-5"); got != "gpt-5" {
+		t.Errorf("Expected model IDs to pass through, got %q", got)
+	}
+
+	config.ModelAliases = map[string]string{"sonnet": "claude-sonnet-4-20250514", "Fast": "gpt-5-nano"}
+	if got := config.ResolveModelAlias("sonnet"); got != "claude-sonnet-4-20250514" {
+		t.Errorf("Expected a configured alias to override the built-in one, got %q", got)
+	}
+	if got := config.ResolveModelAlias("fast"); got != "gpt-5-nano" {
+		t.Errorf("Expected the configured alias, got %q", got)
+	}
+	if BuiltinModelAliases()["sonnet"] != "claude-3-5-sonnet-20241022" {
+		t.Error("Expected configured aliases to leave the built-ins alone")
+	}
+}
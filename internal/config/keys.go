@@ -0,0 +1,253 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// restrictedKeys are managed by dedicated commands rather than Get and Set
+var restrictedKeys = map[string]string{
+	"secrets":        "mad config secrets set",
+	"secretsbackend": "mad config secrets set --backend",
+	"currentproject": "mad config project set",
+}
+
+// Get returns the value at a dotted key such as "limits.maxSteps" or
+// "models.openai". Keys are the JSON field names, matched case-insensitively.
+func Get(c *Config, key string) (interface{}, error) {
+	segments, err := splitKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	v := reflect.ValueOf(c).Elem()
+	for _, segment := range segments {
+		for v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return nil, nil
+			}
+			v = v.Elem()
+		}
+		switch v.Kind() {
+		case reflect.Struct:
+			field, ok := fieldByJSONName(v, segment)
+			if !ok {
+				return nil, unknownKeyError(key)
+			}
+			v = field
+		case reflect.Map:
+			value := v.MapIndex(reflect.ValueOf(segment))
+			if !value.IsValid() {
+				return nil, nil
+			}
+			v = value
+		default:
+			return nil, unknownKeyError(key)
+		}
+	}
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil, nil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() == reflect.Map && v.IsNil() {
+		return nil, nil
+	}
+	return v.Interface(), nil
+}
+
+// Set parses value for the setting at a dotted key and stores it, returning
+// the parsed value. Only single values can be set: strings, booleans, whole
+// numbers, numbers, comma-separated lists of strings, and entries of maps of
+// those (e.g. "models.openai").
+func Set(c *Config, key, value string) (interface{}, error) {
+	segments, err := splitKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	v := reflect.ValueOf(c).Elem()
+	for i, segment := range segments {
+		v = allocate(v)
+		switch v.Kind() {
+		case reflect.Struct:
+			field, ok := fieldByJSONName(v, segment)
+			if !ok {
+				return nil, unknownKeyError(key)
+			}
+			v = field
+		case reflect.Map:
+			if i != len(segments)-1 {
+				return nil, unknownKeyError(key)
+			}
+			parsed, err := parseValue(v.Type().Elem(), key, value)
+			if err != nil {
+				return nil, err
+			}
+			if v.IsNil() {
+				v.Set(reflect.MakeMap(v.Type()))
+			}
+			v.SetMapIndex(reflect.ValueOf(segment), parsed)
+			return parsed.Interface(), nil
+		default:
+			return nil, unknownKeyError(key)
+		}
+	}
+
+	if kind := allocate(v).Kind(); kind == reflect.Struct || kind == reflect.Map {
+		return nil, fmt.Errorf("'%s' is a group of settings; set one of its keys, e.g. %s", key, firstKeyUnder(key))
+	}
+	parsed, err := parseValue(v.Type(), key, value)
+	if err != nil {
+		return nil, err
+	}
+	v.Set(parsed)
+	return reflect.Indirect(parsed).Interface(), nil
+}
+
+// Keys lists the dotted keys that Set accepts, with <name> standing for map
+// entries such as models.<provider>
+func Keys() []string {
+	var keys []string
+	collectKeys(reflect.TypeOf(Config{}), "", &keys)
+	sort.Strings(keys)
+	return keys
+}
+
+// collectKeys appends the settable keys of a struct type under prefix
+func collectKeys(t reflect.Type, prefix string, keys *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		name := jsonName(t.Field(i))
+		if name == "" || restrictedKeys[strings.ToLower(name)] != "" {
+			continue
+		}
+		key := prefix + name
+		fieldType := t.Field(i).Type
+		if fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+		switch fieldType.Kind() {
+		case reflect.Struct:
+			collectKeys(fieldType, key+".", keys)
+		case reflect.Map:
+			*keys = append(*keys, key+".<name>")
+		default:
+			*keys = append(*keys, key)
+		}
+	}
+}
+
+// splitKey splits a dotted key and rejects keys with dedicated commands
+func splitKey(key string) ([]string, error) {
+	segments := strings.Split(key, ".")
+	for _, segment := range segments {
+		if segment == "" {
+			return nil, fmt.Errorf("invalid config key '%s'", key)
+		}
+	}
+	if command, ok := restrictedKeys[strings.ToLower(segments[0])]; ok {
+		return nil, fmt.Errorf("'%s' cannot be accessed here; use '%s'", segments[0], command)
+	}
+	return segments, nil
+}
+
+// allocate dereferences pointers to structs, creating them when nil
+func allocate(v reflect.Value) reflect.Value {
+	for v.Kind() == reflect.Ptr && v.Type().Elem().Kind() == reflect.Struct {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
+// fieldByJSONName finds the struct field whose JSON name matches name
+func fieldByJSONName(v reflect.Value, name string) (reflect.Value, bool) {
+	for i := 0; i < v.NumField(); i++ {
+		if strings.EqualFold(jsonName(v.Type().Field(i)), name) {
+			return v.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+// jsonName returns the field's name in config.json, or "" if it is not serialized
+func jsonName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "-" {
+		return ""
+	}
+	return name
+}
+
+// parseValue converts raw to a value of type t, reporting the expected type on error
+func parseValue(t reflect.Type, key, raw string) (reflect.Value, error) {
+	if t.Kind() == reflect.Ptr {
+		elem, err := parseValue(t.Elem(), key, raw)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		ptr := reflect.New(t.Elem())
+		ptr.Elem().Set(elem)
+		return ptr, nil
+	}
+
+	v := reflect.New(t).Elem()
+	switch t.Kind() {
+	case reflect.String:
+		v.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("%s must be true or false, got '%s'", key, raw)
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("%s must be a whole number, got '%s'", key, raw)
+		}
+		v.SetInt(n)
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("%s must be a number, got '%s'", key, raw)
+		}
+		v.SetFloat(f)
+	case reflect.Slice:
+		if t.Elem().Kind() != reflect.String {
+			return reflect.Value{}, fmt.Errorf("%s cannot be set from the command line", key)
+		}
+		var items []string
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		v.Set(reflect.ValueOf(items))
+	default:
+		return reflect.Value{}, fmt.Errorf("%s cannot be set from the command line", key)
+	}
+	return v, nil
+}
+
+// unknownKeyError names a key that does not exist in the config
+func unknownKeyError(key string) error {
+	return fmt.Errorf("unknown config key '%s' (see 'mad config get' for the list of keys)", key)
+}
+
+// firstKeyUnder returns the first settable key below a group, for error messages
+func firstKeyUnder(group string) string {
+	prefix := strings.ToLower(group) + "."
+	for _, key := range Keys() {
+		if strings.HasPrefix(strings.ToLower(key), prefix) {
+			return key
+		}
+	}
+	return group + ".<key>"
+}
@@ -0,0 +1,116 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGet(t *testing.T) {
+	config := Default()
+
+	cases := map[string]interface{}{
+		"limits.maxSteps":      25,
+		"LIMITS.MAXSTEPS":      25,
+		"confidenceThreshold":  0.90,
+		"log.level":            "info",
+		"models.google":        "gemini-2.5-flash",
+		"models.unknown":       nil,
+		"preferSimpleDiagrams": nil,
+	}
+	for key, expected := range cases {
+		value, err := Get(config, key)
+		if err != nil {
+			t.Errorf("Get(%s) failed: %v", key, err)
+			continue
+		}
+		if value != expected {
+			t.Errorf("Get(%s) = %v, expected %v", key, value, expected)
+		}
+	}
+
+	if _, err := Get(config, "limits.nope"); err == nil || !strings.Contains(err.Error(), "unknown config key") {
+		t.Errorf("Expected an unknown key error, got %v", err)
+	}
+	if _, err := Get(config, "secrets.openai"); err == nil || !strings.Contains(err.Error(), "mad config secrets set") {
+		t.Errorf("Expected secrets to point at the secrets command, got %v", err)
+	}
+}
+
+func TestSet(t *testing.T) {
+	config := Default()
+
+	if value, err := Set(config, "limits.maxSteps", "40"); err != nil || value != 40 || config.Limits.MaxSteps != 40 {
+		t.Errorf("Expected maxSteps 40, got %v (%v)", config.Limits.MaxSteps, err)
+	}
+	if _, err := Set(config, "confidenceThreshold", "0.85"); err != nil || config.ConfidenceThreshold != 0.85 {
+		t.Errorf("Expected threshold 0.85, got %v (%v)", config.ConfidenceThreshold, err)
+	}
+	if _, err := Set(config, "preferSimpleDiagrams", "false"); err != nil || config.PrefersSimpleDiagrams() {
+		t.Errorf("Expected simple diagrams off, got %v (%v)", config.PrefersSimpleDiagrams(), err)
+	}
+	if _, err := Set(config, "rateLimits.openai", "60"); err != nil || config.RateLimits["openai"] != 60 {
+		t.Errorf("Expected a new rate limit map entry, got %v (%v)", config.RateLimits, err)
+	}
+	if _, err := Set(config, "disabledTools", "fetchMermaidDocumentation, getUserInput,"); err != nil || strings.Join(config.DisabledTools, "|") != "fetchMermaidDocumentation|getUserInput" {
+		t.Errorf("Expected a comma-separated list, got %q (%v)", config.DisabledTools, err)
+	}
+
+	failures := map[string]string{
+		"limits.maxSteps=many":    "must be a whole number",
+		"log.redact=maybe":        "must be true or false",
+		"confidenceThreshold=hi":  "must be a number",
+		"limits=5":                "group of settings",
+		"nope=1":                  "unknown config key",
+		"currentProject.name=x":   "mad config project set",
+		"limits.maxSteps.extra=1": "unknown config key",
+	}
+	for input, message := range failures {
+		key, value, _ := strings.Cut(input, "=")
+		if _, err := Set(config, key, value); err == nil || !strings.Contains(err.Error(), message) {
+			t.Errorf("Set(%s, %s): expected error containing %q, got %v", key, value, message, err)
+		}
+	}
+	if config.Limits.MaxSteps != 40 {
+		t.Errorf("Expected failed sets to leave the config unchanged, got maxSteps %d", config.Limits.MaxSteps)
+	}
+}
+
+func TestKeys(t *testing.T) {
+	keys := strings.Join(Keys(), " ")
+	for _, expected := range []string{"limits.maxSteps", "log.level", "safety.mode", "models.<name>", "transcript.stripSpeakers"} {
+		if !strings.Contains(keys, expected) {
+			t.Errorf("Expected %s in keys: %s", expected, keys)
+		}
+	}
+	if strings.Contains(keys, "secrets") || strings.Contains(keys, "currentProject") {
+		t.Errorf("Expected restricted keys to be left out: %s", keys)
+	}
+}
+
+func TestValidateConfidenceThreshold(t *testing.T) {
+	for _, valid := range []float64{0, 0.5, 0.9, 1} {
+		if err := ValidateConfidenceThreshold(valid); err != nil {
+			t.Errorf("Expected %v to be valid, got %v", valid, err)
+		}
+	}
+	for _, raw := range []string{"5", "-1", "1.01", "NaN"} {
+		config := Default()
+		if _, err := Set(config, "confidenceThreshold", raw); err != nil {
+			t.Fatalf("Set failed: %v", err)
+		}
+		if err := ValidateConfidenceThreshold(config.ConfidenceThreshold); err == nil || !strings.Contains(err.Error(), "between 0 and 1") {
+			t.Errorf("Expected %s to be rejected, got %v", raw, err)
+		}
+	}
+}
+
+func TestValidateSafetyMode(t *testing.T) {
+	for _, valid := range []string{"", "standard", "strict", "Off"} {
+		if err := ValidateSafetyMode(valid); err != nil {
+			t.Errorf("Expected %q to be valid, got %v", valid, err)
+		}
+	}
+	if err := ValidateSafetyMode("paranoid"); err == nil {
+		t.Error("Expected an unknown safety mode to be rejected")
+	}
+}
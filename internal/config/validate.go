@@ -0,0 +1,26 @@
+package config
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// ValidateConfidenceThreshold rejects thresholds outside [0, 1]. Confidences
+// are reported between 0 and 1, so a higher threshold rejects every tool call
+// and final manifest and the run loops until it hits maxSteps.
+func ValidateConfidenceThreshold(threshold float64) error {
+	if math.IsNaN(threshold) || threshold < 0 || threshold > 1 {
+		return fmt.Errorf("confidenceThreshold must be between 0 and 1 (e.g. 0.85), got %v", threshold)
+	}
+	return nil
+}
+
+// ValidateSafetyMode rejects modes other than standard, strict, and off ("" is standard)
+func ValidateSafetyMode(mode string) error {
+	switch strings.ToLower(mode) {
+	case "", SafetyModeStandard, SafetyModeStrict, SafetyModeOff:
+		return nil
+	}
+	return fmt.Errorf("safety.mode must be %s, %s, or %s, got '%s'", SafetyModeStandard, SafetyModeStrict, SafetyModeOff, mode)
+}
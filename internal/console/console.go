@@ -0,0 +1,211 @@
+// Package console prints CLI output, replacing emoji and box-drawing
+// characters with plain ASCII markers when ASCII mode is on.
+package console
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf8"
+)
+
+// asciiMode is set by SetASCII
+var asciiMode bool
+
+// output is where status lines go, set by SetOutput; nil means stdout
+var output io.Writer
+
+// input buffers stdin for ReadLine, set by SetInput
+var input = bufio.NewReader(os.Stdin)
+
+// markers replace the emoji that carry meaning; other emoji are dropped
+var markers = map[string]string{
+	"✅":  "[OK]",
+	"❌":  "[FAIL]",
+	"⚠️": "[WARN]",
+	"⚠":  "[WARN]",
+	"ℹ️": "[INFO]",
+	"ℹ":  "[INFO]",
+	"💡":  "[TIP]",
+	"⏭️": "[SKIP]",
+	"⏭":  "[SKIP]",
+	"⏹️": "[STOP]",
+	"⏹":  "[STOP]",
+	"❓":  "[?]",
+}
+
+// symbols replace punctuation and box-drawing characters
+var symbols = map[rune]string{
+	'→': "->",
+	'•': "*",
+	'·': "-",
+	'○': "o",
+	'–': "-",
+	'—': "--",
+	'…': "...",
+	'━': "-",
+	'═': "=",
+	'│': "|",
+	'█': "#",
+}
+
+// SetASCII turns ASCII mode on or off for all later output
+func SetASCII(enabled bool) {
+	asciiMode = enabled
+}
+
+// SetOutput sends later status lines (Printf, Println, Print) to w, for
+// example stderr when stdout carries a JSON report. Data (Dataf, Dataln)
+// still goes to stdout. A nil w restores stdout.
+func SetOutput(w io.Writer) {
+	output = w
+}
+
+// Output returns the writer status lines go to
+func Output() io.Writer {
+	if output == nil {
+		return os.Stdout
+	}
+	return output
+}
+
+// SetInput makes ReadLine read from r instead of stdin. A nil r restores stdin.
+func SetInput(r io.Reader) {
+	if r == nil {
+		r = os.Stdin
+	}
+	input = bufio.NewReader(r)
+}
+
+// ReadLine reads a line of input without surrounding whitespace. Every prompt
+// reads through the same buffered reader, so input typed ahead or piped in for
+// one prompt is still there for the next.
+func ReadLine() (string, error) {
+	line, err := input.ReadString('\n')
+	return strings.TrimSpace(line), err
+}
+
+// ASCII reports whether ASCII mode is on
+func ASCII() bool {
+	return asciiMode
+}
+
+// WantsASCII reports whether output should be ASCII without an explicit flag:
+// when NO_COLOR is set (see https://no-color.org) or stdout is not a terminal
+func WantsASCII() bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return true
+	}
+	info, err := os.Stdout.Stat()
+	if err != nil {
+		return true
+	}
+	return info.Mode()&os.ModeCharDevice == 0
+}
+
+// Printf formats like fmt.Printf and writes to Output()
+func Printf(format string, a ...any) (int, error) {
+	return io.WriteString(Output(), Text(fmt.Sprintf(format, a...)))
+}
+
+// Println formats like fmt.Println and writes to Output()
+func Println(a ...any) (int, error) {
+	return io.WriteString(Output(), Text(fmt.Sprintln(a...)))
+}
+
+// Print formats like fmt.Print and writes to Output()
+func Print(a ...any) (int, error) {
+	return io.WriteString(Output(), Text(fmt.Sprint(a...)))
+}
+
+// Dataf formats like fmt.Printf and writes to stdout unchanged, even in ASCII
+// mode. Use it for a command's data (config values, diffs, log entries) so
+// piped output is byte-for-byte what was stored; status lines use Printf.
+func Dataf(format string, a ...any) (int, error) {
+	return fmt.Fprintf(os.Stdout, format, a...)
+}
+
+// Dataln formats like fmt.Println and writes to stdout unchanged (see Dataf)
+func Dataln(a ...any) (int, error) {
+	return fmt.Fprintln(os.Stdout, a...)
+}
+
+// Fprintf formats like fmt.Fprintf and writes to w. Use it for terminal output
+// only; files should get their content unchanged.
+func Fprintf(w io.Writer, format string, a ...any) (int, error) {
+	return io.WriteString(w, Text(fmt.Sprintf(format, a...)))
+}
+
+// Text returns s unchanged, or in ASCII mode with meaningful emoji replaced by
+// markers such as [OK] and [FAIL], box-drawing characters and arrows by ASCII
+// equivalents, and other emoji removed. Accented letters are kept.
+func Text(s string) string {
+	if !asciiMode {
+		return s
+	}
+	return ToASCII(s)
+}
+
+// ToASCII applies the ASCII mode replacements to s regardless of the mode
+func ToASCII(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); {
+		if marker, size := matchMarker(s[i:]); size > 0 {
+			b.WriteString(marker)
+			i += size
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(s[i:])
+		i += size
+		switch {
+		case symbols[r] != "":
+			b.WriteString(symbols[r])
+		case isEmoji(r):
+			// Drop decorative emoji along with the space that separated them from the text
+			for i < len(s) {
+				next, nextSize := utf8.DecodeRuneInString(s[i:])
+				if next != '\uFE0F' && next != '\u200D' && !isEmoji(next) {
+					break
+				}
+				i += nextSize
+			}
+			for i < len(s) && s[i] == ' ' {
+				i++
+			}
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// matchMarker returns the marker for an emoji at the start of s and its length
+func matchMarker(s string) (string, int) {
+	best, bestSize := "", 0
+	for emoji, marker := range markers {
+		if len(emoji) > bestSize && strings.HasPrefix(s, emoji) {
+			best, bestSize = marker, len(emoji)
+		}
+	}
+	return best, bestSize
+}
+
+// isEmoji reports whether r is a pictograph, dingbat, spinner frame, or emoji modifier
+func isEmoji(r rune) bool {
+	switch {
+	case r >= 0x1F000 && r <= 0x1FAFF: // pictographs, emoticons, transport, supplemental symbols
+		return true
+	case r >= 0x2600 && r <= 0x27BF: // miscellaneous symbols and dingbats
+		return true
+	case r >= 0x2300 && r <= 0x23FF: // miscellaneous technical (⏳, ⏹)
+		return true
+	case r >= 0x2800 && r <= 0x28FF: // braille spinner frames
+		return true
+	case r >= 0x2B00 && r <= 0x2BFF, r == 0xFE0F, r == 0x200D:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,113 @@
+package console
+
+import (
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestToASCII(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"✅ Tool completed successfully\n", "[OK] Tool completed successfully\n"},
+		{"❌ writeFileContents failed (1/3 in a row)", "[FAIL] writeFileContents failed (1/3 in a row)"},
+		{"⚠️  Could not save", "[WARN]  Could not save"},
+		{"ℹ️  No model is set", "[INFO]  No model is set"},
+		{"🤖 Step 2/25", "Step 2/25"},
+		{"🗂️  3 runs", "3 runs"},
+		{"sonnet → claude-3-5-sonnet-20241022", "sonnet -> claude-3-5-sonnet-20241022"},
+		{"━━━ Segment 1 ━━━", "--- Segment 1 ---"},
+		{"⠋ Waiting for model", "Waiting for model"},
+		{"Documentación en español", "Documentación en español"},
+	}
+	for _, test := range tests {
+		if got := ToASCII(test.in); got != test.want {
+			t.Errorf("ToASCII(%q) = %q, want %q", test.in, got, test.want)
+		}
+	}
+}
+
+func TestText(t *testing.T) {
+	t.Cleanup(func() { SetASCII(false) })
+
+	if got := Text("✅ done"); got != "✅ done" {
+		t.Errorf("Expected text to be unchanged outside ASCII mode, got %q", got)
+	}
+	SetASCII(true)
+	if !ASCII() {
+		t.Fatal("Expected ASCII mode to be on")
+	}
+	if got := Text("✅ done"); got != "[OK] done" {
+		t.Errorf("Expected a marker in ASCII mode, got %q", got)
+	}
+}
+
+func TestWantsASCII(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+	if !WantsASCII() {
+		t.Error("Expected NO_COLOR to turn on ASCII mode")
+	}
+}
+
+func TestDataIsUnchangedWhenPiped(t *testing.T) {
+	t.Cleanup(func() { SetASCII(false) })
+	SetASCII(true)
+
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Failed to create pipe: %v", err)
+	}
+	stdout := os.Stdout
+	os.Stdout = writer
+	t.Cleanup(func() { os.Stdout = stdout })
+
+	value := "Use → arrows — and ✅ ━━ é"
+	Dataf("%s = %s\n", "systemPromptExtra", value)
+	Dataln(value)
+	Printf("✅ saved\n")
+	writer.Close()
+	os.Stdout = stdout
+
+	got, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("Failed to read pipe: %v", err)
+	}
+	want := "systemPromptExtra = " + value + "\n" + value + "\n[OK] saved\n"
+	if string(got) != want {
+		t.Errorf("Expected data to be written byte-for-byte and status lines in ASCII, got:\n%q\nwant:\n%q", got, want)
+	}
+}
+
+func TestSetOutputMovesStatusLinesOnly(t *testing.T) {
+	var status strings.Builder
+	SetOutput(&status)
+	t.Cleanup(func() { SetOutput(nil) })
+
+	Printf("%s\n", "running")
+	Println("done")
+	if status.String() != "running\ndone\n" {
+		t.Errorf("Expected status lines on the configured output, got %q", status.String())
+	}
+
+	SetOutput(nil)
+	if Output() != os.Stdout {
+		t.Error("Expected a nil output to restore stdout")
+	}
+}
+
+func TestReadLineKeepsBufferedInput(t *testing.T) {
+	SetInput(strings.NewReader("  docs please \nyes\n"))
+	t.Cleanup(func() { SetInput(nil) })
+
+	// Both lines arrive in one read; the second prompt still gets its answer
+	for _, want := range []string{"docs please", "yes"} {
+		if got, err := ReadLine(); err != nil || got != want {
+			t.Errorf("Expected %q, got %q (%v)", want, got, err)
+		}
+	}
+	if _, err := ReadLine(); err == nil {
+		t.Error("Expected an error once the input is exhausted")
+	}
+}
@@ -0,0 +1,146 @@
+// Package diff produces line-based unified diffs of text files.
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultContext is the number of unchanged lines shown around each change
+const DefaultContext = 3
+
+// opKind identifies whether a line is kept, removed, or added
+type opKind int
+
+const (
+	opEqual opKind = iota
+	opDelete
+	opInsert
+)
+
+// op is one line of an edit script
+type op struct {
+	kind opKind
+	line string
+	a, b int // line indexes in a and b (before the op for inserts/deletes)
+}
+
+// splitLines splits text into lines without their terminators
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
+
+// editScript returns the shortest line edit script turning a into b, using
+// the longest common subsequence of lines
+func editScript(a, b []string) []op {
+	// lcs[i][j] is the LCS length of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var ops []op
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			ops = append(ops, op{kind: opEqual, line: a[i], a: i, b: j})
+			i++
+			j++
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			// Prefer deletions so removed lines are listed before their replacements
+			ops = append(ops, op{kind: opDelete, line: a[i], a: i, b: j})
+			i++
+		default:
+			ops = append(ops, op{kind: opInsert, line: b[j], a: i, b: j})
+			j++
+		}
+	}
+	return ops
+}
+
+// Unified returns a unified diff between two texts, or "" when they are equal
+func Unified(fromName, toName, a, b string, context int) string {
+	ops := editScript(splitLines(a), splitLines(b))
+
+	// Group changes that are within 2*context lines of each other into hunks
+	var hunks [][2]int
+	for i := 0; i < len(ops); i++ {
+		if ops[i].kind == opEqual {
+			continue
+		}
+		start := max(i-context, 0)
+		end := i
+		for end < len(ops) {
+			if ops[end].kind != opEqual {
+				end++
+				continue
+			}
+			run := end
+			for run < len(ops) && ops[run].kind == opEqual {
+				run++
+			}
+			if run == len(ops) || run-end > 2*context {
+				end = min(end+context, len(ops))
+				break
+			}
+			end = run
+		}
+		hunks = append(hunks, [2]int{start, end})
+		i = end - 1
+	}
+	if len(hunks) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "--- %s\n+++ %s\n", fromName, toName)
+	for _, hunk := range hunks {
+		lines := ops[hunk[0]:hunk[1]]
+		aStart, bStart := lines[0].a, lines[0].b
+		aCount, bCount := 0, 0
+		for _, line := range lines {
+			if line.kind != opInsert {
+				aCount++
+			}
+			if line.kind != opDelete {
+				bCount++
+			}
+		}
+		fmt.Fprintf(&sb, "@@ -%s +%s @@\n", hunkRange(aStart, aCount), hunkRange(bStart, bCount))
+		for _, line := range lines {
+			switch line.kind {
+			case opEqual:
+				sb.WriteString(" " + line.line + "\n")
+			case opDelete:
+				sb.WriteString("-" + line.line + "\n")
+			case opInsert:
+				sb.WriteString("+" + line.line + "\n")
+			}
+		}
+	}
+	return sb.String()
+}
+
+// hunkRange formats a hunk's start line and length the way diff -u does
+func hunkRange(start, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", start+1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
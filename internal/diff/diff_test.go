@@ -0,0 +1,43 @@
+package diff
+
+import "testing"
+
+func TestUnified(t *testing.T) {
+	tests := []struct {
+		name     string
+		a, b     string
+		expected string
+	}{
+		{
+			name: "equal",
+			a:    "one\ntwo\n",
+			b:    "one\ntwo\n",
+		},
+		{
+			name:     "changed_line",
+			a:        "sequenceDiagram\n  User->>App: login\n  App->>DB: query\n",
+			b:        "sequenceDiagram\n  User->>App: sign in\n  App->>DB: query\n",
+			expected: "--- a.md\n+++ b.md\n@@ -1,3 +1,3 @@\n sequenceDiagram\n-  User->>App: login\n+  User->>App: sign in\n   App->>DB: query\n",
+		},
+		{
+			name:     "added_file",
+			a:        "",
+			b:        "# Title\n",
+			expected: "--- a.md\n+++ b.md\n@@ -0,0 +1 @@\n+# Title\n",
+		},
+		{
+			name:     "separate_hunks",
+			a:        "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n",
+			b:        "one\n2\n3\n4\n5\n6\n7\n8\n9\nten\n",
+			expected: "--- a.md\n+++ b.md\n@@ -1,2 +1,2 @@\n-1\n+one\n 2\n@@ -9,2 +9,2 @@\n 9\n-10\n+ten\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Unified("a.md", "b.md", tt.a, tt.b, 1); got != tt.expected {
+				t.Errorf("Unexpected diff:\n%s\nexpected:\n%s", got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+// Package jsonl appends JSON Lines records to log files.
+package jsonl
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// mu serializes appends within the process so concurrent tool calls or agents
+// sharing a logs directory cannot interleave partial lines
+var mu sync.Mutex
+
+// AppendLine appends line and a trailing newline to path, creating the file if
+// needed. The record is written with a single O_APPEND write, so separate
+// processes appending to the same file also keep whole lines on local filesystems.
+func AppendLine(path string, line []byte) error {
+	record := make([]byte, 0, len(line)+1)
+	record = append(record, line...)
+	record = append(record, '\n')
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := file.Write(record); err != nil {
+		return fmt.Errorf("failed to write to log file: %w", err)
+	}
+	return nil
+}
+
+// Append marshals v as JSON and appends it to path as one line
+func Append(path string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to marshal log entry: %w", err)
+	}
+	return AppendLine(path, data)
+}
@@ -0,0 +1,61 @@
+package jsonl
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestAppend_Concurrent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs.jsonl")
+	payload := strings.Repeat("x", 8192) // larger than a pipe buffer
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if err := Append(path, map[string]interface{}{"step": i, "payload": payload}); err != nil {
+				t.Errorf("Append failed: %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Failed to open log: %v", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	seen := map[int]bool{}
+	for scanner.Scan() {
+		var entry struct {
+			Step    int    `json:"step"`
+			Payload string `json:"payload"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			t.Fatalf("Corrupted line: %v", err)
+		}
+		if entry.Payload != payload {
+			t.Fatalf("Line %d has a truncated payload", entry.Step)
+		}
+		seen[entry.Step] = true
+	}
+	if len(seen) != 50 {
+		t.Errorf("Expected 50 distinct lines, got %d", len(seen))
+	}
+}
+
+func TestAppend_Unmarshalable(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs.jsonl")
+	if err := Append(path, map[string]interface{}{"bad": make(chan int)}); err == nil {
+		t.Error("Expected error for a value that cannot be marshaled")
+	}
+}
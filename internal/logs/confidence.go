@@ -0,0 +1,73 @@
+package logs
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// HistogramBuckets is the number of equal-width confidence buckets between 0 and 1
+const HistogramBuckets = 10
+
+// ConfidenceStats aggregates the confidence reported at each step of a run
+type ConfidenceStats struct {
+	Count   int
+	Min     float64
+	Mean    float64
+	Max     float64
+	Buckets [HistogramBuckets]int // Buckets[i] counts values in [i/10, (i+1)/10), with 1.0 in the last
+}
+
+// SummarizeConfidence computes the stats for a set of confidence values
+func SummarizeConfidence(values []float64) ConfidenceStats {
+	stats := ConfidenceStats{Count: len(values)}
+	if len(values) == 0 {
+		return stats
+	}
+
+	stats.Min, stats.Max = math.Inf(1), math.Inf(-1)
+	sum := 0.0
+	for _, value := range values {
+		stats.Min = math.Min(stats.Min, value)
+		stats.Max = math.Max(stats.Max, value)
+		sum += value
+
+		bucket := int(math.Max(value, 0) * HistogramBuckets)
+		stats.Buckets[min(bucket, HistogramBuckets-1)]++
+	}
+	stats.Mean = sum / float64(len(values))
+	return stats
+}
+
+// RunConfidences returns the confidence logged at each step of a run, in order
+func RunConfidences(entries []Entry, runID string) []float64 {
+	var values []float64
+	for _, entry := range entries {
+		if entry.RunID() != runID {
+			continue
+		}
+		if confidence, ok := entry["confidence"].(float64); ok {
+			values = append(values, confidence)
+		}
+	}
+	return values
+}
+
+// Histogram renders one line per non-empty bucket, scaling bars to width
+// characters for the fullest bucket
+func (s ConfidenceStats) Histogram(width int) []string {
+	largest := 0
+	for _, count := range s.Buckets {
+		largest = max(largest, count)
+	}
+
+	var lines []string
+	for i, count := range s.Buckets {
+		if count == 0 {
+			continue
+		}
+		bar := max(count*width/largest, 1)
+		lines = append(lines, fmt.Sprintf("%.1f–%.1f │ %s %d", float64(i)/HistogramBuckets, float64(i+1)/HistogramBuckets, strings.Repeat("█", bar), count))
+	}
+	return lines
+}
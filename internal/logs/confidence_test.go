@@ -0,0 +1,41 @@
+package logs
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSummarizeConfidence(t *testing.T) {
+	stats := SummarizeConfidence([]float64{0.95, 0.9, 1.0, 0.42})
+	if stats.Count != 4 || stats.Min != 0.42 || stats.Max != 1.0 {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+	if mean := stats.Mean; mean < 0.8174 || mean > 0.8176 {
+		t.Errorf("Expected mean 0.8175, got %f", mean)
+	}
+	if stats.Buckets[9] != 3 || stats.Buckets[4] != 1 {
+		t.Errorf("Unexpected buckets: %v", stats.Buckets)
+	}
+
+	lines := stats.Histogram(6)
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], "0.4–0.5 │ ██ 1") || !strings.HasPrefix(lines[1], "0.9–1.0 │ ██████ 3") {
+		t.Errorf("Unexpected histogram:\n%s", strings.Join(lines, "\n"))
+	}
+
+	if empty := SummarizeConfidence(nil); empty.Count != 0 || len(empty.Histogram(10)) != 0 {
+		t.Errorf("Expected empty stats, got %+v", empty)
+	}
+}
+
+func TestRunConfidences(t *testing.T) {
+	entries := []Entry{
+		{"run_id": "a", "confidence": 0.8},
+		{"run_id": "b", "confidence": 0.1},
+		{"run_id": "a"},
+		{"run_id": "a", "confidence": 0.9},
+	}
+	values := RunConfidences(entries, "a")
+	if len(values) != 2 || values[0] != 0.8 || values[1] != 0.9 {
+		t.Errorf("Expected [0.8 0.9], got %v", values)
+	}
+}
@@ -0,0 +1,56 @@
+package logs
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// LatencyStats aggregates how long each model call of a run took
+type LatencyStats struct {
+	Count int
+	P50   time.Duration
+	P95   time.Duration
+	Max   time.Duration
+	Total time.Duration
+}
+
+// SummarizeLatency computes the stats for a set of latencies in milliseconds
+func SummarizeLatency(valuesMs []int64) LatencyStats {
+	stats := LatencyStats{Count: len(valuesMs)}
+	if len(valuesMs) == 0 {
+		return stats
+	}
+
+	sorted := append([]int64(nil), valuesMs...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	for _, value := range sorted {
+		stats.Total += time.Duration(value) * time.Millisecond
+	}
+	stats.P50 = percentile(sorted, 50)
+	stats.P95 = percentile(sorted, 95)
+	stats.Max = time.Duration(sorted[len(sorted)-1]) * time.Millisecond
+	return stats
+}
+
+// percentile returns the nearest-rank percentile of sorted millisecond values
+func percentile(sorted []int64, p float64) time.Duration {
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	rank = min(max(rank, 1), len(sorted))
+	return time.Duration(sorted[rank-1]) * time.Millisecond
+}
+
+// RunLatencies returns the latency logged at each step of a run, in order.
+// Steps logged before latencies were recorded are skipped.
+func RunLatencies(entries []Entry, runID string) []int64 {
+	var values []int64
+	for _, entry := range entries {
+		if entry.RunID() != runID {
+			continue
+		}
+		if latency, ok := entry["latencyMs"].(float64); ok {
+			values = append(values, int64(latency))
+		}
+	}
+	return values
+}
@@ -0,0 +1,40 @@
+package logs
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSummarizeLatency(t *testing.T) {
+	values := []int64{900, 100, 300, 200, 500, 400, 800, 700, 600, 5000}
+	stats := SummarizeLatency(values)
+	if stats.Count != 10 || stats.P50 != 500*time.Millisecond || stats.P95 != 5*time.Second || stats.Max != 5*time.Second {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+	if stats.Total != 9500*time.Millisecond {
+		t.Errorf("Expected total 9.5s, got %s", stats.Total)
+	}
+	if values[0] != 900 {
+		t.Error("Expected the input to be left unsorted")
+	}
+
+	if single := SummarizeLatency([]int64{250}); single.P50 != 250*time.Millisecond || single.P95 != 250*time.Millisecond {
+		t.Errorf("Expected one value to be every percentile, got %+v", single)
+	}
+	if empty := SummarizeLatency(nil); empty.Count != 0 {
+		t.Errorf("Expected empty stats, got %+v", empty)
+	}
+}
+
+func TestRunLatencies(t *testing.T) {
+	entries := []Entry{
+		{"run_id": "a", "latencyMs": float64(1200)},
+		{"run_id": "b", "latencyMs": float64(10)},
+		{"run_id": "a"},
+		{"run_id": "a", "latencyMs": float64(800)},
+	}
+	values := RunLatencies(entries, "a")
+	if len(values) != 2 || values[0] != 1200 || values[1] != 800 {
+		t.Errorf("Expected [1200 800], got %v", values)
+	}
+}
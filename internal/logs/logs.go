@@ -0,0 +1,252 @@
+// Package logs rotates, prunes, and reads the logs.jsonl files written by
+// agent runs.
+package logs
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"time"
+)
+
+// FileName is the log file each run appends to
+const FileName = "logs.jsonl"
+
+// DefaultMaxBytes is the size at which logs.jsonl is rotated even when it was
+// started the same day
+const DefaultMaxBytes = 10 * 1024 * 1024
+
+// DefaultRetentionDays is how long rotated logs are kept when no retention is configured
+const DefaultRetentionDays = 30
+
+// dateLayout formats the date in rotated file names
+const dateLayout = "20060102"
+
+// archivePattern matches rotated files: logs-YYYYMMDD.jsonl, or
+// logs-YYYYMMDD-N.jsonl when a day was rotated more than once
+var archivePattern = regexp.MustCompile(`^logs-(\d{8})(?:-\d+)?\.jsonl$`)
+
+// runIDPattern matches run IDs that are safe to use as file names
+var runIDPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// runFilePattern matches per-run logs named by the IDs runs are given: a
+// UUID, or run-<unix nanos> when no random source was available
+var runFilePattern = regexp.MustCompile(`^(?:[0-9a-f]{8}(?:-[0-9a-f]{4}){3}-[0-9a-f]{12}|run-\d+)\.jsonl$`)
+
+// Entry is one parsed line of a log file
+type Entry map[string]interface{}
+
+// Timestamp returns the entry's RFC3339 timestamp, or the zero time if missing
+func (e Entry) Timestamp() time.Time {
+	value, _ := e["timestamp"].(string)
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+// RunID returns the run the entry belongs to
+func (e Entry) RunID() string {
+	id, _ := e["run_id"].(string)
+	return id
+}
+
+// ValidRunID reports whether a run ID can name a per-run log file
+func ValidRunID(runID string) bool {
+	return runIDPattern.MatchString(runID)
+}
+
+// RunFile returns the path of a run's own log file, <dir>/<run-id>.jsonl
+func RunFile(dir, runID string) string {
+	return filepath.Join(dir, runID+".jsonl")
+}
+
+// ReadRun returns the entries in a run's own log file, or nil when the run
+// did not write one. Malformed lines are skipped.
+func ReadRun(dir, runID string) ([]Entry, error) {
+	if !ValidRunID(runID) {
+		return nil, fmt.Errorf("invalid run ID '%s'", runID)
+	}
+	path := RunFile(dir, runID)
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil, nil
+	}
+	return readFile(path)
+}
+
+// Rotate renames logs.jsonl to logs-YYYYMMDD.jsonl, dated by its last write,
+// when it was last written before today or has grown past maxBytes. It returns
+// the new path, or "" when nothing was rotated.
+func Rotate(dir string, maxBytes int64, now time.Time) (string, error) {
+	path := filepath.Join(dir, FileName)
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	modified := info.ModTime().In(now.Location())
+	sameDay := modified.Format(dateLayout) == now.Format(dateLayout)
+	if sameDay && (maxBytes <= 0 || info.Size() < maxBytes) {
+		return "", nil
+	}
+
+	target := archiveName(dir, modified)
+	if err := os.Rename(path, target); err != nil {
+		return "", fmt.Errorf("failed to rotate %s: %w", path, err)
+	}
+	return target, nil
+}
+
+// archiveName returns the first unused rotated file name for a date
+func archiveName(dir string, date time.Time) string {
+	base := "logs-" + date.Format(dateLayout)
+	name := filepath.Join(dir, base+".jsonl")
+	for n := 1; ; n++ {
+		if _, err := os.Stat(name); os.IsNotExist(err) {
+			return name
+		}
+		name = filepath.Join(dir, fmt.Sprintf("%s-%d.jsonl", base, n))
+	}
+}
+
+// archiveDate returns the date in a rotated file's name
+func archiveDate(name string) (time.Time, bool) {
+	match := archivePattern.FindStringSubmatch(name)
+	if match == nil {
+		return time.Time{}, false
+	}
+	date, err := time.ParseInLocation(dateLayout, match[1], time.Local)
+	return date, err == nil
+}
+
+// Prune deletes rotated logs dated more than retentionDays before now, and
+// per-run logs last written before then, and returns their paths. A
+// retentionDays of 0 uses DefaultRetentionDays; negative keeps every file.
+func Prune(dir string, retentionDays int, now time.Time) ([]string, error) {
+	if retentionDays < 0 {
+		return nil, nil
+	}
+	if retentionDays == 0 {
+		retentionDays = DefaultRetentionDays
+	}
+
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	cutoff := today.AddDate(0, 0, -retentionDays)
+	var removed []string
+	for _, entry := range entries {
+		date, ok := archiveDate(entry.Name())
+		if !ok && runFilePattern.MatchString(entry.Name()) {
+			if info, err := entry.Info(); err == nil {
+				date, ok = info.ModTime(), true
+			}
+		}
+		if !ok || !date.Before(cutoff) {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		if err := os.Remove(path); err != nil {
+			return removed, fmt.Errorf("failed to remove %s: %w", path, err)
+		}
+		removed = append(removed, path)
+	}
+	return removed, nil
+}
+
+// Files returns the rotated logs in date order followed by logs.jsonl, skipping
+// rotated files dated before since
+func Files(dir string, since time.Time) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	sinceDay := time.Time{}
+	if !since.IsZero() {
+		local := since.In(time.Local)
+		sinceDay = time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.Local)
+	}
+
+	var archives []string
+	current := ""
+	for _, entry := range entries {
+		if entry.Name() == FileName {
+			current = filepath.Join(dir, FileName)
+			continue
+		}
+		// A file dated by its last write holds nothing newer than that day
+		if date, ok := archiveDate(entry.Name()); ok && !date.Before(sinceDay) {
+			archives = append(archives, filepath.Join(dir, entry.Name()))
+		}
+	}
+	sort.Strings(archives)
+	if current != "" {
+		archives = append(archives, current)
+	}
+	return archives, nil
+}
+
+// Read returns the entries of every log file in dir written at or after since,
+// oldest file first. A zero since returns everything. Malformed lines are skipped.
+func Read(dir string, since time.Time) ([]Entry, error) {
+	files, err := Files(dir, since)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []Entry
+	for _, path := range files {
+		fileEntries, err := readFile(path)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range fileEntries {
+			if since.IsZero() || !entry.Timestamp().Before(since) {
+				entries = append(entries, entry)
+			}
+		}
+	}
+	return entries, nil
+}
+
+// readFile parses every JSON line of a log file
+func readFile(path string) ([]Entry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer file.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024) // chain of thought lines can be large
+	for scanner.Scan() {
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return entries, nil
+}
@@ -0,0 +1,164 @@
+package logs
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeLog writes lines to a file in dir with the given modification time
+func writeLog(t *testing.T, dir, name string, modified time.Time, lines ...string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0644); err != nil {
+		t.Fatalf("Failed to write %s: %v", name, err)
+	}
+	if err := os.Chtimes(path, modified, modified); err != nil {
+		t.Fatalf("Failed to set mtime: %v", err)
+	}
+	return path
+}
+
+func TestRotate(t *testing.T) {
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.Local)
+
+	t.Run("same day is kept", func(t *testing.T) {
+		dir := t.TempDir()
+		writeLog(t, dir, FileName, now.Add(-time.Hour), `{}`)
+		if rotated, err := Rotate(dir, DefaultMaxBytes, now); err != nil || rotated != "" {
+			t.Errorf("Expected no rotation, got %q (%v)", rotated, err)
+		}
+	})
+
+	t.Run("previous day is rotated by date", func(t *testing.T) {
+		dir := t.TempDir()
+		writeLog(t, dir, FileName, now.AddDate(0, 0, -2), `{}`)
+		rotated, err := Rotate(dir, DefaultMaxBytes, now)
+		if err != nil {
+			t.Fatalf("Rotate failed: %v", err)
+		}
+		if filepath.Base(rotated) != "logs-20250308.jsonl" {
+			t.Errorf("Unexpected rotated name %s", rotated)
+		}
+		if _, err := os.Stat(filepath.Join(dir, FileName)); !os.IsNotExist(err) {
+			t.Error("Expected logs.jsonl to be moved")
+		}
+	})
+
+	t.Run("oversized file is rotated with a suffix", func(t *testing.T) {
+		dir := t.TempDir()
+		writeLog(t, dir, "logs-20250310.jsonl", now, `{}`)
+		writeLog(t, dir, FileName, now, strings.Repeat("x", 100))
+		rotated, err := Rotate(dir, 50, now)
+		if err != nil {
+			t.Fatalf("Rotate failed: %v", err)
+		}
+		if filepath.Base(rotated) != "logs-20250310-1.jsonl" {
+			t.Errorf("Unexpected rotated name %s", rotated)
+		}
+	})
+}
+
+func TestPrune(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.Local)
+	writeLog(t, dir, "logs-20250101.jsonl", now, `{}`)
+	writeLog(t, dir, "logs-20250308-1.jsonl", now, `{}`)
+	writeLog(t, dir, FileName, now.AddDate(-1, 0, 0), `{}`)
+	writeLog(t, dir, "events.jsonl", now.AddDate(-1, 0, 0), `{}`)
+
+	removed, err := Prune(dir, 7, now)
+	if err != nil {
+		t.Fatalf("Prune failed: %v", err)
+	}
+	if len(removed) != 1 || filepath.Base(removed[0]) != "logs-20250101.jsonl" {
+		t.Errorf("Expected only the January log to be pruned, got %v", removed)
+	}
+
+	t.Run("per-run logs by last write", func(t *testing.T) {
+		dir := t.TempDir()
+		oldRun := "0b7e2f8a-5c1d-4e3f-9a2b-6c7d8e9f0a1b"
+		writeLog(t, dir, oldRun+".jsonl", now.AddDate(0, 0, -30), `{}`)
+		writeLog(t, dir, "run-1741600000000000000.jsonl", now.AddDate(0, 0, -30), `{}`)
+		writeLog(t, dir, "9c8d7e6f-5a4b-4c3d-8e2f-1a0b9c8d7e6f.jsonl", now, `{}`)
+		writeLog(t, dir, "notes.jsonl", now.AddDate(-1, 0, 0), `{}`)
+
+		removed, err := Prune(dir, 7, now)
+		if err != nil {
+			t.Fatalf("Prune failed: %v", err)
+		}
+		if len(removed) != 2 {
+			t.Errorf("Expected the two old run logs to be pruned, got %v", removed)
+		}
+		if _, err := os.Stat(filepath.Join(dir, "notes.jsonl")); err != nil {
+			t.Error("Expected files not named by a run ID to be kept")
+		}
+	})
+
+	if removed, _ := Prune(dir, -1, now.AddDate(1, 0, 0)); len(removed) != 0 {
+		t.Errorf("Expected negative retention to keep everything, got %v", removed)
+	}
+}
+
+func TestRead_Since(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	writeLog(t, dir, "logs-20240101.jsonl", now, `{"timestamp":"2024-01-01T10:00:00Z","run_id":"old"}`)
+	writeLog(t, dir, "logs-20250102.jsonl", now,
+		`{"timestamp":"2025-01-02T09:00:00Z","run_id":"a","step":1}`,
+		`not json`,
+	)
+	writeLog(t, dir, FileName, now,
+		`{"timestamp":"2025-01-03T09:00:00Z","run_id":"b","step":1}`,
+	)
+
+	since := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	entries, err := Read(dir, since)
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if len(entries) != 2 || entries[0].RunID() != "a" || entries[1].RunID() != "b" {
+		t.Errorf("Expected entries a and b in order, got %v", entries)
+	}
+
+	all, _ := Read(dir, time.Time{})
+	if len(all) != 3 {
+		t.Errorf("Expected 3 entries without a filter, got %d", len(all))
+	}
+}
+
+func TestReadRun(t *testing.T) {
+	dir := t.TempDir()
+	runID := "0b7e2f8a-5c1d-4e3f-9a2b-6c7d8e9f0a1b"
+	writeLog(t, dir, runID+".jsonl", time.Now(),
+		`{"timestamp":"2025-01-03T09:00:00Z","run_id":"`+runID+`","step":1}`,
+		`{"timestamp":"2025-01-03T09:00:05Z","run_id":"`+runID+`","level":"info","message":"started"}`,
+	)
+	writeLog(t, dir, FileName, time.Now(), `{"timestamp":"2025-01-03T09:00:00Z","run_id":"other","step":1}`)
+
+	entries, err := ReadRun(dir, runID)
+	if err != nil {
+		t.Fatalf("ReadRun failed: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Errorf("Expected 2 entries from the run's file, got %d", len(entries))
+	}
+
+	if entries, err := ReadRun(dir, "missing"); err != nil || entries != nil {
+		t.Errorf("Expected no entries for a run without a file, got %v (%v)", entries, err)
+	}
+	if _, err := ReadRun(dir, "../logs"); err == nil {
+		t.Error("Expected an error for a run ID that is not a file name")
+	}
+
+	// Per-run files are not read again as part of the shared logs
+	shared, err := Read(dir, time.Time{})
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if len(shared) != 1 {
+		t.Errorf("Expected only logs.jsonl entries from Read, got %d", len(shared))
+	}
+}
@@ -0,0 +1,225 @@
+package manifest
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// FileName is the manifest a run writes to its output directory
+const FileName = "manifest.json"
+
+// Statuses the agent may report for an artifact
+const (
+	StatusCreated   = "created"
+	StatusGenerated = "generated"
+	StatusUpdated   = "updated"
+	StatusUnchanged = "unchanged"
+	StatusSkipped   = "skipped"
+	StatusFailed    = "failed"
+)
+
+// knownStatuses maps each status to whether the artifact must exist on disk
+var knownStatuses = map[string]bool{
+	StatusCreated:   true,
+	StatusGenerated: true,
+	StatusUpdated:   true,
+	StatusUnchanged: true,
+	StatusSkipped:   false,
+	StatusFailed:    false,
+}
+
+// Entry is the object form of a manifest value, used when the documentation
+// type an artifact was generated for is known. Plain status strings are also valid.
+type Entry struct {
+	Status string `json:"status"`
+	Type   string `json:"type,omitempty"`
+}
+
+// ParseEntry reads a manifest value in either the plain status or the object
+// form. The status is lower-cased and trimmed.
+func ParseEntry(value interface{}) (Entry, bool) {
+	var entry Entry
+	switch v := value.(type) {
+	case string:
+		entry.Status = v
+	case map[string]interface{}:
+		status, ok := v["status"].(string)
+		if !ok {
+			return Entry{}, false
+		}
+		entry.Status = status
+		entry.Type, _ = v["type"].(string)
+	case Entry:
+		entry = v
+	default:
+		return Entry{}, false
+	}
+	entry.Status = strings.ToLower(strings.TrimSpace(entry.Status))
+	return entry, true
+}
+
+// Exists reports whether an artifact with this status is claimed to be on disk
+func (e Entry) Exists() bool {
+	return knownStatuses[e.Status]
+}
+
+// Issue is a discrepancy between the manifest and the files on disk
+type Issue struct {
+	Artifact string
+	Message  string
+}
+
+func (i Issue) String() string {
+	return fmt.Sprintf("%s: %s", i.Artifact, i.Message)
+}
+
+// Write saves a manifest (artifact path -> status or Entry) as manifest.json in dir
+func Write(dir string, entries map[string]interface{}) (string, error) {
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to encode manifest: %w", err)
+	}
+	path := filepath.Join(dir, FileName)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return "", fmt.Errorf("failed to write manifest: %w", err)
+	}
+	return path, nil
+}
+
+// Read loads the entries of a manifest file
+func Read(path string) (map[string]interface{}, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+
+	var entries map[string]interface{}
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("manifest is not a JSON object: %w", err)
+	}
+	return entries, nil
+}
+
+// Validate checks a manifest file against the output directory it lives in.
+// It returns the number of entries checked and any discrepancies found.
+func Validate(path string) (int, []Issue, error) {
+	entries, err := Read(path)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	baseDir := filepath.Dir(path)
+	artifacts := make([]string, 0, len(entries))
+	for artifact := range entries {
+		artifacts = append(artifacts, artifact)
+	}
+	sort.Strings(artifacts)
+
+	var issues []Issue
+	for _, artifact := range artifacts {
+		if issue := validateEntry(baseDir, artifact, entries[artifact]); issue != "" {
+			issues = append(issues, Issue{Artifact: artifact, Message: issue})
+		}
+	}
+	return len(entries), issues, nil
+}
+
+// DiagramSources returns the Markdown and .mmd files a manifest lists as on
+// disk, resolved against the manifest's directory and sorted, so their
+// diagrams can be rendered again
+func DiagramSources(path string) ([]string, error) {
+	entries, err := Read(path)
+	if err != nil {
+		return nil, err
+	}
+
+	baseDir := filepath.Dir(path)
+	var sources []string
+	for artifact, value := range entries {
+		ext := strings.ToLower(filepath.Ext(artifact))
+		if ext != ".md" && ext != ".mmd" {
+			continue
+		}
+		if entry, ok := ParseEntry(value); !ok || !entry.Exists() {
+			continue
+		}
+		if !filepath.IsAbs(artifact) {
+			artifact = filepath.Join(baseDir, artifact)
+		}
+		sources = append(sources, artifact)
+	}
+	sort.Strings(sources)
+	return sources, nil
+}
+
+// validateEntry returns a description of what is wrong with one entry, or ""
+func validateEntry(baseDir, artifact string, value interface{}) string {
+	entry, ok := ParseEntry(value)
+	if !ok {
+		if object, isObject := value.(map[string]interface{}); isObject {
+			value = object["status"]
+		}
+		return fmt.Sprintf("status must be a string, got %v", value)
+	}
+	status := entry.Status
+
+	mustExist, known := knownStatuses[status]
+	if !known {
+		return fmt.Sprintf("unknown status %q (expected one of: created, generated, updated, unchanged, skipped, failed)", status)
+	}
+	if !mustExist {
+		return ""
+	}
+
+	path := artifact
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(baseDir, path)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Sprintf("listed as %s but does not exist", status)
+	}
+	if info.IsDir() {
+		return fmt.Sprintf("listed as %s but is a directory", status)
+	}
+
+	if strings.EqualFold(filepath.Ext(path), ".svg") {
+		if err := checkSVG(path); err != nil {
+			return fmt.Sprintf("listed as %s but is not a valid SVG: %v", status, err)
+		}
+	}
+	return ""
+}
+
+// checkSVG verifies a file is non-empty XML whose root element is <svg>
+func checkSVG(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	decoder := xml.NewDecoder(file)
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			return fmt.Errorf("file is empty")
+		}
+		if err != nil {
+			return fmt.Errorf("malformed XML: %w", err)
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			if start.Name.Local != "svg" {
+				return fmt.Errorf("root element is <%s>, expected <svg>", start.Name.Local)
+			}
+			return nil
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package manifest
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write %s: %v", path, err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "login.md"), "# Login")
+	writeFile(t, filepath.Join(dir, "login.svg"), `<?xml version="1.0"?><svg xmlns="http://www.w3.org/2000/svg"></svg>`)
+	writeFile(t, filepath.Join(dir, "empty.svg"), "")
+	writeFile(t, filepath.Join(dir, "html.svg"), "<html></html>")
+
+	path, err := Write(dir, map[string]interface{}{
+		"login.md":    "created",
+		"login.svg":   "generated",
+		"empty.svg":   "generated",
+		"html.svg":    "generated",
+		"missing.svg": "generated",
+		"later.md":    "skipped",
+		"odd.md":      "done",
+		"number.md":   3,
+	})
+	if err != nil {
+		t.Fatalf("Failed to write manifest: %v", err)
+	}
+
+	count, issues, err := Validate(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if count != 8 {
+		t.Errorf("Expected 8 entries checked, got %d", count)
+	}
+
+	expected := map[string]string{
+		"empty.svg":   "file is empty",
+		"html.svg":    "root element is <html>",
+		"missing.svg": "does not exist",
+		"odd.md":      "unknown status",
+		"number.md":   "must be a string",
+	}
+	if len(issues) != len(expected) {
+		t.Fatalf("Expected %d issues, got %d: %v", len(expected), len(issues), issues)
+	}
+	for _, issue := range issues {
+		want, ok := expected[issue.Artifact]
+		if !ok {
+			t.Errorf("Unexpected issue: %s", issue)
+			continue
+		}
+		if !strings.Contains(issue.Message, want) {
+			t.Errorf("Expected issue for %s to mention %q, got %q", issue.Artifact, want, issue.Message)
+		}
+	}
+}
+
+func TestValidate_NotAnObject(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	writeFile(t, path, `["login.md"]`)
+
+	if _, _, err := Validate(path); err == nil {
+		t.Error("Expected error for a non-object manifest")
+	}
+}
+
+func TestValidate_TypedEntries(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "user-flow"), 0755); err != nil {
+		t.Fatalf("Failed to create subdirectory: %v", err)
+	}
+	writeFile(t, filepath.Join(dir, "user-flow", "login.md"), "# Login")
+
+	path, err := Write(dir, map[string]interface{}{
+		"user-flow/login.md":   Entry{Status: StatusCreated, Type: "User Flow Diagrams"},
+		"data-models/users.md": Entry{Status: StatusCreated, Type: "Data Models (ER Diagrams)"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to write manifest: %v", err)
+	}
+
+	count, issues, err := Validate(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("Expected 2 entries checked, got %d", count)
+	}
+	if len(issues) != 1 || issues[0].Artifact != "data-models/users.md" {
+		t.Errorf("Expected only the missing typed artifact to be reported, got %v", issues)
+	}
+}
+
+func TestDiagramSources(t *testing.T) {
+	dir := t.TempDir()
+	path, err := Write(dir, map[string]interface{}{
+		"login.md":           "created",
+		"login.svg":          "generated",
+		"flows/checkout.mmd": map[string]interface{}{"status": "created", "type": "User Flow Diagrams"},
+		"later.md":           "skipped",
+		"/abs/notes.md":      "updated",
+	})
+	if err != nil {
+		t.Fatalf("Failed to write manifest: %v", err)
+	}
+
+	sources, err := DiagramSources(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := []string{"/abs/notes.md", filepath.Join(dir, "flows", "checkout.mmd"), filepath.Join(dir, "login.md")}
+	if strings.Join(sources, "|") != strings.Join(want, "|") {
+		t.Errorf("Expected %v, got %v", want, sources)
+	}
+}
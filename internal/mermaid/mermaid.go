@@ -0,0 +1,94 @@
+// Package mermaid finds the Mermaid diagrams in Markdown documents.
+package mermaid
+
+import (
+	"strings"
+)
+
+// Block is one ```mermaid fenced block of a Markdown document
+type Block struct {
+	Type      string // diagram keyword from DetectDiagramType, "" when unrecognized
+	Content   string // source between the fences, without them
+	StartLine int    // 1-based line of the opening fence
+	EndLine   int    // 1-based line of the closing fence, or the last line when unclosed
+	Closed    bool   // false when the document ended before the closing fence
+}
+
+// diagramTypes are the keywords that open a Mermaid diagram
+var diagramTypes = []string{
+	"graph", "flowchart", "sequenceDiagram", "classDiagram", "classDiagram-v2",
+	"stateDiagram", "stateDiagram-v2", "erDiagram", "gantt", "pie", "journey",
+	"gitGraph", "mindmap", "timeline", "quadrantChart", "requirementDiagram",
+	"C4Context", "C4Container", "C4Component", "C4Dynamic", "C4Deployment",
+	"sankey-beta", "xychart-beta", "block-beta", "packet-beta", "architecture-beta", "kanban",
+}
+
+// ExtractBlocks returns the ```mermaid blocks of a Markdown document in
+// order, with their diagram types and line ranges
+func ExtractBlocks(markdown string) []Block {
+	var blocks []Block
+	var current *Block
+	var content []string
+
+	lines := strings.Split(markdown, "\n")
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case current == nil && strings.HasPrefix(trimmed, "```mermaid"):
+			current = &Block{StartLine: i + 1}
+			content = nil
+		case current != nil && strings.HasPrefix(trimmed, "```"):
+			current.EndLine, current.Closed = i+1, true
+			blocks = append(blocks, finishBlock(*current, content))
+			current = nil
+		case current != nil:
+			content = append(content, line)
+		}
+	}
+	if current != nil {
+		current.EndLine = len(lines)
+		blocks = append(blocks, finishBlock(*current, content))
+	}
+	return blocks
+}
+
+// finishBlock fills in a block's content and diagram type
+func finishBlock(block Block, lines []string) Block {
+	block.Content = strings.TrimSpace(strings.Join(lines, "\n"))
+	block.Type = DetectDiagramType(block.Content)
+	return block
+}
+
+// DetectDiagramType returns the Mermaid keyword that opens a diagram (e.g.
+// "sequenceDiagram" or "flowchart"), or "" when the first keyword is not a
+// known diagram type
+func DetectDiagramType(source string) string {
+	keyword := DiagramKeyword(source)
+	for _, known := range diagramTypes {
+		if keyword == known {
+			return known
+		}
+	}
+	return ""
+}
+
+// DiagramKeyword returns the first word of a diagram, skipping %% comments,
+// %%{init}%% directives, and --- front matter, whether or not it is a known
+// diagram type. It returns "" for an empty diagram.
+func DiagramKeyword(source string) string {
+	inFrontMatter := false
+	for i, line := range strings.Split(source, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "---" {
+			if i == 0 || inFrontMatter {
+				inFrontMatter = !inFrontMatter
+				continue
+			}
+		}
+		if inFrontMatter || trimmed == "" || strings.HasPrefix(trimmed, "%%") {
+			continue
+		}
+		return strings.Fields(trimmed)[0]
+	}
+	return ""
+}
@@ -0,0 +1,62 @@
+package mermaid
+
+import (
+	"testing"
+)
+
+func TestExtractBlocks(t *testing.T) {
+	markdown := "# Login\n\n```mermaid\nsequenceDiagram\n  A->>B: hi\n```\n\nText\n\n```mermaid\n%% comment\nflowchart TD\n  A --> B\n```\n\n```go\nfmt.Println()\n```\n\n```mermaid\nerDiagram\n  USER ||--o{ ORDER : places"
+	blocks := ExtractBlocks(markdown)
+	if len(blocks) != 3 {
+		t.Fatalf("Expected 3 blocks, got %d: %+v", len(blocks), blocks)
+	}
+
+	expected := []Block{
+		{Type: "sequenceDiagram", Content: "sequenceDiagram\n  A->>B: hi", StartLine: 3, EndLine: 6, Closed: true},
+		{Type: "flowchart", Content: "%% comment\nflowchart TD\n  A --> B", StartLine: 10, EndLine: 14, Closed: true},
+		{Type: "erDiagram", Content: "erDiagram\n  USER ||--o{ ORDER : places", StartLine: 20, EndLine: 22, Closed: false},
+	}
+	for i, want := range expected {
+		if blocks[i] != want {
+			t.Errorf("Block %d: expected %+v, got %+v", i+1, want, blocks[i])
+		}
+	}
+
+	if blocks := ExtractBlocks("# No diagrams\n\n```go\nx\n```\n"); len(blocks) != 0 {
+		t.Errorf("Expected no blocks, got %+v", blocks)
+	}
+}
+
+func TestDetectDiagramType(t *testing.T) {
+	tests := []struct {
+		source string
+		want   string
+	}{
+		{"graph TD\n  A --> B", "graph"},
+		{"stateDiagram-v2\n  [*] --> A", "stateDiagram-v2"},
+		{"%%{init: {'theme': 'dark'}}%%\nsequenceDiagram", "sequenceDiagram"},
+		{"---\ntitle: Orders\n---\nerDiagram", "erDiagram"},
+		{"\n\n  classDiagram\n", "classDiagram"},
+		{"A --> B", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := DetectDiagramType(tt.source); got != tt.want {
+			t.Errorf("DetectDiagramType(%q) = %q, want %q", tt.source, got, tt.want)
+		}
+	}
+}
+
+func TestDiagramKeyword(t *testing.T) {
+	tests := map[string]string{
+		"zenuml\n  A.b()":                  "zenuml",
+		"%% comment\nradar-beta\n  axis A": "radar-beta",
+		"---\ntitle: x\n---\ngraph LR":     "graph",
+		"\n%% only a comment\n":            "",
+	}
+	for source, want := range tests {
+		if got := DiagramKeyword(source); got != want {
+			t.Errorf("DiagramKeyword(%q) = %q, want %q", source, got, want)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package output
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/landanqrew/mermaid-agent-documenter/internal/mermaid"
+)
+
+// FindRenderedImages locates the images Mermaid CLI produced for a Markdown file.
+// mmdc writes <base>.<ext> for a single diagram and <base>-<n>.<ext> for several.
+func FindRenderedImages(imagePath string, diagramCount int) []string {
+	ext := filepath.Ext(imagePath)
+	base := strings.TrimSuffix(imagePath, ext)
+
+	images := make([]string, diagramCount)
+	for i := 0; i < diagramCount; i++ {
+		numbered := fmt.Sprintf("%s-%d%s", base, i+1, ext)
+		if _, err := os.Stat(numbered); err == nil {
+			images[i] = numbered
+			continue
+		}
+		if diagramCount == 1 {
+			if _, err := os.Stat(imagePath); err == nil {
+				images[i] = imagePath
+			}
+		}
+	}
+	return images
+}
+
+// EmbedImages adds an image link after each mermaid block. images[i] is the
+// rendered image for the i-th block (empty entries are skipped); links are
+// made relative to baseDir when possible.
+func EmbedImages(markdown string, images []string, baseDir string) string {
+	// blockAt maps the line of each closing fence to its block's index
+	blockAt := make(map[int]int)
+	for i, block := range mermaid.ExtractBlocks(markdown) {
+		if block.Closed {
+			blockAt[block.EndLine] = i
+		}
+	}
+
+	var sb strings.Builder
+	lines := strings.Split(markdown, "\n")
+	for i, line := range lines {
+		sb.WriteString(line)
+		if i < len(lines)-1 {
+			sb.WriteString("\n")
+		}
+
+		block, ok := blockAt[i+1]
+		if ok && block < len(images) && images[block] != "" {
+			link := images[block]
+			if rel, err := filepath.Rel(baseDir, link); err == nil {
+				link = filepath.ToSlash(rel)
+			}
+			sb.WriteString(fmt.Sprintf("\n![Diagram %d](%s)\n", block+1, link))
+		}
+	}
+
+	return sb.String()
+}
+
+// WriteEmbeddedCompanion writes <name>.rendered.md next to markdownPath with
+// links to the rendered images. It returns the companion path, or "" if no
+// rendered images were found.
+func WriteEmbeddedCompanion(markdownPath, imagePath string) (string, error) {
+	data, err := os.ReadFile(markdownPath)
+	if err != nil {
+		return "", err
+	}
+	markdown := string(data)
+
+	images := FindRenderedImages(imagePath, len(mermaid.ExtractBlocks(markdown)))
+	found := false
+	for _, image := range images {
+		if image != "" {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return "", nil
+	}
+
+	companion := strings.TrimSuffix(markdownPath, filepath.Ext(markdownPath)) + ".rendered.md"
+	embedded := EmbedImages(markdown, images, filepath.Dir(companion))
+	if err := os.WriteFile(companion, []byte(embedded), 0644); err != nil {
+		return "", err
+	}
+	return companion, nil
+}
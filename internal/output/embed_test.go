@@ -0,0 +1,58 @@
+package output
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWriteEmbeddedCompanion(t *testing.T) {
+	dir := t.TempDir()
+
+	markdownPath := filepath.Join(dir, "login.md")
+	markdown := "# Login\n\n```mermaid\nsequenceDiagram\n  A->>B: hi\n```\n\n```mermaid\ngraph TD\n  A --> B\n```\n"
+	if err := os.WriteFile(markdownPath, []byte(markdown), 0644); err != nil {
+		t.Fatalf("Failed to write markdown: %v", err)
+	}
+
+	// mmdc names images <base>-<n>.svg when a file has several diagrams
+	for _, name := range []string{"login-1.svg", "login-2.svg"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("<svg></svg>"), 0644); err != nil {
+			t.Fatalf("Failed to write image: %v", err)
+		}
+	}
+
+	companion, err := WriteEmbeddedCompanion(markdownPath, filepath.Join(dir, "login.svg"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if companion != filepath.Join(dir, "login.rendered.md") {
+		t.Fatalf("Unexpected companion path: %s", companion)
+	}
+
+	data, err := os.ReadFile(companion)
+	if err != nil {
+		t.Fatalf("Failed to read companion: %v", err)
+	}
+	content := string(data)
+
+	for _, expected := range []string{"![Diagram 1](login-1.svg)", "![Diagram 2](login-2.svg)", "sequenceDiagram"} {
+		if !strings.Contains(content, expected) {
+			t.Errorf("Expected companion to contain %q, got:\n%s", expected, content)
+		}
+	}
+}
+
+func TestWriteEmbeddedCompanion_NoImages(t *testing.T) {
+	dir := t.TempDir()
+	markdownPath := filepath.Join(dir, "empty.md")
+	if err := os.WriteFile(markdownPath, []byte("```mermaid\ngraph TD\n```\n"), 0644); err != nil {
+		t.Fatalf("Failed to write markdown: %v", err)
+	}
+
+	companion, err := WriteEmbeddedCompanion(markdownPath, filepath.Join(dir, "empty.svg"))
+	if err != nil || companion != "" {
+		t.Errorf("Expected no companion without images, got %q, %v", companion, err)
+	}
+}
@@ -0,0 +1,231 @@
+package output
+
+import (
+	"fmt"
+	"html"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Format is a documentation output format
+type Format string
+
+const (
+	FormatMarkdown Format = "md"
+	FormatAsciiDoc Format = "adoc"
+	FormatHTML     Format = "html"
+)
+
+// ParseFormat validates a format name, defaulting to Markdown when empty
+func ParseFormat(name string) (Format, error) {
+	switch Format(strings.ToLower(strings.TrimSpace(name))) {
+	case "", FormatMarkdown, "markdown":
+		return FormatMarkdown, nil
+	case FormatAsciiDoc, "asciidoc":
+		return FormatAsciiDoc, nil
+	case FormatHTML:
+		return FormatHTML, nil
+	default:
+		return "", fmt.Errorf("unsupported output format '%s'. Supported formats: md, adoc, html", name)
+	}
+}
+
+// mermaidScript renders <pre class="mermaid"> blocks client-side
+const mermaidScript = `<script type="module">
+  import mermaid from 'https://cdn.jsdelivr.net/npm/mermaid@11/dist/mermaid.esm.min.mjs';
+  mermaid.initialize({ startOnLoad: true });
+</script>`
+
+var (
+	headingPattern = regexp.MustCompile(`^(#{1,6})\s+(.*)$`)
+	listPattern    = regexp.MustCompile(`^\s*[-*]\s+(.*)$`)
+	boldPattern    = regexp.MustCompile(`\*\*(.+?)\*\*`)
+	codePattern    = regexp.MustCompile("`([^`]+)`")
+	imagePattern   = regexp.MustCompile(`!\[([^\]]*)\]\(([^)]+)\)`)
+)
+
+// ConvertFile converts a Markdown file to the given format, writing a sibling
+// file with the format's extension. It returns the path of the new file.
+func ConvertFile(markdownPath string, format Format) (string, error) {
+	if format == FormatMarkdown {
+		return markdownPath, nil
+	}
+
+	data, err := os.ReadFile(markdownPath)
+	if err != nil {
+		return "", err
+	}
+
+	title := strings.TrimSuffix(filepath.Base(markdownPath), filepath.Ext(markdownPath))
+	converted := ConvertMarkdown(string(data), format, title)
+
+	outPath := strings.TrimSuffix(markdownPath, filepath.Ext(markdownPath)) + "." + string(format)
+	if err := os.WriteFile(outPath, []byte(converted), 0644); err != nil {
+		return "", err
+	}
+	return outPath, nil
+}
+
+// ConvertMarkdown converts the Markdown subset the agent produces (headings,
+// paragraphs, lists, and fenced code/mermaid blocks) to the given format
+func ConvertMarkdown(markdown string, format Format, title string) string {
+	switch format {
+	case FormatAsciiDoc:
+		return toAsciiDoc(markdown)
+	case FormatHTML:
+		return toHTML(markdown, title)
+	default:
+		return markdown
+	}
+}
+
+func toAsciiDoc(markdown string) string {
+	var sb strings.Builder
+	inFence := false
+	fenceDelimiter := ""
+
+	for _, line := range strings.Split(strings.ReplaceAll(markdown, "\r\n", "\n"), "\n") {
+		trimmed := strings.TrimSpace(line)
+
+		if strings.HasPrefix(trimmed, "```") {
+			if !inFence {
+				lang := strings.TrimSpace(strings.TrimPrefix(trimmed, "```"))
+				if lang == "mermaid" {
+					sb.WriteString("[mermaid]\n")
+					fenceDelimiter = "...."
+				} else {
+					if lang != "" {
+						sb.WriteString(fmt.Sprintf("[source,%s]\n", lang))
+					}
+					fenceDelimiter = "----"
+				}
+				sb.WriteString(fenceDelimiter + "\n")
+				inFence = true
+			} else {
+				sb.WriteString(fenceDelimiter + "\n")
+				inFence = false
+			}
+			continue
+		}
+
+		if inFence {
+			sb.WriteString(line + "\n")
+			continue
+		}
+
+		if m := headingPattern.FindStringSubmatch(line); m != nil {
+			sb.WriteString(strings.Repeat("=", len(m[1])) + " " + m[2] + "\n")
+			continue
+		}
+		if m := listPattern.FindStringSubmatch(line); m != nil {
+			line = "* " + m[1]
+		}
+		line = imagePattern.ReplaceAllString(line, "image::$2[$1]")
+		sb.WriteString(boldPattern.ReplaceAllString(line, "*$1*") + "\n")
+	}
+
+	return sb.String()
+}
+
+func toHTML(markdown, title string) string {
+	var body strings.Builder
+	var paragraph []string
+	inFence := false
+	inList := false
+	fenceIsMermaid := false
+
+	flushParagraph := func() {
+		if len(paragraph) > 0 {
+			body.WriteString("<p>" + strings.Join(paragraph, " ") + "</p>\n")
+			paragraph = nil
+		}
+	}
+	closeList := func() {
+		if inList {
+			body.WriteString("</ul>\n")
+			inList = false
+		}
+	}
+
+	for _, line := range strings.Split(strings.ReplaceAll(markdown, "\r\n", "\n"), "\n") {
+		trimmed := strings.TrimSpace(line)
+
+		if strings.HasPrefix(trimmed, "```") {
+			if !inFence {
+				flushParagraph()
+				closeList()
+				fenceIsMermaid = strings.TrimSpace(strings.TrimPrefix(trimmed, "```")) == "mermaid"
+				if fenceIsMermaid {
+					body.WriteString(`<pre class="mermaid">` + "\n")
+				} else {
+					body.WriteString("<pre><code>")
+				}
+				inFence = true
+			} else {
+				if fenceIsMermaid {
+					body.WriteString("</pre>\n")
+				} else {
+					body.WriteString("</code></pre>\n")
+				}
+				inFence = false
+			}
+			continue
+		}
+
+		if inFence {
+			body.WriteString(html.EscapeString(line) + "\n")
+			continue
+		}
+
+		if trimmed == "" {
+			flushParagraph()
+			closeList()
+			continue
+		}
+
+		if m := headingPattern.FindStringSubmatch(line); m != nil {
+			flushParagraph()
+			closeList()
+			level := len(m[1])
+			body.WriteString(fmt.Sprintf("<h%d>%s</h%d>\n", level, inlineHTML(m[2]), level))
+			continue
+		}
+
+		if m := listPattern.FindStringSubmatch(line); m != nil {
+			flushParagraph()
+			if !inList {
+				body.WriteString("<ul>\n")
+				inList = true
+			}
+			body.WriteString("<li>" + inlineHTML(m[1]) + "</li>\n")
+			continue
+		}
+
+		closeList()
+		paragraph = append(paragraph, inlineHTML(trimmed))
+	}
+	flushParagraph()
+	closeList()
+
+	return fmt.Sprintf(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>%s</title>
+</head>
+<body>
+%s%s
+</body>
+</html>
+`, html.EscapeString(title), body.String(), mermaidScript)
+}
+
+// inlineHTML escapes text and converts images, bold and inline code spans
+func inlineHTML(text string) string {
+	text = html.EscapeString(text)
+	text = imagePattern.ReplaceAllString(text, `<img src="$2" alt="$1">`)
+	text = boldPattern.ReplaceAllString(text, "<strong>$1</strong>")
+	return codePattern.ReplaceAllString(text, "<code>$1</code>")
+}
@@ -0,0 +1,53 @@
+package output
+
+import (
+	"strings"
+	"testing"
+)
+
+const sampleMarkdown = "# Login Flow\n\nThe **user** signs in.\n\n- Step one\n- Step two\n\n```mermaid\nsequenceDiagram\n  User->>API: POST /login\n```\n"
+
+func TestParseFormat(t *testing.T) {
+	tests := map[string]Format{"": FormatMarkdown, "md": FormatMarkdown, "ADOC": FormatAsciiDoc, "html": FormatHTML}
+	for input, expected := range tests {
+		format, err := ParseFormat(input)
+		if err != nil || format != expected {
+			t.Errorf("ParseFormat(%q) = %q, %v; expected %q", input, format, err, expected)
+		}
+	}
+
+	if _, err := ParseFormat("pdf"); err == nil {
+		t.Error("Expected error for unsupported format")
+	}
+}
+
+func TestConvertMarkdown_AsciiDoc(t *testing.T) {
+	result := ConvertMarkdown(sampleMarkdown, FormatAsciiDoc, "login")
+
+	for _, expected := range []string{"= Login Flow", "The *user* signs in.", "* Step one", "[mermaid]\n....\nsequenceDiagram", "POST /login\n...."} {
+		if !strings.Contains(result, expected) {
+			t.Errorf("Expected AsciiDoc output to contain %q, got:\n%s", expected, result)
+		}
+	}
+}
+
+func TestConvertMarkdown_HTML(t *testing.T) {
+	result := ConvertMarkdown(sampleMarkdown, FormatHTML, "login")
+
+	for _, expected := range []string{"<h1>Login Flow</h1>", "<strong>user</strong>", "<li>Step one</li>", `<pre class="mermaid">`, "User-&gt;&gt;API", "mermaid.initialize"} {
+		if !strings.Contains(result, expected) {
+			t.Errorf("Expected HTML output to contain %q, got:\n%s", expected, result)
+		}
+	}
+}
+
+func TestConvertMarkdown_Images(t *testing.T) {
+	markdown := "![Diagram 1](login-1.svg)\n"
+
+	if result := ConvertMarkdown(markdown, FormatAsciiDoc, "login"); !strings.Contains(result, "image::login-1.svg[Diagram 1]") {
+		t.Errorf("Expected AsciiDoc image macro, got:\n%s", result)
+	}
+	if result := ConvertMarkdown(markdown, FormatHTML, "login"); !strings.Contains(result, `<img src="login-1.svg" alt="Diagram 1">`) {
+		t.Errorf("Expected HTML img tag, got:\n%s", result)
+	}
+}
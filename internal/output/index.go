@@ -0,0 +1,130 @@
+package output
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// IndexFileName is the human-readable index written to the output directory
+const IndexFileName = "index.md"
+
+// untypedGroup heads documents that were not generated for a documentation type
+const untypedGroup = "Documentation"
+
+// IndexEntry is one generated document listed in the index
+type IndexEntry struct {
+	Path   string   // documentation file
+	Type   string   // documentation type, "" when unknown
+	Images []string // images rendered from its diagrams
+}
+
+// IndexInfo describes the run that produced the index
+type IndexInfo struct {
+	Provider    string
+	Model       string
+	GeneratedAt time.Time
+}
+
+// BuildIndex renders an index.md linking each document and its images, grouped
+// by documentation type. Links are relative to dir.
+func BuildIndex(dir string, entries []IndexEntry, info IndexInfo) string {
+	groups := map[string][]IndexEntry{}
+	var types []string
+	for _, entry := range entries {
+		group := entry.Type
+		if group == "" {
+			group = untypedGroup
+		}
+		if _, ok := groups[group]; !ok {
+			types = append(types, group)
+		}
+		groups[group] = append(groups[group], entry)
+	}
+	sort.Slice(types, func(i, j int) bool {
+		// Untyped documents come last
+		if (types[i] == untypedGroup) != (types[j] == untypedGroup) {
+			return types[j] == untypedGroup
+		}
+		return types[i] < types[j]
+	})
+
+	var sb strings.Builder
+	sb.WriteString("# Documentation Index\n\n")
+	sb.WriteString(fmt.Sprintf("Generated %s", info.GeneratedAt.Format("2006-01-02 15:04:05 MST")))
+	if info.Model != "" {
+		sb.WriteString(" with ")
+		if info.Provider != "" {
+			sb.WriteString(info.Provider + "/")
+		}
+		sb.WriteString(info.Model)
+	}
+	sb.WriteString(".\n")
+
+	if len(entries) == 0 {
+		sb.WriteString("\nNo documents were generated.\n")
+		return sb.String()
+	}
+
+	for _, group := range types {
+		docs := groups[group]
+		sort.Slice(docs, func(i, j int) bool { return docs[i].Path < docs[j].Path })
+
+		sb.WriteString(fmt.Sprintf("\n## %s\n\n", group))
+		for _, doc := range docs {
+			sb.WriteString(fmt.Sprintf("- [%s](%s)", documentTitle(doc.Path), relativeLink(dir, doc.Path)))
+			for i, image := range doc.Images {
+				sb.WriteString(fmt.Sprintf(" · [diagram %d](%s)", i+1, relativeLink(dir, image)))
+			}
+			sb.WriteString("\n")
+		}
+	}
+
+	return sb.String()
+}
+
+// WriteIndex writes index.md to dir and returns its path
+func WriteIndex(dir string, entries []IndexEntry, info IndexInfo) (string, error) {
+	path := filepath.Join(dir, IndexFileName)
+	if err := os.WriteFile(path, []byte(BuildIndex(dir, entries, info)), 0644); err != nil {
+		return "", fmt.Errorf("failed to write index: %w", err)
+	}
+	return path, nil
+}
+
+// GuessRenderedImages finds images rendered next to a Markdown file under the
+// same base name, for documents whose render call was not observed in this run
+func GuessRenderedImages(markdownPath string, diagramCount int) []string {
+	if diagramCount == 0 {
+		return nil
+	}
+	base := strings.TrimSuffix(markdownPath, filepath.Ext(markdownPath))
+	for _, ext := range []string{".svg", ".png", ".pdf"} {
+		var found []string
+		for _, image := range FindRenderedImages(base+ext, diagramCount) {
+			if image != "" {
+				found = append(found, image)
+			}
+		}
+		if len(found) > 0 {
+			return found
+		}
+	}
+	return nil
+}
+
+// documentTitle returns the file name without its extension
+func documentTitle(path string) string {
+	return strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+}
+
+// relativeLink returns path relative to dir in slash form, or path itself
+func relativeLink(dir, path string) string {
+	if rel, err := filepath.Rel(dir, path); err == nil {
+		return filepath.ToSlash(rel)
+	}
+	return path
+}
@@ -0,0 +1,64 @@
+package output
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBuildIndex(t *testing.T) {
+	dir := "/out"
+	generatedAt := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	index := BuildIndex(dir, []IndexEntry{
+		{Path: "/out/notes.md"},
+		{Path: "/out/user-flow/login.md", Type: "User Flow Diagrams", Images: []string{"/out/user-flow/login-1.svg", "/out/user-flow/login-2.svg"}},
+		{Path: "/out/data-models/users.md", Type: "Data Models (ER Diagrams)", Images: []string{"/out/data-models/users.svg"}},
+	}, IndexInfo{Provider: "openai", Model: "gpt-5-mini", GeneratedAt: generatedAt})
+
+	for _, want := range []string{
+		"Generated 2025-01-02 03:04:05 UTC with openai/gpt-5-mini.",
+		"- [login](user-flow/login.md) · [diagram 1](user-flow/login-1.svg) · [diagram 2](user-flow/login-2.svg)",
+		"- [users](data-models/users.md) · [diagram 1](data-models/users.svg)",
+		"- [notes](notes.md)\n",
+	} {
+		if !strings.Contains(index, want) {
+			t.Errorf("Expected index to contain %q, got:\n%s", want, index)
+		}
+	}
+
+	// Types are sorted, with untyped documents last
+	dataModels := strings.Index(index, "## Data Models (ER Diagrams)")
+	userFlow := strings.Index(index, "## User Flow Diagrams")
+	untyped := strings.Index(index, "## Documentation")
+	if dataModels < 0 || userFlow < dataModels || untyped < userFlow {
+		t.Errorf("Unexpected group order:\n%s", index)
+	}
+}
+
+func TestBuildIndex_Empty(t *testing.T) {
+	index := BuildIndex("/out", nil, IndexInfo{GeneratedAt: time.Now()})
+	if !strings.Contains(index, "No documents were generated.") {
+		t.Errorf("Expected empty index notice, got:\n%s", index)
+	}
+}
+
+func TestGuessRenderedImages(t *testing.T) {
+	dir := t.TempDir()
+	markdownPath := filepath.Join(dir, "login.md")
+	for _, name := range []string{"login-1.png", "login-2.png"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("png"), 0644); err != nil {
+			t.Fatalf("Failed to write image: %v", err)
+		}
+	}
+
+	images := GuessRenderedImages(markdownPath, 2)
+	if len(images) != 2 || filepath.Base(images[0]) != "login-1.png" {
+		t.Errorf("Expected numbered PNGs, got %v", images)
+	}
+	if images := GuessRenderedImages(filepath.Join(dir, "other.md"), 1); images != nil {
+		t.Errorf("Expected no images for other.md, got %v", images)
+	}
+}
@@ -0,0 +1,29 @@
+package output
+
+import (
+	"fmt"
+	"os"
+)
+
+// CheckWritable creates dir if needed and confirms a file can be written in it
+// by creating and removing a temporary file
+func CheckWritable(dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("cannot create output directory %s: %w", dir, err)
+	}
+	probe, err := os.CreateTemp(dir, ".mad-write-check-*")
+	if err != nil {
+		return fmt.Errorf("output directory %s is not writable: %w", dir, err)
+	}
+	name := probe.Name()
+	_, writeErr := probe.WriteString("ok")
+	closeErr := probe.Close()
+	os.Remove(name)
+	if writeErr != nil {
+		return fmt.Errorf("output directory %s is not writable: %w", dir, writeErr)
+	}
+	if closeErr != nil {
+		return fmt.Errorf("output directory %s is not writable: %w", dir, closeErr)
+	}
+	return nil
+}
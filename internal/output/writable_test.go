@@ -0,0 +1,46 @@
+package output
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCheckWritable(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "nested", "out")
+	if err := CheckWritable(dir); err != nil {
+		t.Fatalf("CheckWritable() error: %v", err)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("output directory was not created: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("CheckWritable() left %d files behind", len(entries))
+	}
+}
+
+func TestCheckWritable_ReadOnly(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("permissions are not enforced for root")
+	}
+	dir := t.TempDir()
+	if err := os.Chmod(dir, 0555); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chmod(dir, 0755) })
+
+	if err := CheckWritable(dir); err == nil {
+		t.Error("CheckWritable() = nil for a read-only directory")
+	}
+}
+
+func TestCheckWritable_PathIsFile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "out")
+	if err := os.WriteFile(file, []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := CheckWritable(file); err == nil {
+		t.Error("CheckWritable() = nil when the output path is a file")
+	}
+}
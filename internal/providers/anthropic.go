@@ -7,9 +7,51 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"strings"
+	"time"
 )
 
-type AnthropicProvider struct{}
+type AnthropicProvider struct {
+	// RequestTimeout bounds each HTTP request (0 uses DefaultRequestTimeout)
+	RequestTimeout time.Duration
+
+	// Temperature overrides defaultAnthropicTemperature when set
+	Temperature *float64
+
+	// RateLimiter throttles requests when a rate limit is configured
+	RateLimiter *RateLimiter
+
+	// BaseURL overrides anthropicBaseURL (used by tests)
+	BaseURL string
+
+	// UserAgent is sent in the User-Agent header ("" uses DefaultUserAgent)
+	UserAgent string
+
+	// Version overrides DefaultAnthropicVersion in the anthropic-version header
+	Version string
+
+	// Beta lists anthropic-beta values sent with every request
+	Beta []string
+
+	// MaxTokens caps each response (0 uses defaultAnthropicMaxTokens)
+	MaxTokens int
+}
+
+// defaultAnthropicMaxTokens is the max_tokens sent when none is configured
+const defaultAnthropicMaxTokens = 4096
+
+// DefaultAnthropicVersion is the anthropic-version header sent when none is configured
+const DefaultAnthropicVersion = "2023-06-01"
+
+// defaultAnthropicTemperature is used when no temperature is configured
+const defaultAnthropicTemperature = 0.7
+
+// anthropicBaseURL is the root of the Anthropic API
+const anthropicBaseURL = "https://api.anthropic.com/v1"
+
+// anthropicModelsPageSize is the largest page the models endpoint returns
+const anthropicModelsPageSize = 1000
 
 type AnthropicMessage struct {
 	Role    string `json:"role"`
@@ -21,13 +63,14 @@ type AnthropicRequest struct {
 	MaxTokens   int                `json:"max_tokens"`
 	Messages    []AnthropicMessage `json:"messages"`
 	System      string             `json:"system,omitempty"`
-	Temperature float64            `json:"temperature,omitempty"`
+	Temperature *float64           `json:"temperature,omitempty"`
 }
 
 type AnthropicResponse struct {
 	Content []struct {
 		Text string `json:"text"`
 	} `json:"content"`
+	StopReason string `json:"stop_reason"`
 }
 
 type AnthropicModelsResponse struct {
@@ -37,36 +80,87 @@ type AnthropicModelsResponse struct {
 		DisplayName string `json:"display_name"`
 		CreatedAt   string `json:"created_at"`
 	} `json:"data"`
+	HasMore bool   `json:"has_more"`
+	LastID  string `json:"last_id"`
 }
 
-func (p *AnthropicProvider) GenerateContent(ctx context.Context, prompt string, model string, apiKey string) (string, error) {
-	reqBody := AnthropicRequest{
+// baseURL returns the API root, honoring BaseURL when set
+func (p *AnthropicProvider) baseURL() string {
+	if p.BaseURL != "" {
+		return p.BaseURL
+	}
+	return anthropicBaseURL
+}
+
+// setHeaders adds the API key, the version and beta headers, and the tracing
+// headers to a request
+func (p *AnthropicProvider) setHeaders(req *http.Request, apiKey string) {
+	version := p.Version
+	if version == "" {
+		version = DefaultAnthropicVersion
+	}
+	req.Header.Set("x-api-key", apiKey)
+	setTracingHeaders(req, p.UserAgent)
+	req.Header.Set("anthropic-version", version)
+	if len(p.Beta) > 0 {
+		req.Header.Set("anthropic-beta", strings.Join(p.Beta, ","))
+	}
+}
+
+// MaxOutputTokens returns the max_tokens sent with each request
+func (p *AnthropicProvider) MaxOutputTokens() int {
+	if p.MaxTokens > 0 {
+		return p.MaxTokens
+	}
+	return defaultAnthropicMaxTokens
+}
+
+// SetMaxOutputTokens changes the max_tokens sent with later requests
+func (p *AnthropicProvider) SetMaxOutputTokens(tokens int) {
+	p.MaxTokens = tokens
+}
+
+// buildRequest creates the messages request body for a prompt
+func (p *AnthropicProvider) buildRequest(prompt string, model string) AnthropicRequest {
+	temperature := defaultAnthropicTemperature
+	if p.Temperature != nil {
+		temperature = *p.Temperature
+	}
+
+	return AnthropicRequest{
 		Model:     model,
-		MaxTokens: 4096,
+		MaxTokens: p.MaxOutputTokens(),
 		Messages: []AnthropicMessage{
 			{
 				Role:    "user",
 				Content: prompt,
 			},
 		},
-		Temperature: 0.7,
+		Temperature: &temperature,
+	}
+}
+
+func (p *AnthropicProvider) GenerateContent(ctx context.Context, prompt string, model string, apiKey string) (string, error) {
+	if err := p.RateLimiter.Wait(ctx); err != nil {
+		return "", err
 	}
 
+	reqBody := p.buildRequest(prompt, model)
+
 	jsonData, err := json.Marshal(reqBody)
 	if err != nil {
 		return "", fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, "POST", "https://api.anthropic.com/v1/messages", bytes.NewBuffer(jsonData))
+	req, err := http.NewRequestWithContext(ctx, "POST", p.baseURL()+"/messages", bytes.NewBuffer(jsonData))
 	if err != nil {
 		return "", fmt.Errorf("failed to create request: %w", err)
 	}
 
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("x-api-key", apiKey)
-	req.Header.Set("anthropic-version", "2023-06-01")
+	p.setHeaders(req, apiKey)
 
-	client := &http.Client{}
+	client := newHTTPClient(p.RequestTimeout)
 	resp, err := client.Do(req)
 	if err != nil {
 		return "", fmt.Errorf("failed to make request: %w", err)
@@ -75,7 +169,7 @@ func (p *AnthropicProvider) GenerateContent(ctx context.Context, prompt string,
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("API error: %s, body: %s", resp.Status, string(body))
+		return "", apiError(resp.Status, body)
 	}
 
 	body, err := io.ReadAll(resp.Body)
@@ -89,22 +183,60 @@ func (p *AnthropicProvider) GenerateContent(ctx context.Context, prompt string,
 	}
 
 	if len(response.Content) == 0 {
-		return "", fmt.Errorf("no content in response")
+		return "", fmt.Errorf("%w: no content in response", ErrEmptyResponse)
 	}
 
+	if response.StopReason == "max_tokens" {
+		return response.Content[0].Text, truncatedError(p.MaxOutputTokens())
+	}
 	return response.Content[0].Text, nil
 }
 
+// ListModels returns every model, following the has_more/last_id cursor
+// until the last page
 func (p *AnthropicProvider) ListModels(ctx context.Context, apiKey string) ([]ModelInfo, error) {
-	req, err := http.NewRequestWithContext(ctx, "GET", "https://api.anthropic.com/v1/models", nil)
+	var models []ModelInfo
+	afterID := ""
+	for {
+		page, err := p.listModelsPage(ctx, apiKey, afterID)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, model := range page.Data {
+			models = append(models, WithCapabilities(ModelInfo{
+				ID:   model.ID,
+				Name: model.DisplayName,
+			}))
+		}
+
+		if !page.HasMore || page.LastID == "" || page.LastID == afterID {
+			return models, nil
+		}
+		afterID = page.LastID
+	}
+}
+
+// listModelsPage fetches one page of models starting after afterID
+func (p *AnthropicProvider) listModelsPage(ctx context.Context, apiKey string, afterID string) (*AnthropicModelsResponse, error) {
+	if err := p.RateLimiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+
+	query := url.Values{}
+	query.Set("limit", fmt.Sprint(anthropicModelsPageSize))
+	if afterID != "" {
+		query.Set("after_id", afterID)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", p.baseURL()+"/models?"+query.Encode(), nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
-	req.Header.Set("x-api-key", apiKey)
-	req.Header.Set("anthropic-version", "2023-06-01")
+	p.setHeaders(req, apiKey)
 
-	client := &http.Client{}
+	client := newHTTPClient(p.RequestTimeout)
 	resp, err := client.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to make request: %w", err)
@@ -125,14 +257,5 @@ func (p *AnthropicProvider) ListModels(ctx context.Context, apiKey string) ([]Mo
 	if err := json.Unmarshal(body, &modelsResp); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
 	}
-
-	var models []ModelInfo
-	for _, model := range modelsResp.Data {
-		models = append(models, ModelInfo{
-			ID:   model.ID,
-			Name: model.DisplayName,
-		})
-	}
-
-	return models, nil
+	return &modelsResp, nil
 }
@@ -0,0 +1,98 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAnthropicProvider_ListModelsPaginates(t *testing.T) {
+	var cursors []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/models" {
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+		if got := r.Header.Get("x-api-key"); got != "test-key" {
+			t.Errorf("Expected API key header, got %q", got)
+		}
+		afterID := r.URL.Query().Get("after_id")
+		cursors = append(cursors, afterID)
+
+		w.Header().Set("Content-Type", "application/json")
+		switch afterID {
+		case "":
+			fmt.Fprint(w, `{"data":[{"id":"claude-a","display_name":"Claude A"},{"id":"claude-b","display_name":"Claude B"}],"has_more":true,"first_id":"claude-a","last_id":"claude-b"}`)
+		case "claude-b":
+			fmt.Fprint(w, `{"data":[{"id":"claude-c","display_name":"Claude C"}],"has_more":false,"first_id":"claude-c","last_id":"claude-c"}`)
+		default:
+			t.Errorf("Unexpected cursor %q", afterID)
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	provider := &AnthropicProvider{BaseURL: server.URL}
+	models, err := provider.ListModels(context.Background(), "test-key")
+	if err != nil {
+		t.Fatalf("ListModels failed: %v", err)
+	}
+
+	if len(cursors) != 2 || cursors[1] != "claude-b" {
+		t.Errorf("Expected two requests following the cursor, got %q", cursors)
+	}
+	var ids []string
+	for _, model := range models {
+		ids = append(ids, model.ID)
+	}
+	if fmt.Sprint(ids) != "[claude-a claude-b claude-c]" {
+		t.Errorf("Expected models from both pages, got %v", ids)
+	}
+}
+
+func TestAnthropicProvider_VersionHeaders(t *testing.T) {
+	tests := []struct {
+		name        string
+		provider    *AnthropicProvider
+		wantVersion string
+		wantBeta    string
+	}{
+		{"defaults", &AnthropicProvider{}, DefaultAnthropicVersion, ""},
+		{"configured", &AnthropicProvider{Version: "2024-10-22", Beta: []string{"prompt-caching-2024-07-31", "output-128k-2025-02-19"}},
+			"2024-10-22", "prompt-caching-2024-07-31,output-128k-2025-02-19"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var version, beta []string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				version = append(version, r.Header.Get("anthropic-version"))
+				beta = append(beta, r.Header.Get("anthropic-beta"))
+				w.Header().Set("Content-Type", "application/json")
+				if r.URL.Path == "/models" {
+					fmt.Fprint(w, `{"data":[],"has_more":false}`)
+					return
+				}
+				fmt.Fprint(w, `{"content":[{"text":"ok"}]}`)
+			}))
+			defer server.Close()
+
+			tt.provider.BaseURL = server.URL
+			if _, err := tt.provider.GenerateContent(context.Background(), "hi", "claude-test", "key"); err != nil {
+				t.Fatalf("GenerateContent failed: %v", err)
+			}
+			if _, err := tt.provider.ListModels(context.Background(), "key"); err != nil {
+				t.Fatalf("ListModels failed: %v", err)
+			}
+
+			for i := range version {
+				if version[i] != tt.wantVersion || beta[i] != tt.wantBeta {
+					t.Errorf("Request %d sent version %q beta %q, want %q and %q", i, version[i], beta[i], tt.wantVersion, tt.wantBeta)
+				}
+			}
+			if len(version) != 2 {
+				t.Errorf("Expected 2 requests, got %d", len(version))
+			}
+		})
+	}
+}
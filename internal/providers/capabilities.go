@@ -0,0 +1,96 @@
+package providers
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ModelCapabilities describes what a model family can do
+type ModelCapabilities struct {
+	ContextWindow  int
+	SupportsTools  bool
+	SupportsVision bool
+}
+
+// modelCapabilities maps model ID prefixes to their capabilities, for providers
+// whose model APIs do not report them. Longer prefixes win.
+var modelCapabilities = map[string]ModelCapabilities{
+	"gpt-5":             {ContextWindow: 400000, SupportsTools: true, SupportsVision: true},
+	"gpt-4.1":           {ContextWindow: 1047576, SupportsTools: true, SupportsVision: true},
+	"gpt-4o":            {ContextWindow: 128000, SupportsTools: true, SupportsVision: true},
+	"gpt-4-turbo":       {ContextWindow: 128000, SupportsTools: true, SupportsVision: true},
+	"gpt-4":             {ContextWindow: 8192, SupportsTools: true},
+	"gpt-3.5-turbo":     {ContextWindow: 16385, SupportsTools: true},
+	"o1":                {ContextWindow: 200000, SupportsTools: true, SupportsVision: true},
+	"o3-mini":           {ContextWindow: 200000, SupportsTools: true},
+	"o3":                {ContextWindow: 200000, SupportsTools: true, SupportsVision: true},
+	"claude-3-5-haiku":  {ContextWindow: 200000, SupportsTools: true},
+	"claude-3-5-sonnet": {ContextWindow: 200000, SupportsTools: true, SupportsVision: true},
+	"claude-3.5-sonnet": {ContextWindow: 200000, SupportsTools: true, SupportsVision: true},
+	"claude-3-7-sonnet": {ContextWindow: 200000, SupportsTools: true, SupportsVision: true},
+	"claude-3":          {ContextWindow: 200000, SupportsTools: true, SupportsVision: true},
+	"claude-sonnet-4":   {ContextWindow: 200000, SupportsTools: true, SupportsVision: true},
+	"claude-opus-4":     {ContextWindow: 200000, SupportsTools: true, SupportsVision: true},
+	"gemini-2.5":        {ContextWindow: 1048576, SupportsTools: true, SupportsVision: true},
+	"gemini-2.0":        {ContextWindow: 1048576, SupportsTools: true, SupportsVision: true},
+	"gemini-1.5-pro":    {ContextWindow: 2097152, SupportsTools: true, SupportsVision: true},
+	"gemini-1.5-flash":  {ContextWindow: 1048576, SupportsTools: true, SupportsVision: true},
+	"gemini-pro-vision": {ContextWindow: 16384, SupportsVision: true},
+	"gemini-pro":        {ContextWindow: 32760, SupportsTools: true},
+}
+
+// LookupCapabilities returns the capabilities for the longest matching model prefix
+func LookupCapabilities(model string) (ModelCapabilities, bool) {
+	prefixes := make([]string, 0, len(modelCapabilities))
+	for prefix := range modelCapabilities {
+		prefixes = append(prefixes, prefix)
+	}
+	sort.Slice(prefixes, func(i, j int) bool { return len(prefixes[i]) > len(prefixes[j]) })
+
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(model, prefix) {
+			return modelCapabilities[prefix], true
+		}
+	}
+	return ModelCapabilities{}, false
+}
+
+// WithCapabilities fills capability fields the provider API did not report
+// from the static table
+func WithCapabilities(info ModelInfo) ModelInfo {
+	capabilities, ok := LookupCapabilities(info.ID)
+	if !ok {
+		return info
+	}
+	if info.ContextWindow == 0 {
+		info.ContextWindow = capabilities.ContextWindow
+	}
+	if !info.SupportsTools {
+		info.SupportsTools = capabilities.SupportsTools
+	}
+	if !info.SupportsVision {
+		info.SupportsVision = capabilities.SupportsVision
+	}
+	return info
+}
+
+// CapabilitySummary describes the model's capabilities for display, e.g.
+// "128k context, tools, vision". It returns "" when nothing is known.
+func (m ModelInfo) CapabilitySummary() string {
+	var parts []string
+	if m.ContextWindow > 0 {
+		if m.ContextWindow >= 1000 {
+			parts = append(parts, fmt.Sprintf("%dk context", m.ContextWindow/1000))
+		} else {
+			parts = append(parts, fmt.Sprintf("%d context", m.ContextWindow))
+		}
+	}
+	if m.SupportsTools {
+		parts = append(parts, "tools")
+	}
+	if m.SupportsVision {
+		parts = append(parts, "vision")
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,39 @@
+package providers
+
+import "testing"
+
+func TestLookupCapabilities_LongestPrefixWins(t *testing.T) {
+	vision, ok := LookupCapabilities("gemini-pro-vision")
+	if !ok || vision.SupportsTools || !vision.SupportsVision {
+		t.Errorf("Expected gemini-pro-vision entry, got %+v", vision)
+	}
+
+	text, ok := LookupCapabilities("gemini-pro")
+	if !ok || !text.SupportsTools || text.SupportsVision {
+		t.Errorf("Expected gemini-pro entry, got %+v", text)
+	}
+
+	if _, ok := LookupCapabilities("unknown-model"); ok {
+		t.Error("Expected no capabilities for an unknown model")
+	}
+}
+
+func TestWithCapabilities_KeepsReportedValues(t *testing.T) {
+	info := WithCapabilities(ModelInfo{ID: "gemini-2.5-flash", ContextWindow: 500000})
+	if info.ContextWindow != 500000 {
+		t.Errorf("Expected API-reported context window to be kept, got %d", info.ContextWindow)
+	}
+	if !info.SupportsTools || !info.SupportsVision {
+		t.Errorf("Expected tools and vision from the static table, got %+v", info)
+	}
+}
+
+func TestModelInfo_CapabilitySummary(t *testing.T) {
+	info := ModelInfo{ID: "gpt-4o", ContextWindow: 128000, SupportsTools: true, SupportsVision: true}
+	if got := info.CapabilitySummary(); got != "128k context, tools, vision" {
+		t.Errorf("Unexpected summary: %q", got)
+	}
+	if got := (ModelInfo{ID: "custom"}).CapabilitySummary(); got != "" {
+		t.Errorf("Expected empty summary for unknown model, got %q", got)
+	}
+}
@@ -0,0 +1,44 @@
+package providers
+
+import (
+	"regexp"
+	"strings"
+)
+
+// openAIChatModelPattern matches OpenAI model IDs served by the chat
+// completions endpoint: gpt-*, chatgpt-*, and the o-series (o1, o3, o4-mini, ...)
+var openAIChatModelPattern = regexp.MustCompile(`^(gpt-|chatgpt-|o\d)`)
+
+// openAINonChatMarkers identify gpt-* and o-series variants that only serve
+// audio, image, embedding, or moderation endpoints
+var openAINonChatMarkers = []string{"embedding", "tts", "whisper", "dall-e", "moderation", "transcribe", "realtime", "audio", "image"}
+
+// IsChatModel reports whether a model ID from the provider's model list can be
+// used for chat. Only OpenAI mixes other model kinds into its list, so every
+// model of other providers is accepted.
+func IsChatModel(provider, modelID string) bool {
+	if provider != "openai" {
+		return true
+	}
+	id := strings.ToLower(modelID)
+	if !openAIChatModelPattern.MatchString(id) {
+		return false
+	}
+	for _, marker := range openAINonChatMarkers {
+		if strings.Contains(id, marker) {
+			return false
+		}
+	}
+	return true
+}
+
+// FilterChatModels keeps the models that IsChatModel accepts
+func FilterChatModels(provider string, models []ModelInfo) []ModelInfo {
+	var chat []ModelInfo
+	for _, model := range models {
+		if IsChatModel(provider, model.ID) {
+			chat = append(chat, model)
+		}
+	}
+	return chat
+}
@@ -0,0 +1,29 @@
+package providers
+
+import "testing"
+
+func TestIsChatModel_OpenAI(t *testing.T) {
+	chat := []string{"gpt-5-mini", "gpt-4o", "gpt-4.1-nano", "chatgpt-4o-latest", "o1", "o3-mini", "o4-mini-2025-04-16"}
+	for _, id := range chat {
+		if !IsChatModel("openai", id) {
+			t.Errorf("Expected %s to be a chat model", id)
+		}
+	}
+
+	other := []string{"text-embedding-3-small", "tts-1", "whisper-1", "dall-e-3", "omni-moderation-latest", "gpt-4o-mini-tts", "gpt-4o-transcribe", "gpt-4o-realtime-preview", "gpt-4o-audio-preview", "gpt-image-1", "davinci-002"}
+	for _, id := range other {
+		if IsChatModel("openai", id) {
+			t.Errorf("Expected %s to be filtered out", id)
+		}
+	}
+}
+
+func TestFilterChatModels_OtherProvidersUnchanged(t *testing.T) {
+	models := []ModelInfo{{ID: "claude-sonnet-4"}, {ID: "text-embedding-like"}}
+	if got := FilterChatModels("anthropic", models); len(got) != 2 {
+		t.Errorf("Expected non-OpenAI models to be kept, got %v", got)
+	}
+	if got := FilterChatModels("openai", []ModelInfo{{ID: "gpt-4o"}, {ID: "tts-1"}}); len(got) != 1 || got[0].ID != "gpt-4o" {
+		t.Errorf("Expected only gpt-4o, got %v", got)
+	}
+}
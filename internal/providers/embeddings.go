@@ -0,0 +1,157 @@
+package providers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"google.golang.org/genai"
+)
+
+// Embedder is implemented by providers with an embeddings endpoint. Anthropic
+// has none, so callers should check with a type assertion.
+type Embedder interface {
+	// Embed returns one vector per text, in order
+	Embed(ctx context.Context, texts []string, model string, apiKey string) ([][]float32, error)
+}
+
+// defaultEmbeddingModels is the embedding model used per provider when none is configured
+var defaultEmbeddingModels = map[string]string{
+	"openai": "text-embedding-3-small",
+	"google": "text-embedding-004",
+}
+
+// DefaultEmbeddingModel returns the default embedding model for a provider, or
+// "" when the provider has no embeddings endpoint
+func DefaultEmbeddingModel(provider string) string {
+	return defaultEmbeddingModels[provider]
+}
+
+// geminiEmbedBatchSize is the most texts the Gemini API embeds in one request
+const geminiEmbedBatchSize = 100
+
+// openAIEmbedBatchSize is how many texts are sent per OpenAI embeddings
+// request. The API takes up to 2048 inputs but also caps the total tokens of
+// a request, so long transcripts are sent in smaller batches.
+const openAIEmbedBatchSize = 100
+
+type OpenAIEmbeddingRequest struct {
+	Model string   `json:"model"`
+	Input []string `json:"input"`
+}
+
+type OpenAIEmbeddingResponse struct {
+	Data []struct {
+		Index     int       `json:"index"`
+		Embedding []float32 `json:"embedding"`
+	} `json:"data"`
+}
+
+// Embed calls the OpenAI embeddings endpoint in batches
+func (p *OpenAIProvider) Embed(ctx context.Context, texts []string, model string, apiKey string) ([][]float32, error) {
+	vectors := make([][]float32, 0, len(texts))
+	for start := 0; start < len(texts); start += openAIEmbedBatchSize {
+		end := min(start+openAIEmbedBatchSize, len(texts))
+		batch, err := p.embedBatch(ctx, texts[start:end], model, apiKey)
+		if err != nil {
+			return nil, err
+		}
+		vectors = append(vectors, batch...)
+	}
+	return vectors, nil
+}
+
+// embedBatch embeds texts with a single OpenAI embeddings request
+func (p *OpenAIProvider) embedBatch(ctx context.Context, texts []string, model string, apiKey string) ([][]float32, error) {
+	if err := p.RateLimiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+
+	jsonData, err := json.Marshal(OpenAIEmbeddingRequest{Model: model, Input: texts})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", p.baseURL()+"/embeddings", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+apiKey)
+	setTracingHeaders(req, p.UserAgent)
+
+	client := newHTTPClient(p.RequestTimeout)
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, apiError(resp.Status, body)
+	}
+
+	var response OpenAIEmbeddingResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	if len(response.Data) != len(texts) {
+		return nil, fmt.Errorf("expected %d embeddings, got %d", len(texts), len(response.Data))
+	}
+
+	vectors := make([][]float32, len(texts))
+	for _, item := range response.Data {
+		if item.Index < 0 || item.Index >= len(texts) {
+			return nil, fmt.Errorf("embedding index %d out of range", item.Index)
+		}
+		vectors[item.Index] = item.Embedding
+	}
+	return vectors, nil
+}
+
+// Embed calls the Gemini (or Vertex AI) embeddings endpoint in batches
+func (p *GeminiProvider) Embed(ctx context.Context, texts []string, model string, apiKey string) ([][]float32, error) {
+	timeout := p.RequestTimeout
+	if timeout <= 0 {
+		timeout = DefaultRequestTimeout
+	}
+
+	client, err := p.newClient(ctx, apiKey)
+	if err != nil {
+		return nil, err
+	}
+
+	vectors := make([][]float32, 0, len(texts))
+	for start := 0; start < len(texts); start += geminiEmbedBatchSize {
+		end := min(start+geminiEmbedBatchSize, len(texts))
+		if err := p.RateLimiter.Wait(ctx); err != nil {
+			return nil, err
+		}
+
+		contents := make([]*genai.Content, 0, end-start)
+		for _, text := range texts[start:end] {
+			contents = append(contents, genai.NewContentFromText(text, genai.RoleUser))
+		}
+
+		batchCtx, cancel := context.WithTimeout(ctx, timeout)
+		result, err := client.Models.EmbedContent(batchCtx, model, contents, nil)
+		cancel()
+		if err != nil {
+			return nil, fmt.Errorf("failed to embed content: %w", err)
+		}
+		if result == nil || len(result.Embeddings) != end-start {
+			return nil, fmt.Errorf("expected %d embeddings from Gemini", end-start)
+		}
+		for _, embedding := range result.Embeddings {
+			vectors = append(vectors, embedding.Values)
+		}
+	}
+	return vectors, nil
+}
@@ -0,0 +1,86 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestOpenAIProvider_Embed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/embeddings" {
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+		var request OpenAIEmbeddingRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Errorf("Failed to decode request: %v", err)
+		}
+		if request.Model != "text-embedding-3-small" || len(request.Input) != 2 {
+			t.Errorf("Unexpected request: %+v", request)
+		}
+		// Out of order, to check vectors are placed by index
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"data":[{"index":1,"embedding":[0,1]},{"index":0,"embedding":[1,0]}]}`)
+	}))
+	defer server.Close()
+
+	provider := &OpenAIProvider{BaseURL: server.URL}
+	vectors, err := provider.Embed(context.Background(), []string{"login flow", "lunch plans"}, "text-embedding-3-small", "test-key")
+	if err != nil {
+		t.Fatalf("Embed failed: %v", err)
+	}
+	if fmt.Sprint(vectors) != "[[1 0] [0 1]]" {
+		t.Errorf("Expected vectors in input order, got %v", vectors)
+	}
+}
+
+func TestOpenAIProvider_EmbedBatches(t *testing.T) {
+	var batches []int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request OpenAIEmbeddingRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Errorf("Failed to decode request: %v", err)
+		}
+		batches = append(batches, len(request.Input))
+		data := make([]map[string]interface{}, len(request.Input))
+		for i, input := range request.Input {
+			var n float32
+			fmt.Sscanf(input, "chunk %g", &n)
+			data[i] = map[string]interface{}{"index": i, "embedding": []float32{n}}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"data": data})
+	}))
+	defer server.Close()
+
+	texts := make([]string, openAIEmbedBatchSize*2+5)
+	for i := range texts {
+		texts[i] = fmt.Sprintf("chunk %d", i)
+	}
+	provider := &OpenAIProvider{BaseURL: server.URL}
+	vectors, err := provider.Embed(context.Background(), texts, "text-embedding-3-small", "test-key")
+	if err != nil {
+		t.Fatalf("Embed failed: %v", err)
+	}
+	if fmt.Sprint(batches) != fmt.Sprint([]int{openAIEmbedBatchSize, openAIEmbedBatchSize, 5}) {
+		t.Errorf("Expected requests of at most %d texts, got %v", openAIEmbedBatchSize, batches)
+	}
+	if len(vectors) != len(texts) || vectors[0][0] != 0 || vectors[len(texts)-1][0] != float32(len(texts)-1) {
+		t.Errorf("Expected one vector per text in input order, got %d vectors", len(vectors))
+	}
+}
+
+func TestEmbedderSupport(t *testing.T) {
+	for provider, supported := range map[string]bool{"openai": true, "google": true, "anthropic": false} {
+		_, ok := newTestProvider(t, provider, ProviderOptions{}).(Embedder)
+		if ok != supported {
+			t.Errorf("%s: expected Embedder support %v, got %v", provider, supported, ok)
+		}
+		if (DefaultEmbeddingModel(provider) != "") != supported {
+			t.Errorf("%s: unexpected default embedding model %q", provider, DefaultEmbeddingModel(provider))
+		}
+	}
+}
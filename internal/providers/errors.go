@@ -0,0 +1,72 @@
+package providers
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrContextLengthExceeded is returned when a request is larger than the model's context window
+var ErrContextLengthExceeded = errors.New("context length exceeded")
+
+// ErrProviderInit is returned when a provider's client cannot be created,
+// e.g. because no API key or Vertex AI project is configured
+var ErrProviderInit = errors.New("failed to initialize provider")
+
+// ErrUnknownProvider is returned when the configured provider is empty or not supported
+var ErrUnknownProvider = errors.New("unknown provider")
+
+// ErrOutputTruncated is returned along with the partial text when a response
+// stopped because it reached the maximum output tokens
+var ErrOutputTruncated = errors.New("response truncated at the max output tokens")
+
+// ErrBadRequest is returned when the provider rejected a request as invalid
+// (HTTP 400) for a reason other than its length, e.g. an unsupported response
+// schema
+var ErrBadRequest = errors.New("bad request")
+
+// ErrEmptyResponse is returned when a response carries no content, e.g. when
+// Gemini returns no candidates or a safety filter blocked the output. It is
+// often transient, so the agent retries the step.
+var ErrEmptyResponse = errors.New("no content generated")
+
+// truncatedError reports a response cut off at maxTokens (0 when the model default applied)
+func truncatedError(maxTokens int) error {
+	if maxTokens <= 0 {
+		return ErrOutputTruncated
+	}
+	return fmt.Errorf("%w (%d)", ErrOutputTruncated, maxTokens)
+}
+
+// contextLengthMarkers are the phrases providers use when rejecting an oversized prompt
+var contextLengthMarkers = []string{
+	"context_length_exceeded",              // OpenAI error code
+	"maximum context length",               // OpenAI message
+	"prompt is too long",                   // Anthropic
+	"input is too long",                    // Anthropic
+	"exceeds the maximum number of tokens", // Gemini
+}
+
+// isContextLengthError reports whether an error message describes an oversized prompt
+func isContextLengthError(message string) bool {
+	message = strings.ToLower(message)
+	for _, marker := range contextLengthMarkers {
+		if strings.Contains(message, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// apiError builds the error for a non-200 provider response, wrapping
+// ErrContextLengthExceeded when the body says the prompt was too long and
+// ErrBadRequest for other 400 responses
+func apiError(status string, body []byte) error {
+	if isContextLengthError(string(body)) {
+		return fmt.Errorf("%w: API error: %s, body: %s", ErrContextLengthExceeded, status, string(body))
+	}
+	if strings.HasPrefix(status, "400 ") {
+		return fmt.Errorf("%w: API error: %s, body: %s", ErrBadRequest, status, string(body))
+	}
+	return fmt.Errorf("API error: %s, body: %s", status, string(body))
+}
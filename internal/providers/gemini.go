@@ -2,37 +2,163 @@ package providers
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
 	"strings"
+	"time"
 
 	"google.golang.org/genai"
 )
 
-type GeminiProvider struct{}
+type GeminiProvider struct {
+	// VertexProject and VertexLocation select the Vertex AI backend (using
+	// Application Default Credentials) when both are set.
+	VertexProject  string
+	VertexLocation string
+
+	// RequestTimeout bounds each generation request (0 uses DefaultRequestTimeout).
+	// The genai client manages its own HTTP transport, so this is applied to the context.
+	RequestTimeout time.Duration
+
+	// Temperature and Seed are passed in the generation config when set
+	Temperature *float64
+	Seed        *int64
+
+	// RateLimiter throttles generation and model list requests when a rate limit is configured
+	RateLimiter *RateLimiter
+
+	// MaxTokens caps each response (0 leaves it to the model)
+	MaxTokens int
+
+	// UserAgent is added to the SDK's User-Agent header ("" uses DefaultUserAgent)
+	UserAgent string
+}
+
+// UsesVertex reports whether the provider is configured for Vertex AI
+func (p *GeminiProvider) UsesVertex() bool {
+	return p.VertexProject != "" && p.VertexLocation != ""
+}
+
+// newClient creates a genai client for the configured backend. Failures wrap
+// ErrProviderInit so callers never use a nil client.
+func (p *GeminiProvider) newClient(ctx context.Context, apiKey string) (*genai.Client, error) {
+	config := &genai.ClientConfig{
+		APIKey:  apiKey,
+		Backend: genai.BackendGeminiAPI,
+	}
+	if p.UsesVertex() {
+		config = &genai.ClientConfig{
+			Backend:  genai.BackendVertexAI,
+			Project:  p.VertexProject,
+			Location: p.VertexLocation,
+		}
+	}
+	// The client is created per call, so the request ID of ctx applies to its requests
+	config.HTTPOptions.Headers = tracingHeaders(ctx, p.UserAgent)
+
+	client, err := genai.NewClient(ctx, config)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrProviderInit, err)
+	}
+	if client == nil {
+		return nil, fmt.Errorf("%w: genai returned no client", ErrProviderInit)
+	}
+	return client, nil
+}
 
 func (p *GeminiProvider) GenerateContent(ctx context.Context, prompt string, model string, apiKey string) (string, error) {
-	client, err := genai.NewClient(ctx, &genai.ClientConfig{
-		APIKey: apiKey,
-	})
+	// Wait before starting the request timeout so throttling doesn't eat into it
+	if err := p.RateLimiter.Wait(ctx); err != nil {
+		return "", err
+	}
+
+	timeout := p.RequestTimeout
+	if timeout <= 0 {
+		timeout = DefaultRequestTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	// Fail fast rather than building a client for a request that cannot run
+	if err := ctx.Err(); err != nil {
+		return "", fmt.Errorf("failed to generate content: %w", err)
+	}
+
+	client, err := p.newClient(ctx, apiKey)
 	if err != nil {
-		return "", fmt.Errorf("failed to create client: %w", err)
+		return "", err
 	}
 
 	result, err := client.Models.GenerateContent(
 		ctx,
 		model,
 		genai.Text(prompt),
-		nil, // no config needed for basic text generation
+		p.generationConfig(ResponseSchema(ctx)),
 	)
 	if err != nil {
+		// Surface the deadline or cancellation rather than a transport error
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return "", fmt.Errorf("failed to generate content: %w", ctxErr)
+		}
+		if isContextLengthError(err.Error()) {
+			return "", fmt.Errorf("failed to generate content: %w: %v", ErrContextLengthExceeded, err)
+		}
+		var apiErr genai.APIError
+		if errors.As(err, &apiErr) && apiErr.Code == http.StatusBadRequest {
+			return "", fmt.Errorf("failed to generate content: %w: %v", ErrBadRequest, err)
+		}
 		return "", fmt.Errorf("failed to generate content: %w", err)
 	}
 
 	if result == nil || len(result.Candidates) == 0 {
-		return "", fmt.Errorf("no content generated")
+		return "", ErrEmptyResponse
 	}
 
-	return result.Text(), nil
+	if result.Candidates[0].FinishReason == genai.FinishReasonMaxTokens {
+		return result.Text(), truncatedError(p.MaxTokens)
+	}
+	text := result.Text()
+	if text == "" {
+		// A blocked candidate (e.g. FinishReasonSafety) has no parts
+		return "", fmt.Errorf("%w (finish reason %s)", ErrEmptyResponse, result.Candidates[0].FinishReason)
+	}
+	return text, nil
+}
+
+// MaxOutputTokens returns the maxOutputTokens sent, or 0 for the model default
+func (p *GeminiProvider) MaxOutputTokens() int {
+	return p.MaxTokens
+}
+
+// SetMaxOutputTokens changes the maxOutputTokens sent with later requests
+func (p *GeminiProvider) SetMaxOutputTokens(tokens int) {
+	p.MaxTokens = tokens
+}
+
+// generationConfig returns sampling and response format overrides, or nil to
+// use the model defaults. A response schema requests application/json
+// responses constrained to it.
+func (p *GeminiProvider) generationConfig(schema map[string]interface{}) *genai.GenerateContentConfig {
+	if p.Temperature == nil && p.Seed == nil && p.MaxTokens <= 0 && schema == nil {
+		return nil
+	}
+
+	config := &genai.GenerateContentConfig{}
+	if p.Temperature != nil {
+		config.Temperature = genai.Ptr(float32(*p.Temperature))
+	}
+	if p.Seed != nil {
+		config.Seed = genai.Ptr(int32(*p.Seed))
+	}
+	if p.MaxTokens > 0 {
+		config.MaxOutputTokens = int32(p.MaxTokens)
+	}
+	if schema != nil {
+		config.ResponseMIMEType = "application/json"
+		config.ResponseJsonSchema = schema
+	}
+	return config
 }
 
 func (p *GeminiProvider) ListModels(ctx context.Context, apiKey string) ([]ModelInfo, error) {
@@ -44,20 +170,30 @@ func (p *GeminiProvider) ListModels(ctx context.Context, apiKey string) ([]Model
 		{ID: "gemini-pro", Name: "Gemini Pro"},
 		{ID: "gemini-pro-vision", Name: "Gemini Pro Vision"},
 	}
+	for i := range knownModels {
+		knownModels[i] = WithCapabilities(knownModels[i])
+	}
 
-	if apiKey == "" {
-		return knownModels, fmt.Errorf("API key is required")
+	// Without credentials, fall back to the static list
+	if apiKey == "" && !p.UsesVertex() {
+		return knownModels, nil
 	}
 
-	// ctx = context.Background()
-	client, err := genai.NewClient(ctx, &genai.ClientConfig{
-		APIKey:  apiKey,
-		Backend: genai.BackendGeminiAPI,
-	})
-	if err != nil {
-		
+	if err := p.RateLimiter.Wait(ctx); err != nil {
+		return knownModels, err
 	}
 
+	timeout := p.RequestTimeout
+	if timeout <= 0 {
+		timeout = DefaultRequestTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	client, err := p.newClient(ctx, apiKey)
+	if err != nil {
+		return knownModels, err
+	}
 
 	// Retrieve the list of models.
 	models, err := client.Models.List(ctx, &genai.ListModelsConfig{})
@@ -66,40 +202,19 @@ func (p *GeminiProvider) ListModels(ctx context.Context, apiKey string) ([]Model
 	}
 
 	modelInfo := []ModelInfo{}
-	// fmt.Println("List of models that support generateContent:")
 	for _, m := range models.Items {
-		for _, action := range m.SupportedActions {
-			if action == "generateContent" {
-				// fmt.Println(m.Name)
-				break
-			}
-		}
-		modelInfo = append(modelInfo, ModelInfo{
-			ID:   strings.ReplaceAll(m.Name, "models/", ""),
-			Name: strings.ReplaceAll(m.DisplayName, "models/", ""),
-		})
-		fmt.Printf("Model: %s, Display Name: %s\n", modelInfo[len(modelInfo) - 1].ID, modelInfo[len(modelInfo) - 1].Name)
+		// Vertex AI returns fully qualified names (publishers/google/models/<id>)
+		// The API reports the input token limit; tools and vision come from the static table
+		modelInfo = append(modelInfo, WithCapabilities(ModelInfo{
+			ID:            m.Name[strings.LastIndex(m.Name, "/")+1:],
+			Name:          strings.ReplaceAll(m.DisplayName, "models/", ""),
+			ContextWindow: int(m.InputTokenLimit),
+		}))
 	}
 
-
-    /*
-	fmt.Println("\nList of models that support embedContent:")
-	for _, m := range models.Items {
-		for _, action := range m.SupportedActions {
-			if action == "embedContent" {
-				name := strings.ReplaceAll(m.DisplayName, "models/", "")
-				id := strings.ReplaceAll(m.Name, "models/", "")
-				fmt.Printf("Model: %s, Display Name: %s\n", id, name)
-				break
-			}
-		}
-	}
-	*/
-
 	if len(modelInfo) > 0 {
 		return modelInfo, nil
 	}
 
-
 	return knownModels, fmt.Errorf("no models found")
 }
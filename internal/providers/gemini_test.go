@@ -2,8 +2,10 @@ package providers
 
 import (
 	"context"
+	"errors"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestGeminiProvider_GenerateContent(t *testing.T) {
@@ -184,3 +186,54 @@ func TestGeminiProvider_ErrorHandling(t *testing.T) {
 		}
 	})
 }
+
+func TestGeminiProvider_ContextHandling(t *testing.T) {
+	t.Run("cancelled context returns promptly", func(t *testing.T) {
+		provider := &GeminiProvider{}
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		start := time.Now()
+		_, err := provider.GenerateContent(ctx, "test", "gemini-1.5-flash", "fake-key")
+		if err == nil {
+			t.Fatal("Expected error for cancelled context, got nil")
+		}
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected context.Canceled, got: %v", err)
+		}
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Errorf("Expected prompt return for cancelled context, took %s", elapsed)
+		}
+	})
+
+	t.Run("request timeout bounds the call", func(t *testing.T) {
+		provider := newTestProvider(t, "google", ProviderOptions{RequestTimeout: time.Nanosecond})
+
+		start := time.Now()
+		_, err := provider.GenerateContent(context.Background(), "test", "gemini-1.5-flash", "fake-key")
+		if err == nil {
+			t.Fatal("Expected error when the request timeout has elapsed, got nil")
+		}
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Expected context.DeadlineExceeded, got: %v", err)
+		}
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Errorf("Expected prompt return after timeout, took %s", elapsed)
+		}
+	})
+}
+
+func TestGeminiProvider_ClientInitFailure(t *testing.T) {
+	// genai falls back to these when no key is passed
+	t.Setenv("GOOGLE_API_KEY", "")
+	t.Setenv("GEMINI_API_KEY", "")
+	provider := &GeminiProvider{}
+	ctx := context.Background()
+
+	if _, err := provider.GenerateContent(ctx, "test prompt", "gemini-1.5-flash", ""); !errors.Is(err, ErrProviderInit) {
+		t.Errorf("Expected GenerateContent to return ErrProviderInit, got %v", err)
+	}
+	if _, err := provider.Embed(ctx, []string{"text"}, "text-embedding-004", ""); !errors.Is(err, ErrProviderInit) {
+		t.Errorf("Expected Embed to return ErrProviderInit, got %v", err)
+	}
+}
@@ -0,0 +1,21 @@
+package providers
+
+import "context"
+
+// responseSchemaKey is the context key of the response schema
+type responseSchemaKey struct{}
+
+// WithResponseSchema returns a context whose request asks for JSON responses
+// matching schema (a JSON Schema object), keeping responses from drifting
+// into prose or fenced code blocks. OpenAI and Gemini have a native JSON
+// mode; OpenAI only guarantees valid JSON, and Anthropic ignores the schema.
+// Providers that reject the schema fail with ErrBadRequest.
+func WithResponseSchema(ctx context.Context, schema map[string]interface{}) context.Context {
+	return context.WithValue(ctx, responseSchemaKey{}, schema)
+}
+
+// ResponseSchema returns the schema set with WithResponseSchema, or nil
+func ResponseSchema(ctx context.Context) map[string]interface{} {
+	schema, _ := ctx.Value(responseSchemaKey{}).(map[string]interface{})
+	return schema
+}
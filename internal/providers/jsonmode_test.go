@@ -0,0 +1,62 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestOpenAIProvider_SendsJSONResponseFormat(t *testing.T) {
+	var formats []interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		formats = append(formats, body["response_format"])
+		fmt.Fprint(w, `{"choices":[{"message":{"content":"{}"},"finish_reason":"stop"}]}`)
+	}))
+	defer server.Close()
+
+	provider := &OpenAIProvider{BaseURL: server.URL}
+	ctx := WithResponseSchema(context.Background(), map[string]interface{}{"type": "object"})
+	provider.GenerateContent(ctx, "hi", "model", "key")
+	provider.GenerateContent(context.Background(), "hi", "model", "key")
+
+	if len(formats) != 2 {
+		t.Fatalf("Expected 2 requests, got %d", len(formats))
+	}
+	if format, ok := formats[0].(map[string]interface{}); !ok || format["type"] != "json_object" {
+		t.Errorf("Expected response_format json_object, got %v", formats[0])
+	}
+	if formats[1] != nil {
+		t.Errorf("Expected JSON mode to apply only to the request that asked for it, got %v", formats[1])
+	}
+}
+
+func TestOpenAIProvider_BadRequest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `{"error":{"message":"'response_format' of type 'json_object' is not supported with this model."}}`)
+	}))
+	defer server.Close()
+
+	provider := &OpenAIProvider{BaseURL: server.URL}
+	_, err := provider.GenerateContent(context.Background(), "hi", "model", "key")
+	if !errors.Is(err, ErrBadRequest) {
+		t.Errorf("Expected ErrBadRequest, got %v", err)
+	}
+}
+
+func TestGeminiProvider_GenerationConfigRequestsJSON(t *testing.T) {
+	provider := &GeminiProvider{}
+	if provider.generationConfig(nil) != nil {
+		t.Fatal("Expected no generation config by default")
+	}
+	config := provider.generationConfig(map[string]interface{}{"type": "object"})
+	if config == nil || config.ResponseMIMEType != "application/json" || config.ResponseJsonSchema == nil {
+		t.Errorf("Expected a JSON response config, got %+v", config)
+	}
+}
@@ -2,34 +2,18 @@ package providers
 
 import (
 	"context"
-	"encoding/json"
 	"os"
 	"testing"
 
-	"path/filepath"
+	"github.com/landanqrew/mermaid-agent-documenter/internal/config"
 )
 
-// Local config struct to avoid import cycle
-type testConfig struct {
-	Secrets map[string]string `json:"secrets,omitempty"`
-}
-
-func loadTestConfig() (*testConfig, error) {
-	home, _ := os.UserHomeDir()
-	configPath := filepath.Join(home, "mermaid-agent-documenter", "config.json")
-
-	if _, err := os.Stat(configPath); os.IsNotExist(err) {
-		return nil, err
-	}
-
-	data, err := os.ReadFile(configPath)
-	if err != nil {
+// loadTestConfig returns the real config, or an error so tests skip when there is none
+func loadTestConfig() (*config.Config, error) {
+	if _, err := os.Stat(config.Path()); err != nil {
 		return nil, err
 	}
-
-	var config testConfig
-	err = json.Unmarshal(data, &config)
-	return &config, err
+	return config.Load()
 }
 
 func TestListGeminiModels(t *testing.T) {
@@ -7,9 +7,40 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"time"
 )
 
-type OpenAIProvider struct{}
+type OpenAIProvider struct {
+	// RequestTimeout bounds each HTTP request (0 uses DefaultRequestTimeout)
+	RequestTimeout time.Duration
+
+	// Temperature and Seed are sent when set (see ProviderOptions.Deterministic)
+	Temperature *float64
+	Seed        *int64
+
+	// RateLimiter throttles requests when a rate limit is configured
+	RateLimiter *RateLimiter
+
+	// BaseURL overrides openAIBaseURL (used by tests)
+	BaseURL string
+
+	// UserAgent is sent in the User-Agent header ("" uses DefaultUserAgent)
+	UserAgent string
+
+	// MaxTokens caps each response (0 leaves it to the model)
+	MaxTokens int
+}
+
+// openAIBaseURL is the root of the OpenAI API
+const openAIBaseURL = "https://api.openai.com/v1"
+
+// baseURL returns the API root, honoring BaseURL when set
+func (p *OpenAIProvider) baseURL() string {
+	if p.BaseURL != "" {
+		return p.BaseURL
+	}
+	return openAIBaseURL
+}
 
 type OpenAIMessage struct {
 	Role    string `json:"role"`
@@ -17,8 +48,18 @@ type OpenAIMessage struct {
 }
 
 type OpenAIRequest struct {
-	Model    string          `json:"model"`
-	Messages []OpenAIMessage `json:"messages"`
+	Model       string          `json:"model"`
+	Messages    []OpenAIMessage `json:"messages"`
+	Temperature *float64        `json:"temperature,omitempty"`
+	Seed        *int64          `json:"seed,omitempty"`
+	MaxTokens   int             `json:"max_completion_tokens,omitempty"`
+
+	ResponseFormat *OpenAIResponseFormat `json:"response_format,omitempty"`
+}
+
+// OpenAIResponseFormat selects the response format, e.g. {"type": "json_object"}
+type OpenAIResponseFormat struct {
+	Type string `json:"type"`
 }
 
 type OpenAIResponse struct {
@@ -26,6 +67,7 @@ type OpenAIResponse struct {
 		Message struct {
 			Content string `json:"content"`
 		} `json:"message"`
+		FinishReason string `json:"finish_reason"`
 	} `json:"choices"`
 }
 
@@ -39,8 +81,12 @@ type OpenAIModelsResponse struct {
 	} `json:"data"`
 }
 
-func (p *OpenAIProvider) GenerateContent(ctx context.Context, prompt string, model string, apiKey string) (string, error) {
-	reqBody := OpenAIRequest{
+// buildRequest creates the chat completion request body for a prompt. A
+// response schema switches the request to JSON mode; strict json_schema mode
+// would reject the free-form args and manifest objects, so only
+// response_format json_object is sent.
+func (p *OpenAIProvider) buildRequest(prompt string, model string, schema map[string]interface{}) OpenAIRequest {
+	request := OpenAIRequest{
 		Model: model,
 		Messages: []OpenAIMessage{
 			{
@@ -48,22 +94,48 @@ func (p *OpenAIProvider) GenerateContent(ctx context.Context, prompt string, mod
 				Content: prompt,
 			},
 		},
+		Temperature: p.Temperature,
+		Seed:        p.Seed,
+		MaxTokens:   p.MaxTokens,
 	}
+	if schema != nil {
+		request.ResponseFormat = &OpenAIResponseFormat{Type: "json_object"}
+	}
+	return request
+}
+
+// MaxOutputTokens returns the max_completion_tokens sent, or 0 for the model default
+func (p *OpenAIProvider) MaxOutputTokens() int {
+	return p.MaxTokens
+}
+
+// SetMaxOutputTokens changes the max_completion_tokens sent with later requests
+func (p *OpenAIProvider) SetMaxOutputTokens(tokens int) {
+	p.MaxTokens = tokens
+}
+
+func (p *OpenAIProvider) GenerateContent(ctx context.Context, prompt string, model string, apiKey string) (string, error) {
+	if err := p.RateLimiter.Wait(ctx); err != nil {
+		return "", err
+	}
+
+	reqBody := p.buildRequest(prompt, model, ResponseSchema(ctx))
 
 	jsonData, err := json.Marshal(reqBody)
 	if err != nil {
 		return "", fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, "POST", "https://api.openai.com/v1/chat/completions", bytes.NewBuffer(jsonData))
+	req, err := http.NewRequestWithContext(ctx, "POST", p.baseURL()+"/chat/completions", bytes.NewBuffer(jsonData))
 	if err != nil {
 		return "", fmt.Errorf("failed to create request: %w", err)
 	}
 
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", "Bearer "+apiKey)
+	setTracingHeaders(req, p.UserAgent)
 
-	client := &http.Client{}
+	client := newHTTPClient(p.RequestTimeout)
 	resp, err := client.Do(req)
 	if err != nil {
 		return "", fmt.Errorf("failed to make request: %w", err)
@@ -72,7 +144,7 @@ func (p *OpenAIProvider) GenerateContent(ctx context.Context, prompt string, mod
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("API error: %s, body: %s", resp.Status, string(body))
+		return "", apiError(resp.Status, body)
 	}
 
 	body, err := io.ReadAll(resp.Body)
@@ -86,21 +158,29 @@ func (p *OpenAIProvider) GenerateContent(ctx context.Context, prompt string, mod
 	}
 
 	if len(response.Choices) == 0 {
-		return "", fmt.Errorf("no choices in response")
+		return "", fmt.Errorf("%w: no choices in response", ErrEmptyResponse)
 	}
 
+	if response.Choices[0].FinishReason == "length" {
+		return response.Choices[0].Message.Content, truncatedError(p.MaxTokens)
+	}
 	return response.Choices[0].Message.Content, nil
 }
 
 func (p *OpenAIProvider) ListModels(ctx context.Context, apiKey string) ([]ModelInfo, error) {
-	req, err := http.NewRequestWithContext(ctx, "GET", "https://api.openai.com/v1/models", nil)
+	if err := p.RateLimiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", p.baseURL()+"/models", nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	req.Header.Set("Authorization", "Bearer "+apiKey)
+	setTracingHeaders(req, p.UserAgent)
 
-	client := &http.Client{}
+	client := newHTTPClient(p.RequestTimeout)
 	resp, err := client.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to make request: %w", err)
@@ -124,11 +204,11 @@ func (p *OpenAIProvider) ListModels(ctx context.Context, apiKey string) ([]Model
 
 	var models []ModelInfo
 	for _, model := range modelsResp.Data {
-		models = append(models, ModelInfo{
+		models = append(models, WithCapabilities(ModelInfo{
 			ID:      model.ID,
 			Name:    model.ID, // OpenAI uses ID as the name
 			Created: model.Created,
-		})
+		}))
 	}
 
 	return models, nil
@@ -0,0 +1,110 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"google.golang.org/genai"
+)
+
+// ErrAuthFailed is returned by Ping when the provider rejects the API key
+var ErrAuthFailed = errors.New("authentication failed")
+
+// ErrUnreachable is returned by Ping when the provider cannot be reached
+var ErrUnreachable = errors.New("provider unreachable")
+
+// DefaultPingTimeout bounds a health check, which should answer quickly
+const DefaultPingTimeout = 15 * time.Second
+
+// pingTimeout returns the shorter of the request timeout and DefaultPingTimeout
+func pingTimeout(requestTimeout time.Duration) time.Duration {
+	if requestTimeout > 0 && requestTimeout < DefaultPingTimeout {
+		return requestTimeout
+	}
+	return DefaultPingTimeout
+}
+
+// pingHTTP sends a lightweight request and classifies the outcome: a 401 or
+// 403 wraps ErrAuthFailed, a transport failure wraps ErrUnreachable, and any
+// other non-200 status is an API error
+func pingHTTP(req *http.Request, timeout time.Duration) error {
+	resp, err := newHTTPClient(pingTimeout(timeout)).Do(req)
+	if err != nil {
+		if req.Context().Err() != nil {
+			return req.Context().Err()
+		}
+		return fmt.Errorf("%w: %v", ErrUnreachable, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		return fmt.Errorf("%w: %s", ErrAuthFailed, resp.Status)
+	}
+	return apiError(resp.Status, body)
+}
+
+// Ping checks the key against the models endpoint without generating anything
+func (p *OpenAIProvider) Ping(ctx context.Context, apiKey string) error {
+	if err := p.RateLimiter.Wait(ctx); err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", p.baseURL()+"/models", nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+apiKey)
+	setTracingHeaders(req, p.UserAgent)
+	return pingHTTP(req, p.RequestTimeout)
+}
+
+// Ping checks the key by fetching a single page of one model
+func (p *AnthropicProvider) Ping(ctx context.Context, apiKey string) error {
+	if err := p.RateLimiter.Wait(ctx); err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", p.baseURL()+"/models?limit=1", nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	p.setHeaders(req, apiKey)
+	return pingHTTP(req, p.RequestTimeout)
+}
+
+// Ping lists a single model with the API key or, for Vertex AI, the
+// Application Default Credentials
+func (p *GeminiProvider) Ping(ctx context.Context, apiKey string) error {
+	ctx, cancel := context.WithTimeout(ctx, pingTimeout(p.RequestTimeout))
+	defer cancel()
+
+	client, err := p.newClient(ctx, apiKey)
+	if err != nil {
+		return err
+	}
+	if _, err := client.Models.List(ctx, &genai.ListModelsConfig{PageSize: 1}); err != nil {
+		return geminiPingError(err)
+	}
+	return nil
+}
+
+// geminiPingError classifies a failed Gemini health check. The Gemini API
+// reports an invalid key as 400 API_KEY_INVALID rather than 401.
+func geminiPingError(err error) error {
+	var apiErr genai.APIError
+	if !errors.As(err, &apiErr) {
+		return fmt.Errorf("%w: %v", ErrUnreachable, err)
+	}
+	if apiErr.Code == http.StatusUnauthorized || apiErr.Code == http.StatusForbidden ||
+		strings.Contains(apiErr.Error(), "API_KEY_INVALID") || strings.Contains(apiErr.Error(), "API key not valid") {
+		return fmt.Errorf("%w: %s", ErrAuthFailed, apiErr.Status)
+	}
+	return fmt.Errorf("API error: %w", apiErr)
+}
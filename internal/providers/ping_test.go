@@ -0,0 +1,76 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"google.golang.org/genai"
+)
+
+func TestPing(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		wantErr error
+	}{
+		{name: "ok", status: http.StatusOK},
+		{name: "invalid key", status: http.StatusUnauthorized, wantErr: ErrAuthFailed},
+		{name: "forbidden", status: http.StatusForbidden, wantErr: ErrAuthFailed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var paths []string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				paths = append(paths, r.URL.Path)
+				w.WriteHeader(tt.status)
+				w.Write([]byte(`{}`))
+			}))
+			defer server.Close()
+
+			for _, provider := range []LLMProvider{
+				&OpenAIProvider{BaseURL: server.URL},
+				&AnthropicProvider{BaseURL: server.URL},
+			} {
+				err := provider.Ping(context.Background(), "key")
+				if tt.wantErr == nil && err != nil {
+					t.Errorf("%T: unexpected error: %v", provider, err)
+				}
+				if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+					t.Errorf("%T: expected %v, got %v", provider, tt.wantErr, err)
+				}
+			}
+			for _, path := range paths {
+				if path != "/models" {
+					t.Errorf("Expected the models endpoint, got %s", path)
+				}
+			}
+		})
+	}
+}
+
+func TestPing_Unreachable(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	url := server.URL
+	server.Close()
+
+	err := (&OpenAIProvider{BaseURL: url}).Ping(context.Background(), "key")
+	if !errors.Is(err, ErrUnreachable) {
+		t.Errorf("Expected ErrUnreachable, got %v", err)
+	}
+}
+
+func TestGeminiPingError(t *testing.T) {
+	invalid := geminiPingError(genai.APIError{Code: 400, Message: "API key not valid. Please pass a valid API key.", Status: "INVALID_ARGUMENT"})
+	if !errors.Is(invalid, ErrAuthFailed) {
+		t.Errorf("Expected ErrAuthFailed for an invalid key, got %v", invalid)
+	}
+	if err := geminiPingError(errors.New("dial tcp: no such host")); !errors.Is(err, ErrUnreachable) {
+		t.Errorf("Expected ErrUnreachable for a network error, got %v", err)
+	}
+	if err := geminiPingError(genai.APIError{Code: 500, Message: "internal", Status: "INTERNAL"}); errors.Is(err, ErrAuthFailed) || errors.Is(err, ErrUnreachable) {
+		t.Errorf("Expected a plain API error, got %v", err)
+	}
+}
@@ -0,0 +1,68 @@
+package providers
+
+import (
+	"sort"
+	"strings"
+)
+
+// ModelPricing holds USD prices per million tokens for a model family
+type ModelPricing struct {
+	InputPerMillion  float64 `json:"inputPerMillion"`
+	OutputPerMillion float64 `json:"outputPerMillion"`
+}
+
+// modelPricing maps model ID prefixes to their list prices. Longer prefixes win.
+var modelPricing = map[string]ModelPricing{
+	"gpt-5-nano":        {InputPerMillion: 0.05, OutputPerMillion: 0.40},
+	"gpt-5-mini":        {InputPerMillion: 0.25, OutputPerMillion: 2.00},
+	"gpt-5":             {InputPerMillion: 1.25, OutputPerMillion: 10.00},
+	"gpt-4o-mini":       {InputPerMillion: 0.15, OutputPerMillion: 0.60},
+	"gpt-4o":            {InputPerMillion: 2.50, OutputPerMillion: 10.00},
+	"gpt-4-turbo":       {InputPerMillion: 10.00, OutputPerMillion: 30.00},
+	"gpt-4":             {InputPerMillion: 30.00, OutputPerMillion: 60.00},
+	"gpt-3.5-turbo":     {InputPerMillion: 0.50, OutputPerMillion: 1.50},
+	"claude-3-5-haiku":  {InputPerMillion: 0.80, OutputPerMillion: 4.00},
+	"claude-3-5-sonnet": {InputPerMillion: 3.00, OutputPerMillion: 15.00},
+	"claude-3.5-sonnet": {InputPerMillion: 3.00, OutputPerMillion: 15.00},
+	"claude-3-haiku":    {InputPerMillion: 0.25, OutputPerMillion: 1.25},
+	"claude-3-sonnet":   {InputPerMillion: 3.00, OutputPerMillion: 15.00},
+	"claude-3-opus":     {InputPerMillion: 15.00, OutputPerMillion: 75.00},
+	"gemini-2.5-pro":    {InputPerMillion: 1.25, OutputPerMillion: 10.00},
+	"gemini-2.5-flash":  {InputPerMillion: 0.30, OutputPerMillion: 2.50},
+	"gemini-1.5-pro":    {InputPerMillion: 1.25, OutputPerMillion: 5.00},
+	"gemini-1.5-flash":  {InputPerMillion: 0.075, OutputPerMillion: 0.30},
+}
+
+// LookupPricing returns the pricing entry for the longest matching model prefix
+func LookupPricing(model string) (ModelPricing, bool) {
+	prefixes := make([]string, 0, len(modelPricing))
+	for prefix := range modelPricing {
+		prefixes = append(prefixes, prefix)
+	}
+	sort.Slice(prefixes, func(i, j int) bool { return len(prefixes[i]) > len(prefixes[j]) })
+
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(model, prefix) {
+			return modelPricing[prefix], true
+		}
+	}
+	return ModelPricing{}, false
+}
+
+// EstimateCost returns the estimated USD cost for the given token counts
+func EstimateCost(model string, promptTokens, completionTokens int) float64 {
+	pricing, ok := LookupPricing(model)
+	if !ok {
+		return 0
+	}
+	return float64(promptTokens)/1_000_000*pricing.InputPerMillion +
+		float64(completionTokens)/1_000_000*pricing.OutputPerMillion
+}
+
+// EstimateTokens gives a rough token count for text (about 4 characters per token)
+func EstimateTokens(text string) int {
+	if text == "" {
+		return 0
+	}
+	return (len(text) + 3) / 4
+}
@@ -2,28 +2,147 @@ package providers
 
 import (
 	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
 )
 
+// DefaultRequestTimeout bounds a single provider HTTP request when no timeout is configured
+const DefaultRequestTimeout = 120 * time.Second
+
 type ModelInfo struct {
-	ID      string `json:"id"`
-	Name    string `json:"name,omitempty"`
-	Created int64  `json:"created,omitempty"`
+	ID             string `json:"id"`
+	Name           string `json:"name,omitempty"`
+	Created        int64  `json:"created,omitempty"`
+	ContextWindow  int    `json:"contextWindow,omitempty"`  // max input tokens, 0 if unknown
+	SupportsTools  bool   `json:"supportsTools,omitempty"`  // tool/function calling
+	SupportsVision bool   `json:"supportsVision,omitempty"` // image input
 }
 
 type LLMProvider interface {
 	GenerateContent(ctx context.Context, prompt string, model string, apiKey string) (string, error)
 	ListModels(ctx context.Context, apiKey string) ([]ModelInfo, error)
+	// Ping checks that the provider is reachable and accepts the key without
+	// generating anything. Failures wrap ErrAuthFailed or ErrUnreachable when
+	// the cause is known.
+	Ping(ctx context.Context, apiKey string) error
+}
+
+// OutputLimiter is implemented by providers whose maximum output tokens can
+// be raised between requests, e.g. after a response was truncated
+type OutputLimiter interface {
+	// MaxOutputTokens returns the current limit, or 0 when the model default applies
+	MaxOutputTokens() int
+	SetMaxOutputTokens(tokens int)
+}
+
+// ProviderOptions carries provider-specific settings from the config
+type ProviderOptions struct {
+	// VertexProject and VertexLocation switch the Gemini provider to the Vertex AI backend
+	VertexProject  string
+	VertexLocation string
+
+	// RequestTimeout bounds each individual HTTP request (0 uses DefaultRequestTimeout)
+	RequestTimeout time.Duration
+
+	// Temperature overrides the provider's default sampling temperature when set
+	Temperature *float64
+
+	// Seed requests reproducible sampling from providers that support it (OpenAI, Gemini)
+	Seed *int64
+
+	// RequestsPerMinute throttles requests to the provider (0 means unlimited).
+	// The limit is shared by every provider instance with the same name.
+	RequestsPerMinute int
+
+	// AnthropicVersion and AnthropicBeta set the Anthropic API version and
+	// beta headers ("" uses DefaultAnthropicVersion)
+	AnthropicVersion string
+	AnthropicBeta    []string
+
+	// MaxOutputTokens caps each response (0 uses the provider default: 4096
+	// for Anthropic, the model's own limit for OpenAI and Gemini)
+	MaxOutputTokens int
+
+	// UserAgent is sent with every request ("" uses DefaultUserAgent)
+	UserAgent string
 }
 
-func GetProvider(providerName string) LLMProvider {
+// DeterministicSeed is the fixed seed used for deterministic runs
+const DeterministicSeed int64 = 42
+
+// Deterministic returns a copy of the options with temperature 0 and a fixed seed.
+// Providers do not guarantee identical output, so determinism is best-effort.
+func (o ProviderOptions) Deterministic() ProviderOptions {
+	temperature := 0.0
+	seed := DeterministicSeed
+	o.Temperature = &temperature
+	o.Seed = &seed
+	return o
+}
+
+// newHTTPClient returns an HTTP client with the given per-request timeout.
+// Context cancellation still applies on top of the timeout.
+func newHTTPClient(timeout time.Duration) *http.Client {
+	if timeout <= 0 {
+		timeout = DefaultRequestTimeout
+	}
+	return &http.Client{Timeout: timeout}
+}
+
+// SupportedProviders lists the provider names NewProvider accepts
+var SupportedProviders = []string{"openai", "anthropic", "google"}
+
+// ValidateProvider returns an error wrapping ErrUnknownProvider when name is
+// empty or not one of SupportedProviders
+func ValidateProvider(name string) error {
+	if name == "" {
+		return fmt.Errorf("%w: no provider is configured", ErrUnknownProvider)
+	}
+	if !slices.Contains(SupportedProviders, name) {
+		return fmt.Errorf("%w '%s' (supported: %s)", ErrUnknownProvider, name, strings.Join(SupportedProviders, ", "))
+	}
+	return nil
+}
+
+// NewProvider returns the named provider configured with the given options,
+// or an error wrapping ErrUnknownProvider for an empty or unknown name
+func NewProvider(providerName string, opts ProviderOptions) (LLMProvider, error) {
+	if err := ValidateProvider(providerName); err != nil {
+		return nil, err
+	}
 	switch providerName {
-	case "openai":
-		return &OpenAIProvider{}
 	case "anthropic":
-		return &AnthropicProvider{}
+		return &AnthropicProvider{
+			RequestTimeout: opts.RequestTimeout,
+			Temperature:    opts.Temperature,
+			RateLimiter:    sharedRateLimiter(providerName, opts.RequestsPerMinute),
+			Version:        opts.AnthropicVersion,
+			Beta:           opts.AnthropicBeta,
+			MaxTokens:      opts.MaxOutputTokens,
+			UserAgent:      opts.UserAgent,
+		}, nil
 	case "google":
-		return &GeminiProvider{}
-	default:
-		return &OpenAIProvider{} // default
+		return &GeminiProvider{
+			VertexProject:  opts.VertexProject,
+			VertexLocation: opts.VertexLocation,
+			RequestTimeout: opts.RequestTimeout,
+			Temperature:    opts.Temperature,
+			Seed:           opts.Seed,
+			RateLimiter:    sharedRateLimiter(providerName, opts.RequestsPerMinute),
+			MaxTokens:      opts.MaxOutputTokens,
+			UserAgent:      opts.UserAgent,
+		}, nil
+	default: // "openai"
+		return &OpenAIProvider{
+			RequestTimeout: opts.RequestTimeout,
+			Temperature:    opts.Temperature,
+			Seed:           opts.Seed,
+			RateLimiter:    sharedRateLimiter(providerName, opts.RequestsPerMinute),
+			MaxTokens:      opts.MaxOutputTokens,
+			UserAgent:      opts.UserAgent,
+		}, nil
 	}
 }
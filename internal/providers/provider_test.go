@@ -0,0 +1,113 @@
+package providers
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestProviderOptions_Deterministic(t *testing.T) {
+	opts := ProviderOptions{VertexProject: "p"}.Deterministic()
+
+	if opts.Temperature == nil || *opts.Temperature != 0 {
+		t.Errorf("Expected temperature 0, got %v", opts.Temperature)
+	}
+	if opts.Seed == nil || *opts.Seed != DeterministicSeed {
+		t.Errorf("Expected seed %d, got %v", DeterministicSeed, opts.Seed)
+	}
+	if opts.VertexProject != "p" {
+		t.Errorf("Expected other options to be preserved, got %q", opts.VertexProject)
+	}
+}
+
+// newTestProvider returns the named provider, failing the test if it is unknown
+func newTestProvider(t *testing.T, name string, opts ProviderOptions) LLMProvider {
+	t.Helper()
+	provider, err := NewProvider(name, opts)
+	if err != nil {
+		t.Fatalf("Failed to create %s provider: %v", name, err)
+	}
+	return provider
+}
+
+func TestNewProvider(t *testing.T) {
+	for _, name := range SupportedProviders {
+		if provider, err := NewProvider(name, ProviderOptions{}); err != nil || provider == nil {
+			t.Errorf("Expected a provider for %q, got %v", name, err)
+		}
+	}
+	if _, ok := newTestProvider(t, "openai", ProviderOptions{}).(*OpenAIProvider); !ok {
+		t.Error("Expected an OpenAI provider for openai")
+	}
+	for _, name := range []string{"", "azure", "OpenAI"} {
+		if provider, err := NewProvider(name, ProviderOptions{}); !errors.Is(err, ErrUnknownProvider) || provider != nil {
+			t.Errorf("Expected ErrUnknownProvider for %q, got %v", name, err)
+		}
+	}
+}
+
+func TestOpenAIProvider_BuildRequest(t *testing.T) {
+	defaultBody, _ := json.Marshal((&OpenAIProvider{}).buildRequest("hi", "gpt-4o", nil))
+	if strings.Contains(string(defaultBody), "temperature") || strings.Contains(string(defaultBody), "seed") {
+		t.Errorf("Expected no sampling overrides by default, got %s", defaultBody)
+	}
+
+	provider := newTestProvider(t, "openai", ProviderOptions{}.Deterministic())
+	body, _ := json.Marshal(provider.(*OpenAIProvider).buildRequest("hi", "gpt-4o", nil))
+	for _, expected := range []string{`"temperature":0`, `"seed":42`} {
+		if !strings.Contains(string(body), expected) {
+			t.Errorf("Expected request to contain %s, got %s", expected, body)
+		}
+	}
+}
+
+func TestAnthropicProvider_BuildRequest(t *testing.T) {
+	defaultBody, _ := json.Marshal((&AnthropicProvider{}).buildRequest("hi", "claude-3-5-sonnet"))
+	if !strings.Contains(string(defaultBody), `"temperature":0.7`) {
+		t.Errorf("Expected default temperature 0.7, got %s", defaultBody)
+	}
+
+	// Temperature 0 must still be sent rather than dropped by omitempty
+	provider := newTestProvider(t, "anthropic", ProviderOptions{}.Deterministic())
+	body, _ := json.Marshal(provider.(*AnthropicProvider).buildRequest("hi", "claude-3-5-sonnet"))
+	if !strings.Contains(string(body), `"temperature":0`) || strings.Contains(string(body), `"temperature":0.7`) {
+		t.Errorf("Expected temperature 0, got %s", body)
+	}
+}
+
+func TestGeminiProvider_GenerationConfig(t *testing.T) {
+	if config := (&GeminiProvider{}).generationConfig(nil); config != nil {
+		t.Errorf("Expected nil config by default, got %+v", config)
+	}
+
+	provider := newTestProvider(t, "google", ProviderOptions{}.Deterministic()).(*GeminiProvider)
+	config := provider.generationConfig(nil)
+	if config == nil || config.Temperature == nil || *config.Temperature != 0 || config.Seed == nil || *config.Seed != int32(DeterministicSeed) {
+		t.Errorf("Expected temperature 0 and seed %d, got %+v", DeterministicSeed, config)
+	}
+}
+
+func TestAPIError_ContextLengthExceeded(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		exceeded bool
+	}{
+		{name: "openai", body: `{"error":{"message":"This model's maximum context length is 128000 tokens.","code":"context_length_exceeded"}}`, exceeded: true},
+		{name: "anthropic", body: `{"type":"error","error":{"type":"invalid_request_error","message":"prompt is too long: 210000 tokens > 200000 maximum"}}`, exceeded: true},
+		{name: "rate_limit", body: `{"error":{"message":"Rate limit reached","code":"rate_limit_exceeded"}}`, exceeded: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := apiError("400 Bad Request", []byte(tt.body))
+			if got := errors.Is(err, ErrContextLengthExceeded); got != tt.exceeded {
+				t.Errorf("Expected errors.Is(ErrContextLengthExceeded) = %v, got %v for %v", tt.exceeded, got, err)
+			}
+			if !strings.Contains(err.Error(), tt.body) {
+				t.Errorf("Expected the error to keep the response body, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package providers
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// RateLimiter is a token bucket that spaces out requests to stay under a
+// requests-per-minute quota. A nil RateLimiter never waits.
+type RateLimiter struct {
+	mu     sync.Mutex
+	rpm    int
+	rate   float64 // tokens added per second
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// NewRateLimiter returns a limiter allowing requestsPerMinute requests per
+// minute. The bucket holds a single token so requests are evenly spaced and
+// no 60-second window exceeds the quota.
+func NewRateLimiter(requestsPerMinute int) *RateLimiter {
+	return &RateLimiter{
+		rpm:    requestsPerMinute,
+		rate:   float64(requestsPerMinute) / 60,
+		burst:  1,
+		tokens: 1,
+		last:   time.Now(),
+	}
+}
+
+// Wait blocks until a request may be made or ctx is done
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+
+	for {
+		l.mu.Lock()
+		now := time.Now()
+		l.tokens += now.Sub(l.last).Seconds() * l.rate
+		if l.tokens > l.burst {
+			l.tokens = l.burst
+		}
+		l.last = now
+		if l.tokens >= 1 {
+			l.tokens--
+			l.mu.Unlock()
+			return nil
+		}
+		wait := time.Duration((1 - l.tokens) / l.rate * float64(time.Second))
+		l.mu.Unlock()
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+var (
+	rateLimitersMu sync.Mutex
+	rateLimiters   = map[string]*RateLimiter{}
+)
+
+// sharedRateLimiter returns the limiter shared by every instance of a
+// provider, or nil when requestsPerMinute is not positive. Changing the
+// limit replaces the provider's limiter.
+func sharedRateLimiter(providerName string, requestsPerMinute int) *RateLimiter {
+	if requestsPerMinute <= 0 {
+		return nil
+	}
+
+	rateLimitersMu.Lock()
+	defer rateLimitersMu.Unlock()
+	if limiter, ok := rateLimiters[providerName]; ok && limiter.rpm == requestsPerMinute {
+		return limiter
+	}
+	limiter := NewRateLimiter(requestsPerMinute)
+	rateLimiters[providerName] = limiter
+	return limiter
+}
@@ -0,0 +1,74 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRateLimiter_SpacesRequests(t *testing.T) {
+	limiter := NewRateLimiter(1200) // one request every 50ms
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if err := limiter.Wait(context.Background()); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	// The first request is immediate, the next two wait ~50ms each
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Errorf("Expected requests to be spaced out, 3 took %v", elapsed)
+	}
+}
+
+func TestRateLimiter_WaitHonorsContext(t *testing.T) {
+	limiter := NewRateLimiter(1) // one request per minute
+	if err := limiter.Wait(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := limiter.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the wait to end with the context, got %v", err)
+	}
+}
+
+func TestNewProvider_SharesRateLimiter(t *testing.T) {
+	first := newTestProvider(t, "anthropic", ProviderOptions{RequestsPerMinute: 30}).(*AnthropicProvider)
+	second := newTestProvider(t, "anthropic", ProviderOptions{RequestsPerMinute: 30}).(*AnthropicProvider)
+	if first.RateLimiter == nil || first.RateLimiter != second.RateLimiter {
+		t.Error("Expected provider instances to share one rate limiter")
+	}
+
+	if unlimited := newTestProvider(t, "anthropic", ProviderOptions{}).(*AnthropicProvider); unlimited.RateLimiter != nil {
+		t.Error("Expected no rate limiter without a configured limit")
+	}
+	if err := (*RateLimiter)(nil).Wait(context.Background()); err != nil {
+		t.Errorf("Expected a nil limiter never to wait, got %v", err)
+	}
+}
+
+func TestListModels_WaitsForRateLimiter(t *testing.T) {
+	tests := map[string]func(*RateLimiter) LLMProvider{
+		"openai":    func(l *RateLimiter) LLMProvider { return &OpenAIProvider{RateLimiter: l} },
+		"anthropic": func(l *RateLimiter) LLMProvider { return &AnthropicProvider{RateLimiter: l} },
+		"google":    func(l *RateLimiter) LLMProvider { return &GeminiProvider{RateLimiter: l} },
+	}
+	for name, newProvider := range tests {
+		limiter := NewRateLimiter(1) // one request per minute, used up below
+		if err := limiter.Wait(context.Background()); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		_, err := newProvider(limiter).ListModels(ctx, "test-key")
+		cancel()
+		// The limiter returns the context's error as is; a request that was
+		// sent instead would wrap it
+		if err != context.DeadlineExceeded {
+			t.Errorf("%s: expected ListModels to wait for the rate limiter, got %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package providers
+
+import (
+	"context"
+	"net/http"
+)
+
+// DefaultUserAgent identifies requests when ProviderOptions.UserAgent is empty
+const DefaultUserAgent = "mermaid-agent-documenter"
+
+// RequestIDHeader carries the correlation ID of each provider request
+const RequestIDHeader = "X-Request-ID"
+
+// requestIDKey is the context key of the request ID
+type requestIDKey struct{}
+
+// WithRequestID returns a context whose provider requests carry id in the
+// X-Request-ID header, e.g. the run ID and step that made the call
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID returns the request ID set with WithRequestID, or ""
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// tracingHeaders returns the User-Agent and, when the context has one, the
+// X-Request-ID header to send with a request
+func tracingHeaders(ctx context.Context, userAgent string) http.Header {
+	if userAgent == "" {
+		userAgent = DefaultUserAgent
+	}
+	headers := http.Header{}
+	headers.Set("User-Agent", userAgent)
+	if id := RequestID(ctx); id != "" {
+		headers.Set(RequestIDHeader, id)
+	}
+	return headers
+}
+
+// setTracingHeaders adds the tracing headers to an HTTP request
+func setTracingHeaders(req *http.Request, userAgent string) {
+	for name, values := range tracingHeaders(req.Context(), userAgent) {
+		req.Header[name] = values
+	}
+}
@@ -0,0 +1,40 @@
+package providers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTracingHeaders(t *testing.T) {
+	var headers []http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers = append(headers, r.Header.Clone())
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	ctx := WithRequestID(context.Background(), "run-1-step-2")
+	(&OpenAIProvider{BaseURL: server.URL, UserAgent: "mad-test/1.0"}).Ping(ctx, "key")
+	(&AnthropicProvider{BaseURL: server.URL, UserAgent: "mad-test/1.0"}).Ping(ctx, "key")
+	(&OpenAIProvider{BaseURL: server.URL}).Ping(context.Background(), "key")
+
+	if len(headers) != 3 {
+		t.Fatalf("Expected 3 requests, got %d", len(headers))
+	}
+	for _, h := range headers[:2] {
+		if got := h.Get("User-Agent"); got != "mad-test/1.0" {
+			t.Errorf("Expected the configured User-Agent, got %q", got)
+		}
+		if got := h.Get(RequestIDHeader); got != "run-1-step-2" {
+			t.Errorf("Expected the request ID from the context, got %q", got)
+		}
+	}
+	if got := headers[2].Get("User-Agent"); got != DefaultUserAgent {
+		t.Errorf("Expected the default User-Agent, got %q", got)
+	}
+	if got := headers[2].Get(RequestIDHeader); got != "" {
+		t.Errorf("Expected no request ID without one in the context, got %q", got)
+	}
+}
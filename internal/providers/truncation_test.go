@@ -0,0 +1,63 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGenerateContent_ReportsTruncation(t *testing.T) {
+	var maxTokens []float64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		switch r.URL.Path {
+		case "/messages":
+			maxTokens = append(maxTokens, body["max_tokens"].(float64))
+			fmt.Fprint(w, `{"content":[{"text":"{\"type\":"}],"stop_reason":"max_tokens"}`)
+		case "/chat/completions":
+			if v, ok := body["max_completion_tokens"].(float64); ok {
+				maxTokens = append(maxTokens, v)
+			}
+			fmt.Fprint(w, `{"choices":[{"message":{"content":"{\"type\":"},"finish_reason":"length"}]}`)
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name     string
+		provider interface {
+			LLMProvider
+			OutputLimiter
+		}
+	}{
+		{"anthropic", &AnthropicProvider{BaseURL: server.URL}},
+		{"openai", &OpenAIProvider{BaseURL: server.URL}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			maxTokens = nil
+			text, err := tt.provider.GenerateContent(context.Background(), "hi", "model", "key")
+			if !errors.Is(err, ErrOutputTruncated) {
+				t.Fatalf("Expected ErrOutputTruncated, got %v", err)
+			}
+			if text != `{"type":` {
+				t.Errorf("Expected the partial text with the error, got %q", text)
+			}
+
+			tt.provider.SetMaxOutputTokens(8192)
+			tt.provider.GenerateContent(context.Background(), "hi", "model", "key")
+			if tt.provider.MaxOutputTokens() != 8192 || len(maxTokens) == 0 || maxTokens[len(maxTokens)-1] != 8192 {
+				t.Errorf("Expected the raised limit to be sent, got %v", maxTokens)
+			}
+		})
+	}
+
+	if got := (&AnthropicProvider{}).MaxOutputTokens(); got != defaultAnthropicMaxTokens {
+		t.Errorf("Expected Anthropic to default to %d max tokens, got %d", defaultAnthropicMaxTokens, got)
+	}
+}
@@ -0,0 +1,87 @@
+package runs
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/landanqrew/mermaid-agent-documenter/internal/manifest"
+)
+
+// archiveTimeLayout dates archived output directories, e.g. 2025-01-31_14-05
+const archiveTimeLayout = "2006-01-02_15-04"
+
+// ArchiveName returns the name of the directory a run's output is archived
+// in: <YYYY-MM-DD_HH-MM>_<run-id>
+func ArchiveName(started time.Time, runID string) string {
+	return started.Format(archiveTimeLayout) + "_" + runID
+}
+
+// parseArchiveName returns the start time and run ID an archive directory is named after
+func parseArchiveName(name string) (time.Time, string, bool) {
+	if len(name) <= len(archiveTimeLayout)+1 || name[len(archiveTimeLayout)] != '_' {
+		return time.Time{}, "", false
+	}
+	started, err := time.ParseInLocation(archiveTimeLayout, name[:len(archiveTimeLayout)], time.Local)
+	if err != nil {
+		return time.Time{}, "", false
+	}
+	return started, name[len(archiveTimeLayout)+1:], true
+}
+
+// ListArchives returns a run for each archived output directory in outputDir
+// that holds a manifest.json. Only the run ID, the start time to the minute,
+// and the number of manifest entries are known for them; Dir is the archive
+// directory.
+func ListArchives(outputDir string) []*Snapshot {
+	entries, err := os.ReadDir(outputDir)
+	if err != nil {
+		return nil
+	}
+
+	var snapshots []*Snapshot
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		started, runID, ok := parseArchiveName(entry.Name())
+		if !ok {
+			continue
+		}
+		dir := filepath.Join(outputDir, entry.Name())
+		artifacts, err := manifest.Read(filepath.Join(dir, manifest.FileName))
+		if err != nil {
+			continue
+		}
+		snapshots = append(snapshots, &Snapshot{
+			Record: Record{RunID: runID, StartedAt: started, Artifacts: len(artifacts)},
+			Dir:    dir,
+		})
+	}
+	return snapshots
+}
+
+// ListAll returns the run snapshots in the logs directory together with the
+// archived runs in outputDir that have no snapshot, newest first. Snapshots
+// whose record cannot be read are skipped and returned as errors.
+func ListAll(logsDir, outputDir string) ([]*Snapshot, []error) {
+	snapshots, errs := List(logsDir)
+	if outputDir == "" {
+		return snapshots, errs
+	}
+
+	known := make(map[string]bool, len(snapshots))
+	for _, snapshot := range snapshots {
+		known[snapshot.RunID] = true
+	}
+	for _, archived := range ListArchives(outputDir) {
+		if !known[archived.RunID] {
+			snapshots = append(snapshots, archived)
+		}
+	}
+	sort.SliceStable(snapshots, func(i, j int) bool {
+		return snapshots[i].StartedAt.After(snapshots[j].StartedAt)
+	})
+	return snapshots, errs
+}
@@ -0,0 +1,132 @@
+package runs
+
+import (
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/landanqrew/mermaid-agent-documenter/internal/diff"
+	"github.com/landanqrew/mermaid-agent-documenter/internal/mermaid"
+)
+
+// Change statuses for files and diagrams
+const (
+	StatusAdded     = "added"
+	StatusRemoved   = "removed"
+	StatusChanged   = "changed"
+	StatusUnchanged = "unchanged"
+)
+
+// DiagramChange describes one Mermaid diagram that differs between runs
+type DiagramChange struct {
+	Index  int    // 1-based position in the file
+	Type   string // e.g. sequenceDiagram, flowchart
+	Status string
+}
+
+// FileChange describes how one output file differs between runs
+type FileChange struct {
+	Path     string
+	Status   string
+	Diagrams []DiagramChange
+	Diff     string // unified diff, empty when unchanged
+}
+
+// Compare lists every file in either snapshot, sorted by path, with the
+// diagrams that were added, removed, or changed
+func Compare(a, b *Snapshot) ([]FileChange, error) {
+	names := map[string]bool{}
+	for _, name := range a.Files {
+		names[name] = true
+	}
+	for _, name := range b.Files {
+		names[name] = true
+	}
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+
+	var changes []FileChange
+	for _, name := range sorted {
+		before, inA, err := readIfListed(a, name)
+		if err != nil {
+			return nil, err
+		}
+		after, inB, err := readIfListed(b, name)
+		if err != nil {
+			return nil, err
+		}
+
+		change := FileChange{Path: name, Status: StatusChanged}
+		switch {
+		case !inA:
+			change.Status = StatusAdded
+		case !inB:
+			change.Status = StatusRemoved
+		case before == after:
+			change.Status = StatusUnchanged
+		}
+		if change.Status != StatusUnchanged {
+			change.Diagrams = compareDiagrams(diagrams(name, before), diagrams(name, after))
+			change.Diff = diff.Unified(a.RunID+"/"+name, b.RunID+"/"+name, before, after, diff.DefaultContext)
+		}
+		changes = append(changes, change)
+	}
+	return changes, nil
+}
+
+// readIfListed reads a file from a snapshot when the snapshot recorded it
+func readIfListed(s *Snapshot, name string) (string, bool, error) {
+	for _, file := range s.Files {
+		if file == name {
+			content, err := s.ReadFile(name)
+			return content, true, err
+		}
+	}
+	return "", false, nil
+}
+
+// diagrams returns the Mermaid diagrams in a file: the whole file for .mmd,
+// otherwise each ```mermaid block
+func diagrams(name, content string) []string {
+	if strings.EqualFold(path.Ext(name), ".mmd") {
+		if strings.TrimSpace(content) == "" {
+			return nil
+		}
+		return []string{strings.TrimSpace(content)}
+	}
+
+	var blocks []string
+	for _, block := range mermaid.ExtractBlocks(content) {
+		if block.Closed {
+			blocks = append(blocks, block.Content)
+		}
+	}
+	return blocks
+}
+
+// compareDiagrams matches diagrams by position and reports the differences
+func compareDiagrams(before, after []string) []DiagramChange {
+	var changes []DiagramChange
+	for i := 0; i < max(len(before), len(after)); i++ {
+		switch {
+		case i >= len(before):
+			changes = append(changes, DiagramChange{Index: i + 1, Type: diagramType(after[i]), Status: StatusAdded})
+		case i >= len(after):
+			changes = append(changes, DiagramChange{Index: i + 1, Type: diagramType(before[i]), Status: StatusRemoved})
+		case before[i] != after[i]:
+			changes = append(changes, DiagramChange{Index: i + 1, Type: diagramType(after[i]), Status: StatusChanged})
+		}
+	}
+	return changes
+}
+
+// diagramType returns the keyword a diagram starts with, or "unknown" when it is empty
+func diagramType(diagram string) string {
+	if keyword := mermaid.DiagramKeyword(diagram); keyword != "" {
+		return keyword
+	}
+	return "unknown"
+}
@@ -0,0 +1,56 @@
+package runs
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// ConversationFile holds a run's model conversation next to its record so a
+// later invocation can refine the run
+const ConversationFile = "conversation.json"
+
+// Conversation is what a refinement needs to continue a run
+type Conversation struct {
+	OutputDir   string                   `json:"outputDir"`
+	Artifacts   []string                 `json:"artifacts"`
+	Steps       int                      `json:"steps"`
+	Refinements int                      `json:"refinements,omitempty"`
+	Turns       []map[string]interface{} `json:"turns"`
+}
+
+// SaveConversation writes a run's conversation into <logsDir>/runs/<run-id>/.
+// The turns include the transcript, so the file is readable by the owner only.
+func SaveConversation(logsDir, runID string, conversation Conversation) error {
+	dir := filepath.Join(logsDir, DirName, runID)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create run snapshot directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(conversation, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode run conversation: %w", err)
+	}
+	return os.WriteFile(filepath.Join(dir, ConversationFile), data, 0600)
+}
+
+// Conversation reads the conversation saved with the snapshot
+func (s *Snapshot) Conversation() (*Conversation, error) {
+	data, err := os.ReadFile(filepath.Join(s.Dir, ConversationFile))
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("run '%s' has no saved conversation to continue", s.RunID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read run conversation: %w", err)
+	}
+
+	var conversation Conversation
+	if err := json.Unmarshal(data, &conversation); err != nil {
+		return nil, fmt.Errorf("failed to parse run conversation: %w", err)
+	}
+	if len(conversation.Turns) == 0 {
+		return nil, fmt.Errorf("run '%s' has no saved conversation to continue", s.RunID)
+	}
+	return &conversation, nil
+}
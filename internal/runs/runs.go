@@ -0,0 +1,187 @@
+// Package runs keeps a snapshot of each run's text outputs in the logs
+// directory so the documentation produced by two runs can be compared.
+package runs
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DirName is the directory under the logs directory holding one snapshot per run
+const DirName = "runs"
+
+// RecordFile describes a snapshot; the copied outputs sit next to it in FilesDir
+const (
+	RecordFile = "run.json"
+	FilesDir   = "files"
+)
+
+// snapshotExtensions are the text outputs copied into a snapshot
+var snapshotExtensions = map[string]bool{".md": true, ".mmd": true}
+
+// Record describes a run and the files copied into its snapshot
+type Record struct {
+	RunID             string    `json:"runId"`
+	Transcript        string    `json:"transcript,omitempty"`
+	Provider          string    `json:"provider"`
+	Model             string    `json:"model"`
+	TerminationReason string    `json:"terminationReason"`
+	StartedAt         time.Time `json:"startedAt"`
+	FinishedAt        time.Time `json:"finishedAt"`
+	Artifacts         int       `json:"artifacts,omitempty"` // every file the run produced, including images
+	Files             []string  `json:"files"`               // paths relative to the output directory
+}
+
+// ArtifactCount returns how many files the run produced. Records saved before
+// the count was kept fall back to the number of snapshotted files.
+func (r Record) ArtifactCount() int {
+	if r.Artifacts > 0 {
+		return r.Artifacts
+	}
+	return len(r.Files)
+}
+
+// Snapshot is a saved run loaded from disk
+type Snapshot struct {
+	Record
+	Dir string
+}
+
+// Save copies the Markdown and Mermaid artifacts of a run into
+// <logsDir>/runs/<run-id>/ and writes its record
+func Save(logsDir, outputDir string, record Record, artifacts []string) error {
+	dir := filepath.Join(logsDir, DirName, record.RunID)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create run snapshot directory: %w", err)
+	}
+
+	record.Artifacts = len(artifacts)
+	record.Files = []string{}
+	for _, artifact := range artifacts {
+		if !snapshotExtensions[strings.ToLower(filepath.Ext(artifact))] {
+			continue
+		}
+		data, err := os.ReadFile(artifact)
+		if err != nil {
+			continue // reported as missing by the manifest check
+		}
+		rel := relativeName(outputDir, artifact)
+		target := filepath.Join(dir, FilesDir, rel)
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return fmt.Errorf("failed to create run snapshot directory: %w", err)
+		}
+		if err := os.WriteFile(target, data, 0644); err != nil {
+			return fmt.Errorf("failed to copy %s: %w", artifact, err)
+		}
+		record.Files = append(record.Files, filepath.ToSlash(rel))
+	}
+	sort.Strings(record.Files)
+
+	data, err := json.MarshalIndent(record, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode run record: %w", err)
+	}
+	return os.WriteFile(filepath.Join(dir, RecordFile), data, 0644)
+}
+
+// relativeName returns an artifact's path relative to the output directory,
+// or its base name when it lives elsewhere
+func relativeName(outputDir, path string) string {
+	if outputDir != "" {
+		if rel, err := filepath.Rel(outputDir, path); err == nil && !strings.HasPrefix(rel, "..") {
+			return rel
+		}
+	}
+	return filepath.Base(path)
+}
+
+// Load reads the snapshot for a run ID. A unique prefix of the ID is accepted.
+func Load(logsDir, runID string) (*Snapshot, error) {
+	entries, err := os.ReadDir(filepath.Join(logsDir, DirName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("no run snapshots in %s", logsDir)
+		}
+		return nil, err
+	}
+
+	var matches []string
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		if entry.Name() == runID {
+			matches = []string{runID}
+			break
+		}
+		if strings.HasPrefix(entry.Name(), runID) {
+			matches = append(matches, entry.Name())
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("run '%s' not found in %s", runID, filepath.Join(logsDir, DirName))
+	case 1:
+	default:
+		return nil, fmt.Errorf("run ID prefix '%s' is ambiguous (%s)", runID, strings.Join(matches, ", "))
+	}
+
+	return load(filepath.Join(logsDir, DirName, matches[0]))
+}
+
+// load reads the record of the snapshot in dir
+func load(dir string) (*Snapshot, error) {
+	data, err := os.ReadFile(filepath.Join(dir, RecordFile))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read run record: %w", err)
+	}
+	snapshot := &Snapshot{Dir: dir}
+	if err := json.Unmarshal(data, &snapshot.Record); err != nil {
+		return nil, fmt.Errorf("failed to parse run record: %w", err)
+	}
+	return snapshot, nil
+}
+
+// List returns every run snapshot in the logs directory, newest first.
+// Snapshots whose record cannot be read are skipped and returned as errors.
+func List(logsDir string) ([]*Snapshot, []error) {
+	entries, err := os.ReadDir(filepath.Join(logsDir, DirName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, []error{err}
+	}
+
+	var snapshots []*Snapshot
+	var errs []error
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		snapshot, err := load(filepath.Join(logsDir, DirName, entry.Name()))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", entry.Name(), err))
+			continue
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	sort.SliceStable(snapshots, func(i, j int) bool {
+		return snapshots[i].StartedAt.After(snapshots[j].StartedAt)
+	})
+	return snapshots, errs
+}
+
+// ReadFile returns the contents of a file copied into the snapshot
+func (s *Snapshot) ReadFile(name string) (string, error) {
+	data, err := os.ReadFile(filepath.Join(s.Dir, FilesDir, filepath.FromSlash(name)))
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
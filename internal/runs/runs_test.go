@@ -0,0 +1,211 @@
+package runs
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// saveRun writes the given files to an output directory and snapshots them
+func saveRun(t *testing.T, logsDir, runID string, files map[string]string) {
+	t.Helper()
+	outputDir := t.TempDir()
+	var artifacts []string
+	for name, content := range files {
+		path := filepath.Join(outputDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create dir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+		artifacts = append(artifacts, path)
+	}
+	if err := Save(logsDir, outputDir, Record{RunID: runID, Model: "test-model"}, artifacts); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+}
+
+func TestSaveAndLoad(t *testing.T) {
+	logsDir := t.TempDir()
+	saveRun(t, logsDir, "abc123-run", map[string]string{
+		"user-flow/login.md": "# Login\n",
+		"login.svg":          "<svg/>",
+	})
+
+	snapshot, err := Load(logsDir, "abc")
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if snapshot.RunID != "abc123-run" || snapshot.Model != "test-model" {
+		t.Errorf("Unexpected record: %+v", snapshot.Record)
+	}
+	if len(snapshot.Files) != 1 || snapshot.Files[0] != "user-flow/login.md" {
+		t.Fatalf("Expected only the Markdown file to be copied, got %v", snapshot.Files)
+	}
+	if content, err := snapshot.ReadFile("user-flow/login.md"); err != nil || content != "# Login\n" {
+		t.Errorf("Unexpected snapshot content %q (%v)", content, err)
+	}
+
+	saveRun(t, logsDir, "abd456-run", nil)
+	if _, err := Load(logsDir, "ab"); err == nil || !strings.Contains(err.Error(), "ambiguous") {
+		t.Errorf("Expected an ambiguous prefix error, got %v", err)
+	}
+	if _, err := Load(logsDir, "zzz"); err == nil {
+		t.Error("Expected an error for an unknown run")
+	}
+}
+
+func TestSaveConversation(t *testing.T) {
+	logsDir := t.TempDir()
+	saveRun(t, logsDir, "abc123-run", nil)
+
+	snapshot, err := Load(logsDir, "abc")
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if _, err := snapshot.Conversation(); err == nil {
+		t.Error("Expected an error for a run without a saved conversation")
+	}
+
+	saved := Conversation{
+		OutputDir: "/work/output",
+		Artifacts: []string{"/work/output/login.md"},
+		Steps:     3,
+		Turns:     []map[string]interface{}{{"role": "user", "content": "Document the login flow"}},
+	}
+	if err := SaveConversation(logsDir, "abc123-run", saved); err != nil {
+		t.Fatalf("SaveConversation failed: %v", err)
+	}
+	conversation, err := snapshot.Conversation()
+	if err != nil {
+		t.Fatalf("Conversation failed: %v", err)
+	}
+	if conversation.OutputDir != saved.OutputDir || conversation.Steps != 3 || len(conversation.Artifacts) != 1 {
+		t.Errorf("Unexpected conversation: %+v", conversation)
+	}
+	if len(conversation.Turns) != 1 || conversation.Turns[0]["content"] != "Document the login flow" {
+		t.Errorf("Expected the turns to round-trip, got %v", conversation.Turns)
+	}
+	if info, err := os.Stat(filepath.Join(snapshot.Dir, ConversationFile)); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("Expected an owner-only conversation file, got %v (%v)", info, err)
+	}
+}
+
+func TestList(t *testing.T) {
+	logsDir := t.TempDir()
+	if snapshots, errs := List(logsDir); len(snapshots) != 0 || len(errs) != 0 {
+		t.Fatalf("Expected no runs in an empty logs directory, got %v (%v)", snapshots, errs)
+	}
+
+	older := time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)
+	for i, runID := range []string{"older-run", "newer-run"} {
+		record := Record{RunID: runID, Transcript: "meeting.txt", StartedAt: older.Add(time.Duration(i) * time.Hour)}
+		if err := Save(logsDir, t.TempDir(), record, []string{"/missing/login.md", "/missing/login.svg"}); err != nil {
+			t.Fatalf("Save failed: %v", err)
+		}
+	}
+	if err := os.MkdirAll(filepath.Join(logsDir, DirName, "broken"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	snapshots, errs := List(logsDir)
+	if len(errs) != 1 {
+		t.Errorf("Expected the snapshot without a record to be reported, got %v", errs)
+	}
+	if len(snapshots) != 2 || snapshots[0].RunID != "newer-run" || snapshots[1].RunID != "older-run" {
+		t.Fatalf("Expected both runs newest first, got %+v", snapshots)
+	}
+	if snapshots[0].Transcript != "meeting.txt" || snapshots[0].ArtifactCount() != 2 {
+		t.Errorf("Expected the transcript and artifact count to be recorded, got %+v", snapshots[0].Record)
+	}
+}
+
+func TestListAll_IncludesArchivedRuns(t *testing.T) {
+	logsDir, outputDir := t.TempDir(), t.TempDir()
+	started := time.Date(2025, 1, 1, 9, 0, 0, 0, time.Local)
+	if err := Save(logsDir, outputDir, Record{RunID: "saved-run", Provider: "openai", StartedAt: started}, nil); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	// Archived output of the saved run, of a run without a snapshot, and a
+	// directory that is not an archive
+	for name, manifestJSON := range map[string]string{
+		ArchiveName(started, "saved-run"):               `{}`,
+		ArchiveName(started.Add(time.Hour), "lost-run"): `{"login.md":"created","login.svg":"created"}`,
+		"diagrams": `{}`,
+		ArchiveName(started.Add(2*time.Hour), "no-manifest"): "",
+	} {
+		dir := filepath.Join(outputDir, name)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if manifestJSON == "" {
+			continue
+		}
+		if err := os.WriteFile(filepath.Join(dir, "manifest.json"), []byte(manifestJSON), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	snapshots, errs := ListAll(logsDir, outputDir)
+	if len(errs) != 0 {
+		t.Errorf("Unexpected errors: %v", errs)
+	}
+	if len(snapshots) != 2 || snapshots[0].RunID != "lost-run" || snapshots[1].RunID != "saved-run" {
+		t.Fatalf("Expected the archived run and the saved run newest first, got %+v", snapshots)
+	}
+	if !snapshots[0].StartedAt.Equal(started.Add(time.Hour)) || snapshots[0].ArtifactCount() != 2 || snapshots[0].Provider != "" {
+		t.Errorf("Expected the archived run's start time and manifest entries, got %+v", snapshots[0].Record)
+	}
+	if snapshots[1].Provider != "openai" {
+		t.Errorf("Expected the snapshot's record for a run with both, got %+v", snapshots[1].Record)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	logsDir := t.TempDir()
+	saveRun(t, logsDir, "run-a", map[string]string{
+		"login.md": "# Login\n\n```mermaid\nsequenceDiagram\n  User->>App: login\n```\n\n```mermaid\nflowchart TD\n  A --> B\n```\n",
+		"old.md":   "# Old\n",
+		"same.mmd": "graph TD\n  A --> B\n",
+	})
+	saveRun(t, logsDir, "run-b", map[string]string{
+		"login.md": "# Login\n\n```mermaid\nsequenceDiagram\n  User->>App: sign in\n```\n",
+		"new.mmd":  "erDiagram\n  Site ||--o{ Wash : hosts\n",
+		"same.mmd": "graph TD\n  A --> B\n",
+	})
+	a, _ := Load(logsDir, "run-a")
+	b, _ := Load(logsDir, "run-b")
+
+	changes, err := Compare(a, b)
+	if err != nil {
+		t.Fatalf("Compare failed: %v", err)
+	}
+
+	statuses := map[string]string{}
+	for _, change := range changes {
+		statuses[change.Path] = change.Status
+	}
+	expected := map[string]string{"login.md": StatusChanged, "new.mmd": StatusAdded, "old.md": StatusRemoved, "same.mmd": StatusUnchanged}
+	for path, status := range expected {
+		if statuses[path] != status {
+			t.Errorf("Expected %s to be %s, got %q", path, status, statuses[path])
+		}
+	}
+
+	login := changes[0]
+	if len(login.Diagrams) != 2 ||
+		login.Diagrams[0] != (DiagramChange{Index: 1, Type: "sequenceDiagram", Status: StatusChanged}) ||
+		login.Diagrams[1] != (DiagramChange{Index: 2, Type: "flowchart", Status: StatusRemoved}) {
+		t.Errorf("Unexpected diagram changes: %+v", login.Diagrams)
+	}
+	if !strings.Contains(login.Diff, "\n-  User->>App: login\n") || !strings.Contains(login.Diff, "\n+  User->>App: sign in\n") {
+		t.Errorf("Expected a unified diff of the change, got:\n%s", login.Diff)
+	}
+	if changes[3].Diff != "" {
+		t.Errorf("Expected no diff for an unchanged file, got %q", changes[3].Diff)
+	}
+}
@@ -0,0 +1,59 @@
+package secrets
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/zalando/go-keyring"
+)
+
+// ErrNotFound is returned when a backend has no secret for a provider
+var ErrNotFound = errors.New("secret not found")
+
+// Backend stores and retrieves API keys for model providers
+type Backend interface {
+	Name() string
+	Get(provider string) (string, error)
+	Set(provider, secret string) error
+	Delete(provider string) error
+}
+
+// KeychainService is the service name secrets are stored under in the OS credential store
+const KeychainService = "mermaid-agent-documenter"
+
+// KeychainBackend stores secrets in the OS credential store
+// (macOS Keychain, Secret Service/libsecret, Windows Credential Manager)
+type KeychainBackend struct{}
+
+func (b *KeychainBackend) Name() string {
+	return "keychain"
+}
+
+func (b *KeychainBackend) Get(provider string) (string, error) {
+	secret, err := keyring.Get(KeychainService, provider)
+	if errors.Is(err, keyring.ErrNotFound) {
+		return "", ErrNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read from keychain: %w", err)
+	}
+	return secret, nil
+}
+
+func (b *KeychainBackend) Set(provider, secret string) error {
+	if err := keyring.Set(KeychainService, provider, secret); err != nil {
+		return fmt.Errorf("failed to write to keychain: %w", err)
+	}
+	return nil
+}
+
+func (b *KeychainBackend) Delete(provider string) error {
+	err := keyring.Delete(KeychainService, provider)
+	if errors.Is(err, keyring.ErrNotFound) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete from keychain: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package secrets
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/zalando/go-keyring"
+)
+
+func TestKeychainBackend(t *testing.T) {
+	keyring.MockInit()
+	backend := &KeychainBackend{}
+
+	if _, err := backend.Get("openai"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Expected ErrNotFound for missing secret, got: %v", err)
+	}
+
+	if err := backend.Set("openai", "sk-test"); err != nil {
+		t.Fatalf("Unexpected error setting secret: %v", err)
+	}
+
+	secret, err := backend.Get("openai")
+	if err != nil {
+		t.Fatalf("Unexpected error getting secret: %v", err)
+	}
+	if secret != "sk-test" {
+		t.Errorf("Expected 'sk-test', got '%s'", secret)
+	}
+
+	if err := backend.Delete("openai"); err != nil {
+		t.Fatalf("Unexpected error deleting secret: %v", err)
+	}
+	if err := backend.Delete("openai"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound deleting missing secret, got: %v", err)
+	}
+}
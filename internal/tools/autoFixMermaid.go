@@ -0,0 +1,175 @@
+package tools
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// participantPattern matches a sequence diagram participant or actor declaration
+var participantPattern = regexp.MustCompile(`^(participant|actor)\s+(.+)$`)
+
+// entityOpenPattern matches the "Entity {" line that opens an ER attribute block,
+// but not relationship lines such as "A ||--o{ B : has"
+var entityOpenPattern = regexp.MustCompile(`^([\w-]+|"[^"]*")\s*\{`)
+
+// AutoFixMermaid deterministically corrects the mistakes models most often
+// make in Mermaid source, in a Markdown file or raw .mmd source:
+//   - type annotations and key markers on ER attributes are removed, leaving
+//     the attribute names (see LintERDiagrams)
+//   - ER attributes separated by commas or semicolons are put on their own lines
+//   - sequence participants whose names contain spaces are quoted
+//
+// It returns the corrected source and a description of each fix, naming the
+// line it was made on. Source without these mistakes is returned unchanged.
+func AutoFixMermaid(content string) (string, []string) {
+	var fixes []string
+	var out []string
+	mode, entity := "", ""
+
+	for i, raw := range strings.Split(content, "\n") {
+		line := strings.TrimSpace(raw)
+		switch {
+		case line == "erDiagram" || strings.HasPrefix(line, "erDiagram "):
+			mode, entity = "er", ""
+			out = append(out, raw)
+			continue
+		case line == "sequenceDiagram" || strings.HasPrefix(line, "sequenceDiagram "):
+			mode, entity = "sequence", ""
+			out = append(out, raw)
+			continue
+		case strings.HasPrefix(line, "```") || startsOtherDiagram(line):
+			mode, entity = "", ""
+			out = append(out, raw)
+			continue
+		}
+
+		indent := raw[:len(raw)-len(strings.TrimLeft(raw, " \t"))]
+		switch mode {
+		case "er":
+			var lines, lineFixes []string
+			lines, entity, lineFixes = fixERLine(raw, indent, entity, i+1)
+			out = append(out, lines...)
+			fixes = append(fixes, lineFixes...)
+		case "sequence":
+			if match := participantPattern.FindStringSubmatch(line); match != nil {
+				name := strings.TrimSpace(match[2])
+				if strings.Contains(name, " ") && !strings.Contains(name, " as ") && !strings.HasPrefix(name, `"`) {
+					out = append(out, fmt.Sprintf(`%s%s "%s"`, indent, match[1], name))
+					fixes = append(fixes, fmt.Sprintf("line %d: quoted %s name %q", i+1, match[1], name))
+					continue
+				}
+			}
+			out = append(out, raw)
+		default:
+			out = append(out, raw)
+		}
+	}
+
+	if len(fixes) == 0 {
+		return content, nil
+	}
+	return strings.Join(out, "\n"), fixes
+}
+
+// fixERLine fixes the attributes on one line of an erDiagram. entity is the
+// entity whose block is open ("" outside a block); the updated value is
+// returned with the replacement lines and the fixes made.
+func fixERLine(raw, indent, entity string, lineNumber int) ([]string, string, []string) {
+	line := strings.TrimSpace(raw)
+
+	// A block opens with "Entity {" and may close on the same line
+	opening, body := "", line
+	if entity == "" {
+		match := entityOpenPattern.FindStringSubmatch(line)
+		if match == nil {
+			return []string{raw}, "", nil
+		}
+		entity = match[1]
+		opening, body = strings.TrimSpace(match[0]), line[len(match[0]):]
+	}
+	closing := ""
+	if end := strings.Index(body, "}"); end >= 0 {
+		body, closing = body[:end], strings.TrimSpace(body[end:])
+	}
+
+	attributes := splitAttributes(body)
+	var fixes []string
+	if len(attributes) > 1 {
+		fixes = append(fixes, fmt.Sprintf("line %d: put %d %s attributes on separate lines", lineNumber, len(attributes), entity))
+	}
+	for i, attribute := range attributes {
+		if typ, ok := typedAttribute(attribute); ok {
+			name := untypedAttribute(attribute, typ)
+			fixes = append(fixes, fmt.Sprintf("line %d: removed type annotation %q from %s attribute %q", lineNumber, typ, entity, name))
+			attributes[i] = name
+		}
+	}
+
+	if closing != "" {
+		entity = ""
+	}
+	if len(fixes) == 0 {
+		return []string{raw}, entity, nil
+	}
+
+	var lines []string
+	attributeIndent := indent
+	if opening != "" {
+		lines = append(lines, indent+opening)
+		attributeIndent = indent + "    "
+	}
+	for _, attribute := range attributes {
+		lines = append(lines, attributeIndent+attribute)
+	}
+	if closing != "" {
+		lines = append(lines, indent+closing)
+	}
+	return lines, entity, fixes
+}
+
+// splitAttributes splits an ER block body on commas and semicolons outside
+// quoted comments and sized types such as decimal(10,2), dropping empty entries
+func splitAttributes(body string) []string {
+	var attributes []string
+	var current strings.Builder
+	inQuote, depth := false, 0
+	flush := func() {
+		if attribute := strings.TrimSpace(current.String()); attribute != "" {
+			attributes = append(attributes, attribute)
+		}
+		current.Reset()
+	}
+	for _, r := range body {
+		switch {
+		case r == '"':
+			inQuote = !inQuote
+			current.WriteRune(r)
+		case r == '(' && !inQuote:
+			depth++
+			current.WriteRune(r)
+		case r == ')' && !inQuote && depth > 0:
+			depth--
+			current.WriteRune(r)
+		case (r == ',' || r == ';') && !inQuote && depth == 0:
+			flush()
+		default:
+			current.WriteRune(r)
+		}
+	}
+	flush()
+	return attributes
+}
+
+// untypedAttribute returns the name of a typed ER attribute: the word after
+// the type, or the first word when only key markers were added
+func untypedAttribute(attribute, typ string) string {
+	if quote := strings.Index(attribute, `"`); quote >= 0 {
+		attribute = attribute[:quote]
+	}
+	fields := strings.Fields(attribute)
+	if len(fields) > 1 && fields[0] == typ && (erAttributeTypes[strings.ToLower(typ)] || sizedTypePattern.MatchString(typ)) {
+		return fields[1]
+	}
+	return fields[0]
+}
@@ -0,0 +1,46 @@
+package tools
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAutoFixMermaid_ERAttributes(t *testing.T) {
+	source := "```mermaid\nerDiagram\n  USER ||--o{ ORDER : places\n  USER {\n    string id PK\n    string email \"login, unique\"\n    name\n  }\n  ORDER { int id PK, decimal(10,2) total; status }\n```"
+	fixed, fixes := AutoFixMermaid(source)
+
+	expected := "```mermaid\nerDiagram\n  USER ||--o{ ORDER : places\n  USER {\n    id\n    email\n    name\n  }\n  ORDER {\n      id\n      total\n      status\n  }\n```"
+	if fixed != expected {
+		t.Errorf("Unexpected fix:\n%s\nwant:\n%s", fixed, expected)
+	}
+	if len(fixes) != 5 {
+		t.Errorf("Expected 5 fixes, got %d: %v", len(fixes), fixes)
+	}
+	if !strings.Contains(fixes[0], "line 5") || !strings.Contains(fixes[0], `"string"`) {
+		t.Errorf("Expected the first fix to name line 5 and the type, got %q", fixes[0])
+	}
+	if issues := LintERDiagrams(fixed); len(issues) != 0 {
+		t.Errorf("Expected the fixed source to pass the lint, got %v", issues)
+	}
+}
+
+func TestAutoFixMermaid_Participants(t *testing.T) {
+	source := "sequenceDiagram\n    participant User Service\n    participant DB as Orders Database\n    actor Admin\n    participant \"Billing API\""
+	fixed, fixes := AutoFixMermaid(source)
+
+	expected := "sequenceDiagram\n    participant \"User Service\"\n    participant DB as Orders Database\n    actor Admin\n    participant \"Billing API\""
+	if fixed != expected {
+		t.Errorf("Unexpected fix:\n%s\nwant:\n%s", fixed, expected)
+	}
+	if len(fixes) != 1 || !strings.Contains(fixes[0], "line 2") {
+		t.Errorf("Expected one fix on line 2, got %v", fixes)
+	}
+}
+
+func TestAutoFixMermaid_Unchanged(t *testing.T) {
+	source := "# Docs\n\n```mermaid\nflowchart TD\n  A[Start, then stop] --> B\n```\n\n```mermaid\nerDiagram\n  USER {\n    id\n  }\n```"
+	fixed, fixes := AutoFixMermaid(source)
+	if fixed != source || len(fixes) != 0 {
+		t.Errorf("Expected valid source to be left alone, got %v:\n%s", fixes, fixed)
+	}
+}
@@ -0,0 +1,215 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultExternalToolTimeout bounds an external tool run when its manifest sets no timeout
+const DefaultExternalToolTimeout = 60 * time.Second
+
+var (
+	externalToolNamePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_-]*$`)
+	placeholderPattern      = regexp.MustCompile(`\{\{\s*([A-Za-z0-9_]+)\s*\}\}`)
+)
+
+// ExternalToolManifest describes an executable the agent can call as a tool.
+// Command is an argv template; {{arg}} placeholders are replaced with tool arguments.
+type ExternalToolManifest struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	Schema      map[string]interface{} `json:"schema,omitempty"`
+	Command     []string               `json:"command"`
+	TimeoutSec  int                    `json:"timeoutSec,omitempty"`
+}
+
+// ExternalTool runs an executable, passing the tool arguments as JSON on stdin
+// and reading a ToolResult as JSON from stdout
+type ExternalTool struct {
+	Manifest ExternalToolManifest
+	Dir      string // directory of the manifest; relative commands resolve against it
+}
+
+func (t *ExternalTool) Name() string {
+	return t.Manifest.Name
+}
+
+func (t *ExternalTool) Description() string {
+	return t.Manifest.Description
+}
+
+func (t *ExternalTool) Schema() map[string]interface{} {
+	if t.Manifest.Schema == nil {
+		return map[string]interface{}{"type": "object"}
+	}
+	return t.Manifest.Schema
+}
+
+func (t *ExternalTool) Execute(args map[string]interface{}) ToolResult {
+	input, err := json.Marshal(args)
+	if err != nil {
+		return ToolResult{
+			Success: false,
+			Error:   "Failed to encode arguments: " + err.Error(),
+		}
+	}
+
+	timeout := DefaultExternalToolTimeout
+	if t.Manifest.TimeoutSec > 0 {
+		timeout = time.Duration(t.Manifest.TimeoutSec) * time.Second
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	argv := t.commandArgs(args)
+	cmd := exec.CommandContext(ctx, argv[0], argv[1:]...)
+	cmd.Dir = t.Dir
+	cmd.Env = os.Environ()
+	cmd.Stdin = bytes.NewReader(input)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	runErr := cmd.Run()
+	if ctx.Err() == context.DeadlineExceeded {
+		return ToolResult{
+			Success: false,
+			Error:   fmt.Sprintf("External tool '%s' timed out after %s", t.Name(), timeout),
+		}
+	}
+
+	var result ToolResult
+	if err := json.Unmarshal(bytes.TrimSpace(stdout.Bytes()), &result); err != nil {
+		detail := strings.TrimSpace(stderr.String())
+		if runErr != nil {
+			return ToolResult{
+				Success: false,
+				Error:   fmt.Sprintf("External tool '%s' failed: %v. %s", t.Name(), runErr, detail),
+			}
+		}
+		return ToolResult{
+			Success: false,
+			Error:   fmt.Sprintf("External tool '%s' did not print a JSON ToolResult on stdout: %v", t.Name(), err),
+		}
+	}
+	if runErr != nil && result.Success {
+		result.Success = false
+		result.Error = fmt.Sprintf("External tool '%s' exited with an error: %v", t.Name(), runErr)
+	}
+	return result
+}
+
+// commandArgs expands the manifest's command template with the tool arguments.
+// Missing arguments expand to an empty string; objects and arrays expand to JSON.
+func (t *ExternalTool) commandArgs(args map[string]interface{}) []string {
+	argv := make([]string, len(t.Manifest.Command))
+	for i, part := range t.Manifest.Command {
+		argv[i] = placeholderPattern.ReplaceAllStringFunc(part, func(match string) string {
+			name := placeholderPattern.FindStringSubmatch(match)[1]
+			switch v := args[name].(type) {
+			case nil:
+				return ""
+			case string:
+				return v
+			case map[string]interface{}, []interface{}:
+				encoded, _ := json.Marshal(v)
+				return string(encoded)
+			default:
+				return fmt.Sprint(v)
+			}
+		})
+	}
+
+	// Commands like ./push.sh live next to their manifest
+	if strings.HasPrefix(argv[0], "./") || strings.HasPrefix(argv[0], "../") {
+		argv[0] = filepath.Join(t.Dir, argv[0])
+	}
+	return argv
+}
+
+// LoadExternalTools reads every *.json tool manifest in dir. Invalid manifests
+// are reported as errors without stopping the others from loading; a missing
+// directory yields no tools.
+func LoadExternalTools(dir string) ([]*ExternalTool, []error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, []error{err}
+	}
+	sort.Strings(paths)
+
+	var loaded []*ExternalTool
+	var errs []error
+	for _, path := range paths {
+		tool, err := loadExternalTool(path)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", filepath.Base(path), err))
+			continue
+		}
+		loaded = append(loaded, tool)
+	}
+	return loaded, errs
+}
+
+// loadExternalTool parses and validates one tool manifest
+func loadExternalTool(path string) (*ExternalTool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var manifest ExternalToolManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("invalid tool manifest: %w", err)
+	}
+	if !externalToolNamePattern.MatchString(manifest.Name) {
+		return nil, fmt.Errorf("tool name %q must start with a letter and contain only letters, digits, '_' or '-'", manifest.Name)
+	}
+	if len(manifest.Command) == 0 || strings.TrimSpace(manifest.Command[0]) == "" {
+		return nil, fmt.Errorf("tool '%s' has no command", manifest.Name)
+	}
+	if manifest.Description == "" {
+		manifest.Description = "External tool " + manifest.Name
+	}
+
+	return &ExternalTool{Manifest: manifest, Dir: filepath.Dir(path)}, nil
+}
+
+// RegisterExternalTools loads the tool manifests in dir and registers them.
+// Tools that would replace an already registered tool are skipped.
+func RegisterExternalTools(dir string) ([]*ExternalTool, []error) {
+	loaded, errs := LoadExternalTools(dir)
+
+	var registered []*ExternalTool
+	for _, tool := range loaded {
+		if existing := GetTool(tool.Name()); existing != nil {
+			if _, external := existing.(*ExternalTool); !external {
+				errs = append(errs, fmt.Errorf("tool '%s' conflicts with a built-in tool and was skipped", tool.Name()))
+				continue
+			}
+		}
+		RegisterTool(tool)
+		registered = append(registered, tool)
+	}
+	return registered, errs
+}
+
+// ExternalTools returns the registered external tools sorted by name
+func ExternalTools() []*ExternalTool {
+	var external []*ExternalTool
+	for _, tool := range toolRegistry {
+		if ext, ok := tool.(*ExternalTool); ok {
+			external = append(external, ext)
+		}
+	}
+	sort.Slice(external, func(i, j int) bool { return external[i].Name() < external[j].Name() })
+	return external
+}
@@ -0,0 +1,98 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeExternalTool(t *testing.T, dir, name, manifest, script string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name+".json"), []byte(manifest), 0644); err != nil {
+		t.Fatalf("Failed to write manifest: %v", err)
+	}
+	if script != "" {
+		if err := os.WriteFile(filepath.Join(dir, name+".sh"), []byte(script), 0755); err != nil {
+			t.Fatalf("Failed to write script: %v", err)
+		}
+	}
+}
+
+func TestExternalTool_Execute(t *testing.T) {
+	dir := t.TempDir()
+	writeExternalTool(t, dir, "echoArgs", `{
+		"name": "echoArgs",
+		"description": "Echo the arguments back",
+		"command": ["./echoArgs.sh", "{{space}}"]
+	}`, "#!/bin/sh\nargs=$(cat)\nprintf '{\"success\":true,\"data\":{\"space\":\"%s\",\"stdin\":%s}}' \"$1\" \"$args\"\n")
+
+	loaded, errs := LoadExternalTools(dir)
+	if len(errs) > 0 || len(loaded) != 1 {
+		t.Fatalf("Expected one tool, got %d (errors: %v)", len(loaded), errs)
+	}
+
+	result := loaded[0].Execute(map[string]interface{}{"space": "DOCS", "page": "Login"})
+	if !result.Success {
+		t.Fatalf("Expected success, got error: %s", result.Error)
+	}
+	data := result.Data.(map[string]interface{})
+	if data["space"] != "DOCS" {
+		t.Errorf("Expected {{space}} to be expanded, got %v", data["space"])
+	}
+	stdin, ok := data["stdin"].(map[string]interface{})
+	if !ok || stdin["page"] != "Login" {
+		t.Errorf("Expected arguments as JSON on stdin, got %v", data["stdin"])
+	}
+}
+
+func TestExternalTool_Failures(t *testing.T) {
+	dir := t.TempDir()
+	writeExternalTool(t, dir, "crash", `{"name":"crash","command":["./crash.sh"]}`, "#!/bin/sh\necho 'boom' >&2\nexit 3\n")
+	writeExternalTool(t, dir, "chatty", `{"name":"chatty","command":["./chatty.sh"]}`, "#!/bin/sh\necho 'hello'\n")
+	writeExternalTool(t, dir, "slow", `{"name":"slow","command":["sleep","5"],"timeoutSec":1}`, "")
+
+	loaded, errs := LoadExternalTools(dir)
+	if len(errs) > 0 || len(loaded) != 3 {
+		t.Fatalf("Expected three tools, got %d (errors: %v)", len(loaded), errs)
+	}
+
+	expected := map[string]string{
+		"crash":  "boom",
+		"chatty": "did not print a JSON ToolResult",
+		"slow":   "timed out",
+	}
+	for _, tool := range loaded {
+		result := tool.Execute(map[string]interface{}{})
+		if result.Success {
+			t.Errorf("Expected %s to fail", tool.Name())
+			continue
+		}
+		if !strings.Contains(result.Error, expected[tool.Name()]) {
+			t.Errorf("Expected %s error to mention %q, got: %s", tool.Name(), expected[tool.Name()], result.Error)
+		}
+	}
+}
+
+func TestRegisterExternalTools(t *testing.T) {
+	dir := t.TempDir()
+	writeExternalTool(t, dir, "pushDocs", `{"name":"pushDocs","description":"Push docs","command":["true"]}`, "")
+	writeExternalTool(t, dir, "override", `{"name":"readFileContents","command":["true"]}`, "")
+	writeExternalTool(t, dir, "broken", `{"name":"bad name","command":["true"]}`, "")
+	writeExternalTool(t, dir, "nocommand", `{"name":"noCommand"}`, "")
+	defer delete(toolRegistry, "pushDocs")
+
+	registered, errs := RegisterExternalTools(dir)
+	if len(registered) != 1 || registered[0].Name() != "pushDocs" {
+		t.Fatalf("Expected only pushDocs to register, got %v", registered)
+	}
+	if len(errs) != 3 {
+		t.Errorf("Expected 3 errors, got %v", errs)
+	}
+	if _, builtin := GetTool("readFileContents").(*ReadFileContentsTool); !builtin {
+		t.Error("Expected built-in readFileContents to be kept")
+	}
+	if tools := ExternalTools(); len(tools) != 1 || tools[0].Name() != "pushDocs" {
+		t.Errorf("Expected ExternalTools to list pushDocs, got %v", tools)
+	}
+}
@@ -1,12 +1,44 @@
 package tools
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"strings"
+	"time"
 )
 
+// FetchOptions controls how fetchMermaidDocumentation downloads pages
+type FetchOptions struct {
+	Timeout time.Duration // limit on each attempt
+	Retries int           // attempts after the first for network errors, 429s and 5xx responses
+	Backoff time.Duration // wait before the first retry, doubled before each later one
+}
+
+// DefaultFetchOptions are used until SetFetchOptions is called
+var DefaultFetchOptions = FetchOptions{Timeout: 15 * time.Second, Retries: 2, Backoff: time.Second}
+
+var fetchOptions = DefaultFetchOptions
+
+// SetFetchOptions replaces the fetch options. Zero fields keep their defaults
+// and negative retries disable retrying.
+func SetFetchOptions(options FetchOptions) {
+	if options.Timeout <= 0 {
+		options.Timeout = DefaultFetchOptions.Timeout
+	}
+	if options.Retries == 0 {
+		options.Retries = DefaultFetchOptions.Retries
+	} else if options.Retries < 0 {
+		options.Retries = 0
+	}
+	if options.Backoff <= 0 {
+		options.Backoff = DefaultFetchOptions.Backoff
+	}
+	fetchOptions = options
+}
+
 type FetchMermaidDocumentationTool struct{}
 
 func (t *FetchMermaidDocumentationTool) Name() string {
@@ -34,6 +66,11 @@ func (t *FetchMermaidDocumentationTool) Schema() map[string]any {
 }
 
 func (t *FetchMermaidDocumentationTool) Execute(args map[string]any) ToolResult {
+	return t.ExecuteContext(context.Background(), args)
+}
+
+// ExecuteContext fetches the documentation, giving up when ctx is done
+func (t *FetchMermaidDocumentationTool) ExecuteContext(ctx context.Context, args map[string]any) ToolResult {
 	var topic string
 
 	if t, exists := args["topic"]; exists {
@@ -52,16 +89,16 @@ func (t *FetchMermaidDocumentationTool) Execute(args map[string]any) ToolResult
 	if topic != "" {
 		// Try to construct a documentation URL for the topic
 		url = fmt.Sprintf("%s/config/diagrams-and-syntaxes/%s.html", baseURL, strings.ToLower(topic))
-		content, err = fetchURL(url)
-		if err != nil {
+		content, err = fetchURL(ctx, url)
+		if err != nil && ctx.Err() == nil {
 			// Fallback to general documentation
 			url = baseURL + "/config/diagrams-and-syntaxes.html"
-			content, err = fetchURL(url)
+			content, err = fetchURL(ctx, url)
 		}
 	} else {
 		// Fetch general Mermaid documentation
 		url = baseURL + "/config/diagrams-and-syntaxes.html"
-		content, err = fetchURL(url)
+		content, err = fetchURL(ctx, url)
 	}
 
 	if err != nil {
@@ -80,20 +117,68 @@ func (t *FetchMermaidDocumentationTool) Execute(args map[string]any) ToolResult
 	}
 }
 
-func fetchURL(url string) (string, error) {
-	resp, err := http.Get(url)
+// errRetryable marks fetch failures worth another attempt
+var errRetryable = errors.New("retryable")
+
+// fetchURL downloads url, retrying network errors, 429s and 5xx responses
+// with exponential backoff as configured by SetFetchOptions
+func fetchURL(ctx context.Context, url string) (string, error) {
+	options := fetchOptions
+	backoff := options.Backoff
+	attempts := options.Retries + 1
+
+	var lastErr error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		content, err := fetchOnce(ctx, url, options.Timeout)
+		if err == nil {
+			return content, nil
+		}
+		lastErr = err
+		if ctx.Err() != nil {
+			return "", fmt.Errorf("fetching %s was cancelled: %w", url, ctx.Err())
+		}
+		if !errors.Is(err, errRetryable) {
+			return "", err
+		}
+		if attempt == attempts {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return "", fmt.Errorf("fetching %s was cancelled: %w", url, ctx.Err())
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+	return "", fmt.Errorf("%s could not be fetched after %d attempts (check your network connection): %w", url, attempts, lastErr)
+}
+
+// fetchOnce makes a single GET request limited to timeout
+func fetchOnce(ctx context.Context, url string, timeout time.Duration) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		return "", err
 	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", errRetryable, err)
+	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+		return "", fmt.Errorf("%w: HTTP %d", errRetryable, resp.StatusCode)
+	}
 	if resp.StatusCode != http.StatusOK {
 		return "", fmt.Errorf("HTTP %d", resp.StatusCode)
 	}
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", err
+		return "", fmt.Errorf("%w: %w", errRetryable, err)
 	}
 
 	return string(body), nil
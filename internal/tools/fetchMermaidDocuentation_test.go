@@ -0,0 +1,98 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// useFetchOptions sets fetch options for one test and restores the defaults after it
+func useFetchOptions(t *testing.T, options FetchOptions) {
+	t.Helper()
+	SetFetchOptions(options)
+	t.Cleanup(func() { fetchOptions = DefaultFetchOptions })
+}
+
+func TestFetchURL_RetriesTransientFailures(t *testing.T) {
+	useFetchOptions(t, FetchOptions{Timeout: time.Second, Retries: 2, Backoff: time.Millisecond})
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte("flowchart docs"))
+	}))
+	defer server.Close()
+
+	content, err := fetchURL(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("Expected the third attempt to succeed, got %v", err)
+	}
+	if content != "flowchart docs" || requests.Load() != 3 {
+		t.Errorf("Expected the page after 3 requests, got %q after %d", content, requests.Load())
+	}
+}
+
+func TestFetchURL_GivesUpAfterRetries(t *testing.T) {
+	useFetchOptions(t, FetchOptions{Timeout: time.Second, Retries: 1, Backoff: time.Millisecond})
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	_, err := fetchURL(context.Background(), server.URL)
+	if err == nil || !strings.Contains(err.Error(), "after 2 attempts") || !strings.Contains(err.Error(), "HTTP 502") {
+		t.Errorf("Expected a clear error after 2 attempts, got %v", err)
+	}
+	if requests.Load() != 2 {
+		t.Errorf("Expected 2 requests, got %d", requests.Load())
+	}
+}
+
+func TestFetchURL_DoesNotRetryNotFound(t *testing.T) {
+	useFetchOptions(t, FetchOptions{Timeout: time.Second, Retries: 2, Backoff: time.Millisecond})
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	if _, err := fetchURL(context.Background(), server.URL); err == nil || requests.Load() != 1 {
+		t.Errorf("Expected a 404 to fail without retrying, got %v after %d requests", err, requests.Load())
+	}
+}
+
+func TestFetchURL_StopsWhenContextIsDone(t *testing.T) {
+	useFetchOptions(t, FetchOptions{Timeout: time.Second, Retries: 5, Backoff: time.Minute})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := fetchURL(ctx, server.URL)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the run deadline to end the backoff, got %v", err)
+	}
+	if time.Since(start) > 5*time.Second {
+		t.Error("Expected fetchURL to return promptly once the context was done")
+	}
+}
+
+func TestSetFetchOptions(t *testing.T) {
+	useFetchOptions(t, FetchOptions{Retries: -1})
+	if fetchOptions.Retries != 0 || fetchOptions.Timeout != DefaultFetchOptions.Timeout || fetchOptions.Backoff != DefaultFetchOptions.Backoff {
+		t.Errorf("Expected defaults with retries disabled, got %+v", fetchOptions)
+	}
+}
@@ -1,49 +1,28 @@
 package tools
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
 	"strings"
+	"time"
+
+	"github.com/landanqrew/mermaid-agent-documenter/internal/config"
 )
 
 type GenerateMermaidImageTool struct{}
 
-// getProjectOutDir returns the project-specific out directory path
+// getProjectOutDir returns the project-specific out directory path, or ""
+// to fall back to the current directory when no project is set
 func (t *GenerateMermaidImageTool) getProjectOutDir() string {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return "" // fallback to current directory
-	}
-
-	configPath := filepath.Join(homeDir, "mermaid-agent-documenter", "config.json")
-	if _, err := os.Stat(configPath); err != nil {
-		return "" // no config found, use current directory
-	}
-
-	data, err := os.ReadFile(configPath)
-	if err != nil {
-		return "" // failed to read config
-	}
-
-	var cfg struct {
-		CurrentProject *struct {
-			RootDir string `json:"rootDir"`
-		} `json:"currentProject,omitempty"`
+	rootDir := config.CurrentProjectRoot()
+	if rootDir == "" {
+		return ""
 	}
-
-	if err := json.Unmarshal(data, &cfg); err != nil {
-		return "" // failed to parse config
-	}
-
-	if cfg.CurrentProject == nil {
-		return "" // no current project
-	}
-
-	// Return the project's out directory
-	return filepath.Join(cfg.CurrentProject.RootDir, "out")
+	return filepath.Join(rootDir, "out")
 }
 
 func (t *GenerateMermaidImageTool) Name() string {
@@ -60,7 +39,7 @@ func (t *GenerateMermaidImageTool) Schema() map[string]interface{} {
 		"properties": map[string]interface{}{
 			"inputFile": map[string]interface{}{
 				"type":        "string",
-				"description": "Path to the Markdown file containing Mermaid diagrams",
+				"description": "Path to a Markdown file containing ```mermaid blocks, or a standalone .mmd file containing a single raw diagram",
 			},
 			"outputFile": map[string]interface{}{
 				"type":        "string",
@@ -72,6 +51,11 @@ func (t *GenerateMermaidImageTool) Schema() map[string]interface{} {
 				"description": "Output format: svg (default), png, or pdf",
 				"default":     "svg",
 			},
+			"fallbackFormat": map[string]interface{}{
+				"type":        "string",
+				"enum":        []string{"svg", "png", "pdf"},
+				"description": "Optional format to retry with when the renderer fails to create the output file (an environment problem, not a syntax error)",
+			},
 			"createDirs": map[string]interface{}{
 				"type":        "boolean",
 				"description": "Whether to create output directories if they don't exist",
@@ -83,6 +67,11 @@ func (t *GenerateMermaidImageTool) Schema() map[string]interface{} {
 }
 
 func (t *GenerateMermaidImageTool) Execute(args map[string]interface{}) ToolResult {
+	return t.ExecuteContext(context.Background(), args)
+}
+
+// ExecuteContext renders the diagram, stopping mmdc when ctx is done
+func (t *GenerateMermaidImageTool) ExecuteContext(ctx context.Context, args map[string]interface{}) ToolResult {
 	inputFile, ok := args["inputFile"].(string)
 	if !ok {
 		return ToolResult{
@@ -103,17 +92,26 @@ func (t *GenerateMermaidImageTool) Execute(args map[string]interface{}) ToolResu
 	if fmt, exists := args["format"].(string); exists && (fmt == "svg" || fmt == "png" || fmt == "pdf") {
 		format = fmt
 	}
+	fallbackFormat, _ := args["fallbackFormat"].(string)
+	if fallbackFormat != "svg" && fallbackFormat != "png" && fallbackFormat != "pdf" {
+		fallbackFormat = ""
+	}
 
 	// Get the project-specific out directory
 	projectOutDir := t.getProjectOutDir()
 	if projectOutDir != "" {
-		// Use project-specific out directory
+		// Use project-specific out directory, keeping subdirectories of it
+		// (such as per documentation type folders) when already targeted
 		filename := filepath.Base(outputFile)
 		if !strings.HasSuffix(outputFile, "."+format) {
 			filename = filename + "." + format
 		}
-		outputFile = filepath.Join(projectOutDir, filename)
-	} else {
+		outputDir := projectOutDir
+		if rel, err := filepath.Rel(projectOutDir, filepath.Dir(outputFile)); err == nil && filepath.IsAbs(outputFile) && !strings.HasPrefix(rel, "..") {
+			outputDir = filepath.Join(projectOutDir, rel)
+		}
+		outputFile = filepath.Join(outputDir, filename)
+	} else if !filepath.IsAbs(outputFile) && !strings.HasPrefix(outputFile, "~") {
 		// Fallback: if no project is set, use current working directory with out/ prefix
 		if !strings.Contains(outputFile, "out/") {
 			parts := strings.Split(outputFile, "/")
@@ -160,6 +158,22 @@ func (t *GenerateMermaidImageTool) Execute(args map[string]interface{}) ToolResu
 		}
 	}
 
+	// Standalone .mmd files hold a single raw diagram that mmdc renders directly
+	inputType := "markdown"
+	mmdcInput := inputFile
+	if filepath.Ext(inputFile) == ".mmd" {
+		inputType = "mermaid"
+		rawInput, cleanup, err := prepareRawDiagram(inputFile)
+		if err != nil {
+			return ToolResult{
+				Success: false,
+				Error:   fmt.Sprintf("Failed to read diagram file: %v", err),
+			}
+		}
+		defer cleanup()
+		mmdcInput = rawInput
+	}
+
 	// Create output directory if needed
 	if createDirs {
 		outputDir := filepath.Dir(outputFile)
@@ -172,10 +186,10 @@ func (t *GenerateMermaidImageTool) Execute(args map[string]interface{}) ToolResu
 	}
 
 	// Check if Mermaid CLI is available
-	if _, err := exec.LookPath("mmdc"); err != nil {
+	if !MermaidCLIInstalled() {
 		return ToolResult{
 			Success: false,
-			Error:   "Mermaid CLI (mmdc) is not installed. Install it with: npm install -g @mermaid-js/mermaid-cli",
+			Error:   MissingMermaidCLIMessage(),
 		}
 	}
 
@@ -186,14 +200,27 @@ func (t *GenerateMermaidImageTool) Execute(args map[string]interface{}) ToolResu
 		fullOutputPath = fullOutputPath + "." + format
 	}
 
-	// Build Mermaid CLI command
-	cmd := exec.Command("mmdc", "-i", inputFile, "-o", fullOutputPath)
-
-	// Set environment variables if needed
-	cmd.Env = os.Environ()
+	// Relative asset references (e.g. imageUrl logos) resolve against the diagram's directory
+	workDir := filepath.Dir(inputFile)
+	output, err := runMmdc(ctx, mmdcInput, fullOutputPath, workDir)
+	if ctxErr := ctx.Err(); err != nil && ctxErr != nil {
+		return ToolResult{
+			Success: false,
+			Error:   fmt.Sprintf("Rendering %s was stopped: %v", inputFile, ctxErr),
+		}
+	}
 
-	// Execute the command
-	output, err := cmd.CombinedOutput()
+	// A missing output file is an environment problem (often headless Chromium),
+	// not a syntax error, so the fallback format may still render
+	requestedFormat := format
+	if fallbackFormat != "" && fallbackFormat != format && outputNotCreated(output, err, fullOutputPath) {
+		fallbackPath := strings.TrimSuffix(fullOutputPath, "."+format) + "." + fallbackFormat
+		fallbackOutput, fallbackErr := runMmdc(ctx, mmdcInput, fallbackPath, workDir)
+		if fallbackErr == nil && !outputNotCreated(fallbackOutput, fallbackErr, fallbackPath) {
+			format, fullOutputPath = fallbackFormat, fallbackPath
+			output, err = fallbackOutput, nil
+		}
+	}
 
 	if err != nil {
 		// Parse Mermaid CLI errors for more specific feedback
@@ -201,17 +228,23 @@ func (t *GenerateMermaidImageTool) Execute(args map[string]interface{}) ToolResu
 
 		// Check for specific error patterns
 		if strings.Contains(errorMsg, "No diagram found") {
+			if inputType == "mermaid" {
+				return ToolResult{
+					Success: false,
+					Error:   fmt.Sprintf("No Mermaid diagram found in file: %s. A .mmd file must contain raw diagram source (e.g. 'graph TD') without ```mermaid fences.", inputFile),
+				}
+			}
 			return ToolResult{
 				Success: false,
 				Error:   fmt.Sprintf("No Mermaid diagrams found in file: %s. Check that diagrams are properly formatted with ```mermaid code blocks.", inputFile),
 			}
 		}
 
-		// Check for multiple diagram parsing issues
-		if strings.Contains(errorMsg, "Found 2 mermaid charts") || strings.Contains(errorMsg, "Found 3 mermaid charts") {
+		// Several diagrams render to one image each, so a failure means one of them is invalid
+		if multipleChartsPattern.MatchString(errorMsg) {
 			return ToolResult{
 				Success: false,
-				Error:   fmt.Sprintf("Multiple diagram types detected in file: %s. Mermaid CLI struggles with multiple diagram types in one file. Split into separate files: one for sequence diagrams, one for ER diagrams, etc.", inputFile),
+				Error:   fmt.Sprintf("Mermaid CLI failed on one of the diagrams in %s (each ```mermaid block is rendered to its own image): %s. Fix the failing diagram's syntax, or write each diagram to its own .mmd file with writeMermaidDiagram.", inputFile, errorMsg),
 			}
 		}
 
@@ -252,20 +285,112 @@ func (t *GenerateMermaidImageTool) Execute(args map[string]interface{}) ToolResu
 	}
 
 	// Verify the output file was created
-	if _, err := os.Stat(fullOutputPath); os.IsNotExist(err) {
+	outputFiles := renderedOutputs(fullOutputPath)
+	if len(outputFiles) == 0 {
 		return ToolResult{
 			Success: false,
 			Error:   fmt.Sprintf("Output file was not created: %s", fullOutputPath),
 		}
 	}
 
+	data := map[string]interface{}{
+		"inputFile":       inputFile,
+		"inputType":       inputType,
+		"outputFile":      fullOutputPath,
+		"format":          format,
+		"requestedFormat": requestedFormat,
+		"fallbackUsed":    format != requestedFormat,
+		"commandOutput":   string(output),
+	}
+	// A Markdown file with several diagrams renders to <name>-1.<ext>, <name>-2.<ext>, ...
+	if outputFiles[0] != fullOutputPath {
+		data["outputFiles"] = outputFiles
+	}
 	return ToolResult{
 		Success: true,
-		Data: map[string]interface{}{
-			"inputFile":     inputFile,
-			"outputFile":    fullOutputPath,
-			"format":        format,
-			"commandOutput": string(output),
-		},
+		Data:    data,
+	}
+}
+
+// multipleChartsPattern matches mmdc's report of a Markdown file with several diagrams
+var multipleChartsPattern = regexp.MustCompile(`Found [2-9]\d* mermaid charts`)
+
+// renderedOutputs returns the images mmdc wrote for path: path itself, or
+// the numbered <base>-1.<ext>, <base>-2.<ext>, ... it writes one per diagram
+// when a Markdown file holds several. It is empty when nothing was written.
+func renderedOutputs(path string) []string {
+	if _, err := os.Stat(path); err == nil {
+		return []string{path}
+	}
+	ext := filepath.Ext(path)
+	base := strings.TrimSuffix(path, ext)
+	var outputs []string
+	for n := 1; ; n++ {
+		numbered := fmt.Sprintf("%s-%d%s", base, n, ext)
+		if _, err := os.Stat(numbered); err != nil {
+			return outputs
+		}
+		outputs = append(outputs, numbered)
+	}
+}
+
+// mmdcWaitDelay bounds how long a killed mmdc's output is waited for, since the
+// Chromium it started can keep the pipes open after mmdc itself has exited
+const mmdcWaitDelay = 2 * time.Second
+
+// runMmdc renders a diagram file with the Mermaid CLI from workDir and returns
+// its combined output. mmdc is killed when ctx is done.
+func runMmdc(ctx context.Context, input, output, workDir string) ([]byte, error) {
+	// Paths are made absolute because mmdc no longer runs from our working directory
+	absInput, err := filepath.Abs(input)
+	if err != nil {
+		return nil, err
+	}
+	absOutput, err := filepath.Abs(output)
+	if err != nil {
+		return nil, err
+	}
+	cmd := exec.CommandContext(ctx, "mmdc", "-i", absInput, "-o", absOutput)
+	cmd.WaitDelay = mmdcWaitDelay
+	cmd.Dir = workDir
+	cmd.Env = os.Environ()
+	return cmd.CombinedOutput()
+}
+
+// outputNotCreated reports whether mmdc ran without producing its output file
+func outputNotCreated(output []byte, err error, path string) bool {
+	if err != nil {
+		return strings.Contains(string(output), "Output file was not created")
+	}
+	return len(renderedOutputs(path)) == 0
+}
+
+// prepareRawDiagram returns a path mmdc can render for a .mmd file. Files that
+// were written with ```mermaid fences are copied, unfenced, to a temp file.
+func prepareRawDiagram(inputFile string) (string, func(), error) {
+	noop := func() {}
+
+	data, err := os.ReadFile(inputFile)
+	if err != nil {
+		return "", noop, err
+	}
+	if !strings.HasPrefix(strings.TrimSpace(string(data)), "```") {
+		return inputFile, noop, nil
+	}
+
+	tmp, err := os.CreateTemp("", "mad-diagram-*.mmd")
+	if err != nil {
+		return "", noop, err
+	}
+	cleanup := func() { os.Remove(tmp.Name()) }
+
+	_, err = tmp.WriteString(normalizeMermaidDiagram(string(data)))
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		cleanup()
+		return "", noop, err
 	}
+	return tmp.Name(), cleanup, nil
 }
@@ -0,0 +1,179 @@
+package tools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPrepareRawDiagram(t *testing.T) {
+	dir := t.TempDir()
+
+	raw := filepath.Join(dir, "raw.mmd")
+	if err := os.WriteFile(raw, []byte("graph TD\n  A --> B\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	path, cleanup, err := prepareRawDiagram(raw)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	cleanup()
+	if path != raw {
+		t.Errorf("Expected raw file to be used as-is, got %s", path)
+	}
+
+	fenced := filepath.Join(dir, "fenced.mmd")
+	if err := os.WriteFile(fenced, []byte("```mermaid\ngraph TD\n  A --> B\n```\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	path, cleanup, err = prepareRawDiagram(fenced)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer cleanup()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read prepared file: %v", err)
+	}
+	if string(data) != "graph TD\n  A --> B\n" {
+		t.Errorf("Expected fences to be stripped, got %q", string(data))
+	}
+}
+
+// installFakeMmdc puts an mmdc on PATH that fails to create .svg output and
+// writes any other format
+func installFakeMmdc(t *testing.T) {
+	t.Helper()
+	installMmdcScript(t, `#!/bin/sh
+while [ $# -gt 0 ]; do
+  if [ "$1" = "-o" ]; then out="$2"; fi
+  shift
+done
+case "$out" in
+  *.svg) echo "Error: Output file was not created"; exit 1 ;;
+  *) echo rendered > "$out" ;;
+esac
+`)
+}
+
+// installMmdcScript puts a shell script on PATH as mmdc
+func installMmdcScript(t *testing.T, script string) {
+	t.Helper()
+	bin := t.TempDir()
+	if err := os.WriteFile(filepath.Join(bin, "mmdc"), []byte(script), 0755); err != nil {
+		t.Fatalf("Failed to write fake mmdc: %v", err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Setenv("HOME", t.TempDir())
+}
+
+func TestGenerateMermaidImage_FallbackFormat(t *testing.T) {
+	installFakeMmdc(t)
+	dir := t.TempDir()
+	input := filepath.Join(dir, "flow.mmd")
+	if err := os.WriteFile(input, []byte("graph TD\n  A --> B\n"), 0644); err != nil {
+		t.Fatalf("Failed to write diagram: %v", err)
+	}
+	tool := &GenerateMermaidImageTool{}
+
+	result := tool.Execute(map[string]interface{}{"inputFile": input, "outputFile": filepath.Join(dir, "flow"), "format": "svg"})
+	if result.Success || !strings.Contains(result.Error, "output file was not created") {
+		t.Fatalf("Expected the SVG render to fail without a fallback, got %+v", result)
+	}
+
+	result = tool.Execute(map[string]interface{}{"inputFile": input, "outputFile": filepath.Join(dir, "flow"), "format": "svg", "fallbackFormat": "png"})
+	if !result.Success {
+		t.Fatalf("Expected the PNG fallback to succeed, got: %s", result.Error)
+	}
+	data := result.Data.(map[string]interface{})
+	if data["format"] != "png" || data["requestedFormat"] != "svg" || data["fallbackUsed"] != true {
+		t.Errorf("Expected the fallback format to be recorded, got %v", data)
+	}
+	if data["outputFile"] != filepath.Join(dir, "flow.png") {
+		t.Errorf("Expected flow.png, got %v", data["outputFile"])
+	}
+}
+
+func TestGenerateMermaidImage_StopsWithContext(t *testing.T) {
+	installMmdcScript(t, "#!/bin/sh\nexec sleep 30\n")
+	dir := t.TempDir()
+	input := filepath.Join(dir, "flow.mmd")
+	if err := os.WriteFile(input, []byte("graph TD\n  A --> B\n"), 0644); err != nil {
+		t.Fatalf("Failed to write diagram: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	result := (&GenerateMermaidImageTool{}).ExecuteContext(ctx, map[string]interface{}{"inputFile": input, "outputFile": filepath.Join(dir, "flow"), "format": "svg"})
+	if result.Success || !strings.Contains(result.Error, "stopped") {
+		t.Errorf("Expected the render to be stopped, got %+v", result)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Expected mmdc to be killed with the context, took %v", elapsed)
+	}
+}
+
+func TestGenerateMermaidImage_RelativeAssets(t *testing.T) {
+	// The fake mmdc fails like a broken image unless ./logo.png resolves
+	installMmdcScript(t, `#!/bin/sh
+while [ $# -gt 0 ]; do
+  if [ "$1" = "-o" ]; then out="$2"; fi
+  shift
+done
+if [ ! -f logo.png ]; then echo "Error: could not load ./logo.png"; exit 1; fi
+echo rendered > "$out"
+`)
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "logo.png"), []byte("png"), 0644); err != nil {
+		t.Fatalf("Failed to write asset: %v", err)
+	}
+	input := filepath.Join(dir, "arch.mmd")
+	diagram := "flowchart LR\n  A[\"<img src='./logo.png' />\"] --> B\n"
+	if err := os.WriteFile(input, []byte(diagram), 0644); err != nil {
+		t.Fatalf("Failed to write diagram: %v", err)
+	}
+
+	tool := &GenerateMermaidImageTool{}
+	result := tool.Execute(map[string]interface{}{"inputFile": input, "outputFile": filepath.Join(dir, "arch"), "format": "png"})
+	if !result.Success {
+		t.Fatalf("Expected the sibling asset to resolve, got: %s", result.Error)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "arch.png")); err != nil {
+		t.Errorf("Expected arch.png to be rendered: %v", err)
+	}
+}
+
+func TestGenerateMermaidImage_SplitsMarkdownDiagrams(t *testing.T) {
+	// Like mmdc, the fake writes one numbered image per diagram in a Markdown file
+	installMmdcScript(t, `#!/bin/sh
+while [ $# -gt 0 ]; do
+  if [ "$1" = "-o" ]; then out="$2"; fi
+  shift
+done
+echo "Found 2 mermaid charts in Markdown input"
+echo one > "${out%.svg}-1.svg"
+echo two > "${out%.svg}-2.svg"
+`)
+	dir := t.TempDir()
+	input := filepath.Join(dir, "documentation.md")
+	markdown := "## User Flow\n\n```mermaid\ngraph TD\n  A --> B\n```\n\n## Data Models\n\n```mermaid\nerDiagram\n  USER ||--o{ ORDER : places\n```\n"
+	if err := os.WriteFile(input, []byte(markdown), 0644); err != nil {
+		t.Fatalf("Failed to write documentation: %v", err)
+	}
+
+	tool := &GenerateMermaidImageTool{}
+	result := tool.Execute(map[string]interface{}{"inputFile": input, "outputFile": filepath.Join(dir, "documentation"), "format": "svg"})
+	if !result.Success {
+		t.Fatalf("Expected the numbered images to count as rendered, got: %s", result.Error)
+	}
+	outputs, _ := result.Data.(map[string]interface{})["outputFiles"].([]string)
+	expected := []string{filepath.Join(dir, "documentation-1.svg"), filepath.Join(dir, "documentation-2.svg")}
+	if len(outputs) != 2 || outputs[0] != expected[0] || outputs[1] != expected[1] {
+		t.Errorf("Expected outputFiles %v, got %v", expected, outputs)
+	}
+}
@@ -1,10 +1,7 @@
 package tools
 
 import (
-	"bufio"
-	"fmt"
-	"os"
-	"strings"
+	"github.com/landanqrew/mermaid-agent-documenter/internal/console"
 )
 
 type GetUserInputTool struct{}
@@ -39,9 +36,8 @@ func (t *GetUserInputTool) Execute(args map[string]interface{}) ToolResult {
 		}
 	}
 
-	fmt.Print(prompt + " ")
-	reader := bufio.NewReader(os.Stdin)
-	answer, err := reader.ReadString('\n')
+	console.Print(prompt + " ")
+	answer, err := console.ReadLine()
 	if err != nil {
 		return ToolResult{
 			Success: false,
@@ -49,9 +45,6 @@ func (t *GetUserInputTool) Execute(args map[string]interface{}) ToolResult {
 		}
 	}
 
-	// Trim whitespace and newlines
-	answer = strings.TrimSpace(answer)
-
 	return ToolResult{
 		Success: true,
 		Data: map[string]interface{}{
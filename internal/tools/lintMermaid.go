@@ -0,0 +1,109 @@
+package tools
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// erAttributeTypes are data types commonly written in front of ER attribute names
+var erAttributeTypes = map[string]bool{
+	"string": true, "str": true, "text": true, "varchar": true, "char": true,
+	"int": true, "integer": true, "bigint": true, "smallint": true, "long": true,
+	"float": true, "double": true, "decimal": true, "number": true, "numeric": true,
+	"bool": true, "boolean": true, "date": true, "datetime": true, "time": true,
+	"timestamp": true, "uuid": true, "json": true, "blob": true, "enum": true,
+}
+
+// erKeyMarkers only appear in Mermaid's typed attribute syntax
+var erKeyMarkers = map[string]bool{"PK": true, "FK": true, "UK": true}
+
+// sizedTypePattern matches types with a size or array suffix, e.g. varchar(255) or string[]
+var sizedTypePattern = regexp.MustCompile(`^[A-Za-z_]\w*(\(\s*\d+(\s*,\s*\d+)?\s*\)|\[\])$`)
+
+// mermaidDiagramStarts are the keywords that begin a diagram other than erDiagram
+var mermaidDiagramStarts = []string{
+	"graph", "flowchart", "sequenceDiagram", "classDiagram", "stateDiagram", "gantt",
+	"pie", "journey", "gitGraph", "mindmap", "timeline", "quadrantChart",
+}
+
+// LintERDiagrams reports ER attributes that carry type annotations in a
+// Markdown file or raw .mmd source. Each issue names the offending line.
+func LintERDiagrams(source string) []string {
+	var issues []string
+	inER := false
+	entity := ""
+
+	for i, raw := range strings.Split(source, "\n") {
+		line := strings.TrimSpace(raw)
+
+		switch {
+		case line == "erDiagram" || strings.HasPrefix(line, "erDiagram "):
+			inER, entity = true, ""
+			continue
+		case !inER:
+			continue
+		case strings.HasPrefix(line, "```") || startsOtherDiagram(line):
+			inER, entity = false, ""
+			continue
+		}
+
+		// A block opens with "Entity {" and may close on the same line
+		body := line
+		if entity == "" {
+			open := strings.Index(line, "{")
+			if open < 0 {
+				continue
+			}
+			entity = strings.TrimSpace(line[:open])
+			body = line[open+1:]
+		}
+		closed := false
+		if end := strings.Index(body, "}"); end >= 0 {
+			body, closed = body[:end], true
+		}
+
+		for _, attribute := range strings.Split(body, ";") {
+			if typ, ok := typedAttribute(attribute); ok {
+				issues = append(issues, fmt.Sprintf("line %d: %s attribute %q has type annotation %q", i+1, entity, strings.TrimSpace(attribute), typ))
+			}
+		}
+		if closed {
+			entity = ""
+		}
+	}
+
+	return issues
+}
+
+// typedAttribute reports whether an ER attribute is written as "type name [PK] ..."
+func typedAttribute(attribute string) (string, bool) {
+	// Drop the optional quoted comment before splitting into words
+	if quote := strings.Index(attribute, `"`); quote >= 0 {
+		attribute = attribute[:quote]
+	}
+	fields := strings.Fields(attribute)
+	if len(fields) < 2 {
+		return "", false
+	}
+	first := fields[0]
+	if erAttributeTypes[strings.ToLower(first)] || sizedTypePattern.MatchString(first) {
+		return first, true
+	}
+	for _, field := range fields[1:] {
+		if erKeyMarkers[strings.TrimSuffix(field, ",")] {
+			return first, true
+		}
+	}
+	return "", false
+}
+
+// startsOtherDiagram reports whether a line begins a non-ER Mermaid diagram
+func startsOtherDiagram(line string) bool {
+	for _, start := range mermaidDiagramStarts {
+		if line == start || strings.HasPrefix(line, start+" ") || strings.HasPrefix(line, start+"-") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,56 @@
+package tools
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLintERDiagrams(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		issues []string
+	}{
+		{
+			name:   "untyped_attributes",
+			source: "erDiagram\n    Site { id name }\n    Wash { id; siteId }\n    Site ||--o{ Wash : hosts\n",
+		},
+		{
+			name:   "typed_block",
+			source: "erDiagram\n    Site {\n        string id PK\n        string name\n    }\n",
+			issues: []string{`line 3: Site attribute "string id PK"`, `line 4: Site attribute "string name"`},
+		},
+		{
+			name:   "typed_one_line_block",
+			source: "erDiagram\n    Wash { int id; varchar(64) plate }\n",
+			issues: []string{`line 2: Wash attribute "int id"`, `line 2: Wash attribute "varchar(64) plate"`},
+		},
+		{
+			name:   "key_marker_with_custom_type",
+			source: "erDiagram\n    Wash {\n        WashId id PK\n    }\n",
+			issues: []string{`line 3: Wash attribute "WashId id PK"`},
+		},
+		{
+			name:   "markdown_fence_ends_diagram",
+			source: "# Data\n\n```mermaid\nerDiagram\n    Site { id }\n```\n\n```mermaid\nclassDiagram\n    class Site {\n        string id\n    }\n```\n",
+		},
+		{
+			name:   "other_diagram_types_ignored",
+			source: "sequenceDiagram\n    User->>App: string id\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issues := LintERDiagrams(tt.source)
+			if len(issues) != len(tt.issues) {
+				t.Fatalf("Expected %d issues, got %d: %v", len(tt.issues), len(issues), issues)
+			}
+			for i, want := range tt.issues {
+				if !strings.HasPrefix(issues[i], want) {
+					t.Errorf("Expected issue %d to start with %q, got %q", i, want, issues[i])
+				}
+			}
+		})
+	}
+}
@@ -5,6 +5,10 @@ import (
 	"os"
 	"path/filepath"
 	"time"
+
+	"github.com/landanqrew/mermaid-agent-documenter/internal/config"
+	"github.com/landanqrew/mermaid-agent-documenter/internal/jsonl"
+	"github.com/landanqrew/mermaid-agent-documenter/internal/logs"
 )
 
 type LogEventTool struct{}
@@ -34,6 +38,10 @@ func (t *LogEventTool) Schema() map[string]interface{} {
 				"type":        "object",
 				"description": "Optional additional data to log",
 			},
+			"runId": map[string]interface{}{
+				"type":        "string",
+				"description": "Optional run ID; the event is also written to logs/<runId>.jsonl",
+			},
 		},
 		"required": []string{"level", "message"},
 	}
@@ -65,16 +73,16 @@ func (t *LogEventTool) Execute(args map[string]interface{}) ToolResult {
 		}
 	}
 
-	// Get log directory
-	home, err := os.UserHomeDir()
-	if err != nil {
+	runID, _ := args["runId"].(string)
+	if runID != "" && !logs.ValidRunID(runID) {
 		return ToolResult{
 			Success: false,
-			Error:   "Failed to get home directory: " + err.Error(),
+			Error:   "Invalid 'runId' argument: " + runID,
 		}
 	}
 
-	logDir := filepath.Join(home, "mermaid-agent-documenter", "logs")
+	// Get log directory
+	logDir := filepath.Join(config.Dir(), "logs")
 	if err := os.MkdirAll(logDir, 0755); err != nil {
 		return ToolResult{
 			Success: false,
@@ -92,18 +100,12 @@ func (t *LogEventTool) Execute(args map[string]interface{}) ToolResult {
 	if data, exists := args["data"]; exists {
 		logEntry["data"] = data
 	}
-
-	// Write to logs.jsonl
-	logFile := filepath.Join(logDir, "events.jsonl")
-	file, err := os.OpenFile(logFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-	if err != nil {
-		return ToolResult{
-			Success: false,
-			Error:   "Failed to open log file: " + err.Error(),
-		}
+	if runID != "" {
+		logEntry["run_id"] = runID
 	}
-	defer file.Close()
 
+	// Write to events.jsonl
+	logFile := filepath.Join(logDir, "events.jsonl")
 	logJSON, err := json.Marshal(logEntry)
 	if err != nil {
 		return ToolResult{
@@ -112,13 +114,23 @@ func (t *LogEventTool) Execute(args map[string]interface{}) ToolResult {
 		}
 	}
 
-	if _, err := file.WriteString(string(logJSON) + "\n"); err != nil {
+	if err := jsonl.AppendLine(logFile, logJSON); err != nil {
 		return ToolResult{
 			Success: false,
 			Error:   "Failed to write log entry: " + err.Error(),
 		}
 	}
 
+	// Also write to the run's own log file
+	if runID != "" {
+		if err := jsonl.AppendLine(logs.RunFile(logDir, runID), logJSON); err != nil {
+			return ToolResult{
+				Success: false,
+				Error:   "Failed to write run log entry: " + err.Error(),
+			}
+		}
+	}
+
 	return ToolResult{
 		Success: true,
 		Data: map[string]interface{}{
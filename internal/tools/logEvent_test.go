@@ -0,0 +1,51 @@
+package tools
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLogEvent_RunID(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	logDir := filepath.Join(home, "mermaid-agent-documenter", "logs")
+	runID := "0b7e2f8a-5c1d-4e3f-9a2b-6c7d8e9f0a1b"
+
+	tool := &LogEventTool{}
+	result := tool.Execute(map[string]interface{}{"level": "info", "message": "started", "runId": runID})
+	if !result.Success {
+		t.Fatalf("Execute failed: %s", result.Error)
+	}
+
+	for _, name := range []string{"events.jsonl", runID + ".jsonl"} {
+		data, err := os.ReadFile(filepath.Join(logDir, name))
+		if err != nil {
+			t.Fatalf("Expected %s to be written: %v", name, err)
+		}
+		var entry map[string]interface{}
+		if err := json.Unmarshal([]byte(strings.TrimSpace(string(data))), &entry); err != nil {
+			t.Fatalf("Invalid entry in %s: %v", name, err)
+		}
+		if entry["run_id"] != runID || entry["message"] != "started" {
+			t.Errorf("Unexpected entry in %s: %v", name, entry)
+		}
+	}
+
+	// Without a run ID only the shared events file is written
+	result = tool.Execute(map[string]interface{}{"level": "info", "message": "no run"})
+	if !result.Success {
+		t.Fatalf("Execute failed: %s", result.Error)
+	}
+	entries, _ := os.ReadDir(logDir)
+	if len(entries) != 2 {
+		t.Errorf("Expected only events.jsonl and the run's file, got %d files", len(entries))
+	}
+
+	result = tool.Execute(map[string]interface{}{"level": "info", "message": "bad", "runId": "../escape"})
+	if result.Success {
+		t.Error("Expected a run ID with a path separator to be rejected")
+	}
+}
@@ -0,0 +1,48 @@
+package tools
+
+import (
+	"fmt"
+	"io"
+	"os/exec"
+)
+
+// MermaidCLIPackage is the npm package that provides mmdc
+const MermaidCLIPackage = "@mermaid-js/mermaid-cli"
+
+// MermaidCLIInstalled reports whether mmdc is on PATH
+func MermaidCLIInstalled() bool {
+	_, err := exec.LookPath("mmdc")
+	return err == nil
+}
+
+// MissingMermaidCLIMessage explains how to install mmdc, pointing to Node.js
+// first when npm itself is not on PATH
+func MissingMermaidCLIMessage() string {
+	if _, err := exec.LookPath("npm"); err != nil {
+		if _, err := exec.LookPath("node"); err == nil {
+			return "Mermaid CLI (mmdc) is not installed, and npm was not found even though Node.js is. Reinstall Node.js with npm (https://nodejs.org/), then run: npm install -g " + MermaidCLIPackage
+		}
+		return "Mermaid CLI (mmdc) is not installed, and neither is Node.js, which it needs. Install Node.js (it includes npm) from https://nodejs.org/, then run: npm install -g " + MermaidCLIPackage
+	}
+	return "Mermaid CLI (mmdc) is not installed. Install it with: npm install -g " + MermaidCLIPackage
+}
+
+// InstallMermaidCLI runs npm install -g for the Mermaid CLI, streaming npm's
+// output to out, and checks that mmdc is on PATH afterwards
+func InstallMermaidCLI(out io.Writer) error {
+	if _, err := exec.LookPath("npm"); err != nil {
+		return fmt.Errorf("npm was not found; install Node.js (it includes npm) from https://nodejs.org/ first")
+	}
+
+	cmd := exec.Command("npm", "install", "-g", MermaidCLIPackage)
+	cmd.Stdout = out
+	cmd.Stderr = out
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("npm install -g %s failed: %w (a permissions error usually means npm's global directory needs sudo or a Node version manager)", MermaidCLIPackage, err)
+	}
+
+	if !MermaidCLIInstalled() {
+		return fmt.Errorf("%s was installed but mmdc is still not on PATH; add the bin directory under `npm prefix -g` to PATH", MermaidCLIPackage)
+	}
+	return nil
+}
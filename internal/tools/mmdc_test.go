@@ -0,0 +1,71 @@
+package tools
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// isolatedPath replaces PATH with an empty directory and returns it
+func isolatedPath(t *testing.T) string {
+	t.Helper()
+	bin := t.TempDir()
+	t.Setenv("PATH", bin)
+	return bin
+}
+
+// writeScript writes an executable shell script into dir
+func writeScript(t *testing.T, dir, name, script string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\n"+script), 0755); err != nil {
+		t.Fatalf("Failed to write %s: %v", name, err)
+	}
+}
+
+func TestMissingMermaidCLIMessage(t *testing.T) {
+	bin := isolatedPath(t)
+	if message := MissingMermaidCLIMessage(); !strings.Contains(message, "neither is Node.js") {
+		t.Errorf("Expected a Node.js hint without node or npm, got: %s", message)
+	}
+
+	writeScript(t, bin, "node", "exit 0\n")
+	if message := MissingMermaidCLIMessage(); !strings.Contains(message, "npm was not found") {
+		t.Errorf("Expected an npm hint when only node is installed, got: %s", message)
+	}
+
+	writeScript(t, bin, "npm", "exit 0\n")
+	if message := MissingMermaidCLIMessage(); !strings.HasPrefix(message, "Mermaid CLI (mmdc) is not installed. Install it with: npm install -g") {
+		t.Errorf("Expected the npm install hint, got: %s", message)
+	}
+}
+
+func TestInstallMermaidCLI(t *testing.T) {
+	chmod, err := exec.LookPath("chmod")
+	if err != nil {
+		t.Skip("chmod not available")
+	}
+	bin := isolatedPath(t)
+	if err := InstallMermaidCLI(io.Discard); err == nil || !strings.Contains(err.Error(), "Node.js") {
+		t.Errorf("Expected a Node.js error without npm, got %v", err)
+	}
+
+	// npm that "installs" nothing
+	writeScript(t, bin, "npm", "exit 0\n")
+	if err := InstallMermaidCLI(io.Discard); err == nil || !strings.Contains(err.Error(), "still not on PATH") {
+		t.Errorf("Expected a PATH error when mmdc does not appear, got %v", err)
+	}
+
+	// npm that installs mmdc next to itself
+	mmdc := filepath.Join(bin, "mmdc")
+	writeScript(t, bin, "npm", fmt.Sprintf("printf '#!/bin/sh\\n' > '%s'\n'%s' +x '%s'\n", mmdc, chmod, mmdc))
+	if err := InstallMermaidCLI(io.Discard); err != nil {
+		t.Fatalf("Expected the install to succeed, got %v", err)
+	}
+	if !MermaidCLIInstalled() {
+		t.Error("Expected mmdc to be on PATH after installing")
+	}
+}
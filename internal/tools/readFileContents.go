@@ -1,14 +1,24 @@
 package tools
 
 import (
-	"encoding/json"
+	"bytes"
 	"fmt"
+	"io"
+	"net/http"
 	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/landanqrew/mermaid-agent-documenter/internal/config"
 )
 
+// DefaultReadMaxBytes caps how much of a file is returned when the caller
+// does not pass maxBytes
+const DefaultReadMaxBytes int64 = 256 * 1024
+
 type ReadFileContentsTool struct{}
 
 // validatePath checks if the given path is within allowed directories
@@ -19,31 +29,14 @@ func (t *ReadFileContentsTool) validatePath(path string) error {
 		return fmt.Errorf("failed to get absolute path: %w", err)
 	}
 
-	// Get home directory
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return fmt.Errorf("failed to get home directory: %w", err)
-	}
-
 	// Allowed base directories
 	allowedDirs := []string{
-		filepath.Join(homeDir, "mermaid-agent-documenter"), // ~/mermaid-agent-documenter/
+		config.Dir(), // ~/mermaid-agent-documenter/
 	}
 
 	// Add current project directory if available
-	configPath := filepath.Join(homeDir, "mermaid-agent-documenter", "config.json")
-	if _, err := os.Stat(configPath); err == nil {
-		data, err := os.ReadFile(configPath)
-		if err == nil {
-			var cfg struct {
-				CurrentProject *struct {
-					RootDir string `json:"rootDir"`
-				} `json:"currentProject,omitempty"`
-			}
-			if err := json.Unmarshal(data, &cfg); err == nil && cfg.CurrentProject != nil {
-				allowedDirs = append(allowedDirs, cfg.CurrentProject.RootDir)
-			}
-		}
+	if rootDir := config.CurrentProjectRoot(); rootDir != "" {
+		allowedDirs = append(allowedDirs, rootDir)
 	}
 
 	// Check if the path is within one of the allowed directories
@@ -73,7 +66,7 @@ func (t *ReadFileContentsTool) Name() string {
 }
 
 func (t *ReadFileContentsTool) Description() string {
-	return "Read the contents of a file"
+	return "Read the contents of a file, with its size in bytes and modification time (RFC3339)"
 }
 
 func (t *ReadFileContentsTool) Schema() map[string]interface{} {
@@ -86,7 +79,7 @@ func (t *ReadFileContentsTool) Schema() map[string]interface{} {
 			},
 			"maxBytes": map[string]interface{}{
 				"type": "number",
-				"description": "Maximum number of bytes to read (optional)",
+				"description": "Maximum number of bytes to read (optional, defaults to 262144)",
 			},
 		},
 		"required": []string{"path"},
@@ -110,7 +103,7 @@ func (t *ReadFileContentsTool) Execute(args map[string]interface{}) ToolResult {
 		}
 	}
 
-	var maxBytes int64 = -1 // read all by default
+	maxBytes := DefaultReadMaxBytes
 	if mb, exists := args["maxBytes"]; exists {
 		switch v := mb.(type) {
 		case float64:
@@ -125,6 +118,9 @@ func (t *ReadFileContentsTool) Execute(args map[string]interface{}) ToolResult {
 			}
 		}
 	}
+	if maxBytes <= 0 {
+		maxBytes = DefaultReadMaxBytes
+	}
 
 	file, err := os.Open(path)
 	if err != nil {
@@ -135,28 +131,35 @@ func (t *ReadFileContentsTool) Execute(args map[string]interface{}) ToolResult {
 	}
 	defer file.Close()
 
-	var data []byte
-	if maxBytes > 0 {
-		data = make([]byte, maxBytes)
-		n, err := file.Read(data)
-		if err != nil && n == 0 {
-			return ToolResult{
-				Success: false,
-				Error:   err.Error(),
-			}
+	// Size and modification time let the agent tell which files are newest
+	info, err := file.Stat()
+	if err != nil {
+		return ToolResult{
+			Success: false,
+			Error:   err.Error(),
 		}
-		data = data[:n]
-	} else {
-		data, err = os.ReadFile(path)
-		if err != nil {
-			return ToolResult{
-				Success: false,
-				Error:   err.Error(),
-			}
+	}
+
+	// Read one extra byte so truncation is only reported when data was dropped
+	data, err := io.ReadAll(io.LimitReader(file, maxBytes+1))
+	if err != nil {
+		return ToolResult{
+			Success: false,
+			Error:   err.Error(),
 		}
 	}
+	truncated := int64(len(data)) > maxBytes
+	if truncated {
+		data = trimPartialRune(data[:maxBytes])
+	}
 
-	truncated := maxBytes > 0 && int64(len(data)) >= maxBytes
+	if isBinary(data) {
+		return ToolResult{
+			Success: false,
+			Error: fmt.Sprintf("File appears to be binary or not UTF-8 text (%s, %d bytes): %s. Only text files such as transcripts, Markdown, and Mermaid sources can be read.",
+				http.DetectContentType(data), info.Size(), path),
+		}
+	}
 
 	return ToolResult{
 		Success: true,
@@ -164,6 +167,27 @@ func (t *ReadFileContentsTool) Execute(args map[string]interface{}) ToolResult {
 			"path":      path,
 			"content":   string(data),
 			"truncated": truncated,
+			"size":      info.Size(),
+			"modTime":   info.ModTime().UTC().Format(time.RFC3339),
 		},
 	}
-}
\ No newline at end of file
+}
+
+// isBinary reports whether data contains NUL bytes or invalid UTF-8
+func isBinary(data []byte) bool {
+	return bytes.IndexByte(data, 0) >= 0 || !utf8.Valid(data)
+}
+
+// trimPartialRune drops an incomplete UTF-8 sequence left at the end of data
+// by cutting a read at maxBytes
+func trimPartialRune(data []byte) []byte {
+	for i := 1; i < utf8.UTFMax && i <= len(data); i++ {
+		if utf8.RuneStart(data[len(data)-i]) {
+			if !utf8.FullRune(data[len(data)-i:]) {
+				return data[:len(data)-i]
+			}
+			break
+		}
+	}
+	return data
+}
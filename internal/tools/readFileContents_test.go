@@ -5,6 +5,7 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestReadFileContentsTool_ValidatePath(t *testing.T) {
@@ -151,6 +152,21 @@ func TestReadFileContentsTool_Execute_ValidFile(t *testing.T) {
 	if !ok || path != testFile {
 		t.Errorf("Expected path '%s', got '%s'", testFile, path)
 	}
+
+	if size, ok := data["size"].(int64); !ok || size != int64(len(testContent)) {
+		t.Errorf("Expected size %d, got %v", len(testContent), data["size"])
+	}
+	modTime, ok := data["modTime"].(string)
+	if !ok {
+		t.Fatalf("Expected modTime to be a string, got %T", data["modTime"])
+	}
+	parsed, err := time.Parse(time.RFC3339, modTime)
+	if err != nil {
+		t.Fatalf("Expected an RFC3339 modTime, got %q: %v", modTime, err)
+	}
+	if info, err := os.Stat(testFile); err == nil && !parsed.Equal(info.ModTime().Truncate(time.Second)) {
+		t.Errorf("Expected modTime %s, got %s", info.ModTime(), modTime)
+	}
 }
 
 func TestReadFileContentsTool_Execute_InvalidPath(t *testing.T) {
@@ -269,3 +285,92 @@ func TestReadFileContentsTool_Execute_WithMaxBytes(t *testing.T) {
 		t.Errorf("Expected truncated to be true, got %v", data["truncated"])
 	}
 }
+
+func TestReadFileContentsTool_Execute_BinaryFile(t *testing.T) {
+	tool := &ReadFileContentsTool{}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		t.Fatalf("Failed to get home directory: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		content []byte
+	}{
+		{name: "null_bytes", content: []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")},
+		{name: "invalid_utf8", content: []byte("caf\xe9 au lait")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testFile := filepath.Join(homeDir, "mermaid-agent-documenter", "test_binary_"+tt.name)
+			if err := os.WriteFile(testFile, tt.content, 0644); err != nil {
+				t.Fatalf("Failed to create test file: %v", err)
+			}
+			defer os.Remove(testFile)
+
+			result := tool.Execute(map[string]interface{}{"path": testFile})
+			if result.Success {
+				t.Fatalf("Expected binary file to be refused, got content %v", result.Data)
+			}
+			if !strings.Contains(result.Error, "binary") {
+				t.Errorf("Expected error about binary content, got: %s", result.Error)
+			}
+		})
+	}
+}
+
+func TestReadFileContentsTool_Execute_DefaultMaxBytes(t *testing.T) {
+	tool := &ReadFileContentsTool{}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		t.Fatalf("Failed to get home directory: %v", err)
+	}
+
+	testFile := filepath.Join(homeDir, "mermaid-agent-documenter", "test_default_max.txt")
+	if err := os.WriteFile(testFile, []byte(strings.Repeat("a", int(DefaultReadMaxBytes)+10)), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	defer os.Remove(testFile)
+
+	result := tool.Execute(map[string]interface{}{"path": testFile})
+	if !result.Success {
+		t.Fatalf("Expected success, got error: %s", result.Error)
+	}
+
+	data := result.Data.(map[string]interface{})
+	if got := len(data["content"].(string)); int64(got) != DefaultReadMaxBytes {
+		t.Errorf("Expected content capped at %d bytes, got %d", DefaultReadMaxBytes, got)
+	}
+	if data["truncated"] != true {
+		t.Errorf("Expected truncated to be true, got %v", data["truncated"])
+	}
+}
+
+func TestReadFileContentsTool_Execute_TruncatesOnRuneBoundary(t *testing.T) {
+	tool := &ReadFileContentsTool{}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		t.Fatalf("Failed to get home directory: %v", err)
+	}
+
+	testFile := filepath.Join(homeDir, "mermaid-agent-documenter", "test_rune_boundary.txt")
+	if err := os.WriteFile(testFile, []byte("abc€def"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	defer os.Remove(testFile)
+
+	// "€" is three bytes, so a 5-byte cut lands inside it
+	result := tool.Execute(map[string]interface{}{"path": testFile, "maxBytes": 5})
+	if !result.Success {
+		t.Fatalf("Expected a truncated multi-byte character not to be treated as binary, got: %s", result.Error)
+	}
+
+	data := result.Data.(map[string]interface{})
+	if data["content"] != "abc" {
+		t.Errorf("Expected content 'abc', got %q", data["content"])
+	}
+}
@@ -1,8 +1,11 @@
 package tools
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"sort"
+	"strings"
 )
 
 type ToolResult struct {
@@ -18,8 +21,23 @@ type Tool interface {
 	Schema() map[string]interface{}
 }
 
+// ContextTool is a Tool that stops when its context is done, such as when the
+// run times out. ExecuteToolContext passes its context to these tools.
+type ContextTool interface {
+	Tool
+	ExecuteContext(ctx context.Context, args map[string]interface{}) ToolResult
+}
+
 var toolRegistry = map[string]Tool{}
 
+// disabledTools are refused by ExecuteTool (see SetDisabledTools)
+var disabledTools = map[string]bool{}
+
+// requiredTools cannot be disabled, with the reason why
+var requiredTools = map[string]string{
+	"writeFileContents": "every run writes its documentation with it",
+}
+
 func RegisterTool(tool Tool) {
 	toolRegistry[tool.Name()] = tool
 }
@@ -36,14 +54,61 @@ func init() {
 	RegisterTool(&ReadDirectoriesTool{})
 	RegisterTool(&ReadFileContentsTool{})
 	RegisterTool(&WriteFileContentsTool{})
+	RegisterTool(&WriteMermaidDiagramTool{})
 	RegisterTool(&GetUserInputTool{})
 	RegisterTool(&FetchMermaidDocumentationTool{})
 	RegisterTool(&LogEventTool{})
 	RegisterTool(&GenerateMermaidImageTool{})
 }
 
+// SetDisabledTools makes ExecuteTool refuse the named tools, replacing any
+// earlier list. Names must pass ValidateDisabledTools.
+func SetDisabledTools(names []string) {
+	disabledTools = map[string]bool{}
+	for _, name := range names {
+		disabledTools[name] = true
+	}
+}
+
+// IsDisabled reports whether ExecuteTool refuses the named tool
+func IsDisabled(name string) bool {
+	return disabledTools[name]
+}
+
+// ValidateDisabledTools rejects names that are not registered tools (external
+// tools must be registered first) and tools a run cannot do without
+func ValidateDisabledTools(names []string) error {
+	for _, name := range names {
+		if reason, ok := requiredTools[name]; ok {
+			return fmt.Errorf("%s cannot be disabled: %s", name, reason)
+		}
+		if GetTool(name) == nil {
+			known := make([]string, 0, len(toolRegistry))
+			for registered := range toolRegistry {
+				known = append(known, registered)
+			}
+			sort.Strings(known)
+			return fmt.Errorf("unknown tool '%s' (available: %s)", name, strings.Join(known, ", "))
+		}
+	}
+	return nil
+}
+
 // ExecuteTool executes a tool by name with JSON arguments
 func ExecuteTool(toolName string, argsJSON string) ToolResult {
+	return ExecuteToolContext(context.Background(), toolName, argsJSON)
+}
+
+// ExecuteToolContext executes a tool by name with JSON arguments, passing ctx
+// to tools that implement ContextTool
+func ExecuteToolContext(ctx context.Context, toolName string, argsJSON string) ToolResult {
+	if IsDisabled(toolName) {
+		return ToolResult{
+			Success: false,
+			Error:   fmt.Sprintf("Tool '%s' is disabled for this run. Continue without it", toolName),
+		}
+	}
+
 	tool := GetTool(toolName)
 	if tool == nil {
 		return ToolResult{
@@ -60,5 +125,8 @@ func ExecuteTool(toolName string, argsJSON string) ToolResult {
 		}
 	}
 
+	if contextTool, ok := tool.(ContextTool); ok {
+		return contextTool.ExecuteContext(ctx, args)
+	}
 	return tool.Execute(args)
 }
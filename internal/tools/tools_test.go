@@ -0,0 +1,36 @@
+package tools
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExecuteTool_DisabledTool(t *testing.T) {
+	SetDisabledTools([]string{"fetchMermaidDocumentation"})
+	defer SetDisabledTools(nil)
+
+	result := ExecuteTool("fetchMermaidDocumentation", `{"topic":"erDiagram"}`)
+	if result.Success || !strings.Contains(result.Error, "disabled") {
+		t.Errorf("Expected a disabled tool result, got %+v", result)
+	}
+	if !IsDisabled("fetchMermaidDocumentation") || IsDisabled("readDirectories") {
+		t.Error("Expected only the named tool to be disabled")
+	}
+
+	SetDisabledTools(nil)
+	if IsDisabled("fetchMermaidDocumentation") {
+		t.Error("Expected SetDisabledTools to replace the earlier list")
+	}
+}
+
+func TestValidateDisabledTools(t *testing.T) {
+	if err := ValidateDisabledTools([]string{"fetchMermaidDocumentation", "getUserInput"}); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if err := ValidateDisabledTools([]string{"fetchDocs"}); err == nil || !strings.Contains(err.Error(), "unknown tool") {
+		t.Errorf("Expected an unknown tool error, got %v", err)
+	}
+	if err := ValidateDisabledTools([]string{"writeFileContents"}); err == nil || !strings.Contains(err.Error(), "cannot be disabled") {
+		t.Errorf("Expected writeFileContents to be required, got %v", err)
+	}
+}
@@ -1,11 +1,13 @@
 package tools
 
 import (
-	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
+
+	"github.com/landanqrew/mermaid-agent-documenter/internal/config"
+	"github.com/landanqrew/mermaid-agent-documenter/internal/console"
 )
 
 type WriteFileContentsTool struct{}
@@ -18,31 +20,14 @@ func (t *WriteFileContentsTool) validatePath(path string) error {
 		return fmt.Errorf("failed to get absolute path: %w", err)
 	}
 
-	// Get home directory
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return fmt.Errorf("failed to get home directory: %w", err)
-	}
-
 	// Allowed base directories
 	allowedDirs := []string{
-		filepath.Join(homeDir, "mermaid-agent-documenter"), // ~/mermaid-agent-documenter/
+		config.Dir(), // ~/mermaid-agent-documenter/
 	}
 
 	// Add current project directory if available
-	configPath := filepath.Join(homeDir, "mermaid-agent-documenter", "config.json")
-	if _, err := os.Stat(configPath); err == nil {
-		data, err := os.ReadFile(configPath)
-		if err == nil {
-			var cfg struct {
-				CurrentProject *struct {
-					RootDir string `json:"rootDir"`
-				} `json:"currentProject,omitempty"`
-			}
-			if err := json.Unmarshal(data, &cfg); err == nil && cfg.CurrentProject != nil {
-				allowedDirs = append(allowedDirs, cfg.CurrentProject.RootDir)
-			}
-		}
+	if rootDir := config.CurrentProjectRoot(); rootDir != "" {
+		allowedDirs = append(allowedDirs, rootDir)
 	}
 
 	// Check if the path is within one of the allowed directories
@@ -96,6 +81,10 @@ func (t *WriteFileContentsTool) Schema() map[string]interface{} {
 				"enum":        []string{"explicit", "allow"},
 				"description": "Overwrite behavior: 'explicit' requires confirmation, 'allow' allows overwriting",
 			},
+			"append": map[string]interface{}{
+				"type":        "boolean",
+				"description": "Append content to the end of the file (creating it if needed) instead of replacing it, to build a large document across several calls",
+			},
 		},
 		"required": []string{"path", "content"},
 	}
@@ -127,7 +116,7 @@ func (t *WriteFileContentsTool) Execute(args map[string]interface{}) ToolResult
 	}
 
 	// Debug: print what we're trying to write
-	fmt.Printf("📝 Writing to: %s (%d chars)\n", path, len(content))
+	console.Printf("📝 Writing to: %s (%d chars)\n", path, len(content))
 
 	createDirs := true
 	if cd, exists := args["createDirs"]; exists {
@@ -136,6 +125,8 @@ func (t *WriteFileContentsTool) Execute(args map[string]interface{}) ToolResult
 		}
 	}
 
+	appendContent, _ := args["append"].(bool)
+
 	overwrite := "allow" // Default to allow for agent workflow
 	if ow, exists := args["overwrite"]; exists {
 		if owStr, ok := ow.(string); ok && (owStr == "explicit" || owStr == "allow") {
@@ -166,6 +157,10 @@ func (t *WriteFileContentsTool) Execute(args map[string]interface{}) ToolResult
 		}
 	}
 
+	if appendContent {
+		return t.appendFile(path, content)
+	}
+
 	// Check if file exists and handle overwrite policy
 	if _, err := os.Stat(path); err == nil {
 		if overwrite == "explicit" {
@@ -192,3 +187,38 @@ func (t *WriteFileContentsTool) Execute(args map[string]interface{}) ToolResult
 		},
 	}
 }
+
+// appendFile adds content to the end of path, creating the file if needed
+func (t *WriteFileContentsTool) appendFile(path, content string) ToolResult {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return ToolResult{
+			Success: false,
+			Error:   "Failed to open file for appending: " + err.Error(),
+		}
+	}
+	defer file.Close()
+
+	if _, err := file.WriteString(content); err != nil {
+		return ToolResult{
+			Success: false,
+			Error:   "Failed to append to file: " + err.Error(),
+		}
+	}
+	info, err := file.Stat()
+	if err != nil {
+		return ToolResult{
+			Success: false,
+			Error:   "Failed to read file size: " + err.Error(),
+		}
+	}
+	return ToolResult{
+		Success: true,
+		Data: map[string]interface{}{
+			"path":         path,
+			"bytesWritten": len(content),
+			"appended":     true,
+			"size":         info.Size(),
+		},
+	}
+}
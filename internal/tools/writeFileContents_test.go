@@ -209,3 +209,40 @@ func TestWriteFileContentsTool_Execute_MissingContent(t *testing.T) {
 		t.Errorf("Expected error about missing content argument, got: %s", result.Error)
 	}
 }
+
+func TestWriteFileContentsTool_Execute_Append(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	tool := &WriteFileContentsTool{}
+	testFile := filepath.Join(home, "mermaid-agent-documenter", "out", "guide.md")
+
+	for _, section := range []string{"# Guide\n", "## Part 1\n", "## Part 2\n"} {
+		result := tool.Execute(map[string]interface{}{"path": testFile, "content": section, "append": true})
+		if !result.Success {
+			t.Fatalf("Append failed: %s", result.Error)
+		}
+	}
+
+	content, err := os.ReadFile(testFile)
+	if err != nil {
+		t.Fatalf("Failed to read appended file: %v", err)
+	}
+	if string(content) != "# Guide\n## Part 1\n## Part 2\n" {
+		t.Errorf("Expected the sections in order, got %q", content)
+	}
+
+	// A normal write still replaces the file
+	result := tool.Execute(map[string]interface{}{"path": testFile, "content": "replaced"})
+	if !result.Success {
+		t.Fatalf("Write failed: %s", result.Error)
+	}
+	if content, _ := os.ReadFile(testFile); string(content) != "replaced" {
+		t.Errorf("Expected the file to be replaced, got %q", content)
+	}
+
+	// Appending is sandboxed like writing
+	outside := filepath.Join(t.TempDir(), "outside.md")
+	if result := tool.Execute(map[string]interface{}{"path": outside, "content": "x", "append": true}); result.Success {
+		t.Error("Expected appending outside the allowed directories to fail")
+	}
+}
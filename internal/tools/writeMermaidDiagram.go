@@ -0,0 +1,142 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/landanqrew/mermaid-agent-documenter/internal/console"
+)
+
+type WriteMermaidDiagramTool struct{}
+
+func (t *WriteMermaidDiagramTool) Name() string {
+	return "writeMermaidDiagram"
+}
+
+func (t *WriteMermaidDiagramTool) Description() string {
+	return "Write a single raw Mermaid diagram to a standalone .mmd file (no Markdown fences). Use this instead of writeFileContents when a diagram should be rendered on its own"
+}
+
+func (t *WriteMermaidDiagramTool) Schema() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"path": map[string]interface{}{
+				"type":        "string",
+				"description": "Path to the .mmd file to write. The .mmd extension is added if missing. Naming convention (in lower snake case) is <transcript-name>_<diagram_type>.mmd",
+			},
+			"diagram": map[string]interface{}{
+				"type":        "string",
+				"description": "Raw Mermaid diagram source, e.g. 'sequenceDiagram\\n  A->>B: hello'. Do not wrap it in ```mermaid fences",
+			},
+			"createDirs": map[string]interface{}{
+				"type":        "boolean",
+				"description": "Whether to create parent directories if they don't exist",
+			},
+		},
+		"required": []string{"path", "diagram"},
+	}
+}
+
+func (t *WriteMermaidDiagramTool) Execute(args map[string]interface{}) ToolResult {
+	path, ok := args["path"].(string)
+	if !ok || path == "" {
+		return ToolResult{
+			Success: false,
+			Error:   "Missing or invalid 'path' argument",
+		}
+	}
+
+	diagram, ok := args["diagram"].(string)
+	if !ok {
+		return ToolResult{
+			Success: false,
+			Error:   "Missing or invalid 'diagram' argument",
+		}
+	}
+
+	diagram = normalizeMermaidDiagram(diagram)
+	if diagram == "" {
+		return ToolResult{
+			Success: false,
+			Error:   "Diagram is empty. Provide raw Mermaid source such as 'graph TD\\n  A --> B'",
+		}
+	}
+
+	if filepath.Ext(path) != ".mmd" {
+		path = strings.TrimSuffix(path, filepath.Ext(path)) + ".mmd"
+	}
+
+	// Expand ~ to home directory
+	if strings.HasPrefix(path, "~") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ToolResult{
+				Success: false,
+				Error:   "Failed to get home directory: " + err.Error(),
+			}
+		}
+		path = strings.Replace(path, "~", home, 1)
+	}
+
+	// Writes share the same directory restrictions as writeFileContents
+	if err := (&WriteFileContentsTool{}).validatePath(path); err != nil {
+		return ToolResult{
+			Success: false,
+			Error:   err.Error(),
+		}
+	}
+
+	createDirs := true
+	if cd, exists := args["createDirs"]; exists {
+		if cdBool, ok := cd.(bool); ok {
+			createDirs = cdBool
+		}
+	}
+
+	if createDirs {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return ToolResult{
+				Success: false,
+				Error:   "Failed to create directories: " + err.Error(),
+			}
+		}
+	}
+
+	console.Printf("📐 Writing diagram to: %s (%d chars)\n", path, len(diagram))
+
+	if err := os.WriteFile(path, []byte(diagram), 0644); err != nil {
+		return ToolResult{
+			Success: false,
+			Error:   "Failed to write file: " + err.Error(),
+		}
+	}
+
+	return ToolResult{
+		Success: true,
+		Data: map[string]interface{}{
+			"path":         path,
+			"bytesWritten": len(diagram),
+		},
+	}
+}
+
+// normalizeMermaidDiagram strips surrounding ```mermaid fences the model may
+// have added and ensures the source ends with a newline
+func normalizeMermaidDiagram(diagram string) string {
+	diagram = strings.TrimSpace(diagram)
+	if strings.HasPrefix(diagram, "```") {
+		if newline := strings.Index(diagram, "\n"); newline != -1 {
+			diagram = diagram[newline+1:]
+		} else {
+			diagram = ""
+		}
+		diagram = strings.TrimSuffix(strings.TrimSpace(diagram), "```")
+		diagram = strings.TrimSpace(diagram)
+	}
+	if diagram == "" {
+		return ""
+	}
+	return diagram + "\n"
+}
@@ -0,0 +1,51 @@
+package tools
+
+import "testing"
+
+func TestNormalizeMermaidDiagram(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "raw_diagram",
+			input:    "graph TD\n  A --> B",
+			expected: "graph TD\n  A --> B\n",
+		},
+		{
+			name:     "fenced_diagram",
+			input:    "```mermaid\nsequenceDiagram\n  A->>B: hi\n```\n",
+			expected: "sequenceDiagram\n  A->>B: hi\n",
+		},
+		{
+			name:     "empty_fence",
+			input:    "```mermaid\n```",
+			expected: "",
+		},
+		{
+			name:     "whitespace_only",
+			input:    "   \n",
+			expected: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := normalizeMermaidDiagram(tt.input); result != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestWriteMermaidDiagramTool_MissingArgs(t *testing.T) {
+	tool := &WriteMermaidDiagramTool{}
+
+	if result := tool.Execute(map[string]interface{}{"diagram": "graph TD"}); result.Success {
+		t.Error("Expected failure without path")
+	}
+	if result := tool.Execute(map[string]interface{}{"path": "flow.mmd", "diagram": "```mermaid\n```"}); result.Success {
+		t.Error("Expected failure for empty diagram")
+	}
+}
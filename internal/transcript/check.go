@@ -0,0 +1,29 @@
+package transcript
+
+import (
+	"errors"
+	"strings"
+	"unicode/utf8"
+)
+
+// ErrEmpty reports a transcript with nothing but whitespace in it
+var ErrEmpty = errors.New("transcript is empty")
+
+// DefaultMinChars is the length below which a transcript is reported as
+// suspiciously short when no minimum is configured
+const DefaultMinChars = 200
+
+// Check returns ErrEmpty for an empty or whitespace-only transcript, and
+// otherwise reports whether it has fewer than minChars characters (ignoring
+// surrounding whitespace). A minChars of 0 uses DefaultMinChars; negative
+// disables the length check.
+func Check(text string, minChars int) (short bool, err error) {
+	trimmed := strings.TrimSpace(text)
+	if trimmed == "" {
+		return false, ErrEmpty
+	}
+	if minChars == 0 {
+		minChars = DefaultMinChars
+	}
+	return minChars > 0 && utf8.RuneCountInString(trimmed) < minChars, nil
+}
@@ -0,0 +1,37 @@
+package transcript
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name      string
+		text      string
+		minChars  int
+		wantShort bool
+		wantErr   error
+	}{
+		{"empty", "", 0, false, ErrEmpty},
+		{"whitespace only", " \n\t\r\n  ", 0, false, ErrEmpty},
+		{"short with default", "User: hi", 0, true, nil},
+		{"long enough", strings.Repeat("a", DefaultMinChars), 0, false, nil},
+		{"surrounding whitespace is not counted", "\n\n" + strings.Repeat("a", 9) + "\n\n", 10, true, nil},
+		{"characters not bytes", strings.Repeat("é", 10), 10, false, nil},
+		{"check disabled", "hi", -1, false, nil},
+		{"disabled still rejects empty", "   ", -1, false, ErrEmpty},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			short, err := Check(tt.text, tt.minChars)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Check() error = %v, want %v", err, tt.wantErr)
+			}
+			if short != tt.wantShort {
+				t.Errorf("Check() short = %v, want %v", short, tt.wantShort)
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package transcript
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// Chunk splits text into segments of at most size bytes, with each segment
+// repeating the last overlap bytes of the previous one so context carries
+// across boundaries. Splits prefer line breaks when one is nearby and never
+// fall inside a UTF-8 character.
+func Chunk(text string, size, overlap int) []string {
+	if size <= 0 || len(text) <= size {
+		return []string{text}
+	}
+	if overlap < 0 || overlap >= size {
+		overlap = 0
+	}
+
+	var chunks []string
+	start := 0
+	for start < len(text) {
+		end := start + size
+		if end >= len(text) {
+			chunks = append(chunks, text[start:])
+			break
+		}
+
+		// Prefer to break at a newline in the second half of the window
+		if idx := strings.LastIndex(text[start:end], "\n"); idx > size/2 {
+			end = start + idx + 1
+		}
+		end = runeBoundary(text, start, end)
+
+		chunks = append(chunks, text[start:end])
+
+		next := runeBoundary(text, start, end-overlap)
+		if next <= start {
+			next = end
+		}
+		start = next
+	}
+
+	return chunks
+}
+
+// runeBoundary moves i back to the start of the UTF-8 character it falls in.
+// When that would reach start (a window smaller than one character), it moves
+// forward past the character instead so every segment makes progress.
+func runeBoundary(text string, start, i int) int {
+	if i <= start || i >= len(text) {
+		return i
+	}
+	j := i
+	for j > start && !utf8.RuneStart(text[j]) {
+		j--
+	}
+	if j > start {
+		return j
+	}
+	for i < len(text) && !utf8.RuneStart(text[i]) {
+		i++
+	}
+	return i
+}
@@ -0,0 +1,60 @@
+package transcript
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestChunk(t *testing.T) {
+	text := strings.Repeat("line of transcript text\n", 20)
+
+	chunks := Chunk(text, 100, 20)
+	if len(chunks) < 2 {
+		t.Fatalf("Expected multiple chunks, got %d", len(chunks))
+	}
+
+	for i, chunk := range chunks {
+		if len(chunk) > 100 {
+			t.Errorf("Chunk %d exceeds size: %d", i, len(chunk))
+		}
+	}
+
+	if !strings.HasSuffix(chunks[len(chunks)-1], "text\n") {
+		t.Errorf("Expected last chunk to end with the transcript tail, got %q", chunks[len(chunks)-1])
+	}
+
+	if single := Chunk("short", 100, 20); len(single) != 1 || single[0] != "short" {
+		t.Errorf("Expected short text to stay in one chunk, got %v", single)
+	}
+}
+
+func TestChunk_NonASCII(t *testing.T) {
+	text := strings.Repeat("Zoë prüft das Rollout-Datum 日本語 ✅ ", 30)
+
+	for _, size := range []int{37, 50, 64} {
+		chunks := Chunk(text, size, 7)
+		if len(chunks) < 2 {
+			t.Fatalf("Expected multiple chunks for size %d, got %d", size, len(chunks))
+		}
+		for i, chunk := range chunks {
+			if !utf8.ValidString(chunk) {
+				t.Errorf("Size %d: chunk %d splits a character: %q", size, i, chunk)
+			}
+			if len(chunk) > size {
+				t.Errorf("Size %d: chunk %d exceeds size: %d", size, i, len(chunk))
+			}
+			if !strings.Contains(text, chunk) {
+				t.Errorf("Size %d: chunk %d is not part of the text: %q", size, i, chunk)
+			}
+		}
+		if !strings.HasPrefix(text, chunks[0]) || !strings.HasSuffix(text, chunks[len(chunks)-1]) {
+			t.Errorf("Size %d: expected the chunks to cover the text from start to end", size)
+		}
+	}
+
+	// A window smaller than one character still makes progress
+	if chunks := Chunk("日本語", 2, 0); strings.Join(chunks, "") != "日本語" {
+		t.Errorf("Expected tiny windows to keep whole characters, got %q", chunks)
+	}
+}
@@ -0,0 +1,101 @@
+package transcript
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Default patterns used when the config does not override them
+const (
+	DefaultTimestampPattern = `^\[?\d{1,2}:\d{2}(:\d{2})?\]?\s*`
+	DefaultSpeakerPattern   = `^[A-Za-z][\w .'-]{0,40}:\s+`
+)
+
+// CleanOptions controls which chat markup is stripped from a transcript
+type CleanOptions struct {
+	StripTimestamps  bool
+	TimestampPattern string
+	StripSpeakers    bool
+	SpeakerPattern   string
+}
+
+// CleanResult reports what Clean did to a transcript
+type CleanResult struct {
+	Text          string
+	OriginalBytes int
+	CleanedBytes  int
+}
+
+// BytesRemoved returns how many bytes cleaning removed
+func (r CleanResult) BytesRemoved() int {
+	return r.OriginalBytes - r.CleanedBytes
+}
+
+var (
+	quoteMarker      = regexp.MustCompile(`^(>\s?)+`)
+	inlineWhitespace = regexp.MustCompile(`[ \t]+`)
+)
+
+// Clean normalizes whitespace, strips quote markers and optional timestamp and
+// speaker prefixes, and collapses runs of blank lines.
+func Clean(text string, opts CleanOptions) (CleanResult, error) {
+	var timestampRe, speakerRe *regexp.Regexp
+	var err error
+
+	if opts.StripTimestamps {
+		pattern := opts.TimestampPattern
+		if pattern == "" {
+			pattern = DefaultTimestampPattern
+		}
+		if timestampRe, err = regexp.Compile(pattern); err != nil {
+			return CleanResult{}, fmt.Errorf("invalid timestamp pattern: %w", err)
+		}
+	}
+
+	if opts.StripSpeakers {
+		pattern := opts.SpeakerPattern
+		if pattern == "" {
+			pattern = DefaultSpeakerPattern
+		}
+		if speakerRe, err = regexp.Compile(pattern); err != nil {
+			return CleanResult{}, fmt.Errorf("invalid speaker pattern: %w", err)
+		}
+	}
+
+	originalBytes := len(text)
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+
+	var cleaned []string
+	blankRun := false
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		line = quoteMarker.ReplaceAllString(line, "")
+		if timestampRe != nil {
+			line = timestampRe.ReplaceAllString(line, "")
+		}
+		if speakerRe != nil {
+			line = speakerRe.ReplaceAllString(line, "")
+		}
+		line = strings.TrimSpace(inlineWhitespace.ReplaceAllString(line, " "))
+
+		// Collapse consecutive blank lines into one
+		if line == "" {
+			if blankRun || len(cleaned) == 0 {
+				continue
+			}
+			blankRun = true
+		} else {
+			blankRun = false
+		}
+		cleaned = append(cleaned, line)
+	}
+
+	result := strings.TrimSpace(strings.Join(cleaned, "\n"))
+
+	return CleanResult{
+		Text:          result,
+		OriginalBytes: originalBytes,
+		CleanedBytes:  len(result),
+	}, nil
+}
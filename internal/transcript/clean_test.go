@@ -0,0 +1,54 @@
+package transcript
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestClean(t *testing.T) {
+	input := "[10:02] Alice: The user opens the app.\r\n\r\n\r\n> [10:03] Bob:   It calls   the login API.\n\n\n"
+
+	tests := []struct {
+		name     string
+		opts     CleanOptions
+		expected string
+	}{
+		{
+			name:     "whitespace_and_quotes_only",
+			opts:     CleanOptions{},
+			expected: "[10:02] Alice: The user opens the app.\n\n[10:03] Bob: It calls the login API.",
+		},
+		{
+			name:     "strip_timestamps",
+			opts:     CleanOptions{StripTimestamps: true},
+			expected: "Alice: The user opens the app.\n\nBob: It calls the login API.",
+		},
+		{
+			name:     "strip_timestamps_and_speakers",
+			opts:     CleanOptions{StripTimestamps: true, StripSpeakers: true},
+			expected: "The user opens the app.\n\nIt calls the login API.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Clean(input, tt.opts)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if result.Text != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, result.Text)
+			}
+			if result.BytesRemoved() <= 0 {
+				t.Errorf("Expected bytes to be removed, got %d", result.BytesRemoved())
+			}
+		})
+	}
+}
+
+func TestClean_InvalidPattern(t *testing.T) {
+	_, err := Clean("text", CleanOptions{StripTimestamps: true, TimestampPattern: "("})
+	if err == nil || !strings.Contains(err.Error(), "timestamp pattern") {
+		t.Errorf("Expected invalid timestamp pattern error, got: %v", err)
+	}
+}
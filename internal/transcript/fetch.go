@@ -0,0 +1,110 @@
+package transcript
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+)
+
+// DefaultMaxDownloadBytes caps a transcript fetched over HTTP when no limit is configured
+const DefaultMaxDownloadBytes = 10 << 20
+
+// fetchTimeout bounds the whole download of a transcript
+const fetchTimeout = 60 * time.Second
+
+// IsURL reports whether a transcript argument is an http:// or https:// URL
+func IsURL(arg string) bool {
+	lower := strings.ToLower(arg)
+	return strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://")
+}
+
+// URLFileName returns the file name at the end of a transcript URL, without
+// its query, so the format can be detected from its extension
+func URLFileName(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Path == "" || parsed.Path == "/" {
+		return "transcript.txt"
+	}
+	return path.Base(parsed.Path)
+}
+
+// allowedContentType reports whether a response's Content-Type can hold a
+// transcript: plain text, subtitles, or JSON. HTML is refused because it is
+// usually a login or error page rather than the transcript.
+func allowedContentType(contentType string) bool {
+	if contentType == "" {
+		return true
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	switch {
+	case mediaType == "text/html":
+		return false
+	case strings.HasPrefix(mediaType, "text/"):
+		return true
+	}
+	switch mediaType {
+	case "application/json", "application/x-subrip", "application/octet-stream":
+		return true
+	}
+	return false
+}
+
+// Fetch downloads a transcript over HTTP(S), sending token as a bearer token
+// when set. Responses other than 200, with a Content-Type that is not text,
+// subtitles, or JSON, or larger than maxBytes (0 uses DefaultMaxDownloadBytes)
+// are rejected.
+func Fetch(ctx context.Context, rawURL, token string, maxBytes int64) ([]byte, error) {
+	if maxBytes <= 0 {
+		maxBytes = DefaultMaxDownloadBytes
+	}
+	ctx, cancel := context.WithTimeout(ctx, fetchTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid transcript URL: %w", err)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	req.Header.Set("Accept", "text/plain, text/vtt, application/x-subrip, application/json;q=0.9, */*;q=0.1")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download transcript: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		hint := ""
+		if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+			hint = " (check transcript.bearerToken)"
+		}
+		return nil, fmt.Errorf("failed to download transcript: %s%s", resp.Status, hint)
+	}
+	contentType := resp.Header.Get("Content-Type")
+	if !allowedContentType(contentType) {
+		return nil, fmt.Errorf("transcript URL returned %s, expected plain text, subtitles, or JSON", contentType)
+	}
+	if resp.ContentLength > maxBytes {
+		return nil, fmt.Errorf("transcript at %s is %d bytes, above the %d byte download limit", rawURL, resp.ContentLength, maxBytes)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to download transcript: %w", err)
+	}
+	if int64(len(data)) > maxBytes {
+		return nil, fmt.Errorf("transcript at %s is larger than the %d byte download limit", rawURL, maxBytes)
+	}
+	return data, nil
+}
@@ -0,0 +1,66 @@
+package transcript
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestFetch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/private.txt":
+			if r.Header.Get("Authorization") != "Bearer secret" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+			w.Write([]byte("User logs in."))
+		case "/login.html":
+			w.Header().Set("Content-Type", "text/html")
+			w.Write([]byte("<html>Sign in</html>"))
+		case "/large.txt":
+			w.Header().Set("Content-Type", "text/plain")
+			w.Write([]byte(strings.Repeat("a", 100)))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	ctx := context.Background()
+
+	data, err := Fetch(ctx, server.URL+"/private.txt", "secret", 0)
+	if err != nil || string(data) != "User logs in." {
+		t.Errorf("Expected the transcript, got %q (%v)", data, err)
+	}
+	if _, err := Fetch(ctx, server.URL+"/private.txt", "", 0); err == nil || !strings.Contains(err.Error(), "bearerToken") {
+		t.Errorf("Expected an unauthorized error, got %v", err)
+	}
+	if _, err := Fetch(ctx, server.URL+"/login.html", "", 0); err == nil || !strings.Contains(err.Error(), "text/html") {
+		t.Errorf("Expected HTML to be rejected, got %v", err)
+	}
+	if _, err := Fetch(ctx, server.URL+"/large.txt", "", 50); err == nil || !strings.Contains(err.Error(), "download limit") {
+		t.Errorf("Expected the size limit to apply, got %v", err)
+	}
+	if _, err := Fetch(ctx, server.URL+"/missing.txt", "", 0); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("Expected a not found error, got %v", err)
+	}
+}
+
+func TestURLFileName(t *testing.T) {
+	tests := map[string]string{
+		"https://example.com/calls/standup.vtt?token=abc": "standup.vtt",
+		"http://example.com/":                             "transcript.txt",
+		"https://example.com/api/transcripts/42":          "42",
+	}
+	for input, want := range tests {
+		if got := URLFileName(input); got != want {
+			t.Errorf("URLFileName(%q) = %q, want %q", input, got, want)
+		}
+	}
+	if !IsURL("HTTPS://example.com/t.txt") || IsURL("transcripts/t.txt") {
+		t.Error("Expected only http(s) URLs to be detected")
+	}
+}
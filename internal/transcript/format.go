@@ -0,0 +1,190 @@
+package transcript
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Format identifies how a transcript file is encoded
+type Format string
+
+const (
+	FormatText        Format = "text"
+	FormatSRT         Format = "srt"
+	FormatVTT         Format = "vtt"
+	FormatWhisperJSON Format = "whisper-json"
+)
+
+var (
+	cueTimingPattern = regexp.MustCompile(`^\s*(\d{1,2}:)?\d{2}:\d{2}[.,]\d{3}\s+-->\s+(\d{1,2}:)?\d{2}:\d{2}[.,]\d{3}`)
+	srtStartPattern  = regexp.MustCompile(`^\s*\d+\s*\r?\n\s*\d{1,2}:\d{2}:\d{2},\d{3}\s+-->`)
+	cueIndexPattern  = regexp.MustCompile(`^\d+$`)
+	voiceTagPattern  = regexp.MustCompile(`^<v(?:\.[^ >]*)?\s+([^>]+)>`)
+	markupTagPattern = regexp.MustCompile(`<[^>]*>`)
+)
+
+// whisperTranscript is the JSON written by Whisper (and WhisperX, which adds speakers)
+type whisperTranscript struct {
+	Text     *string `json:"text"`
+	Segments []struct {
+		Text    string `json:"text"`
+		Speaker string `json:"speaker"`
+	} `json:"segments"`
+}
+
+// DetectFormat identifies a transcript's format from its extension, falling
+// back to its content for unknown extensions. .txt and .md are always text.
+func DetectFormat(path string, data []byte) Format {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".txt", ".md":
+		return FormatText
+	case ".srt":
+		return FormatSRT
+	case ".vtt":
+		return FormatVTT
+	case ".json":
+		if isWhisperJSON(data) {
+			return FormatWhisperJSON
+		}
+		return FormatText
+	}
+
+	content := strings.TrimPrefix(string(data), "\ufeff")
+	switch {
+	case strings.HasPrefix(strings.TrimSpace(content), "WEBVTT"):
+		return FormatVTT
+	case srtStartPattern.MatchString(content):
+		return FormatSRT
+	case isWhisperJSON(data):
+		return FormatWhisperJSON
+	}
+	return FormatText
+}
+
+// isWhisperJSON reports whether data is a JSON object with Whisper's text or segments
+func isWhisperJSON(data []byte) bool {
+	var parsed whisperTranscript
+	if err := json.Unmarshal(data, &parsed); err != nil {
+		return false
+	}
+	return parsed.Text != nil || len(parsed.Segments) > 0
+}
+
+// Normalize converts subtitle and Whisper JSON transcripts into plain dialogue
+// text, one line per cue or segment, with "Speaker: " prefixes where known.
+// Plain text is returned unchanged.
+func Normalize(path string, data []byte) (string, Format, error) {
+	format := DetectFormat(path, data)
+	switch format {
+	case FormatSRT, FormatVTT:
+		return subtitleText(string(data)), format, nil
+	case FormatWhisperJSON:
+		text, err := whisperText(data)
+		return text, format, err
+	}
+	return string(data), FormatText, nil
+}
+
+// subtitleText extracts the spoken text from SRT or WebVTT cues, dropping
+// indices, timings, headers, NOTE/STYLE/REGION blocks, and markup
+func subtitleText(content string) string {
+	content = strings.TrimPrefix(strings.ReplaceAll(content, "\r\n", "\n"), "\ufeff")
+
+	var lines []dialogueLine
+	for _, block := range strings.Split(content, "\n\n") {
+		blockLines := strings.Split(strings.Trim(block, "\n"), "\n")
+		first := strings.TrimSpace(blockLines[0])
+		if first == "" || strings.HasPrefix(first, "WEBVTT") || strings.HasPrefix(first, "NOTE") ||
+			strings.HasPrefix(first, "STYLE") || strings.HasPrefix(first, "REGION") {
+			continue
+		}
+
+		// Text follows the timing line; anything before it is a cue identifier
+		textStart := -1
+		for i, line := range blockLines {
+			if cueTimingPattern.MatchString(line) {
+				textStart = i + 1
+				break
+			}
+		}
+		if textStart < 0 {
+			continue
+		}
+
+		for _, line := range blockLines[textStart:] {
+			line = strings.TrimSpace(line)
+			if line == "" || cueIndexPattern.MatchString(line) {
+				continue
+			}
+			speaker := ""
+			if match := voiceTagPattern.FindStringSubmatch(line); match != nil {
+				speaker = strings.TrimSpace(match[1])
+			}
+			text := strings.TrimSpace(markupTagPattern.ReplaceAllString(line, ""))
+			if text != "" {
+				lines = append(lines, dialogueLine{speaker: speaker, text: text})
+			}
+		}
+	}
+	return joinDialogue(lines)
+}
+
+// whisperText extracts segment text (with speakers when diarized) or the full text
+func whisperText(data []byte) (string, error) {
+	var parsed whisperTranscript
+	if err := json.Unmarshal(data, &parsed); err != nil {
+		return "", fmt.Errorf("invalid Whisper JSON: %w", err)
+	}
+
+	if len(parsed.Segments) == 0 {
+		if parsed.Text == nil {
+			return "", nil
+		}
+		return strings.TrimSpace(*parsed.Text) + "\n", nil
+	}
+
+	lines := make([]dialogueLine, 0, len(parsed.Segments))
+	for _, segment := range parsed.Segments {
+		if text := strings.TrimSpace(segment.Text); text != "" {
+			lines = append(lines, dialogueLine{speaker: segment.Speaker, text: text})
+		}
+	}
+	return joinDialogue(lines), nil
+}
+
+// dialogueLine is one utterance, optionally attributed to a speaker
+type dialogueLine struct {
+	speaker string
+	text    string
+}
+
+// joinDialogue writes one line per utterance, merging consecutive lines from
+// the same speaker and dropping repeats left by rolling captions
+func joinDialogue(lines []dialogueLine) string {
+	var merged []dialogueLine
+	for _, line := range lines {
+		if n := len(merged); n > 0 {
+			last := &merged[n-1]
+			if last.speaker == line.speaker && last.text == line.text {
+				continue
+			}
+			if line.speaker != "" && last.speaker == line.speaker {
+				last.text += " " + line.text
+				continue
+			}
+		}
+		merged = append(merged, line)
+	}
+
+	var sb strings.Builder
+	for _, line := range merged {
+		if line.speaker != "" {
+			sb.WriteString(line.speaker + ": ")
+		}
+		sb.WriteString(line.text + "\n")
+	}
+	return sb.String()
+}
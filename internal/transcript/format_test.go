@@ -0,0 +1,106 @@
+package transcript
+
+import (
+	"testing"
+)
+
+func TestDetectFormat(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+		data string
+		want Format
+	}{
+		{"txt", "meeting.txt", "WEBVTT\n\n00:01.000 --> 00:02.000\nhi", FormatText},
+		{"md", "meeting.md", "# Notes", FormatText},
+		{"srt_ext", "meeting.srt", "1\n00:00:01,000 --> 00:00:02,000\nhi", FormatSRT},
+		{"vtt_ext", "meeting.vtt", "WEBVTT", FormatVTT},
+		{"whisper_ext", "meeting.json", `{"text":"hi","segments":[]}`, FormatWhisperJSON},
+		{"other_json", "meeting.json", `{"notes":"hi"}`, FormatText},
+		{"vtt_sniffed", "meeting", "\ufeffWEBVTT\n\n00:01.000 --> 00:02.000\nhi", FormatVTT},
+		{"srt_sniffed", "meeting.sub", "1\r\n00:00:01,000 --> 00:00:02,000\r\nhi", FormatSRT},
+		{"whisper_sniffed", "meeting.out", `{"segments":[{"text":"hi"}]}`, FormatWhisperJSON},
+		{"plain_sniffed", "meeting", "Alice: hi", FormatText},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DetectFormat(tt.path, []byte(tt.data)); got != tt.want {
+				t.Errorf("DetectFormat(%q) = %q, want %q", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalize_SRT(t *testing.T) {
+	srt := "1\r\n00:00:01,000 --> 00:00:04,000\r\nThe user opens the login page.\r\n\r\n" +
+		"2\r\n00:00:04,500 --> 00:00:07,000\r\n<i>They enter</i> their password\r\nand submit.\r\n\r\n" +
+		"3\r\n00:00:07,000 --> 00:00:08,000\r\nand submit.\r\n"
+
+	text, format, err := Normalize("meeting.srt", []byte(srt))
+	if err != nil || format != FormatSRT {
+		t.Fatalf("Unexpected result: %q, %v", format, err)
+	}
+	want := "The user opens the login page.\nThey enter their password\nand submit.\n"
+	if text != want {
+		t.Errorf("Expected:\n%q\ngot:\n%q", want, text)
+	}
+}
+
+func TestNormalize_VTT(t *testing.T) {
+	vtt := `WEBVTT
+Kind: captions
+
+NOTE This is a comment
+
+STYLE
+::cue { color: white }
+
+intro
+00:00:01.000 --> 00:00:03.000 align:start
+<v Alice>We need to document checkout.</v>
+
+00:03.000 --> 00:05.000
+<v.loud Alice>It starts at the <c.yellow>cart</c>.
+
+00:05.000 --> 00:07.000
+<v Bob>Then payment runs.
+`
+
+	text, format, err := Normalize("meeting.vtt", []byte(vtt))
+	if err != nil || format != FormatVTT {
+		t.Fatalf("Unexpected result: %q, %v", format, err)
+	}
+	want := "Alice: We need to document checkout. It starts at the cart.\nBob: Then payment runs.\n"
+	if text != want {
+		t.Errorf("Expected:\n%q\ngot:\n%q", want, text)
+	}
+}
+
+func TestNormalize_WhisperJSON(t *testing.T) {
+	diarized := `{"text":" full text","segments":[
+		{"start":0,"end":2,"text":" Orders are created in the API.","speaker":"SPEAKER_00"},
+		{"start":2,"end":4,"text":" Then stored.","speaker":"SPEAKER_00"},
+		{"start":4,"end":6,"text":" What about refunds?","speaker":"SPEAKER_01"}
+	]}`
+	text, format, err := Normalize("meeting.json", []byte(diarized))
+	if err != nil || format != FormatWhisperJSON {
+		t.Fatalf("Unexpected result: %q, %v", format, err)
+	}
+	want := "SPEAKER_00: Orders are created in the API. Then stored.\nSPEAKER_01: What about refunds?\n"
+	if text != want {
+		t.Errorf("Expected:\n%q\ngot:\n%q", want, text)
+	}
+
+	text, _, err = Normalize("meeting.json", []byte(`{"text":" Just the text. "}`))
+	if err != nil || text != "Just the text.\n" {
+		t.Errorf("Expected full text fallback, got %q (%v)", text, err)
+	}
+}
+
+func TestNormalize_TextPassthrough(t *testing.T) {
+	input := "1\n00:00:01,000 --> 00:00:02,000\nkept as is"
+	text, format, err := Normalize("notes.txt", []byte(input))
+	if err != nil || format != FormatText || text != input {
+		t.Errorf("Expected .txt passthrough, got %q, %q, %v", text, format, err)
+	}
+}
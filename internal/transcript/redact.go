@@ -0,0 +1,30 @@
+package transcript
+
+import "regexp"
+
+// piiPatterns are the kinds of personal data RedactPII replaces, in order:
+// card numbers and SSNs come before phone numbers, which could match parts of them
+var piiPatterns = []struct {
+	pattern     *regexp.Regexp
+	replacement string
+}{
+	{regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`), "[EMAIL]"},
+	{regexp.MustCompile(`\b(?:\d[ -]?){12,15}\d\b`), "[CARD_NUMBER]"},
+	{regexp.MustCompile(`\b\d{3}-\d{2}-\d{4}\b`), "[SSN]"},
+	{regexp.MustCompile(`(?:\+\d{1,3}[ .-]?)?(?:\(\d{3}\)|\b\d{3})[ .-]\d{3}[ .-]\d{4}\b`), "[PHONE]"},
+}
+
+// RedactPII replaces email addresses, card numbers, US social security
+// numbers, and phone numbers with placeholders such as [EMAIL], and returns
+// the redacted text with the number of replacements. Matching is
+// conservative: numbers need their usual separators to count as phone numbers.
+func RedactPII(text string) (string, int) {
+	redactions := 0
+	for _, pii := range piiPatterns {
+		text = pii.pattern.ReplaceAllStringFunc(text, func(string) string {
+			redactions++
+			return pii.replacement
+		})
+	}
+	return text, redactions
+}
@@ -0,0 +1,27 @@
+package transcript
+
+import "testing"
+
+func TestRedactPII(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+		count int
+	}{
+		{"email", "Send it to jane.doe+docs@example.co.uk today", "Send it to [EMAIL] today", 1},
+		{"phone", "Call (555) 123-4567 or +1 555.987.6543", "Call [PHONE] or [PHONE]", 2},
+		{"card", "Card 4111 1111 1111 1111 was charged", "Card [CARD_NUMBER] was charged", 1},
+		{"ssn", "SSN 123-45-6789 on file", "SSN [SSN] on file", 1},
+		{"plain numbers", "Order 12345 shipped in 2024 with 3 items", "Order 12345 shipped in 2024 with 3 items", 0},
+		{"no pii", "User logs in with email and password.", "User logs in with email and password.", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, count := RedactPII(tt.input)
+			if got != tt.want || count != tt.count {
+				t.Errorf("Expected %q (%d), got %q (%d)", tt.want, tt.count, got, count)
+			}
+		})
+	}
+}
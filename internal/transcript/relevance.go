@@ -0,0 +1,57 @@
+package transcript
+
+import (
+	"math"
+	"sort"
+)
+
+// DefaultRelevantFraction is the share of chunks the semantic filter keeps
+const DefaultRelevantFraction = 0.5
+
+// CosineSimilarity returns the cosine of the angle between two vectors, or 0
+// when either is empty, zero, or the lengths differ
+func CosineSimilarity(a, b []float32) float64 {
+	if len(a) == 0 || len(a) != len(b) {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
+
+// SelectRelevant scores each chunk by its best similarity to any query and
+// returns the indices of the highest-scoring fraction of chunks (at least
+// one), in their original order
+func SelectRelevant(chunkVectors, queryVectors [][]float32, fraction float64) []int {
+	if len(chunkVectors) == 0 {
+		return nil
+	}
+
+	scores := make([]float64, len(chunkVectors))
+	for i, chunk := range chunkVectors {
+		scores[i] = math.Inf(-1)
+		for _, query := range queryVectors {
+			scores[i] = max(scores[i], CosineSimilarity(chunk, query))
+		}
+	}
+
+	keep := int(math.Ceil(fraction * float64(len(chunkVectors))))
+	keep = max(1, min(keep, len(chunkVectors)))
+
+	ranked := make([]int, len(chunkVectors))
+	for i := range ranked {
+		ranked[i] = i
+	}
+	sort.SliceStable(ranked, func(i, j int) bool { return scores[ranked[i]] > scores[ranked[j]] })
+
+	kept := ranked[:keep]
+	sort.Ints(kept)
+	return kept
+}
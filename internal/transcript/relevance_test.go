@@ -0,0 +1,45 @@
+package transcript
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestCosineSimilarity(t *testing.T) {
+	if got := CosineSimilarity([]float32{1, 0}, []float32{1, 0}); math.Abs(got-1) > 1e-9 {
+		t.Errorf("Expected identical vectors to score 1, got %f", got)
+	}
+	if got := CosineSimilarity([]float32{1, 0}, []float32{0, 1}); math.Abs(got) > 1e-9 {
+		t.Errorf("Expected orthogonal vectors to score 0, got %f", got)
+	}
+	if got := CosineSimilarity([]float32{1, 0}, []float32{1, 0, 0}); got != 0 {
+		t.Errorf("Expected mismatched lengths to score 0, got %f", got)
+	}
+	if got := CosineSimilarity([]float32{0, 0}, []float32{1, 0}); got != 0 {
+		t.Errorf("Expected a zero vector to score 0, got %f", got)
+	}
+}
+
+func TestSelectRelevant(t *testing.T) {
+	chunks := [][]float32{
+		{0, 1},     // off-topic
+		{1, 0},     // matches the first query
+		{0.1, 1},   // mostly off-topic
+		{0.7, 0.7}, // matches the second query
+	}
+	queries := [][]float32{{1, 0}, {1, 1}}
+
+	if got := SelectRelevant(chunks, queries, 0.5); !reflect.DeepEqual(got, []int{1, 3}) {
+		t.Errorf("Expected chunks 1 and 3 in order, got %v", got)
+	}
+	if got := SelectRelevant(chunks, queries, 0); !reflect.DeepEqual(got, []int{1}) {
+		t.Errorf("Expected at least one chunk to be kept, got %v", got)
+	}
+	if got := SelectRelevant(chunks, queries, 1); len(got) != len(chunks) {
+		t.Errorf("Expected every chunk to be kept, got %v", got)
+	}
+	if got := SelectRelevant(nil, queries, 0.5); got != nil {
+		t.Errorf("Expected no chunks, got %v", got)
+	}
+}
@@ -0,0 +1,44 @@
+package transcript
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// SummarySuffix is appended to a transcript's base name to name its summary
+const SummarySuffix = ".summary.txt"
+
+// SummaryPath returns the summary file for a transcript path, next to the
+// transcript: transcripts/meeting.txt -> transcripts/meeting.summary.txt
+func SummaryPath(transcriptPath string) string {
+	dir := filepath.Dir(transcriptPath)
+	base := filepath.Base(transcriptPath)
+	name := strings.TrimSuffix(base, filepath.Ext(base))
+	return filepath.Join(dir, name+SummarySuffix)
+}
+
+// SummaryPrompt builds the prompt for condensing a transcript (or one part of
+// it) into a structured summary that keeps what documentation needs
+func SummaryPrompt(text string, part, total int) string {
+	var sb strings.Builder
+	sb.WriteString(`Summarize the following application transcript for a documentation writer who will draw Mermaid diagrams from your summary alone.
+
+Keep every detail needed for diagrams and drop small talk. Use exactly these sections in plain text:
+
+OVERVIEW: 2-4 sentences on what the application does.
+ACTORS AND COMPONENTS: users, roles, services, databases, and external systems.
+FLOWS: each user or system flow as a numbered list of steps (who does what to whom).
+DATA ENTITIES: entities with their key attributes and relationships.
+DECISIONS AND CONSTRAINTS: technical decisions, rules, and limits mentioned.
+OPEN QUESTIONS: anything unresolved or ambiguous.
+
+Write "none" under a section with nothing to report. Do not invent details.
+`)
+	if total > 1 {
+		sb.WriteString(fmt.Sprintf("\nThis is part %d of %d of a longer transcript; summarize only this part.\n", part, total))
+	}
+	sb.WriteString("\nTRANSCRIPT:\n")
+	sb.WriteString(text)
+	return sb.String()
+}
@@ -0,0 +1,39 @@
+package transcript
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSummaryPath(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"meeting.txt", "meeting.summary.txt"},
+		{"transcripts/meeting.txt", filepath.Join("transcripts", "meeting.summary.txt")},
+		{"/abs/path/call.md", filepath.Join("/abs/path", "call.summary.txt")},
+		{"notes", "notes.summary.txt"},
+	}
+
+	for _, tt := range tests {
+		if got := SummaryPath(tt.input); got != tt.expected {
+			t.Errorf("SummaryPath(%q) = %q, expected %q", tt.input, got, tt.expected)
+		}
+	}
+}
+
+func TestSummaryPrompt(t *testing.T) {
+	prompt := SummaryPrompt("User logs in.", 1, 1)
+	if !strings.Contains(prompt, "FLOWS:") || !strings.HasSuffix(prompt, "User logs in.") {
+		t.Errorf("Unexpected prompt:\n%s", prompt)
+	}
+	if strings.Contains(prompt, "part 1 of 1") {
+		t.Error("Single-part prompts should not mention parts")
+	}
+
+	if !strings.Contains(SummaryPrompt("text", 2, 3), "part 2 of 3") {
+		t.Error("Expected multi-part prompt to name the part")
+	}
+}
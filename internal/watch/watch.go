@@ -0,0 +1,74 @@
+package watch
+
+import (
+	"context"
+	"os"
+	"time"
+)
+
+const (
+	// DefaultInterval is how often the file is checked for changes
+	DefaultInterval = 500 * time.Millisecond
+	// DefaultDebounce is how long the file must stay unchanged before a change fires
+	DefaultDebounce = time.Second
+)
+
+// Options controls how a file is watched
+type Options struct {
+	Interval time.Duration
+	Debounce time.Duration
+}
+
+// fileState is the part of a file's metadata used to detect modifications
+type fileState struct {
+	exists  bool
+	modTime time.Time
+	size    int64
+}
+
+func stat(path string) fileState {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileState{}
+	}
+	return fileState{exists: true, modTime: info.ModTime(), size: info.Size()}
+}
+
+// File polls path for modifications and calls onChange once the file has been
+// stable for the debounce period, so a burst of rapid saves triggers one call.
+// onChange runs on the watching goroutine; changes made while it runs are
+// picked up afterwards. File blocks until ctx is cancelled.
+func File(ctx context.Context, path string, opts Options, onChange func()) error {
+	if opts.Interval <= 0 {
+		opts.Interval = DefaultInterval
+	}
+	if opts.Debounce < 0 {
+		opts.Debounce = 0
+	}
+
+	last := stat(path)
+	var pendingSince time.Time
+
+	ticker := time.NewTicker(opts.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case now := <-ticker.C:
+			current := stat(path)
+			if current != last {
+				last = current
+				pendingSince = now
+				continue
+			}
+			// Deleted files (e.g. mid atomic save) fire once they reappear
+			if !pendingSince.IsZero() && current.exists && now.Sub(pendingSince) >= opts.Debounce {
+				pendingSince = time.Time{}
+				onChange()
+				last = stat(path)
+			}
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package watch
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestFile_DebouncesRapidWrites(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "transcript.txt")
+	if err := os.WriteFile(path, []byte("v0"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var calls int32
+	done := make(chan error, 1)
+	go func() {
+		done <- File(ctx, path, Options{Interval: 10 * time.Millisecond, Debounce: 100 * time.Millisecond}, func() {
+			atomic.AddInt32(&calls, 1)
+		})
+	}()
+
+	time.Sleep(30 * time.Millisecond)
+	// Several quick saves with growing sizes so each is detectable
+	for i := 1; i <= 5; i++ {
+		if err := os.WriteFile(path, []byte("v"+string(make([]byte, i))), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	time.Sleep(400 * time.Millisecond)
+	if got := atomic.LoadInt32(&calls); got != 1 {
+		t.Errorf("Expected 1 debounced change, got %d", got)
+	}
+
+	cancel()
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected context.Canceled, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("File did not return after cancellation")
+	}
+}
+
+func TestFile_NoChange(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "transcript.txt")
+	if err := os.WriteFile(path, []byte("stable"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 150*time.Millisecond)
+	defer cancel()
+
+	called := false
+	File(ctx, path, Options{Interval: 10 * time.Millisecond, Debounce: 20 * time.Millisecond}, func() {
+		called = true
+	})
+	if called {
+		t.Error("Expected no change callback for an unmodified file")
+	}
+}
@@ -0,0 +1,106 @@
+// Package workspace clears the generated outputs, logs, and run snapshots
+// from a project without touching its transcripts.
+package workspace
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ProtectedDirs are project directories that are never cleared or deleted
+var ProtectedDirs = []string{"transcripts"}
+
+// CheckTarget returns an error unless dir is strictly inside root and is not,
+// and does not contain, one of root's ProtectedDirs. Symlinks are resolved so
+// a link cannot point the clean outside the project.
+func CheckTarget(root, dir string) error {
+	resolvedRoot, err := resolve(root)
+	if err != nil {
+		return fmt.Errorf("invalid project root %s: %w", root, err)
+	}
+	resolvedDir, err := resolve(dir)
+	if err != nil {
+		return fmt.Errorf("invalid directory %s: %w", dir, err)
+	}
+
+	if !within(resolvedRoot, resolvedDir) || resolvedDir == resolvedRoot {
+		return fmt.Errorf("refusing to clean %s: it is not inside %s", dir, root)
+	}
+	for _, name := range ProtectedDirs {
+		protected := filepath.Join(resolvedRoot, name)
+		if within(protected, resolvedDir) || within(resolvedDir, protected) {
+			return fmt.Errorf("refusing to clean %s: it holds or is inside %s/", dir, name)
+		}
+	}
+	return nil
+}
+
+// Entries returns the paths of dir's top-level entries, sorted, skipping the
+// names in skip. A missing directory has no entries.
+func Entries(dir string, skip ...string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	skipped := make(map[string]bool, len(skip))
+	for _, name := range skip {
+		skipped[name] = true
+	}
+	var paths []string
+	for _, entry := range entries {
+		if !skipped[entry.Name()] {
+			paths = append(paths, filepath.Join(dir, entry.Name()))
+		}
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// Remove deletes each path (directories recursively) and returns how many
+// were removed before any error
+func Remove(paths []string) (int, error) {
+	for i, path := range paths {
+		if err := os.RemoveAll(path); err != nil {
+			return i, fmt.Errorf("failed to remove %s: %w", path, err)
+		}
+	}
+	return len(paths), nil
+}
+
+// resolve returns the absolute path with symlinks resolved. A path that does
+// not exist yet is resolved through its closest existing parent.
+func resolve(path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	resolved, err := filepath.EvalSymlinks(abs)
+	if err == nil {
+		return resolved, nil
+	}
+	if !os.IsNotExist(err) {
+		return "", err
+	}
+	parent := filepath.Dir(abs)
+	if parent == abs {
+		return abs, nil
+	}
+	resolvedParent, err := resolve(parent)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(resolvedParent, filepath.Base(abs)), nil
+}
+
+// within reports whether path is dir or inside it
+func within(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
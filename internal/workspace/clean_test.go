@@ -0,0 +1,66 @@
+package workspace
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCheckTarget(t *testing.T) {
+	root := t.TempDir()
+	for _, dir := range []string{"out", "logs", "transcripts"} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	outside := t.TempDir()
+	if err := os.Symlink(outside, filepath.Join(root, "linked")); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := map[string]string{
+		filepath.Join(root, "out"):                  "",
+		filepath.Join(root, "logs", "runs"):         "", // may not exist yet
+		root:                                        "not inside",
+		filepath.Join(root, ".."):                   "not inside",
+		filepath.Join(root, "transcripts"):          "transcripts/",
+		filepath.Join(root, "transcripts", "old"):   "transcripts/",
+		filepath.Join(root, "linked"):               "not inside",
+		filepath.Join(root, "out", "..", "..", "x"): "not inside",
+	}
+	for dir, expected := range cases {
+		err := CheckTarget(root, dir)
+		if expected == "" && err != nil {
+			t.Errorf("CheckTarget(%s) unexpected error: %v", dir, err)
+		}
+		if expected != "" && (err == nil || !strings.Contains(err.Error(), expected)) {
+			t.Errorf("CheckTarget(%s) = %v, expected an error containing %q", dir, err, expected)
+		}
+	}
+}
+
+func TestEntriesAndRemove(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a.md", "b.svg", "runs/r1/record.json"} {
+		path := filepath.Join(dir, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	paths, err := Entries(dir, "runs")
+	if err != nil || len(paths) != 2 || filepath.Base(paths[0]) != "a.md" || filepath.Base(paths[1]) != "b.svg" {
+		t.Fatalf("Expected a.md and b.svg, got %v (%v)", paths, err)
+	}
+	if removed, err := Remove(paths); err != nil || removed != 2 {
+		t.Fatalf("Expected 2 removed, got %d (%v)", removed, err)
+	}
+	if remaining, _ := Entries(dir); len(remaining) != 1 || filepath.Base(remaining[0]) != "runs" {
+		t.Errorf("Expected only runs/ to remain, got %v", remaining)
+	}
+	if missing, err := Entries(filepath.Join(dir, "missing")); err != nil || missing != nil {
+		t.Errorf("Expected no entries for a missing directory, got %v (%v)", missing, err)
+	}
+}